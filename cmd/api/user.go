@@ -10,7 +10,10 @@ import (
 
 	"usermgmt/internal/config"
 	"usermgmt/internal/handler"
+	"usermgmt/internal/lifecycle"
+	"usermgmt/internal/logging"
 	"usermgmt/internal/svc"
+	"usermgmt/internal/worker"
 )
 
 var configFile = flag.String("f", "etc/user-api.yaml", "the config file")
@@ -22,18 +25,27 @@ func main() {
 	var c config.Config
 	conf.MustLoad(*configFile, &c)
 	logx.MustSetup(c.Log)
+	logging.InstallRedaction()
 
 	svcCtx := svc.NewServiceContext(c)
 	if err := svcCtx.AutoMigrate(); err != nil {
 		panic(fmt.Sprintf("failed to migrate database: %v", err))
 	}
 
+	// middleware.RequestMiddleware writes the access log; go-zero's own would duplicate it and dumps
+	// the request headers of failed requests.
+	c.Middlewares.Log = false
 	server := rest.MustNewServer(c.RestConf, rest.WithCors(c.Security.AllowOrigins...))
-
-	defer server.Stop()
-
 	handler.RegisterHandlers(server, svcCtx)
 
+	runner := lifecycle.NewRunner(svcCtx, server)
+	runner.Add(worker.NewRoleExpirySweeper(svcCtx))
+	runner.Add(worker.NewPolicyReloader(svcCtx))
+	runner.Add(worker.NewReviewCloser(svcCtx))
+	runner.Add(worker.NewOutboxRelay(svcCtx))
+	runner.Add(worker.NewWebhookSender(svcCtx))
+
 	fmt.Printf("Starting server at %s:%d...\n", c.Host, c.Port)
-	server.Start()
+	runner.Run()
+	logx.Close()
 }
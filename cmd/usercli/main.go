@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"usermgmt/internal/config"
+	"usermgmt/internal/errorx"
+	adminlogic "usermgmt/internal/logic/admin"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/types"
+)
+
+var configFile = flag.String("f", "etc/user-api.yaml", "the config file")
+
+const usage = `usage: usercli [-f config] <command> [flags]
+
+commands:
+  import  [-format csv|ndjson] [-mode create|upsert] [-dry-run] <file|->
+  export  [-format csv|ndjson] [-keyword kw] [-status enabled|disabled] [-o file]
+`
+
+// main runs offline user administration tasks against the configured database.
+func main() {
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var c config.Config
+	conf.MustLoad(*configFile, &c)
+	logx.MustSetup(c.Log)
+
+	svcCtx := svc.NewServiceContext(c)
+	ctx := context.Background()
+
+	var err error
+	switch cmd, args := flag.Arg(0), flag.Args()[1:]; cmd {
+	case "import":
+		err = runImport(ctx, svcCtx, args)
+	case "export":
+		err = runExport(ctx, svcCtx, args)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		var appErr *errorx.AppError
+		if errors.As(err, &appErr) && appErr.Details != nil {
+			fmt.Fprintf(os.Stderr, "usercli: %s: %v\n", appErr.Message, appErr.Details)
+		} else {
+			fmt.Fprintf(os.Stderr, "usercli: %v\n", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func runImport(ctx context.Context, svcCtx *svc.ServiceContext, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	format := fs.String("format", "", "input format: csv or ndjson (default from file extension)")
+	mode := fs.String("mode", adminlogic.ImportModeCreate, "create or upsert")
+	dryRun := fs.Bool("dry-run", false, "validate and report without writing")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("import expects exactly one input file")
+	}
+
+	path := fs.Arg(0)
+	var src io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		src = file
+	}
+
+	req := &types.ImportUsersRequest{
+		Format: adminlogic.ResolveFormat(*format, path),
+		Mode:   *mode,
+		DryRun: *dryRun,
+	}
+	if err := svcCtx.Validator.StructCtx(ctx, req); err != nil {
+		return err
+	}
+
+	resp, err := adminlogic.NewImportUsersLogic(ctx, svcCtx).Import(src, req)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(resp); err != nil {
+		return err
+	}
+	if resp.Failed > 0 {
+		return fmt.Errorf("%d of %d rows failed", resp.Failed, resp.Total)
+	}
+	return nil
+}
+
+func runExport(ctx context.Context, svcCtx *svc.ServiceContext, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", adminlogic.FormatCSV, "output format: csv or ndjson")
+	keyword := fs.String("keyword", "", "keyword filter on username/email/full name")
+	status := fs.String("status", "", "status filter: enabled or disabled")
+	output := fs.String("o", "-", "output file, - for stdout")
+	_ = fs.Parse(args)
+
+	req := &types.ExportUsersRequest{
+		Format:  *format,
+		Keyword: *keyword,
+		Status:  *status,
+	}
+	if err := svcCtx.Validator.StructCtx(ctx, req); err != nil {
+		return err
+	}
+
+	var dst io.Writer = os.Stdout
+	if *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		dst = file
+	}
+
+	return adminlogic.NewExportUsersLogic(ctx, svcCtx).Export(dst, req)
+}
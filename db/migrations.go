@@ -0,0 +1,41 @@
+// Package db embeds the SQL migrations so that the service knows which schema version it expects.
+package db
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+)
+
+//go:embed migrations/*/*.sql
+var migrations embed.FS
+
+// LatestVersion returns the version of the newest migration for driver, i.e. the numeric prefix
+// of the last file in migrations/<driver> (013 for 013_auth_source.sql).
+func LatestVersion(driver string) (uint, error) {
+	entries, err := fs.ReadDir(migrations, path.Join("migrations", driver))
+	if err != nil {
+		return 0, fmt.Errorf("no migrations for driver %q: %w", driver, err)
+	}
+	var latest uint
+	for _, entry := range entries {
+		prefix, _, ok := strings.Cut(entry.Name(), "_")
+		if !ok {
+			continue
+		}
+		version, err := strconv.ParseUint(prefix, 10, 32)
+		if err != nil {
+			continue
+		}
+		if uint(version) > latest {
+			latest = uint(version)
+		}
+	}
+	if latest == 0 {
+		return 0, fmt.Errorf("no migrations for driver %q", driver)
+	}
+	return latest, nil
+}
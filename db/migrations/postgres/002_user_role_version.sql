@@ -0,0 +1,6 @@
+-- Optimistic concurrency for role assignment (ETag / If-Match)
+BEGIN;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role_version BIGINT NOT NULL DEFAULT 0;
+
+COMMIT;
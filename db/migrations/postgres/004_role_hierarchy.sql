@@ -0,0 +1,16 @@
+-- Hierarchical roles: a role inherits every permission of its parent
+BEGIN;
+
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS parent_id BIGINT;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_roles_parent') THEN
+        ALTER TABLE roles
+            ADD CONSTRAINT fk_roles_parent FOREIGN KEY (parent_id) REFERENCES roles(id) ON DELETE SET NULL;
+    END IF;
+END$$;
+
+CREATE INDEX IF NOT EXISTS idx_roles_parent_id ON roles(parent_id);
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Department attribute used by ABAC policies
+BEGIN;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS department VARCHAR(100) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_users_department ON users(department);
+
+COMMIT;
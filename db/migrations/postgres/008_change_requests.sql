@@ -0,0 +1,24 @@
+-- Two-person approval: sensitive admin changes wait here for a second admin
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS change_requests (
+    id BIGSERIAL PRIMARY KEY,
+    action VARCHAR(64) NOT NULL,
+    target_user_id BIGINT NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    requested_by BIGINT NOT NULL,
+    reviewed_by BIGINT,
+    comment VARCHAR(500),
+    error VARCHAR(255),
+    expires_at TIMESTAMPTZ NOT NULL,
+    reviewed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_change_requests_target_user_id ON change_requests(target_user_id);
+CREATE INDEX IF NOT EXISTS idx_change_requests_status ON change_requests(status);
+CREATE INDEX IF NOT EXISTS idx_change_requests_expires_at ON change_requests(expires_at);
+
+COMMIT;
@@ -0,0 +1,42 @@
+-- Access review campaigns: periodic certification of direct role grants
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS access_reviews (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    roles VARCHAR(500) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    deadline TIMESTAMPTZ NOT NULL,
+    auto_revoke BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by BIGINT,
+    closed_by BIGINT,
+    closed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_access_reviews_status ON access_reviews(status);
+CREATE INDEX IF NOT EXISTS idx_access_reviews_deadline ON access_reviews(deadline);
+
+-- Items keep no foreign keys to users/roles so the signed-off record outlives deletions.
+CREATE TABLE IF NOT EXISTS access_review_items (
+    id BIGSERIAL PRIMARY KEY,
+    review_id BIGINT NOT NULL REFERENCES access_reviews(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL,
+    role_id BIGINT NOT NULL,
+    username VARCHAR(50) NOT NULL,
+    role_name VARCHAR(50) NOT NULL,
+    grant_expires_at TIMESTAMPTZ,
+    reviewer_id BIGINT,
+    decision VARCHAR(10) NOT NULL DEFAULT '',
+    decided_by BIGINT,
+    decided_at TIMESTAMPTZ,
+    comment VARCHAR(500),
+    outcome VARCHAR(20),
+    error VARCHAR(64)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_access_review_item ON access_review_items(review_id, user_id, role_id);
+CREATE INDEX IF NOT EXISTS idx_access_review_items_reviewer_id ON access_review_items(reviewer_id);
+
+COMMIT;
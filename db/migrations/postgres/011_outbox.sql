@@ -0,0 +1,24 @@
+-- Transactional outbox: domain events written with the change they describe
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_id VARCHAR(64) NOT NULL,
+    type VARCHAR(64) NOT NULL,
+    aggregate_key VARCHAR(64),
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    sinks VARCHAR(255) NOT NULL DEFAULT '',
+    last_error VARCHAR(500),
+    published_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_event_id ON outbox(event_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_status ON outbox(status);
+CREATE INDEX IF NOT EXISTS idx_outbox_next_attempt_at ON outbox(next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_published_at ON outbox(published_at);
+
+COMMIT;
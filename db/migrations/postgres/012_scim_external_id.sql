@@ -0,0 +1,10 @@
+-- SCIM provisioning: identifiers assigned by the identity provider
+BEGIN;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS external_id VARCHAR(255);
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS external_id VARCHAR(255);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_external_id ON users(external_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_roles_external_id ON roles(external_id);
+
+COMMIT;
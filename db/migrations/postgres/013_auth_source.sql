@@ -0,0 +1,6 @@
+-- Authentication source: the provider that owns the account (local password or LDAP)
+BEGIN;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS auth_source VARCHAR(20) NOT NULL DEFAULT 'local';
+
+COMMIT;
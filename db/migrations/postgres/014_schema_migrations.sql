@@ -0,0 +1,14 @@
+-- Schema version: the migrations that have been applied, so that /readyz can tell a database that
+-- is behind the running binary. Every later migration inserts its own version.
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT PRIMARY KEY,
+    applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO schema_migrations (version)
+SELECT generate_series(1, 14)
+ON CONFLICT (version) DO NOTHING;
+
+COMMIT;
@@ -0,0 +1,283 @@
+-- SQLite schema for user management and RBAC, equivalent to postgres/001-014.
+-- Columns and constraint names follow what GORM's SQLite migrator generates for internal/model, so
+-- the AutoMigrate run at startup finds nothing to change and does not rebuild tables. On top of
+-- that, this file adds the ON DELETE actions and foreign keys the models do not declare.
+-- SQLite does not enforce VARCHAR lengths or ENUMs; the service validates both before writing.
+-- Foreign keys are only enforced when enabled per connection (the service adds
+-- _pragma=foreign_keys(1) to the DSN).
+PRAGMA foreign_keys = ON;
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS `users` (
+    `id`            integer PRIMARY KEY AUTOINCREMENT,
+    `username`      text NOT NULL,
+    `external_id`   text,
+    `email`         text NOT NULL,
+    `password_hash` text NOT NULL,
+    `auth_source`   text NOT NULL DEFAULT "local",
+    `full_name`     text,
+    `department`    text,
+    `status`        text DEFAULT "enabled",
+    `last_login_at` datetime,
+    `role_version`  integer NOT NULL DEFAULT 0,
+    `created_at`    datetime,
+    `updated_at`    datetime,
+    CONSTRAINT `chk_users_status` CHECK (`status` IN ('enabled', 'disabled'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_users_username` ON `users`(`username`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_users_email` ON `users`(`email`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_users_external_id` ON `users`(`external_id`);
+CREATE INDEX IF NOT EXISTS `idx_users_department` ON `users`(`department`);
+CREATE INDEX IF NOT EXISTS `idx_users_last_login_at` ON `users`(`last_login_at`);
+CREATE INDEX IF NOT EXISTS `idx_users_status` ON `users`(`status`);
+CREATE INDEX IF NOT EXISTS `idx_users_created_at` ON `users`(`created_at` DESC);
+
+CREATE TABLE IF NOT EXISTS `roles` (
+    `id`          integer PRIMARY KEY AUTOINCREMENT,
+    `name`        text NOT NULL,
+    `external_id` text,
+    `description` text,
+    `parent_id`   integer,
+    `created_at`  datetime,
+    `updated_at`  datetime,
+    CONSTRAINT `fk_roles_parent` FOREIGN KEY (`parent_id`) REFERENCES `roles`(`id`) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_roles_name` ON `roles`(`name`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_roles_external_id` ON `roles`(`external_id`);
+CREATE INDEX IF NOT EXISTS `idx_roles_parent_id` ON `roles`(`parent_id`);
+
+CREATE TABLE IF NOT EXISTS `permissions` (
+    `id`          integer PRIMARY KEY AUTOINCREMENT,
+    `code`        text NOT NULL,
+    `description` text,
+    `created_at`  datetime,
+    `updated_at`  datetime
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_permissions_code` ON `permissions`(`code`);
+
+CREATE TABLE IF NOT EXISTS `user_roles` (
+    `user_id`    integer,
+    `role_id`    integer,
+    `expires_at` datetime,
+    `granted_by` integer,
+    `created_at` datetime,
+    PRIMARY KEY (`user_id`, `role_id`),
+    CONSTRAINT `fk_users_role_grants` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`) ON DELETE CASCADE,
+    CONSTRAINT `fk_user_roles_role` FOREIGN KEY (`role_id`) REFERENCES `roles`(`id`) ON DELETE CASCADE,
+    CONSTRAINT `fk_user_roles_granted_by` FOREIGN KEY (`granted_by`) REFERENCES `users`(`id`) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS `idx_user_roles_role_id` ON `user_roles`(`role_id`);
+CREATE INDEX IF NOT EXISTS `idx_user_roles_expires_at` ON `user_roles`(`expires_at`);
+
+CREATE TABLE IF NOT EXISTS `role_permissions` (
+    `role_id`       integer,
+    `permission_id` integer,
+    `created_at`    datetime,
+    PRIMARY KEY (`role_id`, `permission_id`),
+    CONSTRAINT `fk_role_permissions_role` FOREIGN KEY (`role_id`) REFERENCES `roles`(`id`) ON DELETE CASCADE,
+    CONSTRAINT `fk_role_permissions_permission` FOREIGN KEY (`permission_id`) REFERENCES `permissions`(`id`) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS `audit_logs` (
+    `id`             integer PRIMARY KEY AUTOINCREMENT,
+    `actor_id`       integer,
+    `action`         text NOT NULL,
+    `target_user_id` integer,
+    `details`        text,
+    `created_at`     datetime
+);
+
+CREATE INDEX IF NOT EXISTS `idx_audit_logs_actor_id` ON `audit_logs`(`actor_id`);
+CREATE INDEX IF NOT EXISTS `idx_audit_logs_target_user_id` ON `audit_logs`(`target_user_id`);
+CREATE INDEX IF NOT EXISTS `idx_audit_logs_action` ON `audit_logs`(`action`);
+
+CREATE TABLE IF NOT EXISTS `organizations` (
+    `id`         integer PRIMARY KEY AUTOINCREMENT,
+    `slug`       text NOT NULL,
+    `name`       text NOT NULL,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_organizations_slug` ON `organizations`(`slug`);
+
+CREATE TABLE IF NOT EXISTS `org_members` (
+    `org_id`     integer,
+    `user_id`    integer,
+    `created_at` datetime,
+    PRIMARY KEY (`org_id`, `user_id`),
+    CONSTRAINT `fk_org_members_org` FOREIGN KEY (`org_id`) REFERENCES `organizations`(`id`) ON DELETE CASCADE,
+    CONSTRAINT `fk_org_members_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS `idx_org_members_user_id` ON `org_members`(`user_id`);
+
+CREATE TABLE IF NOT EXISTS `org_member_roles` (
+    `org_id`     integer,
+    `user_id`    integer,
+    `role_id`    integer,
+    `created_at` datetime,
+    PRIMARY KEY (`org_id`, `user_id`, `role_id`),
+    CONSTRAINT `fk_org_member_roles_member` FOREIGN KEY (`org_id`, `user_id`) REFERENCES `org_members`(`org_id`, `user_id`) ON DELETE CASCADE,
+    CONSTRAINT `fk_org_member_roles_role` FOREIGN KEY (`role_id`) REFERENCES `roles`(`id`) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS `idx_org_member_roles_role_id` ON `org_member_roles`(`role_id`);
+
+CREATE TABLE IF NOT EXISTS `user_groups` (
+    `id`          integer PRIMARY KEY AUTOINCREMENT,
+    `name`        text NOT NULL,
+    `description` text,
+    `created_at`  datetime,
+    `updated_at`  datetime
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_user_groups_name` ON `user_groups`(`name`);
+
+CREATE TABLE IF NOT EXISTS `user_group_members` (
+    `group_id`   integer,
+    `user_id`    integer,
+    `created_at` datetime,
+    PRIMARY KEY (`group_id`, `user_id`),
+    CONSTRAINT `fk_user_group_members_group` FOREIGN KEY (`group_id`) REFERENCES `user_groups`(`id`) ON DELETE CASCADE,
+    CONSTRAINT `fk_users_groups` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS `idx_user_group_members_user_id` ON `user_group_members`(`user_id`);
+
+CREATE TABLE IF NOT EXISTS `user_group_roles` (
+    `group_id`   integer,
+    `role_id`    integer,
+    `created_at` datetime,
+    PRIMARY KEY (`group_id`, `role_id`),
+    CONSTRAINT `fk_user_group_roles_group` FOREIGN KEY (`group_id`) REFERENCES `user_groups`(`id`) ON DELETE CASCADE,
+    CONSTRAINT `fk_user_group_roles_role` FOREIGN KEY (`role_id`) REFERENCES `roles`(`id`) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS `idx_user_group_roles_role_id` ON `user_group_roles`(`role_id`);
+
+CREATE TABLE IF NOT EXISTS `change_requests` (
+    `id`             integer PRIMARY KEY AUTOINCREMENT,
+    `action`         text NOT NULL,
+    `target_user_id` integer NOT NULL,
+    `payload`        text NOT NULL,
+    `status`         text NOT NULL,
+    `requested_by`   integer NOT NULL,
+    `reviewed_by`    integer,
+    `comment`        text,
+    `error`          text,
+    `expires_at`     datetime NOT NULL,
+    `reviewed_at`    datetime,
+    `created_at`     datetime,
+    `updated_at`     datetime
+);
+
+CREATE INDEX IF NOT EXISTS `idx_change_requests_status` ON `change_requests`(`status`);
+CREATE INDEX IF NOT EXISTS `idx_change_requests_target_user_id` ON `change_requests`(`target_user_id`);
+CREATE INDEX IF NOT EXISTS `idx_change_requests_expires_at` ON `change_requests`(`expires_at`);
+
+CREATE TABLE IF NOT EXISTS `access_reviews` (
+    `id`          integer PRIMARY KEY AUTOINCREMENT,
+    `name`        text NOT NULL,
+    `roles`       text NOT NULL,
+    `status`      text NOT NULL,
+    `deadline`    datetime NOT NULL,
+    `auto_revoke` numeric NOT NULL DEFAULT false,
+    `created_by`  integer,
+    `closed_by`   integer,
+    `closed_at`   datetime,
+    `created_at`  datetime,
+    `updated_at`  datetime
+);
+
+CREATE INDEX IF NOT EXISTS `idx_access_reviews_status` ON `access_reviews`(`status`);
+CREATE INDEX IF NOT EXISTS `idx_access_reviews_deadline` ON `access_reviews`(`deadline`);
+
+CREATE TABLE IF NOT EXISTS `access_review_items` (
+    `id`               integer PRIMARY KEY AUTOINCREMENT,
+    `review_id`        integer NOT NULL,
+    `user_id`          integer NOT NULL,
+    `role_id`          integer NOT NULL,
+    `username`         text NOT NULL,
+    `role_name`        text NOT NULL,
+    `grant_expires_at` datetime,
+    `reviewer_id`      integer,
+    `decision`         text NOT NULL DEFAULT "",
+    `decided_by`       integer,
+    `decided_at`       datetime,
+    `comment`          text,
+    `outcome`          text,
+    `error`            text,
+    CONSTRAINT `fk_access_reviews_items` FOREIGN KEY (`review_id`) REFERENCES `access_reviews`(`id`) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_access_review_item` ON `access_review_items`(`review_id`, `user_id`, `role_id`);
+CREATE INDEX IF NOT EXISTS `idx_access_review_items_reviewer_id` ON `access_review_items`(`reviewer_id`);
+
+CREATE TABLE IF NOT EXISTS `webhook_subscriptions` (
+    `id`         integer PRIMARY KEY AUTOINCREMENT,
+    `name`       text NOT NULL,
+    `url`        text NOT NULL,
+    `secret`     text NOT NULL,
+    `events`     text NOT NULL,
+    `active`     numeric NOT NULL DEFAULT true,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+
+CREATE TABLE IF NOT EXISTS `webhook_deliveries` (
+    `id`               integer PRIMARY KEY AUTOINCREMENT,
+    `subscription_id`  integer NOT NULL,
+    `event_id`         text NOT NULL,
+    `event`            text NOT NULL,
+    `payload`          text NOT NULL,
+    `status`           text NOT NULL,
+    `attempts`         integer NOT NULL DEFAULT 0,
+    `next_attempt_at`  datetime NOT NULL,
+    `last_status_code` integer,
+    `last_error`       text,
+    `delivered_at`     datetime,
+    `created_at`       datetime,
+    `updated_at`       datetime,
+    CONSTRAINT `fk_webhook_deliveries_subscription` FOREIGN KEY (`subscription_id`) REFERENCES `webhook_subscriptions`(`id`) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS `idx_webhook_deliveries_subscription_id` ON `webhook_deliveries`(`subscription_id`);
+CREATE INDEX IF NOT EXISTS `idx_webhook_deliveries_event_id` ON `webhook_deliveries`(`event_id`);
+CREATE INDEX IF NOT EXISTS `idx_webhook_deliveries_status` ON `webhook_deliveries`(`status`);
+CREATE INDEX IF NOT EXISTS `idx_webhook_deliveries_next_attempt_at` ON `webhook_deliveries`(`next_attempt_at`);
+
+CREATE TABLE IF NOT EXISTS `outbox` (
+    `id`              integer PRIMARY KEY AUTOINCREMENT,
+    `event_id`        text NOT NULL,
+    `type`            text NOT NULL,
+    `aggregate_key`   text,
+    `payload`         text NOT NULL,
+    `status`          text NOT NULL,
+    `attempts`        integer NOT NULL DEFAULT 0,
+    `next_attempt_at` datetime NOT NULL,
+    `sinks`           text NOT NULL DEFAULT "",
+    `last_error`      text,
+    `published_at`    datetime,
+    `created_at`      datetime
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_outbox_event_id` ON `outbox`(`event_id`);
+CREATE INDEX IF NOT EXISTS `idx_outbox_status` ON `outbox`(`status`);
+CREATE INDEX IF NOT EXISTS `idx_outbox_next_attempt_at` ON `outbox`(`next_attempt_at`);
+CREATE INDEX IF NOT EXISTS `idx_outbox_published_at` ON `outbox`(`published_at`);
+
+CREATE TABLE IF NOT EXISTS `schema_migrations` (
+    `version`    integer,
+    `applied_at` datetime NOT NULL,
+    PRIMARY KEY (`version`)
+);
+
+INSERT OR IGNORE INTO `schema_migrations` (`version`, `applied_at`) VALUES (1, CURRENT_TIMESTAMP);
+
+COMMIT;
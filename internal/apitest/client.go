@@ -0,0 +1,271 @@
+package apitest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/middleware"
+	"usermgmt/internal/scim"
+	"usermgmt/internal/types"
+	"usermgmt/pkg/response"
+)
+
+// Request describes a call to the API. A Body that is a string or []byte is sent as-is, anything
+// else is encoded as JSON.
+type Request struct {
+	Method string
+	Path   string
+	Token  string
+	Body   interface{}
+	Header http.Header
+}
+
+// Response is a fully read API response.
+type Response struct {
+	Method string
+	Path   string
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Decode unmarshals the body into v.
+func (r *Response) Decode(tb testing.TB, v interface{}) {
+	tb.Helper()
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		tb.Fatalf("%s %s: decode %s: %v", r.Method, r.Path, r.Body, err)
+	}
+}
+
+// User is an account created through the API, logged in with Password.
+type User struct {
+	ID       uint
+	Username string
+	Token    string
+}
+
+// Send performs req.
+func (h *Harness) Send(tb testing.TB, req Request) *Response {
+	tb.Helper()
+	httpResp, err := h.Server.Client().Do(h.newRequest(tb, context.Background(), req))
+	if err != nil {
+		tb.Fatalf("%s %s: %v", req.Method, req.Path, err)
+	}
+	defer httpResp.Body.Close()
+	raw, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		tb.Fatalf("%s %s: read body: %v", req.Method, req.Path, err)
+	}
+	return &Response{Method: req.Method, Path: req.Path, Status: httpResp.StatusCode, Header: httpResp.Header, Body: raw}
+}
+
+// ServeContext runs req through the routes in-process with ctx, e.g. one that is already cancelled,
+// which a request sent over the network cannot carry.
+func (h *Harness) ServeContext(tb testing.TB, ctx context.Context, req Request) *Response {
+	tb.Helper()
+	recorder := httptest.NewRecorder()
+	h.Server.Config.Handler.ServeHTTP(recorder, h.newRequest(tb, ctx, req))
+	return &Response{Method: req.Method, Path: req.Path, Status: recorder.Code, Header: recorder.Header(), Body: recorder.Body.Bytes()}
+}
+
+func (h *Harness) newRequest(tb testing.TB, ctx context.Context, req Request) *http.Request {
+	tb.Helper()
+	var body io.Reader
+	switch payload := req.Body.(type) {
+	case nil:
+	case string:
+		body = strings.NewReader(payload)
+	case []byte:
+		body = bytes.NewReader(payload)
+	default:
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			tb.Fatalf("%s %s: encode body: %v", req.Method, req.Path, err)
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, h.URL(req.Path), body)
+	if err != nil {
+		tb.Fatalf("%s %s: %v", req.Method, req.Path, err)
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if req.Token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+req.Token)
+	}
+	for key, values := range req.Header {
+		httpReq.Header[key] = values
+	}
+	return httpReq
+}
+
+// Do sends a JSON request on behalf of the holder of token; an empty token sends none.
+func (h *Harness) Do(tb testing.TB, method, path, token string, body interface{}) *Response {
+	tb.Helper()
+	return h.Send(tb, Request{Method: method, Path: path, Token: token, Body: body})
+}
+
+// Get is Do without a body.
+func (h *Harness) Get(tb testing.TB, path, token string) *Response {
+	tb.Helper()
+	return h.Do(tb, http.MethodGet, path, token, nil)
+}
+
+// SCIM sends a request with the SCIM bearer token.
+func (h *Harness) SCIM(tb testing.TB, method, path string, body interface{}) *Response {
+	tb.Helper()
+	return h.Send(tb, Request{Method: method, Path: "/scim/v2" + path, Token: SCIMToken, Body: body})
+}
+
+// Register signs up username with Password and returns the new user.
+func (h *Harness) Register(tb testing.TB, username string) types.UserDTO {
+	tb.Helper()
+	resp := h.Do(tb, http.MethodPost, "/api/v1/auth/register", "", types.RegisterRequest{
+		Username: username,
+		Email:    username + "@example.com",
+		Password: Password,
+		FullName: "User " + username,
+	})
+	var out types.ProfileResponse
+	ExpectOK(tb, resp, &out)
+	return out.User
+}
+
+// Login signs in and returns the access token.
+func (h *Harness) Login(tb testing.TB, username, password string) string {
+	tb.Helper()
+	resp := h.Do(tb, http.MethodPost, "/api/v1/auth/login", "", types.LoginRequest{Username: username, Password: password})
+	var out types.LoginResponse
+	ExpectOK(tb, resp, &out)
+	return out.AccessToken
+}
+
+// CreateUser registers username, grants roles directly and logs in, so the token carries them.
+func (h *Harness) CreateUser(tb testing.TB, username string, roles ...string) *User {
+	tb.Helper()
+	dto := h.Register(tb, username)
+	if len(roles) > 0 {
+		h.GrantRoles(tb, dto.ID, roles...)
+	}
+	user := &User{ID: dto.ID, Username: username, Token: h.Login(tb, username, Password)}
+	h.users[username] = user
+	return user
+}
+
+// Admin returns the scenario's "root" administrator, creating it on first use.
+func (h *Harness) Admin(tb testing.TB) *User {
+	tb.Helper()
+	if user, ok := h.users["root"]; ok {
+		return user
+	}
+	return h.CreateUser(tb, "root", "admin")
+}
+
+// Relogin refreshes the token of user, e.g. after its roles changed.
+func (h *Harness) Relogin(tb testing.TB, user *User) {
+	tb.Helper()
+	user.Token = h.Login(tb, user.Username, Password)
+}
+
+// ExpectStatus stops tb unless resp has the given status.
+func ExpectStatus(tb testing.TB, resp *Response, status int) {
+	tb.Helper()
+	if resp.Status != status {
+		tb.Fatalf("%s %s: status %d, want %d: %s", resp.Method, resp.Path, resp.Status, status, resp.Body)
+	}
+}
+
+// ExpectOK checks for 200 and decodes the body into out unless it is nil.
+func ExpectOK(tb testing.TB, resp *Response, out interface{}) {
+	tb.Helper()
+	ExpectStatus(tb, resp, http.StatusOK)
+	if out != nil {
+		resp.Decode(tb, out)
+	}
+}
+
+// ExpectError checks that resp is want's status and code and returns the error body.
+func (h *Harness) ExpectError(tb testing.TB, resp *Response, want *errorx.AppError) response.ErrorBody {
+	tb.Helper()
+	var body response.ErrorBody
+	if err := json.Unmarshal(resp.Body, &body); err != nil || body.Code == "" {
+		tb.Fatalf("%s %s: status %d, want error %s: %s", resp.Method, resp.Path, resp.Status, want.Code, resp.Body)
+	}
+	if resp.Status != want.Status || body.Code != want.Code {
+		tb.Fatalf("%s %s: %d %s, want %d %s: %s", resp.Method, resp.Path, resp.Status, body.Code, want.Status, want.Code, resp.Body)
+	}
+	if body.RequestID == "" || body.RequestID != resp.Header.Get(middleware.RequestIDHeader) {
+		tb.Fatalf("%s %s: error body requestId %q does not match the %s header %q",
+			resp.Method, resp.Path, body.RequestID, middleware.RequestIDHeader, resp.Header.Get(middleware.RequestIDHeader))
+	}
+	h.coverage.add(body.Code)
+	return body
+}
+
+// ExpectProblem checks that resp is want as RFC 7807 problem details and returns them.
+func (h *Harness) ExpectProblem(tb testing.TB, resp *Response, want *errorx.AppError) response.Problem {
+	tb.Helper()
+	h.ExpectError(tb, resp, want)
+	if contentType := resp.Header.Get("Content-Type"); contentType != response.ProblemContentType {
+		tb.Fatalf("%s %s: content type %q, want %s", resp.Method, resp.Path, contentType, response.ProblemContentType)
+	}
+	var problem response.Problem
+	resp.Decode(tb, &problem)
+	if problem.Status != want.Status || problem.Title == "" || problem.Instance != strings.SplitN(resp.Path, "?", 2)[0] {
+		tb.Fatalf("%s %s: incomplete problem details: %s", resp.Method, resp.Path, resp.Body)
+	}
+	return problem
+}
+
+// ExpectInvalidField checks that a VALIDATION_FAILED body names field with tag, and nothing else.
+func ExpectInvalidField(tb testing.TB, body response.ErrorBody, field, tag string) {
+	tb.Helper()
+	var items []errorx.ValidationErrorItem
+	DecodeDetails(tb, body, &items)
+	if len(items) != 1 || items[0].Field != field || items[0].Tag != tag {
+		tb.Fatalf("validation details %+v, want field %s with tag %s", body.Details, field, tag)
+	}
+}
+
+// DecodeDetails unmarshals the details of an error body into v.
+func DecodeDetails(tb testing.TB, body response.ErrorBody, v interface{}) {
+	tb.Helper()
+	raw, err := json.Marshal(body.Details)
+	if err == nil {
+		err = json.Unmarshal(raw, v)
+	}
+	if err != nil {
+		tb.Fatalf("decode details of %s: %v", body.Code, err)
+	}
+}
+
+// ExpectSCIMError checks a SCIM error body: the status and scimType SCIM clients see, and the
+// message of the underlying error, which the detail starts with.
+func (h *Harness) ExpectSCIMError(tb testing.TB, resp *Response, want *errorx.AppError, scimType string) scim.Error {
+	tb.Helper()
+	var body scim.Error
+	resp.Decode(tb, &body)
+	if resp.Status != want.Status || body.ScimType != scimType || !strings.HasPrefix(body.Detail, want.Message) {
+		tb.Fatalf("%s %s: %d %q, want %d %q (%s): %s", resp.Method, resp.Path, resp.Status, body.ScimType, want.Status, scimType, want.Code, resp.Body)
+	}
+	h.coverage.add(want.Code)
+	return body
+}
+
+// ExpectResultCode checks the code reported for one item of a batch, e.g. a bulk operation.
+func (h *Harness) ExpectResultCode(tb testing.TB, what, code string, want *errorx.AppError) {
+	tb.Helper()
+	if code != want.Code {
+		tb.Fatalf("%s: code %q, want %s", what, code, want.Code)
+	}
+	h.coverage.add(code)
+}
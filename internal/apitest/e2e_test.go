@@ -0,0 +1,33 @@
+package apitest_test
+
+import (
+	"testing"
+
+	"usermgmt/internal/apitest"
+)
+
+// TestScenarios runs the end-to-end suite under go test, one subtest per scenario, and requires a
+// full run to assert every error code.
+func TestScenarios(t *testing.T) {
+	coverage := apitest.NewCoverage()
+	scenarios := apitest.Scenarios()
+
+	ran := 0
+	for _, s := range scenarios {
+		s := s
+		t.Run(s.Name, func(t *testing.T) {
+			ran++
+			options := append([]apitest.Option{apitest.WithCoverage(coverage)}, s.Options...)
+			h := apitest.MustNew(t, options...)
+			defer h.Close()
+			s.Run(t, h)
+		})
+	}
+
+	if ran < len(scenarios) || t.Failed() {
+		return
+	}
+	if missing := coverage.Missing(); len(missing) > 0 {
+		t.Fatalf("coverage: %s", coverage)
+	}
+}
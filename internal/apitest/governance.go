@@ -0,0 +1,281 @@
+package apitest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"usermgmt/internal/config"
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/scim"
+	"usermgmt/internal/types"
+)
+
+func safeguardConfig(c *config.Config) {
+	c.Safeguards.MinActiveAdmins = 2
+	c.Safeguards.ProtectedUsers = []string{"breakglass"}
+}
+
+func approvalConfig(c *config.Config) {
+	c.Approvals.SensitiveRoles = []string{"admin"}
+	c.Approvals.PrivilegedRoles = []string{"admin"}
+}
+
+// denyDisablePolicy forbids disabling anyone in the finance department.
+const denyDisablePolicy = `
+defaultEffect: allow
+rules:
+  - id: keep-finance-enabled
+    description: finance 部门的用户不能被禁用
+    effect: deny
+    actions: [user.status.update]
+    conditions:
+      - attr: action.status
+        op: eq
+        value: disabled
+      - attr: resource.department
+        op: eq
+        value: finance
+`
+
+// adminSafeguards checks that admins cannot lock themselves or the platform out.
+func adminSafeguards(t testing.TB, h *Harness) {
+	root := h.Admin(t)
+	second := h.CreateUser(t, "second", "admin")
+	breakglass := h.CreateUser(t, "breakglass")
+
+	h.ExpectError(t, h.Do(t, http.MethodPatch, idPath("/api/v1/admin/users/%s/status", root.ID), root.Token, types.UpdateUserStatusRequest{Status: "disabled"}), errorx.ErrSelfDisable)
+	h.ExpectError(t, h.Do(t, http.MethodDelete, idPath("/api/v1/admin/users/%s/roles/admin", root.ID), root.Token, nil), errorx.ErrSelfDemotion)
+	body := h.ExpectError(t, h.Do(t, http.MethodPatch, idPath("/api/v1/admin/users/%s/status", second.ID), root.Token, types.UpdateUserStatusRequest{Status: "disabled"}), errorx.ErrMinAdmins)
+	if body.Details == nil {
+		t.Fatalf("MIN_ADMINS_REQUIRED without details")
+	}
+	h.ExpectError(t, h.Do(t, http.MethodPatch, idPath("/api/v1/admin/users/%s/status", breakglass.ID), root.Token, types.UpdateUserStatusRequest{Status: "disabled"}), errorx.ErrProtectedAccount)
+
+	var bulk types.BulkUserResponse
+	ExpectOK(t, h.Do(t, http.MethodPost, "/api/v1/admin/users/bulk", root.Token, types.BulkUserRequest{
+		Action: "delete",
+		IDs:    []uint{root.ID, breakglass.ID},
+		Mode:   "bestEffort",
+		DryRun: true,
+	}), &bulk)
+	if !bulk.DryRun || len(bulk.Results) != 2 {
+		t.Fatalf("bulk delete dry run = %+v", bulk)
+	}
+	h.ExpectResultCode(t, "dry-run delete of root", bulk.Results[0].Code, errorx.ErrSelfDelete)
+	h.ExpectResultCode(t, "dry-run delete of breakglass", bulk.Results[1].Code, errorx.ErrProtectedAccount)
+
+	// Upserting existing accounts by import is held to the same rules.
+	rows := strings.Join([]string{
+		`{"username":"root","email":"root@example.com","fullName":"Root","status":"disabled"}`,
+		`{"username":"breakglass","email":"breakglass@example.com","fullName":"Break Glass","status":"disabled"}`,
+		`{"username":"second","email":"second@example.com","fullName":"Second","roles":["viewer"]}`,
+	}, "\n")
+	var imported types.ImportUsersResponse
+	ExpectOK(t, h.Do(t, http.MethodPost, "/api/v1/admin/users/import?format=ndjson&mode=upsert", root.Token, rows), &imported)
+	if imported.Updated != 0 || len(imported.Errors) != 3 {
+		t.Fatalf("guarded import = %+v", imported)
+	}
+	h.ExpectResultCode(t, "import disabling root", imported.Errors[0].Code, errorx.ErrSelfDisable)
+	h.ExpectResultCode(t, "import disabling breakglass", imported.Errors[1].Code, errorx.ErrProtectedAccount)
+	h.ExpectResultCode(t, "import demoting second", imported.Errors[2].Code, errorx.ErrMinAdmins)
+
+	// A third admin lifts the floor, so one of the others may now step down.
+	h.CreateUser(t, "third", "admin")
+	ExpectOK(t, h.Do(t, http.MethodDelete, idPath("/api/v1/admin/users/%s/roles/admin", second.ID), root.Token, nil), nil)
+}
+
+// twoPersonApproval routes sensitive grants through a second admin.
+func twoPersonApproval(t testing.TB, h *Harness) {
+	root := h.Admin(t)
+	second := h.CreateUser(t, "second", "admin")
+	alice := h.CreateUser(t, "alice")
+
+	body := h.ExpectError(t, h.Do(t, http.MethodPost, idPath("/api/v1/admin/users/%s/roles/admin", alice.ID), root.Token, nil), errorx.ErrApprovalPending)
+	var pending types.ChangeRequestDTO
+	DecodeDetails(t, body, &pending)
+	if pending.ID == 0 || pending.Status != "pending" || pending.TargetUserID != alice.ID {
+		t.Fatalf("change request = %+v", pending)
+	}
+
+	var list types.ListApprovalsResponse
+	ExpectOK(t, h.Get(t, "/api/v1/admin/approvals?status=pending", second.Token), &list)
+	if len(list.Data) != 1 || list.Data[0].ID != pending.ID {
+		t.Fatalf("pending approvals = %+v", list.Data)
+	}
+
+	approvePath := idPath("/api/v1/admin/approvals/%s/approve", pending.ID)
+	h.ExpectError(t, h.Do(t, http.MethodPost, approvePath, root.Token, types.ReviewApprovalRequest{}), errorx.ErrSelfApproval)
+	var approved types.ChangeRequestDTO
+	ExpectOK(t, h.Do(t, http.MethodPost, approvePath, second.Token, types.ReviewApprovalRequest{Comment: "ok"}), &approved)
+	if approved.Status != "approved" {
+		t.Fatalf("approved change = %+v", approved)
+	}
+	h.ExpectError(t, h.Do(t, http.MethodPost, approvePath, second.Token, types.ReviewApprovalRequest{}), errorx.ErrApprovalClosed)
+	h.ExpectError(t, h.Get(t, "/api/v1/admin/approvals/999", root.Token), errorx.ErrApprovalNotFound)
+
+	h.Relogin(t, alice)
+	var profile types.ProfileResponse
+	ExpectOK(t, h.Get(t, "/api/v1/me", alice.Token), &profile)
+	if strings.Join(profile.User.Roles, ",") != "admin" {
+		t.Fatalf("roles after approval = %v", profile.User.Roles)
+	}
+
+	bob := h.CreateUser(t, "bob")
+	var bulk types.BulkUserResponse
+	ExpectOK(t, h.Do(t, http.MethodPost, "/api/v1/admin/users/bulk", root.Token, types.BulkUserRequest{
+		Action: "addRoles",
+		IDs:    []uint{bob.ID},
+		Roles:  []string{"admin"},
+		DryRun: true,
+	}), &bulk)
+	if len(bulk.Results) != 1 {
+		t.Fatalf("bulk addRoles dry run = %+v", bulk)
+	}
+	h.ExpectResultCode(t, "dry-run grant of admin", bulk.Results[0].Code, errorx.ErrApprovalRequired)
+
+	var imported types.ImportUsersResponse
+	row := `{"username":"bob","email":"bob@example.com","fullName":"Bob","roles":["admin"]}`
+	ExpectOK(t, h.Do(t, http.MethodPost, "/api/v1/admin/users/import?format=ndjson&mode=upsert", root.Token, row), &imported)
+	if len(imported.Errors) != 1 {
+		t.Fatalf("import granting admin = %+v", imported)
+	}
+	h.ExpectResultCode(t, "import grant of admin", imported.Errors[0].Code, errorx.ErrApprovalRequired)
+
+	// SCIM cannot hand out a sensitive role, neither as a member nor by renaming a group.
+	var groups struct {
+		Resources []scim.Group `json:"Resources"`
+	}
+	ExpectOK(t, h.SCIM(t, http.MethodGet, `/Groups?filter=displayName%20eq%20%22admin%22&excludedAttributes=members`, nil), &groups)
+	if len(groups.Resources) != 1 {
+		t.Fatalf("admin group = %+v", groups)
+	}
+	adminGroup := groups.Resources[0].ID
+	addBob := scim.PatchRequest{Schemas: []string{scim.SchemaPatchOp}, Operations: []scim.PatchOperation{
+		{Op: "add", Path: "members", Value: []byte(fmt.Sprintf(`[{"value":"%d"}]`, bob.ID))},
+	}}
+	h.ExpectSCIMError(t, h.SCIM(t, http.MethodPatch, "/Groups/"+adminGroup, addBob), errorx.ErrApprovalRequired, "")
+	h.ExpectSCIMError(t, h.SCIM(t, http.MethodPost, "/Groups", scim.Group{
+		Schemas: []string{scim.SchemaGroup}, DisplayName: "Admin", Members: []scim.MultiValue{{Value: fmt.Sprint(bob.ID)}},
+	}), errorx.ErrApprovalRequired, "")
+	removeAlice := scim.PatchRequest{Schemas: []string{scim.SchemaPatchOp}, Operations: []scim.PatchOperation{
+		{Op: "remove", Path: fmt.Sprintf(`members[value eq "%d"]`, alice.ID)},
+	}}
+	ExpectOK(t, h.SCIM(t, http.MethodPatch, "/Groups/"+adminGroup, removeAlice), nil)
+}
+
+// accessReview runs a review campaign from creation to export.
+func accessReview(t testing.TB, h *Harness) {
+	root := h.Admin(t)
+	carol := h.CreateUser(t, "carol", "viewer")
+	alice := h.CreateUser(t, "alice", "support")
+	bob := h.CreateUser(t, "bob", "support")
+	mallory := h.CreateUser(t, "mallory")
+
+	create := types.CreateAccessReviewRequest{
+		Name:        "Q3 support review",
+		Roles:       []string{"support"},
+		ReviewerIDs: []uint{carol.ID, alice.ID},
+		Deadline:    time.Now().Add(-time.Hour).Format(time.RFC3339),
+		AutoRevoke:  true,
+	}
+	h.ExpectError(t, h.Do(t, http.MethodPost, "/api/v1/admin/reviews", root.Token, create), errorx.ErrValidation)
+	create.Deadline = time.Now().Add(24 * time.Hour).Format(time.RFC3339)
+	var review types.AccessReviewDTO
+	ExpectOK(t, h.Do(t, http.MethodPost, "/api/v1/admin/reviews", root.Token, create), &review)
+	if review.Total != 2 || review.Status != "open" {
+		t.Fatalf("review = %+v", review)
+	}
+
+	ExpectOK(t, h.Get(t, idPath("/api/v1/admin/reviews/%s", review.ID), root.Token), &review)
+	items := make(map[uint]types.AccessReviewItemDTO)
+	for _, item := range review.Items {
+		if item.ReviewerID == nil || *item.ReviewerID == item.UserID {
+			t.Fatalf("item %+v is unassigned or self-assigned", item)
+		}
+		items[item.UserID] = item
+	}
+	aliceItem, bobItem := items[alice.ID], items[bob.ID]
+
+	decide := func(user *User, itemID uint, decision string) *Response {
+		return h.Do(t, http.MethodPut, idPath("/api/v1/me/reviews/%s", itemID), user.Token, types.DecideReviewItemRequest{Decision: decision})
+	}
+	h.ExpectError(t, decide(alice, aliceItem.ID, "keep"), errorx.ErrSelfReview)
+	h.ExpectError(t, decide(mallory, bobItem.ID, "keep"), errorx.ErrNotReviewer)
+	h.ExpectError(t, decide(carol, 9999, "keep"), errorx.ErrReviewItemNotFound)
+	h.ExpectError(t, decide(carol, aliceItem.ID, "maybe"), errorx.ErrValidation)
+
+	var assigned types.ListReviewItemsResponse
+	ExpectOK(t, h.Get(t, "/api/v1/me/reviews", carol.Token), &assigned)
+	if len(assigned.Data) != 1 {
+		t.Fatalf("carol's review items = %+v", assigned.Data)
+	}
+	ExpectOK(t, decide(carol, aliceItem.ID, "revoke"), nil)
+	ExpectOK(t, decide(root, bobItem.ID, "keep"), nil)
+
+	exportPath := idPath("/api/v1/admin/reviews/%s/export?format=csv", review.ID)
+	h.ExpectError(t, h.Get(t, exportPath, root.Token), errorx.ErrReviewOpen)
+
+	closePath := idPath("/api/v1/admin/reviews/%s/close", review.ID)
+	ExpectOK(t, h.Do(t, http.MethodPost, closePath, root.Token, nil), &review)
+	if review.Status != "closed" {
+		t.Fatalf("closed review = %+v", review)
+	}
+	h.ExpectError(t, h.Do(t, http.MethodPost, closePath, root.Token, nil), errorx.ErrReviewClosed)
+	h.ExpectError(t, decide(carol, aliceItem.ID, "keep"), errorx.ErrReviewClosed)
+
+	resp := h.Get(t, exportPath, root.Token)
+	ExpectStatus(t, resp, http.StatusOK)
+	if !strings.Contains(string(resp.Body), "alice") || !strings.Contains(string(resp.Body), "revoke") {
+		t.Fatalf("review export = %s", resp.Body)
+	}
+	h.ExpectError(t, h.Get(t, "/api/v1/admin/reviews/999", root.Token), errorx.ErrReviewNotFound)
+
+	var profile types.ProfileResponse
+	ExpectOK(t, h.Get(t, "/api/v1/me", alice.Token), &profile)
+	if len(profile.User.Roles) != 0 {
+		t.Fatalf("alice kept %v after a revoke decision", profile.User.Roles)
+	}
+}
+
+// accessPolicy checks that ABAC rules apply on top of roles and can be explained.
+func accessPolicy(t testing.TB, h *Harness) {
+	root := h.Admin(t)
+	alice := h.CreateUser(t, "alice")
+	h.Exec(t, "UPDATE users SET department = ? WHERE id = ?", "finance", alice.ID)
+
+	statusPath := idPath("/api/v1/admin/users/%s/status", alice.ID)
+	body := h.ExpectError(t, h.Do(t, http.MethodPatch, statusPath, root.Token, types.UpdateUserStatusRequest{Status: "disabled"}), errorx.ErrPolicyDenied)
+	var denied map[string]interface{}
+	DecodeDetails(t, body, &denied)
+	if !strings.Contains(fmt.Sprint(denied), "keep-finance-enabled") {
+		t.Fatalf("POLICY_DENIED does not name the rule: %+v", body.Details)
+	}
+
+	var explained types.ExplainPolicyResponse
+	ExpectOK(t, h.Do(t, http.MethodPost, "/api/v1/admin/policy/explain", root.Token, types.ExplainPolicyRequest{
+		Action:     "user.status.update",
+		ResourceID: alice.ID,
+		Params:     map[string]string{"status": "disabled"},
+	}), &explained)
+	if explained.Decision != "deny" || explained.DecidingRule != "keep-finance-enabled" {
+		t.Fatalf("explain = %+v", explained)
+	}
+
+	var bulk types.BulkUserResponse
+	ExpectOK(t, h.Do(t, http.MethodPost, "/api/v1/admin/users/bulk", root.Token, types.BulkUserRequest{
+		Action: "status", IDs: []uint{alice.ID}, Status: "disabled", Mode: "bestEffort",
+	}), &bulk)
+	if bulk.Failed != 1 || bulk.Results[0].Code != errorx.ErrPolicyDenied.Code {
+		t.Fatalf("bulk disable under policy = %+v", bulk)
+	}
+
+	// Support staff reach the status route only for ordinary accounts, whatever the policy allows.
+	helper := h.CreateUser(t, "helper", "support")
+	h.ExpectError(t, h.Do(t, http.MethodPatch, idPath("/api/v1/admin/users/%s/status", root.ID), helper.Token, types.UpdateUserStatusRequest{Status: "disabled"}), errorx.ErrForbidden)
+
+	h.Exec(t, "UPDATE users SET department = ? WHERE id = ?", "sales", alice.ID)
+	ExpectOK(t, h.Do(t, http.MethodPatch, statusPath, helper.Token, types.UpdateUserStatusRequest{Status: "disabled"}), nil)
+}
@@ -0,0 +1,318 @@
+// Package apitest runs the HTTP API end to end. A Harness serves the routes of
+// handler.RegisterHandlers on an httptest server backed by a throwaway SQLite database, and
+// Scenarios drives complete flows through it; TestScenarios runs them under go test.
+package apitest
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/rest"
+	"github.com/zeromicro/go-zero/rest/router"
+	"gorm.io/gorm/logger"
+
+	"usermgmt/internal/config"
+	"usermgmt/internal/handler"
+	"usermgmt/internal/model"
+	"usermgmt/internal/svc"
+)
+
+// Roles seeded into every harness database, with the permissions they carry directly.
+var seedRoles = []struct {
+	name        string
+	description string
+	permissions []string
+}{
+	{"admin", "Platform administrator", []string{"users.read", "users.write", "roles.write"}},
+	{"support", "Customer support", []string{"users.read", "users.status"}},
+	{"viewer", "Read-only access", []string{"users.read"}},
+}
+
+// Password is used for every account the helpers create.
+const Password = "Passw0rd!"
+
+// SCIMToken is the bearer token SCIM clients present to a harness.
+const SCIMToken = "e2e-scim-token"
+
+// baseConfig is the service configuration of a harness, filled in with the log level, database DSN
+// and SCIM token. Every section is present, even if empty, because go-zero only applies the defaults
+// of optional sections that appear. Options adjust the result after loading.
+const baseConfig = `
+Name: user-api-e2e
+Host: 127.0.0.1
+Port: 0
+Log:
+  Mode: console
+  Level: severe
+Database:
+  Driver: sqlite
+  DSN: %q
+  MaxIdleConns: 4
+  MaxOpenConns: 4
+  ConnMaxLifetime: 1h
+JWT:
+  AccessSecret: e2e-access-secret
+  AccessExpire: 1h
+  RefreshExpire: 24h
+Password:
+  BcryptCost: 4
+Pagination:
+  DefaultPageSize: 20
+  MaxPageSize: 100
+Security:
+  AllowOrigins: ["*"]
+SCIM:
+  Token: %s
+  BaseURL: http://localhost/scim/v2
+Bulk: {}
+RoleGrants: {}
+Tenancy: {}
+Policy: {}
+Safeguards: {}
+Approvals: {}
+Reviews: {}
+Webhooks: {}
+Outbox: {}
+Auth:
+  LDAP: {}
+Metrics: {}
+Health: {}
+Lifecycle: {}
+AccessLog: {}
+Errors: {}
+I18n: {}
+`
+
+// Option adjusts a harness before the service starts.
+type Option func(*options)
+
+type options struct {
+	configure []func(*config.Config)
+	policy    string
+	locales   map[string]string
+	coverage  *Coverage
+}
+
+// WithConfig changes the service configuration, e.g. to enable approvals or safeguards.
+func WithConfig(fn func(c *config.Config)) Option {
+	return func(o *options) {
+		o.configure = append(o.configure, fn)
+	}
+}
+
+// WithPolicy writes rules as the ABAC policy file and enables policy checks.
+func WithPolicy(rules string) Option {
+	return func(o *options) {
+		o.policy = rules
+	}
+}
+
+// WithLocale writes catalog as the locale file <locale>.yaml of I18n.Dir.
+func WithLocale(locale, catalog string) Option {
+	return func(o *options) {
+		if o.locales == nil {
+			o.locales = make(map[string]string)
+		}
+		o.locales[locale] = catalog
+	}
+}
+
+// WithCoverage records the error codes asserted through the harness in coverage, so that several
+// harnesses can share one report.
+func WithCoverage(coverage *Coverage) Option {
+	return func(o *options) {
+		o.coverage = coverage
+	}
+}
+
+// Harness is a running instance of the whole API.
+type Harness struct {
+	Server *httptest.Server
+	Svc    *svc.ServiceContext
+
+	dir      string
+	coverage *Coverage
+	users    map[string]*User
+}
+
+// New starts a harness on a fresh database with the seed roles.
+func New(opts ...Option) (h *Harness, err error) {
+	o := options{coverage: NewCoverage()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	dir, err := os.MkdirTemp("", "usermgmt-e2e-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		// NewServiceContext panics on bad configuration.
+		if r := recover(); r != nil {
+			err = fmt.Errorf("start harness: %v", r)
+		}
+		if err != nil {
+			os.RemoveAll(dir)
+		}
+	}()
+
+	c, err := loadConfig(dir, &o)
+	if err != nil {
+		return nil, err
+	}
+	svcCtx := svc.NewServiceContext(c)
+	svcCtx.DB.Logger = logger.Discard
+	if err := svcCtx.AutoMigrate(); err != nil {
+		return nil, fmt.Errorf("migrate: %w", err)
+	}
+	if err := seed(svcCtx); err != nil {
+		return nil, fmt.Errorf("seed: %w", err)
+	}
+
+	server, err := rest.NewServer(c.RestConf)
+	if err != nil {
+		return nil, err
+	}
+	handler.RegisterHandlers(server, svcCtx)
+
+	// rest.Server only serves after binding its own port, so its routes are mounted on a router
+	// of our own. RegisterHandlers adds no per-group options, so the routes carry everything.
+	mux := router.NewRouter()
+	for _, route := range server.Routes() {
+		if err := mux.Handle(route.Method, route.Path, route.Handler); err != nil {
+			return nil, fmt.Errorf("mount %s %s: %w", route.Method, route.Path, err)
+		}
+	}
+
+	return &Harness{
+		Server:   httptest.NewServer(mux),
+		Svc:      svcCtx,
+		dir:      dir,
+		coverage: o.coverage,
+		users:    make(map[string]*User),
+	}, nil
+}
+
+// MustNew starts a harness and stops tb on failure.
+func MustNew(tb testing.TB, opts ...Option) *Harness {
+	tb.Helper()
+	h, err := New(opts...)
+	if err != nil {
+		tb.Fatalf("%v", err)
+	}
+	return h
+}
+
+func loadConfig(dir string, o *options) (config.Config, error) {
+	dsn := filepath.Join(dir, "e2e.db") + "?_pragma=journal_mode(WAL)"
+
+	var c config.Config
+	if err := conf.LoadFromYamlBytes([]byte(fmt.Sprintf(baseConfig, dsn, SCIMToken)), &c); err != nil {
+		return c, fmt.Errorf("load config: %w", err)
+	}
+	if o.policy != "" {
+		c.Policy.File = filepath.Join(dir, "policies.yaml")
+		if err := os.WriteFile(c.Policy.File, []byte(o.policy), 0o600); err != nil {
+			return c, err
+		}
+	}
+	if len(o.locales) > 0 {
+		c.I18n.Dir = filepath.Join(dir, "locales")
+		if err := os.Mkdir(c.I18n.Dir, 0o700); err != nil {
+			return c, err
+		}
+		for locale, catalog := range o.locales {
+			if err := os.WriteFile(filepath.Join(c.I18n.Dir, locale+".yaml"), []byte(catalog), 0o600); err != nil {
+				return c, err
+			}
+		}
+	}
+	for _, fn := range o.configure {
+		fn(&c)
+	}
+	return c, nil
+}
+
+func seed(svcCtx *svc.ServiceContext) error {
+	db := svcCtx.DB
+	for _, spec := range seedRoles {
+		role := model.Role{Name: spec.name, Description: spec.description}
+		if err := db.Create(&role).Error; err != nil {
+			return err
+		}
+		for _, code := range spec.permissions {
+			permission := model.Permission{Code: code}
+			if err := db.Where(model.Permission{Code: code}).FirstOrCreate(&permission).Error; err != nil {
+				return err
+			}
+			if err := db.Create(&model.RolePermission{RoleID: role.ID, PermissionID: permission.ID}).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Close stops the server and deletes the database.
+func (h *Harness) Close() {
+	h.Server.Close()
+	h.Svc.Close()
+	os.RemoveAll(h.dir)
+}
+
+// URL returns the absolute URL of path on the harness.
+func (h *Harness) URL(path string) string {
+	return h.Server.URL + path
+}
+
+// Drain runs the background work that follows a request once: outbox events are published and
+// due webhook deliveries are attempted.
+func (h *Harness) Drain(tb testing.TB) {
+	tb.Helper()
+	ctx := context.Background()
+	if _, err := h.Svc.Outbox.PublishDue(ctx, time.Now()); err != nil {
+		tb.Fatalf("publish outbox: %v", err)
+	}
+	if _, err := h.Svc.Webhooks.DeliverDue(ctx, time.Now()); err != nil {
+		tb.Fatalf("deliver webhooks: %v", err)
+	}
+}
+
+// Exec runs raw SQL against the harness database, for setup the API cannot express.
+func (h *Harness) Exec(tb testing.TB, sql string, args ...interface{}) {
+	tb.Helper()
+	if err := h.Svc.DB.Exec(sql, args...).Error; err != nil {
+		tb.Fatalf("exec %q: %v", sql, err)
+	}
+}
+
+// GrantRoles gives userID the named roles directly in the database, bypassing approvals and
+// safeguards. It is how the first admin of a scenario is made.
+func (h *Harness) GrantRoles(tb testing.TB, userID uint, roles ...string) {
+	tb.Helper()
+	for _, name := range roles {
+		var role model.Role
+		if err := h.Svc.DB.Where("name = ?", name).First(&role).Error; err != nil {
+			tb.Fatalf("load role %s: %v", name, err)
+		}
+		grant := model.UserRole{UserID: userID, RoleID: role.ID}
+		if err := h.Svc.DB.Create(&grant).Error; err != nil {
+			tb.Fatalf("grant %s to user %d: %v", name, userID, err)
+		}
+	}
+}
+
+func idPath(format string, ids ...uint) string {
+	args := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, strconv.FormatUint(uint64(id), 10))
+	}
+	return fmt.Sprintf(format, args...)
+}
@@ -0,0 +1,547 @@
+package apitest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"usermgmt/internal/config"
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/middleware"
+	"usermgmt/internal/scim"
+	"usermgmt/internal/types"
+	"usermgmt/internal/webhook"
+	"usermgmt/pkg/response"
+)
+
+// unreachableLDAPConfig adds an LDAP provider that nothing listens on.
+func unreachableLDAPConfig(c *config.Config) {
+	c.Auth.Providers = []string{"local", "ldap"}
+	c.Auth.LDAP.URL = "ldap://127.0.0.1:1"
+	c.Auth.LDAP.Timeout = time.Second
+	c.Auth.LDAP.BaseDN = "ou=people,dc=example,dc=com"
+}
+
+// userGroups grants roles through group membership.
+func userGroups(t testing.TB, h *Harness) {
+	root := h.Admin(t)
+	alice := h.CreateUser(t, "alice")
+
+	var group types.GroupDTO
+	ExpectOK(t, h.Do(t, http.MethodPost, "/api/v1/admin/groups", root.Token, types.CreateGroupRequest{Name: "helpdesk", Roles: []string{"support"}}), &group)
+	h.ExpectError(t, h.Do(t, http.MethodPost, "/api/v1/admin/groups", root.Token, types.CreateGroupRequest{Name: "helpdesk"}), errorx.ErrGroupExists)
+	h.ExpectError(t, h.Do(t, http.MethodPost, "/api/v1/admin/groups", root.Token, types.CreateGroupRequest{Name: "ops", Roles: []string{"ghost"}}), errorx.ErrValidation)
+
+	ExpectOK(t, h.Do(t, http.MethodPost, "/api/v1/admin/groups/helpdesk/members", root.Token, types.AddGroupMembersRequest{UserIDs: []uint{alice.ID}}), nil)
+	h.ExpectError(t, h.Do(t, http.MethodPost, "/api/v1/admin/groups/helpdesk/members", root.Token, types.AddGroupMembersRequest{UserIDs: []uint{9999}}), errorx.ErrUserNotFound)
+	h.ExpectError(t, h.Do(t, http.MethodPost, "/api/v1/admin/groups/ghosts/members", root.Token, types.AddGroupMembersRequest{UserIDs: []uint{alice.ID}}), errorx.ErrGroupNotFound)
+
+	var list types.ListUsersResponse
+	ExpectOK(t, h.Get(t, usersPath("", "helpdesk"), root.Token), &list)
+	if list.TotalItems != 1 || list.Data[0].ID != alice.ID {
+		t.Fatalf("helpdesk members = %+v", list.Data)
+	}
+
+	// Group roles count like direct ones.
+	var permissions types.UserPermissionsResponse
+	ExpectOK(t, h.Get(t, idPath("/api/v1/admin/users/%s/permissions", alice.ID), root.Token), &permissions)
+	if strings.Join(permissions.Permissions, ",") != "users.read,users.status" {
+		t.Fatalf("permissions through helpdesk = %v", permissions.Permissions)
+	}
+
+	ExpectOK(t, h.Do(t, http.MethodDelete, "/api/v1/admin/groups/helpdesk", root.Token, nil), nil)
+	h.ExpectError(t, h.Do(t, http.MethodDelete, "/api/v1/admin/groups/helpdesk", root.Token, nil), errorx.ErrGroupNotFound)
+}
+
+// organisations checks tenant resolution and org-admin scoping.
+func organisations(t testing.TB, h *Harness) {
+	root := h.Admin(t)
+	alice := h.CreateUser(t, "alice")
+	bob := h.CreateUser(t, "bob")
+	eve := h.CreateUser(t, "eve")
+
+	var org types.OrgDTO
+	ExpectOK(t, h.Do(t, http.MethodPost, "/api/v1/admin/orgs", root.Token, types.CreateOrgRequest{Slug: "acme", Name: "Acme Corp"}), &org)
+	h.ExpectError(t, h.Do(t, http.MethodPost, "/api/v1/admin/orgs", root.Token, types.CreateOrgRequest{Slug: "acme", Name: "Acme Again"}), errorx.ErrOrgExists)
+
+	inOrg := func(user *User, ref, method, path string, body interface{}) *Response {
+		header := http.Header{}
+		header.Set("X-Org-ID", ref)
+		return h.Send(t, Request{Method: method, Path: path, Token: user.Token, Body: body, Header: header})
+	}
+	ExpectOK(t, inOrg(root, "acme", http.MethodPut, idPath("/api/v1/org/members/%s", alice.ID), types.UpsertOrgMemberRequest{Roles: []string{"admin"}}), nil)
+	ExpectOK(t, inOrg(root, "acme", http.MethodPut, idPath("/api/v1/org/members/%s", bob.ID), types.UpsertOrgMemberRequest{Roles: []string{}}), nil)
+
+	var members types.ListOrgMembersResponse
+	ExpectOK(t, inOrg(alice, "acme", http.MethodGet, "/api/v1/org/members", nil), &members)
+	if len(members.Data) != 2 {
+		t.Fatalf("acme members = %+v", members.Data)
+	}
+	var users types.ListUsersResponse
+	ExpectOK(t, inOrg(alice, idPath("%s", org.ID), http.MethodGet, usersPath("", ""), nil), &users)
+	if users.TotalItems != 2 {
+		t.Fatalf("org admin sees %d users, want the 2 members", users.TotalItems)
+	}
+
+	h.ExpectError(t, inOrg(eve, "acme", http.MethodGet, "/api/v1/org/members", nil), errorx.ErrNotOrgMember)
+	h.ExpectError(t, inOrg(alice, "globex", http.MethodGet, "/api/v1/org/members", nil), errorx.ErrOrgNotFound)
+	h.ExpectError(t, h.Get(t, "/api/v1/admin/users", alice.Token), errorx.ErrForbidden)
+	h.ExpectError(t, inOrg(bob, "acme", http.MethodGet, "/api/v1/admin/users", nil), errorx.ErrForbidden)
+	h.ExpectError(t, inOrg(alice, "acme", http.MethodPut, idPath("/api/v1/org/members/%s", eve.ID), types.UpsertOrgMemberRequest{Roles: []string{"ghost"}}), errorx.ErrValidation)
+
+	// A unique key the logic has no error of its own for answers with the generic CONFLICT.
+	h.Exec(t, "CREATE UNIQUE INDEX idx_org_single_admin ON org_member_roles(org_id, role_id)")
+	h.ExpectError(t, inOrg(root, "acme", http.MethodPut, idPath("/api/v1/org/members/%s", bob.ID), types.UpsertOrgMemberRequest{Roles: []string{"admin"}}), errorx.ErrConflict)
+}
+
+// receiver records the deliveries a webhook endpoint gets.
+type receiver struct {
+	mu         sync.Mutex
+	deliveries []http.Header
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries = append(r.deliveries, req.Header.Clone())
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (r *receiver) received() []http.Header {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]http.Header(nil), r.deliveries...)
+}
+
+// webhooks delivers domain events to a subscriber and redelivers on request.
+func webhooks(t testing.TB, h *Harness) {
+	root := h.Admin(t)
+	// Publish root's own registration before anyone subscribes.
+	h.Drain(t)
+	sink := &receiver{}
+	server := httptest.NewServer(sink)
+	defer server.Close()
+
+	h.ExpectError(t, h.Do(t, http.MethodPost, "/api/v1/admin/webhooks", root.Token, types.CreateWebhookRequest{
+		Name: "audit", URL: server.URL, Events: []string{"user.exploded"},
+	}), errorx.ErrValidation)
+	var hook types.WebhookDTO
+	ExpectOK(t, h.Do(t, http.MethodPost, "/api/v1/admin/webhooks", root.Token, types.CreateWebhookRequest{
+		Name: "audit", URL: server.URL, Events: []string{"user.registered"},
+	}), &hook)
+	if hook.Secret == "" {
+		t.Fatalf("new webhook has no secret")
+	}
+
+	h.Register(t, "alice")
+	h.Drain(t)
+	received := sink.received()
+	if len(received) != 1 {
+		t.Fatalf("receiver got %d deliveries, want 1", len(received))
+	}
+	if got := received[0].Get(webhook.HeaderEventType); got != "user.registered" {
+		t.Fatalf("%s = %q", webhook.HeaderEventType, got)
+	}
+	if !strings.HasPrefix(received[0].Get(webhook.HeaderSignature), "t=") || received[0].Get(webhook.HeaderEventID) == "" {
+		t.Fatalf("delivery headers = %v", received[0])
+	}
+
+	var deliveries types.ListWebhookDeliveriesResponse
+	ExpectOK(t, h.Get(t, idPath("/api/v1/admin/webhooks/%s/deliveries", hook.ID), root.Token), &deliveries)
+	if len(deliveries.Data) != 1 || deliveries.Data[0].Status != "succeeded" {
+		t.Fatalf("deliveries = %+v", deliveries.Data)
+	}
+	ExpectOK(t, h.Do(t, http.MethodPost, idPath("/api/v1/admin/webhooks/%s/deliveries/%s/redeliver", hook.ID, deliveries.Data[0].ID), root.Token, nil), nil)
+	h.Drain(t)
+	if len(sink.received()) != 2 {
+		t.Fatalf("redelivery was not sent")
+	}
+	h.ExpectError(t, h.Do(t, http.MethodPost, idPath("/api/v1/admin/webhooks/%s/deliveries/999/redeliver", hook.ID), root.Token, nil), errorx.ErrDeliveryNotFound)
+
+	ExpectOK(t, h.Do(t, http.MethodDelete, idPath("/api/v1/admin/webhooks/%s", hook.ID), root.Token, nil), nil)
+	h.ExpectError(t, h.Do(t, http.MethodDelete, idPath("/api/v1/admin/webhooks/%s", hook.ID), root.Token, nil), errorx.ErrWebhookNotFound)
+
+	// Status and role changes made through groups and bulk actions publish events too.
+	changes := &receiver{}
+	changeServer := httptest.NewServer(changes)
+	defer changeServer.Close()
+	ExpectOK(t, h.Do(t, http.MethodPost, "/api/v1/admin/webhooks", root.Token, types.CreateWebhookRequest{
+		Name: "changes", URL: changeServer.URL, Events: []string{"user.disabled", "user.roles_changed"},
+	}), nil)
+	bob := h.Register(t, "bob")
+	ExpectOK(t, h.Do(t, http.MethodPost, "/api/v1/admin/groups", root.Token, types.CreateGroupRequest{Name: "helpdesk", Roles: []string{"support"}}), nil)
+	ExpectOK(t, h.Do(t, http.MethodPost, "/api/v1/admin/groups/helpdesk/members", root.Token, types.AddGroupMembersRequest{UserIDs: []uint{bob.ID}}), nil)
+	ExpectOK(t, h.Do(t, http.MethodPut, "/api/v1/admin/groups/helpdesk/roles", root.Token, types.SetGroupRolesRequest{Roles: []string{"viewer"}}), nil)
+	ExpectOK(t, h.Do(t, http.MethodDelete, idPath("/api/v1/admin/groups/helpdesk/members/%s", bob.ID), root.Token, nil), nil)
+	ExpectOK(t, h.Do(t, http.MethodPost, "/api/v1/admin/users/bulk", root.Token, types.BulkUserRequest{
+		Action: "status", IDs: []uint{bob.ID}, Status: "disabled",
+	}), nil)
+	h.Drain(t)
+	got := make([]string, 0)
+	for _, delivery := range changes.received() {
+		got = append(got, delivery.Get(webhook.HeaderEventType))
+	}
+	sort.Strings(got)
+	if want := "user.disabled,user.roles_changed,user.roles_changed,user.roles_changed"; strings.Join(got, ",") != want {
+		t.Fatalf("change events = %v, want %s", got, want)
+	}
+}
+
+// scimProvisioning drives the SCIM API the way an identity provider does.
+func scimProvisioning(t testing.TB, h *Harness) {
+	active := true
+	newUser := scim.User{
+		Schemas:  []string{scim.SchemaUser},
+		UserName: "jdoe",
+		Name:     &scim.Name{GivenName: "Jane", FamilyName: "Doe"},
+		Emails:   []scim.MultiValue{{Value: "jdoe@example.com", Primary: true}},
+		Active:   &active,
+	}
+	resp := h.SCIM(t, http.MethodPost, "/Users", newUser)
+	ExpectStatus(t, resp, http.StatusCreated)
+	var created scim.User
+	resp.Decode(t, &created)
+	if created.ID == "" || created.UserName != "jdoe" {
+		t.Fatalf("created = %+v", created)
+	}
+	h.ExpectSCIMError(t, h.SCIM(t, http.MethodPost, "/Users", newUser), errorx.ErrUserExists, scim.ErrorUniqueness)
+
+	var list scim.ListResponse
+	ExpectOK(t, h.SCIM(t, http.MethodGet, `/Users?filter=userName%20eq%20%22jdoe%22`, nil), &list)
+	if list.TotalResults != 1 {
+		t.Fatalf("filtered users = %+v", list)
+	}
+	h.ExpectSCIMError(t, h.SCIM(t, http.MethodGet, `/Users?filter=userName%20like%20%22j%22`, nil), errorx.ErrInvalidFilter, scim.ErrorInvalidFilter)
+
+	patch := func(ops ...scim.PatchOperation) *Response {
+		return h.SCIM(t, http.MethodPatch, "/Users/"+created.ID, scim.PatchRequest{Schemas: []string{scim.SchemaPatchOp}, Operations: ops})
+	}
+	ExpectOK(t, patch(scim.PatchOperation{Op: "replace", Path: "active", Value: []byte("false")}), &created)
+	if created.Active == nil || *created.Active {
+		t.Fatalf("patched user still active: %+v", created)
+	}
+	h.ExpectSCIMError(t, patch(scim.PatchOperation{Op: "remove", Path: "userName"}), errorx.ErrImmutable, scim.ErrorMutability)
+	h.ExpectSCIMError(t, patch(scim.PatchOperation{Op: "remove"}), errorx.ErrInvalidPath, scim.ErrorInvalidPath)
+
+	h.ExpectSCIMError(t, h.SCIM(t, http.MethodGet, "/Users/999", nil), errorx.ErrUserNotFound, "")
+	h.ExpectSCIMError(t, h.SCIM(t, http.MethodPost, "/Groups", scim.Group{Schemas: []string{scim.SchemaGroup}, DisplayName: "admin"}), errorx.ErrRoleExists, scim.ErrorUniqueness)
+	h.ExpectSCIMError(t, h.SCIM(t, http.MethodGet, "/Schemas/urn:nope", nil), errorx.ErrResourceNotFound, "")
+
+	resp = h.Send(t, Request{Method: http.MethodGet, Path: "/scim/v2/Users", Token: "wrong-token"})
+	ExpectStatus(t, resp, http.StatusUnauthorized)
+}
+
+// externalAccounts covers accounts whose credentials live in a directory.
+func externalAccounts(t testing.TB, h *Harness) {
+	alice := h.CreateUser(t, "alice")
+
+	// Local accounts still sign in when the directory is down.
+	h.Relogin(t, alice)
+	resp := h.Do(t, http.MethodPost, "/api/v1/auth/login", "", types.LoginRequest{Username: "dir-user", Password: Password})
+	h.ExpectError(t, resp, errorx.ErrAuthUnavailable)
+
+	h.Exec(t, "UPDATE users SET auth_source = ? WHERE id = ?", "ldap", alice.ID)
+	resp = h.Do(t, http.MethodPost, "/api/v1/me/password", alice.Token, types.ChangePasswordRequest{OldPassword: Password, NewPassword: "N3wPassw0rd!"})
+	h.ExpectError(t, resp, errorx.ErrExternalAccount)
+}
+
+// internalErrors checks that storage failures surface as INTERNAL_ERROR without leaking details.
+func internalErrors(t testing.TB, h *Harness) {
+	root := h.Admin(t)
+	h.Exec(t, "DROP TABLE user_groups")
+	body := h.ExpectError(t, h.Get(t, "/api/v1/admin/groups", root.Token), errorx.ErrInternal)
+	if body.Details != nil {
+		t.Fatalf("INTERNAL_ERROR leaks details: %+v", body.Details)
+	}
+}
+
+func metricsConfig(c *config.Config) {
+	c.Metrics.Enabled = true
+}
+
+// metricsEndpoint checks that auth and admin activity shows up on /metrics.
+func metricsEndpoint(t testing.TB, h *Harness) {
+	root := h.Admin(t)
+	alice := h.CreateUser(t, "alice")
+	h.Do(t, http.MethodPost, "/api/v1/auth/login", "", types.LoginRequest{Username: "alice", Password: "wrong-password"})
+	h.Get(t, "/api/v1/me", "")
+	ExpectOK(t, h.Do(t, http.MethodPost, idPath("/api/v1/admin/users/%s/roles/viewer", alice.ID), root.Token, nil), nil)
+	ExpectOK(t, h.Do(t, http.MethodPatch, idPath("/api/v1/admin/users/%s/status", alice.ID), root.Token, types.UpdateUserStatusRequest{Status: "disabled"}), nil)
+	h.Drain(t)
+
+	resp := h.Get(t, "/metrics", "")
+	ExpectStatus(t, resp, http.StatusOK)
+	for _, series := range []string{
+		`usermgmt_auth_logins_total{outcome="success"}`,
+		`usermgmt_auth_logins_total{outcome="INVALID_CREDENTIALS"}`,
+		`usermgmt_auth_registrations_total{outcome="success"}`,
+		`usermgmt_auth_token_failures_total{reason="missing"}`,
+		`usermgmt_auth_password_verify_duration_seconds_count{match="false"}`,
+		`usermgmt_users_role_changes_total{change="granted",role="viewer"}`,
+		`usermgmt_users_status_changes_total{status="disabled"}`,
+		`usermgmt_db_query_duration_seconds_count{operation="create",outcome="success",table="users"}`,
+		`go_sql_max_open_connections{db_name="sqlite"}`,
+	} {
+		if !strings.Contains(string(resp.Body), series) {
+			t.Fatalf("/metrics has no %s", series)
+		}
+	}
+}
+
+// healthProbes checks /healthz, /version and that /readyz fails for a missing table, for a schema
+// behind the newest migration and while draining, with liveness unaffected.
+func healthProbes(t testing.TB, h *Harness) {
+	var live types.HealthResponse
+	ExpectOK(t, h.Get(t, "/healthz", ""), &live)
+	var version types.VersionResponse
+	ExpectOK(t, h.Get(t, "/version", ""), &version)
+	if version.Version == "" || version.GoVersion == "" {
+		t.Fatalf("/version is incomplete: %+v", version)
+	}
+
+	var ready types.ReadinessResponse
+	ExpectOK(t, h.Get(t, "/readyz", ""), &ready)
+	for _, check := range []string{"database", "migrations", "signingKeys", "lifecycle"} {
+		if ready.Checks[check] != "ok" {
+			t.Fatalf("readiness check %s = %q, want ok", check, ready.Checks[check])
+		}
+	}
+
+	for _, drift := range []string{"DROP TABLE webhook_deliveries", "ALTER TABLE users DROP COLUMN full_name"} {
+		h.Exec(t, drift)
+		resp := h.Get(t, "/readyz", "")
+		ExpectStatus(t, resp, http.StatusServiceUnavailable)
+		resp.Decode(t, &ready)
+		if ready.Status != "unavailable" || ready.Checks["migrations"] != "unavailable" {
+			t.Fatalf("readiness after %q = %+v", drift, ready)
+		}
+		if err := h.Svc.AutoMigrate(); err != nil {
+			t.Fatalf("re-migrate: %v", err)
+		}
+		ExpectOK(t, h.Get(t, "/readyz", ""), nil)
+	}
+
+	// AutoMigrate records no version, so readiness does not depend on schema_migrations rows.
+	var versions int64
+	if err := h.Svc.DB.Table("schema_migrations").Count(&versions).Error; err != nil || versions != 0 {
+		t.Fatalf("schema_migrations rows after AutoMigrate = %d, %v, want none", versions, err)
+	}
+
+	h.Svc.StartDraining()
+	resp := h.Get(t, "/readyz", "")
+	ExpectStatus(t, resp, http.StatusServiceUnavailable)
+	resp.Decode(t, &ready)
+	if ready.Checks["lifecycle"] != "draining" {
+		t.Fatalf("readiness while draining = %+v", ready)
+	}
+	ExpectOK(t, h.Get(t, "/healthz", ""), nil)
+}
+
+// requestIDs checks that a valid X-Request-ID is kept and echoed, and that a missing or malformed
+// one is replaced by a generated ID.
+func requestIDs(t testing.TB, h *Harness) {
+	sent := http.Header{middleware.RequestIDHeader: []string{"client-req-42"}}
+	resp := h.Send(t, Request{Method: http.MethodGet, Path: "/api/v1/me", Header: sent})
+	if body := h.ExpectError(t, resp, errorx.ErrInvalidCredentials); body.RequestID != "client-req-42" {
+		t.Fatalf("request ID %q was not kept: %+v", "client-req-42", body)
+	}
+
+	malformed := http.Header{middleware.RequestIDHeader: []string{"not a valid id"}}
+	resp = h.Send(t, Request{Method: http.MethodGet, Path: "/api/v1/me", Header: malformed})
+	if body := h.ExpectError(t, resp, errorx.ErrInvalidCredentials); body.RequestID == "not a valid id" {
+		t.Fatalf("malformed request ID was kept: %+v", body)
+	}
+
+	first := h.Get(t, "/healthz", "")
+	second := h.Get(t, "/healthz", "")
+	ExpectStatus(t, first, http.StatusOK)
+	id := first.Header.Get(middleware.RequestIDHeader)
+	if id == "" || id == second.Header.Get(middleware.RequestIDHeader) {
+		t.Fatalf("generated request IDs %q and %q are not unique", id, second.Header.Get(middleware.RequestIDHeader))
+	}
+}
+
+// errorResponses checks that parser failures only name the offending field, that clients can ask
+// for problem details, and that abandoned and timed-out requests are not reported as server errors.
+func errorResponses(t testing.TB, h *Harness) {
+	root := h.Admin(t)
+
+	body := h.ExpectError(t, h.Do(t, http.MethodPost, "/api/v1/auth/register", "", `{"username":1}`), errorx.ErrValidation)
+	ExpectInvalidField(t, body, "username", "type")
+	body = h.ExpectError(t, h.Do(t, http.MethodPost, "/api/v1/auth/register", "", `{"username":"alice"}`), errorx.ErrValidation)
+	ExpectInvalidField(t, body, "email", "required")
+	resp := h.Do(t, http.MethodPost, "/api/v1/auth/register", "", `{"username":`)
+	if body = h.ExpectError(t, resp, errorx.ErrValidation); body.Details != nil || strings.Contains(string(resp.Body), "EOF") {
+		t.Fatalf("malformed JSON leaks parser details: %s", resp.Body)
+	}
+	body = h.ExpectError(t, h.Get(t, "/api/v1/admin/users/abc/permissions", root.Token), errorx.ErrValidation)
+	ExpectInvalidField(t, body, "id", "number")
+
+	accept := http.Header{"Accept": []string{"application/json, application/problem+json"}}
+	resp = h.Send(t, Request{Method: http.MethodGet, Path: "/api/v1/me", Header: accept})
+	if problem := h.ExpectProblem(t, resp, errorx.ErrInvalidCredentials); problem.Type != "about:blank" {
+		t.Fatalf("problem type %q, want about:blank", problem.Type)
+	}
+	refused := http.Header{"Accept": []string{"application/problem+json;q=0, application/json"}}
+	resp = h.Send(t, Request{Method: http.MethodGet, Path: "/api/v1/me", Header: refused})
+	h.ExpectError(t, resp, errorx.ErrInvalidCredentials)
+	if contentType := resp.Header.Get("Content-Type"); strings.HasPrefix(contentType, "application/problem+json") {
+		t.Fatalf("problem details sent although refused by Accept")
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	list := Request{Method: http.MethodGet, Path: "/api/v1/admin/users", Token: root.Token}
+	h.ExpectError(t, h.ServeContext(t, cancelled, list), errorx.ErrRequestCanceled)
+	expired, cancel := context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+	h.ExpectError(t, h.ServeContext(t, expired, list), errorx.ErrRequestTimeout)
+}
+
+func problemConfig(c *config.Config) {
+	c.Errors.Format = "problem"
+	c.Errors.TypeBase = "https://errors.example.com/"
+}
+
+// problemDetails checks that with Errors.Format problem every error is written as problem details
+// typed by code, while SCIM keeps the error format its protocol prescribes.
+func problemDetails(t testing.TB, h *Harness) {
+	h.Register(t, "alice")
+	resp := h.Do(t, http.MethodPost, "/api/v1/auth/login", "", types.LoginRequest{Username: "alice", Password: "wrong-password"})
+	problem := h.ExpectProblem(t, resp, errorx.ErrInvalidCredentials)
+	if problem.Type != "https://errors.example.com/INVALID_CREDENTIALS" || problem.Detail != errorx.ErrInvalidCredentials.Message {
+		t.Fatalf("problem details = %+v", problem)
+	}
+
+	resp = h.Do(t, http.MethodPost, "/api/v1/auth/register", "", types.RegisterRequest{Username: "bob", Email: "bob", Password: Password, FullName: "Bob"})
+	ExpectInvalidField(t, h.ExpectError(t, resp, errorx.ErrValidation), "email", "email")
+
+	h.ExpectSCIMError(t, h.SCIM(t, http.MethodGet, "/Users/999", nil), errorx.ErrUserNotFound, "")
+}
+
+// japaneseCatalog is a partial locale added from a file; what it lacks falls back to zh-CN.
+const japaneseCatalog = `
+messages:
+  INVALID_CREDENTIALS: ユーザー名またはパスワードが正しくありません
+fields:
+  email: メールアドレス
+validation:
+  email: "{field}の形式が正しくありません"
+`
+
+// localizedErrors checks Accept-Language negotiation, translated validation messages, error
+// details and confirmations, a locale loaded from a file, and that both built-in catalogs cover
+// every error code.
+func localizedErrors(t testing.TB, h *Harness) {
+	for _, locale := range []string{"zh-CN", "en"} {
+		localizer := h.Svc.I18n.Localizer(locale)
+		for _, appErr := range AppErrors {
+			if localizer.Message(appErr.Code, "") == "" {
+				t.Fatalf("locale %s has no message for %s", locale, appErr.Code)
+			}
+		}
+	}
+
+	login := func(acceptLanguage string) (*Response, response.ErrorBody) {
+		resp := h.Send(t, Request{
+			Method: http.MethodPost,
+			Path:   "/api/v1/auth/login",
+			Body:   types.LoginRequest{Username: "nobody", Password: "wrong-password"},
+			Header: http.Header{"Accept-Language": []string{acceptLanguage}},
+		})
+		return resp, h.ExpectError(t, resp, errorx.ErrInvalidCredentials)
+	}
+	for _, c := range []struct{ acceptLanguage, locale, message string }{
+		{"", "zh-CN", errorx.ErrInvalidCredentials.Message},
+		{"en-US,en;q=0.9", "en", "Invalid username or password"},
+		{"fr-FR, zh;q=0.5", "zh-CN", errorx.ErrInvalidCredentials.Message},
+		{"ko", "zh-CN", errorx.ErrInvalidCredentials.Message},
+		{"ja-JP", "ja", "ユーザー名またはパスワードが正しくありません"},
+	} {
+		resp, body := login(c.acceptLanguage)
+		if body.Message != c.message || resp.Header.Get("Content-Language") != c.locale {
+			t.Fatalf("Accept-Language %q: %s message %q, want %s %q", c.acceptLanguage, resp.Header.Get("Content-Language"), body.Message, c.locale, c.message)
+		}
+	}
+
+	register := func(acceptLanguage string, req types.RegisterRequest) []errorx.ValidationErrorItem {
+		resp := h.Send(t, Request{
+			Method: http.MethodPost,
+			Path:   "/api/v1/auth/register",
+			Body:   req,
+			Header: http.Header{"Accept-Language": []string{acceptLanguage}},
+		})
+		var items []errorx.ValidationErrorItem
+		DecodeDetails(t, h.ExpectError(t, resp, errorx.ErrValidation), &items)
+		return items
+	}
+	invalid := types.RegisterRequest{Username: "al", Email: "not-an-email", Password: Password, FullName: "Alice"}
+	for _, c := range []struct {
+		acceptLanguage string
+		messages       []string
+	}{
+		{"en", []string{"Username must be at least 3 characters long", "Email must be a valid email address"}},
+		{"zh-CN", []string{"用户名长度不能少于 3 个字符", "邮箱必须是合法的邮箱地址"}},
+		{"ja", []string{"用户名长度不能少于 3 个字符", "メールアドレスの形式が正しくありません"}},
+	} {
+		items := register(c.acceptLanguage, invalid)
+		if len(items) != len(c.messages) {
+			t.Fatalf("Accept-Language %q: validation details %+v", c.acceptLanguage, items)
+		}
+		for i, item := range items {
+			if item.Message != c.messages[i] {
+				t.Fatalf("Accept-Language %q: %s message %q, want %q", c.acceptLanguage, item.Field, item.Message, c.messages[i])
+			}
+		}
+	}
+
+	root := h.Admin(t)
+	alice := h.CreateUser(t, "alice")
+	for _, c := range []struct{ acceptLanguage, detail, scimDetail, done string }{
+		{"en", "ttl must be a positive duration such as 8h", "Invalid request parameters: count must be an integer", "Password changed"},
+		{"zh-CN", "ttl 必须是正的时长，例如 8h", "请求参数不合法: count 必须是整数", "密码修改成功"},
+		{"ja", "ttl 必须是正的时长，例如 8h", "请求参数不合法: count 必须是整数", "密码修改成功"},
+	} {
+		language := http.Header{"Accept-Language": []string{c.acceptLanguage}}
+		resp := h.Send(t, Request{
+			Method: http.MethodPost,
+			Path:   fmt.Sprintf("/api/v1/admin/users/%d/roles/support", alice.ID),
+			Token:  root.Token,
+			Body:   types.GrantRoleRequest{TTL: "-1h"},
+			Header: language,
+		})
+		if body := h.ExpectError(t, resp, errorx.ErrValidation); body.Details != c.detail {
+			t.Fatalf("Accept-Language %q: details %#v, want %q", c.acceptLanguage, body.Details, c.detail)
+		}
+
+		resp = h.Send(t, Request{
+			Method: http.MethodGet,
+			Path:   "/scim/v2/Users?count=many",
+			Token:  SCIMToken,
+			Header: language,
+		})
+		var scimErr scim.Error
+		ExpectStatus(t, resp, http.StatusBadRequest)
+		if resp.Decode(t, &scimErr); scimErr.ScimType != scim.ErrorInvalidValue || scimErr.Detail != c.scimDetail {
+			t.Fatalf("Accept-Language %q: SCIM error %+v, want detail %q", c.acceptLanguage, scimErr, c.scimDetail)
+		}
+
+		var done struct{ Message string }
+		resp = h.Send(t, Request{
+			Method: http.MethodPost,
+			Path:   "/api/v1/me/password",
+			Token:  alice.Token,
+			Body:   types.ChangePasswordRequest{OldPassword: Password, NewPassword: Password},
+			Header: language,
+		})
+		if ExpectOK(t, resp, &done); done.Message != c.done {
+			t.Fatalf("Accept-Language %q: message %q, want %q", c.acceptLanguage, done.Message, c.done)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package apitest
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/types"
+)
+
+// Scenarios returns the end-to-end suite. Together the scenarios assert every code in AppErrors.
+func Scenarios() []Scenario {
+	return []Scenario{
+		{Name: "account lifecycle", Run: accountLifecycle},
+		{Name: "admin user management", Run: adminUserManagement},
+		{Name: "role hierarchy and permissions", Run: roleHierarchy},
+		{Name: "bulk import and export", Run: bulkImportExport},
+		{Name: "admin safeguards", Options: []Option{WithConfig(safeguardConfig)}, Run: adminSafeguards},
+		{Name: "two-person approval", Options: []Option{WithConfig(approvalConfig)}, Run: twoPersonApproval},
+		{Name: "access review", Run: accessReview},
+		{Name: "access policy", Options: []Option{WithPolicy(denyDisablePolicy)}, Run: accessPolicy},
+		{Name: "user groups", Run: userGroups},
+		{Name: "organisations", Run: organisations},
+		{Name: "webhooks", Run: webhooks},
+		{Name: "scim provisioning", Run: scimProvisioning},
+		{Name: "external accounts", Options: []Option{WithConfig(unreachableLDAPConfig)}, Run: externalAccounts},
+		{Name: "internal errors", Run: internalErrors},
+		{Name: "metrics", Options: []Option{WithConfig(metricsConfig)}, Run: metricsEndpoint},
+		{Name: "health probes", Run: healthProbes},
+		{Name: "request ids", Run: requestIDs},
+		{Name: "error responses", Run: errorResponses},
+		{Name: "problem details", Options: []Option{WithConfig(problemConfig)}, Run: problemDetails},
+		{Name: "localized errors", Options: []Option{WithLocale("ja", japaneseCatalog)}, Run: localizedErrors},
+	}
+}
+
+// accountLifecycle walks a user through register, login, profile and password change.
+func accountLifecycle(t testing.TB, h *Harness) {
+	registered := h.Register(t, "alice")
+	if registered.Username != "alice" || registered.Status != "enabled" {
+		t.Fatalf("registered user = %+v", registered)
+	}
+
+	resp := h.Do(t, http.MethodPost, "/api/v1/auth/register", "", types.RegisterRequest{
+		Username: "alice", Email: "other@example.com", Password: Password, FullName: "Alice Again",
+	})
+	h.ExpectError(t, resp, errorx.ErrUserExists)
+
+	resp = h.Do(t, http.MethodPost, "/api/v1/auth/register", "", types.RegisterRequest{
+		Username: "al", Email: "not-an-email", Password: "short", FullName: "A",
+	})
+	body := h.ExpectError(t, resp, errorx.ErrValidation)
+	if body.Details == nil {
+		t.Fatalf("validation error without details: %s", resp.Body)
+	}
+
+	resp = h.Do(t, http.MethodPost, "/api/v1/auth/login", "", types.LoginRequest{Username: "alice", Password: "wrong-password"})
+	h.ExpectError(t, resp, errorx.ErrInvalidCredentials)
+	h.ExpectError(t, h.Get(t, "/api/v1/me", ""), errorx.ErrInvalidCredentials)
+	h.ExpectError(t, h.Get(t, "/api/v1/me", "not-a-token"), errorx.ErrInvalidCredentials)
+
+	token := h.Login(t, "alice", Password)
+	var profile types.ProfileResponse
+	ExpectOK(t, h.Get(t, "/api/v1/me", token), &profile)
+	if profile.User.ID != registered.ID || profile.User.Email != "alice@example.com" {
+		t.Fatalf("profile = %+v", profile.User)
+	}
+
+	h.Register(t, "bob")
+	resp = h.Do(t, http.MethodPut, "/api/v1/me", token, types.UpdateProfileRequest{Email: "bob@example.com", FullName: "Alice"})
+	h.ExpectError(t, resp, errorx.ErrUserExists)
+	resp = h.Do(t, http.MethodPut, "/api/v1/me", token, types.UpdateProfileRequest{Email: "Alice@Example.org", FullName: "Alice Liddell"})
+	ExpectOK(t, resp, &profile)
+	if profile.User.FullName != "Alice Liddell" {
+		t.Fatalf("updated profile = %+v", profile.User)
+	}
+
+	resp = h.Do(t, http.MethodPost, "/api/v1/me/password", token, types.ChangePasswordRequest{OldPassword: "wrong-password", NewPassword: "N3wPassw0rd!"})
+	h.ExpectError(t, resp, errorx.ErrInvalidCredentials)
+	resp = h.Do(t, http.MethodPost, "/api/v1/me/password", token, types.ChangePasswordRequest{OldPassword: Password, NewPassword: "x"})
+	h.ExpectError(t, resp, errorx.ErrValidation)
+	resp = h.Do(t, http.MethodPost, "/api/v1/me/password", token, types.ChangePasswordRequest{OldPassword: Password, NewPassword: "N3wPassw0rd!"})
+	ExpectOK(t, resp, nil)
+
+	resp = h.Do(t, http.MethodPost, "/api/v1/auth/login", "", types.LoginRequest{Username: "alice", Password: Password})
+	h.ExpectError(t, resp, errorx.ErrInvalidCredentials)
+	h.Login(t, "alice", "N3wPassw0rd!")
+}
+
+// adminUserManagement covers listing, status changes and every way of changing a user's roles.
+func adminUserManagement(t testing.TB, h *Harness) {
+	root := h.Admin(t)
+	alice := h.CreateUser(t, "alice")
+	h.CreateUser(t, "bob")
+
+	h.ExpectError(t, h.Get(t, "/api/v1/admin/users", alice.Token), errorx.ErrForbidden)
+	h.ExpectError(t, h.Get(t, "/api/v1/admin/roles", alice.Token), errorx.ErrForbidden)
+
+	var list types.ListUsersResponse
+	ExpectOK(t, h.Get(t, usersPath("ALI", ""), root.Token), &list)
+	if list.TotalItems != 1 || list.Data[0].Username != "alice" {
+		t.Fatalf("keyword search = %+v", list)
+	}
+	ExpectOK(t, h.Get(t, usersPath("%", ""), root.Token), &list)
+	if list.TotalItems != 0 {
+		t.Fatalf("%% must match literally, got %d users", list.TotalItems)
+	}
+
+	statusPath := idPath("/api/v1/admin/users/%s/status", alice.ID)
+	var profile types.ProfileResponse
+	ExpectOK(t, h.Do(t, http.MethodPatch, statusPath, root.Token, types.UpdateUserStatusRequest{Status: "disabled"}), &profile)
+	if profile.User.Status != "disabled" {
+		t.Fatalf("status = %s", profile.User.Status)
+	}
+	resp := h.Do(t, http.MethodPost, "/api/v1/auth/login", "", types.LoginRequest{Username: "alice", Password: Password})
+	h.ExpectError(t, resp, errorx.ErrUserDisabled)
+	h.ExpectError(t, h.Do(t, http.MethodPatch, statusPath, root.Token, types.UpdateUserStatusRequest{Status: "paused"}), errorx.ErrValidation)
+	h.ExpectError(t, h.Do(t, http.MethodPatch, "/api/v1/admin/users/9999/status", root.Token, types.UpdateUserStatusRequest{Status: "disabled"}), errorx.ErrUserNotFound)
+	ExpectOK(t, h.Do(t, http.MethodPatch, statusPath, root.Token, types.UpdateUserStatusRequest{Status: "enabled"}), nil)
+	h.Relogin(t, alice)
+
+	rolesPath := idPath("/api/v1/admin/users/%s/roles", alice.ID)
+	assign := func(etag string, roles ...string) *Response {
+		header := http.Header{}
+		if etag != "" {
+			header.Set("If-Match", etag)
+		}
+		return h.Send(t, Request{Method: http.MethodPost, Path: rolesPath, Token: root.Token, Body: types.AssignRolesRequest{Roles: roles}, Header: header})
+	}
+	resp = assign(`"0"`, "support")
+	ExpectOK(t, resp, &profile)
+	etag := resp.Header.Get("ETag")
+	if etag != `"1"` || strings.Join(profile.User.Roles, ",") != "support" {
+		t.Fatalf("assign: ETag %s, roles %v", etag, profile.User.Roles)
+	}
+	h.ExpectError(t, assign(`"0"`, "viewer"), errorx.ErrVersionConflict)
+	body := h.ExpectError(t, assign(etag, "support", "ghost"), errorx.ErrValidation)
+	if !strings.Contains(fmt.Sprint(body.Details), "ghost") {
+		t.Fatalf("unknown role not reported: %+v", body.Details)
+	}
+
+	resp = h.Do(t, http.MethodPost, rolesPath+"/viewer", root.Token, types.GrantRoleRequest{TTL: "8h"})
+	ExpectOK(t, resp, &profile)
+	var temporary bool
+	for _, grant := range profile.User.RoleGrants {
+		temporary = temporary || (grant.Role == "viewer" && grant.ExpiresAt != nil)
+	}
+	if !temporary {
+		t.Fatalf("viewer grant has no expiry: %+v", profile.User.RoleGrants)
+	}
+	h.ExpectError(t, h.Do(t, http.MethodPost, rolesPath+"/ghost", root.Token, nil), errorx.ErrRoleNotFound)
+	h.ExpectError(t, h.Do(t, http.MethodPost, rolesPath+"/viewer", root.Token, types.GrantRoleRequest{ExpiresAt: "tomorrow"}), errorx.ErrValidation)
+
+	ExpectOK(t, h.Do(t, http.MethodDelete, rolesPath+"/support", root.Token, nil), &profile)
+	if strings.Join(profile.User.Roles, ",") != "viewer" {
+		t.Fatalf("roles after revoke = %v", profile.User.Roles)
+	}
+
+	// Tokens carry the roles held at login.
+	h.Relogin(t, alice)
+	ExpectOK(t, h.Get(t, "/api/v1/me", alice.Token), &profile)
+	if strings.Join(profile.User.Roles, ",") != "viewer" || profile.User.RoleVersion != 3 {
+		t.Fatalf("profile after role changes = %+v", profile.User)
+	}
+}
+
+// roleHierarchy checks inherited permissions and the parent cycle guard.
+func roleHierarchy(t testing.TB, h *Harness) {
+	root := h.Admin(t)
+	alice := h.CreateUser(t, "alice", "support")
+
+	var role types.RoleDTO
+	ExpectOK(t, h.Do(t, http.MethodPut, "/api/v1/admin/roles/support/parent", root.Token, types.SetRoleParentRequest{Parent: "viewer"}), &role)
+	if strings.Join(role.Ancestors, ",") != "viewer" {
+		t.Fatalf("support ancestors = %v", role.Ancestors)
+	}
+	h.ExpectError(t, h.Do(t, http.MethodPut, "/api/v1/admin/roles/viewer/parent", root.Token, types.SetRoleParentRequest{Parent: "support"}), errorx.ErrRoleCycle)
+	h.ExpectError(t, h.Do(t, http.MethodPut, "/api/v1/admin/roles/ghost/parent", root.Token, types.SetRoleParentRequest{Parent: "viewer"}), errorx.ErrRoleNotFound)
+
+	var roles types.ListRolesResponse
+	ExpectOK(t, h.Get(t, "/api/v1/admin/roles", root.Token), &roles)
+	if len(roles.Data) != len(seedRoles) {
+		t.Fatalf("roles = %+v", roles.Data)
+	}
+
+	var permissions types.UserPermissionsResponse
+	ExpectOK(t, h.Get(t, idPath("/api/v1/admin/users/%s/permissions", alice.ID), root.Token), &permissions)
+	if strings.Join(permissions.Permissions, ",") != "users.read,users.status" {
+		t.Fatalf("permissions = %v", permissions.Permissions)
+	}
+	var explained types.ExplainPermissionResponse
+	ExpectOK(t, h.Get(t, idPath("/api/v1/admin/users/%s/permissions/users.read", alice.ID), root.Token), &explained)
+	if !explained.Granted || len(explained.Paths) == 0 {
+		t.Fatalf("explain users.read = %+v", explained)
+	}
+	h.ExpectError(t, h.Get(t, "/api/v1/admin/users/9999/permissions", root.Token), errorx.ErrUserNotFound)
+}
+
+// bulkImportExport imports accounts, operates on them in bulk and exports the result.
+func bulkImportExport(t testing.TB, h *Harness) {
+	root := h.Admin(t)
+	h.CreateUser(t, "alice")
+
+	rows := strings.Join([]string{
+		`{"username":"imp1","email":"imp1@example.com","fullName":"Imported One","roles":["viewer"]}`,
+		`{"username":"imp2","email":"imp2@example.com","fullName":"Imported Two"}`,
+		`{"username":"alice","email":"alice2@example.com","fullName":"Duplicate"}`,
+	}, "\n")
+	var imported types.ImportUsersResponse
+	ExpectOK(t, h.Do(t, http.MethodPost, "/api/v1/admin/users/import?format=ndjson", root.Token, rows), &imported)
+	if imported.Created != 2 || imported.Failed != 1 || len(imported.Errors) != 1 {
+		t.Fatalf("import = %+v", imported)
+	}
+	h.ExpectResultCode(t, "import row 3", imported.Errors[0].Code, errorx.ErrUserExists)
+
+	var bulk types.BulkUserResponse
+	ExpectOK(t, h.Do(t, http.MethodPost, "/api/v1/admin/users/bulk", root.Token, types.BulkUserRequest{
+		Action: "status",
+		Filter: &types.BulkUserFilter{Keyword: "imp"},
+		Status: "disabled",
+	}), &bulk)
+	if bulk.Total != 2 || bulk.Succeeded != 2 {
+		t.Fatalf("bulk disable = %+v", bulk)
+	}
+	ExpectOK(t, h.Do(t, http.MethodPost, "/api/v1/admin/users/bulk", root.Token, types.BulkUserRequest{
+		Action: "addRoles",
+		IDs:    []uint{root.ID, 9999},
+		Roles:  []string{"viewer"},
+		Mode:   "bestEffort",
+	}), &bulk)
+	if bulk.Succeeded != 1 || bulk.Failed != 1 {
+		t.Fatalf("bulk addRoles = %+v", bulk)
+	}
+	h.ExpectResultCode(t, "bulk result for user 9999", bulk.Results[0].Code, errorx.ErrUserNotFound)
+	h.ExpectError(t, h.Do(t, http.MethodPost, "/api/v1/admin/users/bulk", root.Token, types.BulkUserRequest{Action: "delete"}), errorx.ErrValidation)
+	h.ExpectError(t, h.Do(t, http.MethodPost, "/api/v1/admin/users/bulk", root.Token, types.BulkUserRequest{
+		Action: "delete", Filter: &types.BulkUserFilter{}, DryRun: true,
+	}), errorx.ErrValidation)
+	var byRole types.BulkUserResponse
+	ExpectOK(t, h.Do(t, http.MethodPost, "/api/v1/admin/users/bulk", root.Token, types.BulkUserRequest{
+		Action: "status", Filter: &types.BulkUserFilter{Role: "viewer"}, Status: "enabled", DryRun: true,
+	}), &byRole)
+	if byRole.Total != 2 || byRole.Results[0].Username != "root" || byRole.Results[1].Username != "imp1" {
+		t.Fatalf("bulk preview by role = %+v", byRole)
+	}
+
+	resp := h.Get(t, "/api/v1/admin/users/export?format=csv&status=disabled", root.Token)
+	ExpectStatus(t, resp, http.StatusOK)
+	lines := strings.Split(strings.TrimSpace(string(resp.Body)), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1]+lines[2], "imp1") {
+		t.Fatalf("export = %s", resp.Body)
+	}
+}
+
+// usersPath is the admin user list filtered by keyword and group; empty filters are left out.
+func usersPath(keyword, group string) string {
+	query := url.Values{}
+	if keyword != "" {
+		query.Set("keyword", keyword)
+	}
+	if group != "" {
+		query.Set("group", group)
+	}
+	return "/api/v1/admin/users?" + query.Encode()
+}
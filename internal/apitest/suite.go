@@ -0,0 +1,105 @@
+package apitest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"usermgmt/internal/errorx"
+)
+
+// Scenario is one end-to-end flow, run against a harness of its own started with Options.
+type Scenario struct {
+	Name    string
+	Options []Option
+	Run     func(t testing.TB, h *Harness)
+}
+
+// Coverage counts the error codes asserted by scenarios.
+type Coverage struct {
+	mu    sync.Mutex
+	codes map[string]int
+}
+
+// NewCoverage returns an empty coverage report.
+func NewCoverage() *Coverage {
+	return &Coverage{codes: make(map[string]int)}
+}
+
+func (c *Coverage) add(code string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.codes[code]++
+}
+
+// Missing lists the codes of AppErrors that no scenario asserted.
+func (c *Coverage) Missing() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var missing []string
+	for _, appErr := range AppErrors {
+		if c.codes[appErr.Code] == 0 {
+			missing = append(missing, appErr.Code)
+		}
+	}
+	return missing
+}
+
+// String summarises the report, e.g. "40/41 error codes, missing INTERNAL_ERROR".
+func (c *Coverage) String() string {
+	missing := c.Missing()
+	summary := fmt.Sprintf("%d/%d error codes", len(AppErrors)-len(missing), len(AppErrors))
+	if len(missing) > 0 {
+		summary += ", missing " + strings.Join(missing, ", ")
+	}
+	return summary
+}
+
+// AppErrors are the errors declared by package errorx that the suite is expected to provoke.
+var AppErrors = []*errorx.AppError{
+	errorx.ErrValidation,
+	errorx.ErrUserExists,
+	errorx.ErrInvalidCredentials,
+	errorx.ErrUserDisabled,
+	errorx.ErrForbidden,
+	errorx.ErrUserNotFound,
+	errorx.ErrRoleNotFound,
+	errorx.ErrVersionConflict,
+	errorx.ErrRoleCycle,
+	errorx.ErrOrgNotFound,
+	errorx.ErrOrgExists,
+	errorx.ErrNotOrgMember,
+	errorx.ErrGroupNotFound,
+	errorx.ErrSelfDisable,
+	errorx.ErrSelfDelete,
+	errorx.ErrSelfDemotion,
+	errorx.ErrMinAdmins,
+	errorx.ErrProtectedAccount,
+	errorx.ErrApprovalPending,
+	errorx.ErrApprovalNotFound,
+	errorx.ErrApprovalClosed,
+	errorx.ErrSelfApproval,
+	errorx.ErrApprovalRequired,
+	errorx.ErrReviewNotFound,
+	errorx.ErrReviewItemNotFound,
+	errorx.ErrReviewClosed,
+	errorx.ErrReviewOpen,
+	errorx.ErrNotReviewer,
+	errorx.ErrSelfReview,
+	errorx.ErrWebhookNotFound,
+	errorx.ErrDeliveryNotFound,
+	errorx.ErrPolicyDenied,
+	errorx.ErrGroupExists,
+	errorx.ErrRoleExists,
+	errorx.ErrInvalidFilter,
+	errorx.ErrInvalidPath,
+	errorx.ErrImmutable,
+	errorx.ErrResourceNotFound,
+	errorx.ErrAuthUnavailable,
+	errorx.ErrExternalAccount,
+	errorx.ErrRequestCanceled,
+	errorx.ErrRequestTimeout,
+	errorx.ErrConflict,
+	errorx.ErrInternal,
+}
@@ -0,0 +1,223 @@
+package authn
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"usermgmt/internal/config"
+	"usermgmt/internal/model"
+)
+
+const defaultUserFilter = "(&(objectClass=person)(uid=%s))"
+
+// LDAPConn is the part of an LDAP session the provider uses; *ldap.Conn implements it.
+type LDAPConn interface {
+	Bind(username, password string) error
+	Search(req *ldap.SearchRequest) (*ldap.SearchResult, error)
+	Close() error
+}
+
+// DialFunc opens an LDAP session. NewLDAPProvider derives one from the configuration; tests can
+// pass their own.
+type DialFunc func(ctx context.Context) (LDAPConn, error)
+
+// LDAPProvider authenticates by binding to the directory as the user's entry.
+type LDAPProvider struct {
+	conf config.LDAPConf
+	dial DialFunc
+}
+
+// NewLDAPProvider creates the LDAP provider. A nil dial connects to conf.URL.
+func NewLDAPProvider(conf config.LDAPConf, dial DialFunc) (*LDAPProvider, error) {
+	if conf.UserFilter == "" {
+		conf.UserFilter = defaultUserFilter
+	}
+	if !strings.Contains(conf.UserFilter, "%s") {
+		return nil, fmt.Errorf("ldap UserFilter %q must contain %%s", conf.UserFilter)
+	}
+	if _, err := ldap.CompileFilter(strings.ReplaceAll(conf.UserFilter, "%s", "x")); err != nil {
+		return nil, fmt.Errorf("ldap UserFilter: %w", err)
+	}
+	if dial == nil {
+		if conf.URL == "" {
+			return nil, errors.New("ldap URL is required")
+		}
+		u, err := url.Parse(conf.URL)
+		if err != nil || (u.Scheme != "ldap" && u.Scheme != "ldaps") {
+			return nil, fmt.Errorf("ldap URL %q must be ldap:// or ldaps://", conf.URL)
+		}
+		tlsConfig, err := ldapTLSConfig(conf, u.Hostname())
+		if err != nil {
+			return nil, err
+		}
+		dial = func(ctx context.Context) (LDAPConn, error) {
+			return dialLDAP(ctx, conf, u.Scheme == "ldap", tlsConfig)
+		}
+	}
+	return &LDAPProvider{conf: conf, dial: dial}, nil
+}
+
+// dialLDAP connects to conf.URL, upgrading a plain connection with StartTLS when configured.
+// Timeout bounds dialing and every request.
+func dialLDAP(ctx context.Context, conf config.LDAPConf, plain bool, tlsConfig *tls.Config) (*ldap.Conn, error) {
+	timeout := conf.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < timeout {
+		timeout = time.Until(deadline)
+	}
+	conn, err := ldap.DialURL(conf.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: timeout}),
+		ldap.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(timeout)
+	if conf.StartTLS && plain {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("ldap StartTLS: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+func ldapTLSConfig(conf config.LDAPConf, serverName string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: conf.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if conf.CAFile == "" {
+		return tlsConfig, nil
+	}
+	pem, err := os.ReadFile(conf.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("read ldap CAFile: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("ldap CAFile %s contains no certificates", conf.CAFile)
+	}
+	tlsConfig.RootCAs = pool
+	return tlsConfig, nil
+}
+
+func (p *LDAPProvider) Name() string {
+	return model.AuthSourceLDAP
+}
+
+func (p *LDAPProvider) Authenticate(ctx context.Context, username, password string) (*Identity, error) {
+	if password == "" {
+		// An empty password would be an unauthenticated bind, which servers accept.
+		return nil, ErrInvalidCredentials
+	}
+	conn, err := p.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if p.conf.BindDN != "" {
+		if err := conn.Bind(p.conf.BindDN, p.conf.BindPassword); err != nil {
+			return nil, fmt.Errorf("service bind: %w", err)
+		}
+	}
+	result, err := conn.Search(ldap.NewSearchRequest(
+		p.conf.BaseDN,
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		2, 0, false,
+		strings.ReplaceAll(p.conf.UserFilter, "%s", ldap.EscapeFilter(username)),
+		[]string{p.conf.UsernameAttr, p.conf.EmailAttr, p.conf.NameAttr, p.conf.GroupAttr},
+		nil,
+	))
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, fmt.Errorf("search user: %w", err)
+	}
+	var entries []*ldap.Entry
+	if result != nil {
+		entries = result.Entries
+	}
+	switch {
+	case len(entries) == 0:
+		return nil, ErrUnknownUser
+	case len(entries) > 1 || err != nil:
+		return nil, fmt.Errorf("user filter matches more than one entry for %q", username)
+	}
+
+	entry := entries[0]
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("user bind: %w", err)
+	}
+
+	identity := &Identity{
+		Provider: model.AuthSourceLDAP,
+		Username: entry.GetEqualFoldAttributeValue(p.conf.UsernameAttr),
+		Email:    strings.ToLower(strings.TrimSpace(entry.GetEqualFoldAttributeValue(p.conf.EmailAttr))),
+		FullName: strings.TrimSpace(entry.GetEqualFoldAttributeValue(p.conf.NameAttr)),
+	}
+	if identity.Username == "" {
+		identity.Username = username
+	}
+	if p.conf.SyncGroups {
+		identity.Roles, identity.ManagedRoles = MapGroups(p.conf.GroupRoles, entry.GetEqualFoldAttributeValues(p.conf.GroupAttr))
+	}
+	return identity, nil
+}
+
+// MapGroups returns the roles the mappings grant for the given group DNs, and every role the
+// mappings mention. A mapping matches a group by full DN or by the value of its first RDN.
+func MapGroups(mappings []config.LDAPGroupMapping, groups []string) ([]string, []string) {
+	names := make(map[string]struct{}, len(groups)*2)
+	for _, group := range groups {
+		dn := normalizeDN(group)
+		names[dn] = struct{}{}
+		if rdn := strings.SplitN(dn, ",", 2)[0]; strings.Contains(rdn, "=") {
+			names[strings.SplitN(rdn, "=", 2)[1]] = struct{}{}
+		}
+	}
+
+	granted := make([]string, 0)
+	managed := make([]string, 0, len(mappings))
+	seenGranted := make(map[string]bool)
+	seenManaged := make(map[string]bool)
+	for _, mapping := range mappings {
+		role := strings.TrimSpace(mapping.Role)
+		if role == "" {
+			continue
+		}
+		if !seenManaged[role] {
+			seenManaged[role] = true
+			managed = append(managed, role)
+		}
+		if _, ok := names[normalizeDN(mapping.Group)]; ok && !seenGranted[role] {
+			seenGranted[role] = true
+			granted = append(granted, role)
+		}
+	}
+	return granted, managed
+}
+
+func normalizeDN(dn string) string {
+	parts := strings.Split(dn, ",")
+	for i, part := range parts {
+		parts[i] = strings.ToLower(strings.TrimSpace(part))
+	}
+	return strings.Join(parts, ",")
+}
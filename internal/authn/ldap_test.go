@@ -0,0 +1,266 @@
+package authn
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"usermgmt/internal/config"
+	"usermgmt/internal/model"
+)
+
+const (
+	serviceDN       = "cn=svc,dc=example,dc=com"
+	servicePassword = "svc-secret"
+)
+
+// fakeDirectory is an LDAP server in memory: entries are matched on their uid in the search
+// filter and a bind succeeds with the password stored for the DN.
+type fakeDirectory struct {
+	entries   []*ldap.Entry
+	passwords map[string]string
+	searchErr error
+
+	binds  []string
+	closed int
+}
+
+func (d *fakeDirectory) dial(ctx context.Context) (LDAPConn, error) {
+	return &fakeConn{dir: d}, nil
+}
+
+type fakeConn struct {
+	dir *fakeDirectory
+}
+
+func (c *fakeConn) Bind(username, password string) error {
+	c.dir.binds = append(c.dir.binds, username)
+	if want, ok := c.dir.passwords[username]; !ok || want != password {
+		return ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("invalid credentials"))
+	}
+	return nil
+}
+
+func (c *fakeConn) Search(req *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	if c.dir.searchErr != nil {
+		return nil, c.dir.searchErr
+	}
+	result := &ldap.SearchResult{}
+	for _, entry := range c.dir.entries {
+		if strings.Contains(req.Filter, "(uid="+entry.GetAttributeValue("uid")+")") {
+			result.Entries = append(result.Entries, entry)
+		}
+	}
+	return result, nil
+}
+
+func (c *fakeConn) Close() error {
+	c.dir.closed++
+	return nil
+}
+
+func testLDAPConf() config.LDAPConf {
+	return config.LDAPConf{
+		BindDN:       serviceDN,
+		BindPassword: servicePassword,
+		BaseDN:       "ou=people,dc=example,dc=com",
+		UsernameAttr: "uid",
+		EmailAttr:    "mail",
+		NameAttr:     "cn",
+		GroupAttr:    "memberOf",
+	}
+}
+
+func newFakeDirectory() *fakeDirectory {
+	return &fakeDirectory{
+		entries: []*ldap.Entry{
+			ldap.NewEntry("uid=jdoe,ou=people,dc=example,dc=com", map[string][]string{
+				"uid":      {"jdoe"},
+				"mail":     {" JDoe@Example.com "},
+				"cn":       {"Jane Doe"},
+				"memberOf": {"CN=Ops, OU=Groups, DC=example, DC=com", "cn=staff,ou=groups,dc=example,dc=com"},
+			}),
+			ldap.NewEntry("uid=twin,ou=a,dc=example,dc=com", map[string][]string{"uid": {"twin"}}),
+			ldap.NewEntry("uid=twin,ou=b,dc=example,dc=com", map[string][]string{"uid": {"twin"}}),
+		},
+		passwords: map[string]string{
+			serviceDN:                              servicePassword,
+			"uid=jdoe,ou=people,dc=example,dc=com": "directory-pass",
+		},
+	}
+}
+
+func TestLDAPAuthenticate(t *testing.T) {
+	groupRoles := []config.LDAPGroupMapping{
+		{Group: "cn=ops,ou=groups,dc=example,dc=com", Role: "admin"},
+		{Group: "staff", Role: "viewer"},
+		{Group: "auditors", Role: "auditor"},
+	}
+	tests := []struct {
+		name      string
+		configure func(*config.LDAPConf, *fakeDirectory)
+		username  string
+		password  string
+		want      *Identity
+		wantErr   error
+		errText   string
+		wantBinds []string
+	}{
+		{
+			name:     "successful bind",
+			username: "jdoe",
+			password: "directory-pass",
+			want: &Identity{
+				Provider: model.AuthSourceLDAP,
+				Username: "jdoe",
+				Email:    "jdoe@example.com",
+				FullName: "Jane Doe",
+			},
+			wantBinds: []string{serviceDN, "uid=jdoe,ou=people,dc=example,dc=com"},
+		},
+		{
+			name:      "group to role mapping",
+			configure: func(c *config.LDAPConf, _ *fakeDirectory) { c.SyncGroups, c.GroupRoles = true, groupRoles },
+			username:  "jdoe",
+			password:  "directory-pass",
+			want: &Identity{
+				Provider:     model.AuthSourceLDAP,
+				Username:     "jdoe",
+				Email:        "jdoe@example.com",
+				FullName:     "Jane Doe",
+				Roles:        []string{"admin", "viewer"},
+				ManagedRoles: []string{"admin", "viewer", "auditor"},
+			},
+			wantBinds: []string{serviceDN, "uid=jdoe,ou=people,dc=example,dc=com"},
+		},
+		{
+			name:      "anonymous search",
+			configure: func(c *config.LDAPConf, _ *fakeDirectory) { c.BindDN = "" },
+			username:  "jdoe",
+			password:  "directory-pass",
+			want: &Identity{
+				Provider: model.AuthSourceLDAP,
+				Username: "jdoe",
+				Email:    "jdoe@example.com",
+				FullName: "Jane Doe",
+			},
+			wantBinds: []string{"uid=jdoe,ou=people,dc=example,dc=com"},
+		},
+		{name: "wrong password", username: "jdoe", password: "nope", wantErr: ErrInvalidCredentials, wantBinds: []string{serviceDN, "uid=jdoe,ou=people,dc=example,dc=com"}},
+		{name: "empty password never binds", username: "jdoe", password: "", wantErr: ErrInvalidCredentials},
+		{name: "unknown user", username: "ghost", password: "x", wantErr: ErrUnknownUser, wantBinds: []string{serviceDN}},
+		{
+			name:      "service bind fails",
+			configure: func(c *config.LDAPConf, _ *fakeDirectory) { c.BindPassword = "stale" },
+			username:  "jdoe",
+			password:  "directory-pass",
+			errText:   "service bind",
+			wantBinds: []string{serviceDN},
+		},
+		{name: "ambiguous user", username: "twin", password: "x", errText: "more than one entry", wantBinds: []string{serviceDN}},
+		{
+			name: "search fails",
+			configure: func(_ *config.LDAPConf, d *fakeDirectory) {
+				d.searchErr = ldap.NewError(ldap.LDAPResultBusy, errors.New("busy"))
+			},
+			username:  "jdoe",
+			password:  "directory-pass",
+			errText:   "search user",
+			wantBinds: []string{serviceDN},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf, dir := testLDAPConf(), newFakeDirectory()
+			if tt.configure != nil {
+				tt.configure(&conf, dir)
+			}
+			provider, err := NewLDAPProvider(conf, dir.dial)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := provider.Authenticate(context.Background(), tt.username, tt.password)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+			case tt.errText != "":
+				if err == nil || !strings.Contains(err.Error(), tt.errText) {
+					t.Fatalf("err = %v, want %q", err, tt.errText)
+				}
+			case err != nil:
+				t.Fatalf("err = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("identity = %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(dir.binds, tt.wantBinds) {
+				t.Fatalf("binds = %v, want %v", dir.binds, tt.wantBinds)
+			}
+			if len(tt.wantBinds) > 0 && dir.closed != 1 {
+				t.Fatalf("connection closed %d times, want 1", dir.closed)
+			}
+		})
+	}
+}
+
+func TestNewLDAPProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		conf    config.LDAPConf
+		wantErr bool
+	}{
+		{name: "ldap url", conf: config.LDAPConf{URL: "ldap://dir.example.com"}},
+		{name: "ldaps url", conf: config.LDAPConf{URL: "ldaps://dir.example.com:636"}},
+		{name: "missing url", conf: config.LDAPConf{}, wantErr: true},
+		{name: "http url", conf: config.LDAPConf{URL: "http://dir.example.com"}, wantErr: true},
+		{name: "filter without placeholder", conf: config.LDAPConf{URL: "ldap://dir", UserFilter: "(uid=admin)"}, wantErr: true},
+		{name: "malformed filter", conf: config.LDAPConf{URL: "ldap://dir", UserFilter: "(uid=%s"}, wantErr: true},
+		{name: "missing ca file", conf: config.LDAPConf{URL: "ldaps://dir", CAFile: "/nonexistent/ca.pem"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewLDAPProvider(tt.conf, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMapGroups(t *testing.T) {
+	mappings := []config.LDAPGroupMapping{
+		{Group: "cn=ops,ou=groups,dc=example,dc=com", Role: "admin"},
+		{Group: "Support", Role: "support"},
+		{Group: "helpdesk", Role: "support"},
+		{Group: "anything", Role: " "},
+	}
+	tests := []struct {
+		name        string
+		groups      []string
+		wantGranted []string
+	}{
+		{name: "full dn ignores case and spacing", groups: []string{"CN=Ops, OU=Groups, DC=example, DC=com"}, wantGranted: []string{"admin"}},
+		{name: "first rdn value", groups: []string{"cn=support,ou=groups,dc=example,dc=com"}, wantGranted: []string{"support"}},
+		{name: "role granted once", groups: []string{"cn=support,dc=x", "cn=helpdesk,dc=x"}, wantGranted: []string{"support"}},
+		{name: "other rdn does not match", groups: []string{"cn=staff,ou=support,dc=example,dc=com"}, wantGranted: []string{}},
+		{name: "no groups", wantGranted: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			granted, managed := MapGroups(mappings, tt.groups)
+			if !reflect.DeepEqual(granted, tt.wantGranted) {
+				t.Fatalf("granted = %v, want %v", granted, tt.wantGranted)
+			}
+			if want := []string{"admin", "support"}; !reflect.DeepEqual(managed, want) {
+				t.Fatalf("managed = %v, want %v", managed, want)
+			}
+		})
+	}
+}
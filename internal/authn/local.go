@@ -0,0 +1,53 @@
+package authn
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"usermgmt/internal/metrics"
+	"usermgmt/internal/model"
+	"usermgmt/internal/repository"
+	"usermgmt/internal/tracing"
+)
+
+// LocalProvider checks the bcrypt password hash stored in users. Accounts owned by another
+// provider are unknown to it.
+type LocalProvider struct {
+	users repository.UserRepository
+}
+
+// NewLocalProvider creates the local password provider.
+func NewLocalProvider(users repository.UserRepository) *LocalProvider {
+	return &LocalProvider{users: users}
+}
+
+func (p *LocalProvider) Name() string {
+	return model.AuthSourceLocal
+}
+
+func (p *LocalProvider) Authenticate(ctx context.Context, username, password string) (*Identity, error) {
+	user, err := p.users.GetByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUnknownUser
+		}
+		return nil, err
+	}
+	if user.AuthSource != "" && user.AuthSource != model.AuthSourceLocal {
+		return nil, ErrUnknownUser
+	}
+	start := time.Now()
+	err = tracing.VerifyPassword(ctx, user.PasswordHash, password)
+	metrics.ObservePasswordVerify(start, err)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return &Identity{
+		Provider: model.AuthSourceLocal,
+		UserID:   user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		FullName: user.FullName,
+	}, nil
+}
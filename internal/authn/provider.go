@@ -0,0 +1,74 @@
+package authn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+var (
+	// ErrUnknownUser means the provider does not own the account; the next provider is tried.
+	ErrUnknownUser = errors.New("authn: unknown user")
+	// ErrInvalidCredentials means the provider owns the account and rejected the password; no
+	// further provider is tried.
+	ErrInvalidCredentials = errors.New("authn: invalid credentials")
+	// ErrUnavailable means no provider accepted the login and at least one failed.
+	ErrUnavailable = errors.New("authn: provider unavailable")
+)
+
+// Identity is an authenticated login. Local identities carry the user ID; external ones carry the
+// directory attributes used to provision and update the account.
+type Identity struct {
+	Provider string
+	UserID   uint
+	Username string
+	Email    string
+	FullName string
+	// Roles and ManagedRoles are set when the provider owns role assignment: the user must hold
+	// exactly Roles among ManagedRoles. A nil ManagedRoles leaves roles untouched.
+	Roles        []string
+	ManagedRoles []string
+}
+
+// Provider verifies a username and password.
+type Provider interface {
+	Name() string
+	Authenticate(ctx context.Context, username, password string) (*Identity, error)
+}
+
+// Chain tries providers in order. A provider that does not know the user or fails passes the
+// login on to the next one; one that rejects the password ends it.
+type Chain struct {
+	providers []Provider
+}
+
+// NewChain creates a chain of providers in fallback order.
+func NewChain(providers ...Provider) *Chain {
+	return &Chain{providers: providers}
+}
+
+// Authenticate returns the first identity accepted by a provider.
+func (c *Chain) Authenticate(ctx context.Context, username, password string) (*Identity, error) {
+	var failure error
+	for _, provider := range c.providers {
+		identity, err := provider.Authenticate(ctx, username, password)
+		switch {
+		case err == nil:
+			return identity, nil
+		case errors.Is(err, ErrUnknownUser):
+			continue
+		case errors.Is(err, ErrInvalidCredentials):
+			return nil, ErrInvalidCredentials
+		}
+		logx.WithContext(ctx).Errorf("auth provider %s failed: %v", provider.Name(), err)
+		if failure == nil {
+			failure = fmt.Errorf("%w: %s: %v", ErrUnavailable, provider.Name(), err)
+		}
+	}
+	if failure != nil {
+		return nil, failure
+	}
+	return nil, ErrInvalidCredentials
+}
@@ -0,0 +1,69 @@
+package authn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// stubProvider answers every login with a fixed result and records that it was asked.
+type stubProvider struct {
+	name  string
+	err   error
+	calls *[]string
+}
+
+func (p stubProvider) Name() string {
+	return p.name
+}
+
+func (p stubProvider) Authenticate(ctx context.Context, username, password string) (*Identity, error) {
+	*p.calls = append(*p.calls, p.name)
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &Identity{Provider: p.name, Username: username}, nil
+}
+
+func TestChainFallbackOrder(t *testing.T) {
+	outage := errors.New("connection refused")
+	tests := []struct {
+		name      string
+		results   []error
+		wantFrom  string
+		wantErr   error
+		wantCalls []string
+	}{
+		{name: "first provider wins", results: []error{nil, nil}, wantFrom: "p0", wantCalls: []string{"p0"}},
+		{name: "unknown user falls through", results: []error{ErrUnknownUser, nil}, wantFrom: "p1", wantCalls: []string{"p0", "p1"}},
+		{name: "outage falls through", results: []error{outage, nil}, wantFrom: "p1", wantCalls: []string{"p0", "p1"}},
+		{name: "wrong password stops the chain", results: []error{ErrInvalidCredentials, nil}, wantErr: ErrInvalidCredentials, wantCalls: []string{"p0"}},
+		{name: "nobody knows the user", results: []error{ErrUnknownUser, ErrUnknownUser}, wantErr: ErrInvalidCredentials, wantCalls: []string{"p0", "p1"}},
+		{name: "unknown after an outage", results: []error{outage, ErrUnknownUser}, wantErr: ErrUnavailable, wantCalls: []string{"p0", "p1"}},
+		{name: "wrong password after an outage", results: []error{outage, ErrInvalidCredentials}, wantErr: ErrInvalidCredentials, wantCalls: []string{"p0", "p1"}},
+		{name: "no providers", wantErr: ErrInvalidCredentials, wantCalls: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := make([]string, 0)
+			providers := make([]Provider, 0, len(tt.results))
+			for i, err := range tt.results {
+				providers = append(providers, stubProvider{name: fmt.Sprintf("p%d", i), err: err, calls: &calls})
+			}
+
+			identity, err := NewChain(providers...).Authenticate(context.Background(), "jdoe", "secret")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil || identity.Provider != tt.wantFrom {
+				t.Fatalf("identity = %+v, err = %v, want from %s", identity, err, tt.wantFrom)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Fatalf("providers asked = %v, want %v", calls, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+// Package buildinfo holds the build metadata served at /version. The variables are set at link
+// time, e.g.
+//
+//	go build -ldflags "-X usermgmt/internal/buildinfo.Version=v1.4.0 \
+//	  -X usermgmt/internal/buildinfo.Commit=$(git rev-parse HEAD) \
+//	  -X usermgmt/internal/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/api
+//
+// When they are not set, Commit and BuildTime fall back to the revision and commit time that go
+// build stamps into binaries built from a git checkout.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Info is the metadata of the running binary.
+type Info struct {
+	Version   string
+	Commit    string
+	BuildTime string
+	GoVersion string
+	Modified  bool
+}
+
+// Get returns the build metadata, completed from debug.ReadBuildInfo where the linker flags
+// left it empty.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.BuildTime == "" {
+				info.BuildTime = setting.Value
+			}
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
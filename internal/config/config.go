@@ -13,13 +13,39 @@ type Config struct {
 	Password   PasswordConf   `json:"Password"`
 	Pagination PaginationConf `json:"Pagination"`
 	Security   SecurityConf   `json:"Security"`
+	Bulk       BulkConf       `json:"Bulk,optional"`
+	RoleGrants RoleGrantConf  `json:"RoleGrants,optional"`
+	Tenancy    TenancyConf    `json:"Tenancy,optional"`
+	Policy     PolicyConf     `json:"Policy,optional"`
+	Safeguards SafeguardConf  `json:"Safeguards,optional"`
+	Approvals  ApprovalConf   `json:"Approvals,optional"`
+	Reviews    ReviewConf     `json:"Reviews,optional"`
+	Webhooks   WebhookConf    `json:"Webhooks,optional"`
+	Outbox     OutboxConf     `json:"Outbox,optional"`
+	SCIM       SCIMConf       `json:"SCIM,optional"`
+	Auth       AuthConf       `json:"Auth,optional"`
+	Metrics    MetricsConf    `json:"Metrics,optional"`
+	Health     HealthConf     `json:"Health,optional"`
+	Lifecycle  LifecycleConf  `json:"Lifecycle,optional"`
+	AccessLog  AccessLogConf  `json:"AccessLog,optional"`
+	Errors     ErrorsConf     `json:"Errors,optional"`
+	I18n       I18nConf       `json:"I18n,optional"`
 }
 
+// DatabaseConf selects the database. Driver is postgres, mysql or sqlite (pure Go, no cgo);
+// DSN is in the driver's own format. LogLevel is GORM's: warn logs failed and slow statements,
+// info every statement; either way without their bound values. At startup the connection is attempted ConnectAttempts times,
+// waiting ConnectBackoff after the first failure and doubling the wait up to ConnectMaxBackoff.
 type DatabaseConf struct {
-	DSN             string        `json:"DSN"`
-	MaxIdleConns    int           `json:"MaxIdleConns"`
-	MaxOpenConns    int           `json:"MaxOpenConns"`
-	ConnMaxLifetime time.Duration `json:"ConnMaxLifetime"`
+	Driver            string        `json:"Driver,default=postgres,options=postgres|mysql|sqlite"`
+	DSN               string        `json:"DSN"`
+	MaxIdleConns      int           `json:"MaxIdleConns"`
+	MaxOpenConns      int           `json:"MaxOpenConns"`
+	ConnMaxLifetime   time.Duration `json:"ConnMaxLifetime"`
+	LogLevel          string        `json:"LogLevel,default=warn,options=silent|error|warn|info"`
+	ConnectAttempts   int           `json:"ConnectAttempts,default=10"`
+	ConnectBackoff    time.Duration `json:"ConnectBackoff,default=1s"`
+	ConnectMaxBackoff time.Duration `json:"ConnectMaxBackoff,default=30s"`
 }
 
 type JWTConf struct {
@@ -40,3 +66,156 @@ type PaginationConf struct {
 type SecurityConf struct {
 	AllowOrigins []string `json:"AllowOrigins"`
 }
+
+type BulkConf struct {
+	MaxUsers      int `json:"MaxUsers,default=500"`
+	MaxImportRows int `json:"MaxImportRows,default=10000"`
+	ExportBatch   int `json:"ExportBatch,default=500"`
+}
+
+type RoleGrantConf struct {
+	SweepInterval time.Duration `json:"SweepInterval,default=1m"`
+	SweepBatch    int           `json:"SweepBatch,default=500"`
+}
+
+type TenancyConf struct {
+	Header         string `json:"Header,default=X-Org-ID"`
+	SuperAdminRole string `json:"SuperAdminRole,default=admin"`
+	OrgAdminRole   string `json:"OrgAdminRole,default=admin"`
+}
+
+// PolicyConf points at the ABAC policy file; an empty File disables policy checks.
+type PolicyConf struct {
+	File           string        `json:"File,optional"`
+	ReloadInterval time.Duration `json:"ReloadInterval,default=10s"`
+}
+
+// SafeguardConf tunes the admin lock-out protections.
+type SafeguardConf struct {
+	AdminRole       string   `json:"AdminRole,default=admin"`
+	MinActiveAdmins int      `json:"MinActiveAdmins,default=1"`
+	ProtectedUsers  []string `json:"ProtectedUsers,optional"`
+}
+
+// ApprovalConf lists the changes that need a second admin: granting a SensitiveRoles role, or
+// disabling a user who holds a PrivilegedRoles role. Empty lists disable the workflow.
+type ApprovalConf struct {
+	SensitiveRoles  []string      `json:"SensitiveRoles,optional"`
+	PrivilegedRoles []string      `json:"PrivilegedRoles,optional"`
+	TTL             time.Duration `json:"TTL,default=24h"`
+}
+
+// ReviewConf controls how often overdue access review campaigns are closed.
+type ReviewConf struct {
+	SweepInterval time.Duration `json:"SweepInterval,default=1m"`
+}
+
+// WebhookConf controls webhook delivery. A failed attempt is retried after BaseBackoff, doubling
+// each time up to MaxBackoff, until MaxAttempts is reached.
+type WebhookConf struct {
+	PollInterval time.Duration `json:"PollInterval,default=5s"`
+	Timeout      time.Duration `json:"Timeout,default=10s"`
+	MaxAttempts  int           `json:"MaxAttempts,default=8"`
+	BaseBackoff  time.Duration `json:"BaseBackoff,default=30s"`
+	MaxBackoff   time.Duration `json:"MaxBackoff,default=1h"`
+	Batch        int           `json:"Batch,default=100"`
+	Concurrency  int           `json:"Concurrency,default=8"`
+}
+
+// OutboxConf controls the relay that publishes outbox events to the sinks. A failed publish is
+// retried after BaseBackoff, doubling up to MaxBackoff, without an attempt limit. Published events
+// are purged once older than Retention.
+type OutboxConf struct {
+	PollInterval time.Duration `json:"PollInterval,default=1s"`
+	Batch        int           `json:"Batch,default=100"`
+	BaseBackoff  time.Duration `json:"BaseBackoff,default=5s"`
+	MaxBackoff   time.Duration `json:"MaxBackoff,default=10m"`
+	Retention    time.Duration `json:"Retention,default=168h"`
+}
+
+// SCIMConf enables the SCIM 2.0 provisioning API under /scim/v2 for clients presenting Token as a
+// bearer token; an empty Token disables it. BaseURL is the public URL of /scim/v2 used in resource
+// locations. MaxResults caps the page size of list requests.
+type SCIMConf struct {
+	Token      string `json:"Token,optional"`
+	BaseURL    string `json:"BaseURL,optional"`
+	MaxResults int    `json:"MaxResults,default=100"`
+}
+
+// AuthConf lists the login providers in the order they are tried ("local", "ldap"); empty means
+// local passwords only.
+type AuthConf struct {
+	Providers []string `json:"Providers,optional"`
+	LDAP      LDAPConf `json:"LDAP,optional"`
+}
+
+// LDAPConf configures the LDAP bind provider. Users are looked up under BaseDN with UserFilter,
+// where %s is the escaped login name (default "(&(objectClass=person)(uid=%s))"), binding as BindDN
+// first unless it is empty, and then authenticated by binding as the entry found. With SyncGroups
+// the GroupRoles mappings replace the mapped roles of the user on every login.
+type LDAPConf struct {
+	URL                string             `json:"URL,optional"`
+	StartTLS           bool               `json:"StartTLS,optional"`
+	InsecureSkipVerify bool               `json:"InsecureSkipVerify,optional"`
+	CAFile             string             `json:"CAFile,optional"`
+	Timeout            time.Duration      `json:"Timeout,default=5s"`
+	BindDN             string             `json:"BindDN,optional"`
+	BindPassword       string             `json:"BindPassword,optional"`
+	BaseDN             string             `json:"BaseDN,optional"`
+	UserFilter         string             `json:"UserFilter,optional"`
+	UsernameAttr       string             `json:"UsernameAttr,default=uid"`
+	EmailAttr          string             `json:"EmailAttr,default=mail"`
+	NameAttr           string             `json:"NameAttr,default=cn"`
+	GroupAttr          string             `json:"GroupAttr,default=memberOf"`
+	SyncGroups         bool               `json:"SyncGroups,optional"`
+	GroupRoles         []LDAPGroupMapping `json:"GroupRoles,optional"`
+}
+
+// LDAPGroupMapping grants Role to members of Group, given as a full DN or its CN.
+type LDAPGroupMapping struct {
+	Group string `json:"Group"`
+	Role  string `json:"Role"`
+}
+
+// MetricsConf serves Prometheus metrics on the API port at Path. The endpoint is not authenticated,
+// so expose it only to the scraper (e.g. block it at the reverse proxy).
+type MetricsConf struct {
+	Enabled bool   `json:"Enabled,optional"`
+	Path    string `json:"Path,default=/metrics"`
+}
+
+// HealthConf tunes the probes. ReadyTimeout bounds the checks of /readyz, the database ping
+// included, so that a hung database fails the probe instead of blocking it.
+type HealthConf struct {
+	ReadyTimeout time.Duration `json:"ReadyTimeout,default=2s"`
+}
+
+// LifecycleConf bounds the graceful shutdown that follows SIGTERM/SIGINT and go-zero's
+// Shutdown.WrapUpTime: in-flight requests get DrainTimeout to finish before their connections are
+// closed, then the background workers get WorkerStopTimeout to finish their current run.
+type LifecycleConf struct {
+	DrainTimeout      time.Duration `json:"DrainTimeout,default=10s"`
+	WorkerStopTimeout time.Duration `json:"WorkerStopTimeout,default=5s"`
+}
+
+// AccessLogConf tunes the JSON access log written for every request by middleware.RequestMiddleware.
+// Successful requests to SkipPaths (e.g. the probes) are not logged.
+type AccessLogConf struct {
+	SkipPaths []string `json:"SkipPaths,optional"`
+}
+
+// ErrorsConf shapes error responses. Format problem writes every error as RFC 7807 problem details
+// (application/problem+json); with json, clients still get them by accepting that media type.
+// TypeBase, e.g. https://docs.example.com/errors/, turns the error code into the problem type URI.
+type ErrorsConf struct {
+	Format   string `json:"Format,default=json,options=json|problem"`
+	TypeBase string `json:"TypeBase,optional"`
+}
+
+// I18nConf selects the language of error messages. The locale is negotiated from Accept-Language
+// among the built-in zh-CN and en catalogs and the <locale>.yaml files of Dir, which are loaded at
+// startup and may override built-in texts; DefaultLocale answers requests that match none.
+type I18nConf struct {
+	DefaultLocale string `json:"DefaultLocale,default=zh-CN"`
+	Dir           string `json:"Dir,optional"`
+}
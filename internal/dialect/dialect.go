@@ -0,0 +1,49 @@
+// Package dialect keeps the SQL that differs between the supported databases in one place.
+package dialect
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Driver names, equal to the Name() of the corresponding GORM dialector.
+const (
+	Postgres = "postgres"
+	MySQL    = "mysql"
+	SQLite   = "sqlite"
+)
+
+// Name returns the driver name of db.
+func Name(db *gorm.DB) string {
+	return db.Dialector.Name()
+}
+
+// LikeEscape is appended to LIKE comparisons whose pattern went through EscapeLike. SQLite has no
+// default escape character and MySQL treats a backslash inside a literal as an escape itself, so
+// an explicit character that is plain in every dialect is used.
+const LikeEscape = " ESCAPE '!'"
+
+// EscapeLike escapes LIKE wildcards in value for use with LikeEscape.
+func EscapeLike(value string) string {
+	return strings.NewReplacer(`!`, `!!`, `%`, `!%`, `_`, `!_`).Replace(value)
+}
+
+// ContainsFold narrows query to rows where any of columns contains keyword, ignoring case.
+// PostgreSQL uses ILIKE; MySQL and SQLite compare lower-cased values, which for SQLite only folds
+// ASCII letters.
+func ContainsFold(query *gorm.DB, keyword string, columns ...string) *gorm.DB {
+	pattern := "%" + EscapeLike(keyword) + "%"
+	conditions := make([]string, 0, len(columns))
+	args := make([]interface{}, 0, len(columns))
+	for _, column := range columns {
+		if Name(query) == Postgres {
+			conditions = append(conditions, column+" ILIKE ?"+LikeEscape)
+			args = append(args, pattern)
+		} else {
+			conditions = append(conditions, "LOWER("+column+") LIKE ?"+LikeEscape)
+			args = append(args, strings.ToLower(pattern))
+		}
+	}
+	return query.Where(strings.Join(conditions, " OR "), args...)
+}
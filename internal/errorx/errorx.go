@@ -3,6 +3,7 @@ package errorx
 import (
 	"errors"
 	"net/http"
+	"reflect"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -39,6 +40,10 @@ func Wrap(err error, status int, code, message string) *AppError {
 	return &AppError{Status: status, Code: code, Message: message, Details: err.Error()}
 }
 
+// StatusClientClosedRequest is the non-standard status (from nginx) recorded for requests the client
+// abandoned, so that they are neither counted as server errors nor as successes.
+const StatusClientClosedRequest = 499
+
 // Domain specific errors.
 var (
 	ErrValidation         = New(http.StatusBadRequest, "VALIDATION_FAILED", "请求参数不合法")
@@ -47,6 +52,43 @@ var (
 	ErrUserDisabled       = New(http.StatusForbidden, "USER_DISABLED", "用户已被禁用")
 	ErrForbidden          = New(http.StatusForbidden, "FORBIDDEN", "无访问权限")
 	ErrUserNotFound       = New(http.StatusNotFound, "USER_NOT_FOUND", "用户不存在")
+	ErrRoleNotFound       = New(http.StatusNotFound, "ROLE_NOT_FOUND", "角色不存在")
+	ErrVersionConflict    = New(http.StatusPreconditionFailed, "VERSION_CONFLICT", "数据已被修改，请刷新后重试")
+	ErrRoleCycle          = New(http.StatusConflict, "ROLE_CYCLE", "角色继承关系不能形成循环")
+	ErrOrgNotFound        = New(http.StatusNotFound, "ORG_NOT_FOUND", "组织不存在")
+	ErrOrgExists          = New(http.StatusConflict, "ORG_EXISTS", "组织标识已存在")
+	ErrNotOrgMember       = New(http.StatusForbidden, "NOT_ORG_MEMBER", "当前用户不属于该组织")
+	ErrGroupNotFound      = New(http.StatusNotFound, "GROUP_NOT_FOUND", "用户组不存在")
+	ErrSelfDisable        = New(http.StatusConflict, "SELF_DISABLE_FORBIDDEN", "不能禁用自己的账号")
+	ErrSelfDelete         = New(http.StatusConflict, "SELF_DELETE_FORBIDDEN", "不能删除自己的账号")
+	ErrSelfDemotion       = New(http.StatusConflict, "SELF_DEMOTION_FORBIDDEN", "不能移除自己的管理员角色")
+	ErrMinAdmins          = New(http.StatusConflict, "MIN_ADMINS_REQUIRED", "操作后可用管理员数量将低于下限")
+	ErrProtectedAccount   = New(http.StatusForbidden, "PROTECTED_ACCOUNT", "受保护账号不允许禁用、删除或移除角色")
+	ErrApprovalPending    = New(http.StatusAccepted, "APPROVAL_PENDING", "变更已提交，等待另一位管理员审批")
+	ErrApprovalNotFound   = New(http.StatusNotFound, "APPROVAL_NOT_FOUND", "审批单不存在")
+	ErrApprovalClosed     = New(http.StatusConflict, "APPROVAL_CLOSED", "审批单已处理或已过期")
+	ErrSelfApproval       = New(http.StatusForbidden, "SELF_APPROVAL_FORBIDDEN", "不能审批自己发起或针对自己的变更")
+	ErrApprovalRequired   = New(http.StatusConflict, "APPROVAL_REQUIRED", "该变更需要双人审批，请单独提交")
+	ErrReviewNotFound     = New(http.StatusNotFound, "REVIEW_NOT_FOUND", "访问审查不存在")
+	ErrReviewItemNotFound = New(http.StatusNotFound, "REVIEW_ITEM_NOT_FOUND", "审查条目不存在")
+	ErrReviewClosed       = New(http.StatusConflict, "REVIEW_CLOSED", "访问审查已结束")
+	ErrReviewOpen         = New(http.StatusConflict, "REVIEW_OPEN", "访问审查尚未结束，无法导出")
+	ErrNotReviewer        = New(http.StatusForbidden, "NOT_REVIEWER", "该条目未分配给当前用户")
+	ErrSelfReview         = New(http.StatusForbidden, "SELF_REVIEW_FORBIDDEN", "不能审查自己的授权")
+	ErrWebhookNotFound    = New(http.StatusNotFound, "WEBHOOK_NOT_FOUND", "Webhook 订阅不存在")
+	ErrDeliveryNotFound   = New(http.StatusNotFound, "WEBHOOK_DELIVERY_NOT_FOUND", "投递记录不存在")
+	ErrPolicyDenied       = New(http.StatusForbidden, "POLICY_DENIED", "操作被访问策略拒绝")
+	ErrGroupExists        = New(http.StatusConflict, "GROUP_EXISTS", "用户组名称已存在")
+	ErrRoleExists         = New(http.StatusConflict, "ROLE_EXISTS", "角色名称已存在")
+	ErrInvalidFilter      = New(http.StatusBadRequest, "INVALID_FILTER", "过滤表达式不合法")
+	ErrInvalidPath        = New(http.StatusBadRequest, "INVALID_PATH", "属性路径不合法或不支持")
+	ErrImmutable          = New(http.StatusBadRequest, "IMMUTABLE_ATTRIBUTE", "该属性不允许修改")
+	ErrResourceNotFound   = New(http.StatusNotFound, "RESOURCE_NOT_FOUND", "资源不存在")
+	ErrAuthUnavailable    = New(http.StatusServiceUnavailable, "AUTH_PROVIDER_UNAVAILABLE", "认证服务暂不可用，请稍后重试")
+	ErrExternalAccount    = New(http.StatusConflict, "EXTERNAL_ACCOUNT", "该账号由外部目录管理，请在目录中修改")
+	ErrRequestCanceled    = New(StatusClientClosedRequest, "REQUEST_CANCELED", "请求已被客户端取消")
+	ErrRequestTimeout     = New(http.StatusServiceUnavailable, "REQUEST_TIMEOUT", "请求处理超时，请稍后重试")
+	ErrConflict           = New(http.StatusConflict, "CONFLICT", "数据与已有记录冲突，请刷新后重试")
 	ErrInternal           = New(http.StatusInternalServerError, "INTERNAL_ERROR", "服务器内部错误")
 )
 
@@ -64,6 +106,17 @@ type ValidationErrorItem struct {
 	Field string `json:"field"`
 	Tag   string `json:"tag"`
 	Param string `json:"param"`
+	// Message is the readable text, filled in the locale of the response (see i18n.Localizer).
+	Message string `json:"message,omitempty"`
+	// Kind is string, number or list when the wording depends on it, e.g. for min and max.
+	Kind string `json:"-"`
+}
+
+// Detail explains an error in words of the response locale: Key names a text of the i18n catalogs
+// and Args fill its {name} placeholders. i18n.Localizer renders it as a string.
+type Detail struct {
+	Key  string            `json:"key"`
+	Args map[string]string `json:"args,omitempty"`
 }
 
 func FromValidationError(err error) *AppError {
@@ -75,9 +128,25 @@ func FromValidationError(err error) *AppError {
 				Field: fe.Field(),
 				Tag:   fe.Tag(),
 				Param: fe.Param(),
+				Kind:  kindOf(fe.Kind()),
 			})
 		}
 		return ErrValidation.WithDetails(items)
 	}
 	return ErrValidation.WithDetails(err.Error())
 }
+
+func kindOf(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "list"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,86 @@
+package errorx
+
+import (
+	"context"
+	"errors"
+	"regexp"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+// From maps an error that reached a handler onto the AppError that answers it:
+//   - an AppError anywhere in the chain is returned as is;
+//   - validator errors become VALIDATION_FAILED with one item per field;
+//   - gorm.ErrRecordNotFound becomes RESOURCE_NOT_FOUND, and a unique key violation
+//     (gorm.ErrDuplicatedKey, see gorm.Config.TranslateError) becomes CONFLICT; logic that knows
+//     which record clashed returns its own error instead, e.g. USER_EXISTS or GROUP_EXISTS;
+//   - context.Canceled and context.DeadlineExceeded become REQUEST_CANCELED and REQUEST_TIMEOUT;
+//   - anything else is INTERNAL_ERROR, without details.
+//
+// Logic turns most storage failures into ErrInternal itself, so once ctx is done an internal error
+// is reported as the cancellation or timeout that most likely caused it.
+func From(ctx context.Context, err error) *AppError {
+	if err == nil {
+		return nil
+	}
+	appErr := classify(err)
+	if appErr.Code == ErrInternal.Code && ctx != nil && ctx.Err() != nil {
+		return classify(ctx.Err())
+	}
+	return appErr
+}
+
+func classify(err error) *AppError {
+	var appErr *AppError
+	var validationErrs validator.ValidationErrors
+	switch {
+	case errors.As(err, &appErr):
+		return appErr
+	case errors.As(err, &validationErrs):
+		return FromValidationError(err)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrResourceNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return ErrConflict
+	case errors.Is(err, context.Canceled):
+		return ErrRequestCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrRequestTimeout
+	default:
+		return ErrInternal
+	}
+}
+
+// InvalidField reports a request field that could not be read, e.g. a path parameter that is not
+// a number, in the same shape as a validator error.
+func InvalidField(field, tag string) *AppError {
+	return ErrValidation.WithDetails([]ValidationErrorItem{{Field: field, Tag: tag}})
+}
+
+// parseErrorPatterns recognise the field named by go-zero's httpx.Parse errors, most specific first.
+var parseErrorPatterns = []struct {
+	pattern *regexp.Regexp
+	tag     string
+}{
+	{regexp.MustCompile(`"([^"]+)" is not set`), "required"},
+	{regexp.MustCompile(`for field "([^"]+)" is not defined in options`), "oneof"},
+	{regexp.MustCompile(`type mismatch for field "([^"]+)"`), "type"},
+	{regexp.MustCompile("^fullName: `([^`]+)`"), "type"},
+}
+
+// FromParseError reports a request that httpx.Parse could not read. The parser's messages quote
+// the input and name Go types and decoders, so only the offending field, if any, is passed on.
+func FromParseError(err error) *AppError {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	message := err.Error()
+	for _, p := range parseErrorPatterns {
+		if match := p.pattern.FindStringSubmatch(message); match != nil {
+			return InvalidField(match[1], p.tag)
+		}
+	}
+	return ErrValidation
+}
@@ -0,0 +1,76 @@
+package event
+
+import "context"
+
+// Header names carried by broker adapters.
+const (
+	HeaderEventID   = "Event-Id"
+	HeaderEventType = "Event-Type"
+)
+
+// NATSPublishFunc publishes data to subject with msgID as the de-duplication ID. It keeps the
+// client library out of this package; with JetStream it is typically
+//
+//	func(ctx context.Context, subject, msgID string, data []byte) error {
+//		_, err := js.Publish(ctx, subject, data, jetstream.WithMsgID(msgID))
+//		return err
+//	}
+type NATSPublishFunc func(ctx context.Context, subject, msgID string, data []byte) error
+
+// NATSSink publishes each message to "<prefix>.<type>", using the event ID as the JetStream
+// message ID so the server drops duplicates within its de-duplication window.
+type NATSSink struct {
+	prefix  string
+	publish NATSPublishFunc
+}
+
+// NewNATSSink builds a NATS sink; prefix defaults to "usermgmt".
+func NewNATSSink(prefix string, publish NATSPublishFunc) *NATSSink {
+	if prefix == "" {
+		prefix = "usermgmt"
+	}
+	return &NATSSink{prefix: prefix, publish: publish}
+}
+
+func (s *NATSSink) Name() string {
+	return "nats"
+}
+
+func (s *NATSSink) Publish(ctx context.Context, msg Message) error {
+	return s.publish(ctx, s.prefix+"."+msg.Type, msg.ID, msg.Body)
+}
+
+// KafkaProduceFunc writes one record and returns once the broker acknowledged it. With
+// segmentio/kafka-go it is typically a wrapper around Writer.WriteMessages.
+type KafkaProduceFunc func(ctx context.Context, topic string, key, value []byte, headers map[string]string) error
+
+// KafkaSink writes every message to one topic keyed by the event key, so events of one user keep
+// their order within a partition. The event ID travels in the Event-Id header for consumers to
+// de-duplicate on.
+type KafkaSink struct {
+	topic   string
+	produce KafkaProduceFunc
+}
+
+// NewKafkaSink builds a Kafka sink; topic defaults to "usermgmt.events".
+func NewKafkaSink(topic string, produce KafkaProduceFunc) *KafkaSink {
+	if topic == "" {
+		topic = "usermgmt.events"
+	}
+	return &KafkaSink{topic: topic, produce: produce}
+}
+
+func (s *KafkaSink) Name() string {
+	return "kafka"
+}
+
+func (s *KafkaSink) Publish(ctx context.Context, msg Message) error {
+	key := msg.Key
+	if key == "" {
+		key = msg.ID
+	}
+	return s.produce(ctx, s.topic, []byte(key), msg.Body, map[string]string{
+		HeaderEventID:   msg.ID,
+		HeaderEventType: msg.Type,
+	})
+}
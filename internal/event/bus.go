@@ -0,0 +1,49 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Handler consumes one message. Handlers run at-least-once and must be idempotent on msg.ID.
+type Handler func(ctx context.Context, msg Message) error
+
+// Bus is the in-process sink: it hands each message to the handlers subscribed to its type.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+// NewBus builds an empty bus.
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[string][]Handler)}
+}
+
+// Subscribe registers handler for eventType; "*" receives every type.
+func (b *Bus) Subscribe(eventType string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+}
+
+func (b *Bus) Name() string {
+	return "bus"
+}
+
+// Publish runs every matching handler. If any fails the whole message is retried, so handlers
+// that already succeeded will see it again.
+func (b *Bus) Publish(ctx context.Context, msg Message) error {
+	b.mu.RLock()
+	handlers := make([]Handler, 0, len(b.handlers[msg.Type])+len(b.handlers["*"]))
+	handlers = append(handlers, b.handlers[msg.Type]...)
+	handlers = append(handlers, b.handlers["*"]...)
+	b.mu.RUnlock()
+
+	for i, handler := range handlers {
+		if err := handler(ctx, msg); err != nil {
+			return fmt.Errorf("handler %d for %s: %w", i, msg.Type, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package event
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"usermgmt/internal/types"
+)
+
+// User lifecycle events.
+const (
+	UserRegistered   = "user.registered"
+	UserEnabled      = "user.enabled"
+	UserDisabled     = "user.disabled"
+	UserEmailChanged = "user.email_changed"
+	UserRolesChanged = "user.roles_changed"
+)
+
+// Types lists every domain event type.
+var Types = []string{
+	UserRegistered,
+	UserEnabled,
+	UserDisabled,
+	UserEmailChanged,
+	UserRolesChanged,
+}
+
+// Event is the JSON envelope published to every sink. ID is the idempotency key: it is stable
+// across retries, so consumers de-duplicate on it. Key groups events of one aggregate (e.g. the
+// user ID) for sinks that preserve per-key ordering.
+type Event struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	Key        string      `json:"-"`
+	OccurredAt time.Time   `json:"occurredAt"`
+	Data       interface{} `json:"data"`
+}
+
+// UserEventData is the payload of every user.* event.
+type UserEventData struct {
+	User          types.UserDTO `json:"user"`
+	PreviousEmail string        `json:"previousEmail,omitempty"`
+	PreviousRoles []string      `json:"previousRoles,omitempty"`
+}
+
+// New stamps a new event with a random ID and the current time.
+func New(eventType, key string, data interface{}) Event {
+	return Event{
+		ID:         newID(),
+		Type:       eventType,
+		Key:        key,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+}
+
+// NewUserEvent builds a user.* event keyed by the user ID.
+func NewUserEvent(eventType string, data UserEventData) Event {
+	return New(eventType, strconv.FormatUint(uint64(data.User.ID), 10), data)
+}
+
+func newID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return "evt_" + hex.EncodeToString(buf)
+}
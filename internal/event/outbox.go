@@ -0,0 +1,26 @@
+package event
+
+import (
+	"encoding/json"
+
+	"gorm.io/gorm"
+
+	"usermgmt/internal/model"
+)
+
+// Record writes e to the outbox inside tx, so the event is committed or rolled back together with
+// the change it describes. The relay publishes it after commit.
+func Record(tx *gorm.DB, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&model.OutboxEvent{
+		EventID:       e.ID,
+		Type:          e.Type,
+		AggregateKey:  e.Key,
+		Payload:       string(body),
+		Status:        model.OutboxPending,
+		NextAttemptAt: e.OccurredAt,
+	}).Error
+}
@@ -0,0 +1,159 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+
+	"usermgmt/internal/config"
+	"usermgmt/internal/model"
+	"usermgmt/internal/retry"
+)
+
+// publishTimeout bounds one sink call; a claimed event is leased for twice as long.
+const publishTimeout = 30 * time.Second
+
+// Relay publishes committed outbox events to every registered sink, at least once. An event is
+// marked published only after all sinks accepted it; a sink that fails is retried with backoff
+// while the sinks that succeeded are not called again.
+type Relay struct {
+	db   *gorm.DB
+	conf config.OutboxConf
+
+	mu    sync.RWMutex
+	sinks []Sink
+}
+
+// NewRelay builds a relay with defaults filled in for unset config values.
+func NewRelay(db *gorm.DB, conf config.OutboxConf, sinks ...Sink) *Relay {
+	if conf.Batch <= 0 {
+		conf.Batch = 100
+	}
+	if conf.BaseBackoff <= 0 {
+		conf.BaseBackoff = 5 * time.Second
+	}
+	if conf.MaxBackoff < conf.BaseBackoff {
+		conf.MaxBackoff = conf.BaseBackoff
+	}
+	return &Relay{db: db, conf: conf, sinks: sinks}
+}
+
+// Register adds a sink, e.g. a NATS or Kafka adapter wired at startup. Sink names must be unique
+// because they record per-event progress.
+func (r *Relay) Register(sink Sink) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sinks = append(r.sinks, sink)
+}
+
+// PublishDue publishes up to one batch of pending events whose next attempt is due, oldest first,
+// and returns how many were attempted. Each event is claimed by pushing its next attempt past the
+// lease, so concurrent relays (e.g. several replicas) do not publish it twice.
+func (r *Relay) PublishDue(ctx context.Context, now time.Time) (int, error) {
+	db := r.db.WithContext(ctx)
+
+	var due []model.OutboxEvent
+	if err := db.Where("status = ? AND next_attempt_at <= ?", model.OutboxPending, now).
+		Order("id").
+		Limit(r.conf.Batch).
+		Find(&due).Error; err != nil {
+		return 0, err
+	}
+
+	lease := now.Add(2 * publishTimeout)
+	attempted := 0
+	for i := range due {
+		row := &due[i]
+		claim := db.Model(&model.OutboxEvent{}).
+			Where("id = ? AND status = ? AND next_attempt_at = ?", row.ID, model.OutboxPending, row.NextAttemptAt).
+			Update("next_attempt_at", lease)
+		if claim.Error != nil {
+			return attempted, claim.Error
+		}
+		if claim.RowsAffected == 0 {
+			continue
+		}
+		attempted++
+		r.publish(ctx, row)
+	}
+	return attempted, nil
+}
+
+// Purge deletes published events older than Retention and returns how many were removed.
+func (r *Relay) Purge(ctx context.Context, now time.Time) (int64, error) {
+	if r.conf.Retention <= 0 {
+		return 0, nil
+	}
+	result := r.db.WithContext(ctx).
+		Where("status = ? AND published_at < ?", model.OutboxPublished, now.Add(-r.conf.Retention)).
+		Delete(&model.OutboxEvent{})
+	return result.RowsAffected, result.Error
+}
+
+// publish hands row to the sinks that have not accepted it yet and records the progress.
+func (r *Relay) publish(ctx context.Context, row *model.OutboxEvent) {
+	r.mu.RLock()
+	sinks := append([]Sink(nil), r.sinks...)
+	r.mu.RUnlock()
+
+	done := make(map[string]struct{})
+	for _, name := range strings.Split(row.Sinks, ",") {
+		if name != "" {
+			done[name] = struct{}{}
+		}
+	}
+
+	msg := Message{
+		ID:         row.EventID,
+		Type:       row.Type,
+		Key:        row.AggregateKey,
+		OccurredAt: row.CreatedAt,
+		Body:       []byte(row.Payload),
+	}
+	var failures []error
+	for _, sink := range sinks {
+		if _, ok := done[sink.Name()]; ok {
+			continue
+		}
+		sinkCtx, cancel := context.WithTimeout(ctx, publishTimeout)
+		err := sink.Publish(sinkCtx, msg)
+		cancel()
+		if err != nil {
+			failures = append(failures, errors.New(sink.Name()+": "+err.Error()))
+			continue
+		}
+		done[sink.Name()] = struct{}{}
+	}
+
+	names := make([]string, 0, len(done))
+	for _, sink := range sinks {
+		if _, ok := done[sink.Name()]; ok {
+			names = append(names, sink.Name())
+		}
+	}
+	row.Attempts++
+	updates := map[string]interface{}{
+		"attempts": row.Attempts,
+		"sinks":    strings.Join(names, ","),
+	}
+	now := time.Now()
+	if len(failures) == 0 {
+		updates["status"] = model.OutboxPublished
+		updates["published_at"] = now
+		updates["last_error"] = ""
+	} else {
+		err := errors.Join(failures...)
+		updates["last_error"] = retry.Truncate(err.Error())
+		updates["next_attempt_at"] = now.Add(retry.Backoff(r.conf.BaseBackoff, r.conf.MaxBackoff, row.Attempts))
+		logx.WithContext(ctx).Errorf("publish outbox event %s (%s) attempt %d failed: %v", row.EventID, row.Type, row.Attempts, err)
+	}
+
+	if err := r.db.WithContext(ctx).Model(&model.OutboxEvent{}).Where("id = ?", row.ID).Updates(updates).Error; err != nil {
+		logx.WithContext(ctx).Errorf("record outbox event %s failed: %v", row.EventID, err)
+	}
+}
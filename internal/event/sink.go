@@ -0,0 +1,23 @@
+package event
+
+import (
+	"context"
+	"time"
+)
+
+// Message is an outbox event as handed to sinks. Body is the JSON-encoded Event envelope; ID is
+// its idempotency key. Delivery is at-least-once, so a sink may see the same ID more than once.
+type Message struct {
+	ID         string
+	Type       string
+	Key        string
+	OccurredAt time.Time
+	Body       []byte
+}
+
+// Sink publishes messages to one destination. Publish must only return nil once the destination
+// has accepted the message; an error makes the relay retry it later.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, msg Message) error
+}
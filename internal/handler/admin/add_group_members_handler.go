@@ -0,0 +1,43 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	"usermgmt/internal/errorx"
+	adminlogic "usermgmt/internal/logic/admin"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/types"
+	"usermgmt/pkg/response"
+)
+
+func AddGroupMembersHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name, err := parseGroupFromPath(r)
+		if err != nil {
+			svcCtx.Responder.Fail(w, r, err)
+			return
+		}
+
+		var req types.AddGroupMembersRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			svcCtx.Responder.Fail(w, r, errorx.FromParseError(err))
+			return
+		}
+
+		if err := svcCtx.Validator.StructCtx(r.Context(), req); err != nil {
+			svcCtx.Responder.Fail(w, r, err)
+			return
+		}
+
+		logic := adminlogic.NewAddGroupMembersLogic(r.Context(), svcCtx)
+		resp, err := logic.Add(name, &req)
+		if err != nil {
+			svcCtx.Responder.Fail(w, r, err)
+			return
+		}
+
+		response.Success(w, r, resp)
+	}
+}
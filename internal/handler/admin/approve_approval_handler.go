@@ -0,0 +1,45 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	"usermgmt/internal/errorx"
+	adminlogic "usermgmt/internal/logic/admin"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/types"
+	"usermgmt/pkg/response"
+)
+
+func ApproveApprovalHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseApprovalIDFromPath(r)
+		if err != nil {
+			svcCtx.Responder.Fail(w, r, err)
+			return
+		}
+
+		var req types.ReviewApprovalRequest
+		if r.ContentLength != 0 {
+			if err := httpx.Parse(r, &req); err != nil {
+				svcCtx.Responder.Fail(w, r, errorx.FromParseError(err))
+				return
+			}
+		}
+
+		if err := svcCtx.Validator.StructCtx(r.Context(), req); err != nil {
+			svcCtx.Responder.Fail(w, r, err)
+			return
+		}
+
+		logic := adminlogic.NewReviewApprovalLogic(r.Context(), svcCtx)
+		resp, err := logic.Approve(uint(id), &req)
+		if err != nil {
+			svcCtx.Responder.Fail(w, r, err)
+			return
+		}
+
+		response.Success(w, r, resp)
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"usermgmt/internal/errorx"
 	adminlogic "usermgmt/internal/logic/admin"
+	"usermgmt/internal/logic/common"
 	"usermgmt/internal/svc"
 	"usermgmt/internal/types"
 	"usermgmt/pkg/response"
@@ -16,29 +17,29 @@ func AssignRolesHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userID, err := parseUserIDFromPath(r)
 		if err != nil {
-			response.Error(w, r, http.StatusBadRequest, errorx.ErrValidation.Code, err.Error(), nil)
+			svcCtx.Responder.Fail(w, r, err)
 			return
 		}
 
 		var req types.AssignRolesRequest
 		if err := httpx.Parse(r, &req); err != nil {
-			response.Error(w, r, http.StatusBadRequest, errorx.ErrValidation.Code, err.Error(), nil)
+			svcCtx.Responder.Fail(w, r, errorx.FromParseError(err))
 			return
 		}
 
 		if err := svcCtx.Validator.StructCtx(r.Context(), req); err != nil {
-			appErr := errorx.FromValidationError(err)
-			response.Error(w, r, appErr.Status, appErr.Code, appErr.Message, appErr.Details)
+			svcCtx.Responder.Fail(w, r, err)
 			return
 		}
 
 		logic := adminlogic.NewAssignRolesLogic(r.Context(), svcCtx)
 		resp, err := logic.Assign(uint(userID), &req)
 		if err != nil {
-			handleError(w, r, err)
+			svcCtx.Responder.Fail(w, r, err)
 			return
 		}
 
+		w.Header().Set("ETag", common.RoleETag(resp.User.RoleVersion))
 		response.Success(w, r, resp)
 	}
 }
@@ -0,0 +1,28 @@
+package admin
+
+import (
+	"net/http"
+
+	adminlogic "usermgmt/internal/logic/admin"
+	"usermgmt/internal/svc"
+	"usermgmt/pkg/response"
+)
+
+func CloseAccessReviewHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseReviewIDFromPath(r)
+		if err != nil {
+			svcCtx.Responder.Fail(w, r, err)
+			return
+		}
+
+		logic := adminlogic.NewCloseAccessReviewLogic(r.Context(), svcCtx)
+		resp, err := logic.Close(uint(id))
+		if err != nil {
+			svcCtx.Responder.Fail(w, r, err)
+			return
+		}
+
+		response.Success(w, r, resp)
+	}
+}
@@ -0,0 +1,37 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	"usermgmt/internal/errorx"
+	adminlogic "usermgmt/internal/logic/admin"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/types"
+	"usermgmt/pkg/response"
+)
+
+func CreateGroupHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.CreateGroupRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			svcCtx.Responder.Fail(w, r, errorx.FromParseError(err))
+			return
+		}
+
+		if err := svcCtx.Validator.StructCtx(r.Context(), req); err != nil {
+			svcCtx.Responder.Fail(w, r, err)
+			return
+		}
+
+		logic := adminlogic.NewCreateGroupLogic(r.Context(), svcCtx)
+		resp, err := logic.Create(&req)
+		if err != nil {
+			svcCtx.Responder.Fail(w, r, err)
+			return
+		}
+
+		response.Success(w, r, resp)
+	}
+}
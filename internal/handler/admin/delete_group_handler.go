@@ -0,0 +1,26 @@
+package admin
+
+import (
+	"net/http"
+
+	adminlogic "usermgmt/internal/logic/admin"
+	"usermgmt/internal/svc"
+)
+
+func DeleteGroupHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name, err := parseGroupFromPath(r)
+		if err != nil {
+			svcCtx.Responder.Fail(w, r, err)
+			return
+		}
+
+		logic := adminlogic.NewDeleteGroupLogic(r.Context(), svcCtx)
+		if err := logic.Delete(name); err != nil {
+			svcCtx.Responder.Fail(w, r, err)
+			return
+		}
+
+		svcCtx.Responder.Done(w, r, "groupDeleted")
+	}
+}
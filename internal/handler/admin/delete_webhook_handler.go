@@ -0,0 +1,26 @@
+package admin
+
+import (
+	"net/http"
+
+	adminlogic "usermgmt/internal/logic/admin"
+	"usermgmt/internal/svc"
+)
+
+func DeleteWebhookHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseWebhookIDFromPath(r)
+		if err != nil {
+			svcCtx.Responder.Fail(w, r, err)
+			return
+		}
+
+		logic := adminlogic.NewDeleteWebhookLogic(r.Context(), svcCtx)
+		if err := logic.Delete(uint(id)); err != nil {
+			svcCtx.Responder.Fail(w, r, err)
+			return
+		}
+
+		svcCtx.Responder.Done(w, r, "webhookDeleted")
+	}
+}
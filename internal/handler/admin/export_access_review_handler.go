@@ -0,0 +1,49 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	adminlogic "usermgmt/internal/logic/admin"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/types"
+)
+
+func ExportAccessReviewHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseReviewIDFromPath(r)
+		if err != nil {
+			svcCtx.Responder.Fail(w, r, err)
+			return
+		}
+
+		var req types.ExportAccessReviewRequest
+		if err := httpx.ParseForm(r, &req); err != nil {
+			svcCtx.Responder.Fail(w, r, err)
+			return
+		}
+		req.Format = adminlogic.ResolveFormat(req.Format, r.Header.Get("Accept"))
+
+		if err := svcCtx.Validator.StructCtx(r.Context(), req); err != nil {
+			svcCtx.Responder.Fail(w, r, err)
+			return
+		}
+
+		logic := adminlogic.NewExportAccessReviewLogic(r.Context(), svcCtx)
+		review, err := logic.Load(uint(id))
+		if err != nil {
+			svcCtx.Responder.Fail(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", adminlogic.ContentType(req.Format))
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"access-review-%d.%s\"", review.ID, req.Format))
+		// The campaign is fully loaded, so a write error here means the client went away.
+		if err := logic.Export(w, review, req.Format); err != nil {
+			logx.WithContext(r.Context()).Errorf("write access review export failed: %v", err)
+		}
+	}
+}
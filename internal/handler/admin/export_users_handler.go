@@ -0,0 +1,61 @@
+package admin
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	adminlogic "usermgmt/internal/logic/admin"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/types"
+)
+
+func ExportUsersHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.ExportUsersRequest
+		if err := httpx.ParseForm(r, &req); err != nil {
+			svcCtx.Responder.Fail(w, r, err)
+			return
+		}
+		req.Format = adminlogic.ResolveFormat(req.Format, r.Header.Get("Accept"))
+
+		if err := svcCtx.Validator.StructCtx(r.Context(), req); err != nil {
+			svcCtx.Responder.Fail(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", adminlogic.ContentType(req.Format))
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"users.%s\"", req.Format))
+
+		logic := adminlogic.NewExportUsersLogic(r.Context(), svcCtx)
+		out := &countingWriter{w: w}
+		if err := logic.Export(out, &req); err != nil {
+			if out.n == 0 {
+				svcCtx.Responder.Fail(w, r, err)
+				return
+			}
+			// The status and part of the rows are already sent, so an error body would only be
+			// appended to the export. Drop the connection instead, so that the client sees an
+			// incomplete response rather than a complete-looking truncated file.
+			logx.WithContext(r.Context()).Errorf("export users aborted after %d bytes: %v", out.n, err)
+			if conn, _, err := http.NewResponseController(w).Hijack(); err == nil {
+				conn.Close()
+			}
+		}
+	}
+}
+
+// countingWriter records how much of the response body has been written.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
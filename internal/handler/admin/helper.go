@@ -1,33 +1,70 @@
 package admin
 
 import (
-	"errors"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"usermgmt/internal/errorx"
-	"usermgmt/pkg/response"
 )
 
-// handleError unifies error responses for admin handlers.
-func handleError(w http.ResponseWriter, r *http.Request, err error) {
-	if err == nil {
-		return
+func parseUserIDFromPath(r *http.Request) (uint64, error) {
+	return parseIDAfter(r, "users", "id")
+}
+
+func parseRoleFromPath(r *http.Request) (string, error) {
+	return parseSegmentAfter(r, "roles", "role")
+}
+
+func parseGroupFromPath(r *http.Request) (string, error) {
+	return parseSegmentAfter(r, "groups", "group")
+}
+
+func parsePermissionFromPath(r *http.Request) (string, error) {
+	return parseSegmentAfter(r, "permissions", "permission")
+}
+
+func parseMemberIDFromPath(r *http.Request) (uint64, error) {
+	return parseIDAfter(r, "members", "id")
+}
+
+func parseApprovalIDFromPath(r *http.Request) (uint64, error) {
+	return parseIDAfter(r, "approvals", "id")
+}
+
+func parseReviewIDFromPath(r *http.Request) (uint64, error) {
+	return parseIDAfter(r, "reviews", "id")
+}
+
+func parseWebhookIDFromPath(r *http.Request) (uint64, error) {
+	return parseIDAfter(r, "webhooks", "id")
+}
+
+func parseDeliveryIDFromPath(r *http.Request) (uint64, error) {
+	return parseIDAfter(r, "deliveries", "deliveryId")
+}
+
+// parseIDAfter reads the numeric path segment following name as the parameter field.
+func parseIDAfter(r *http.Request, name, field string) (uint64, error) {
+	segment, err := parseSegmentAfter(r, name, field)
+	if err != nil {
+		return 0, err
 	}
-	if appErr, ok := err.(*errorx.AppError); ok {
-		response.Error(w, r, appErr.Status, appErr.Code, appErr.Message, appErr.Details)
-		return
+	id, err := strconv.ParseUint(segment, 10, 64)
+	if err != nil {
+		return 0, errorx.InvalidField(field, "number")
 	}
-	response.Error(w, r, errorx.ErrInternal.Status, errorx.ErrInternal.Code, errorx.ErrInternal.Message, nil)
+	return id, nil
 }
 
-func parseUserIDFromPath(r *http.Request) (uint64, error) {
+// parseSegmentAfter returns the path segment following the given static segment. A missing one is
+// reported as VALIDATION_FAILED for field, the parameter name of the route.
+func parseSegmentAfter(r *http.Request, name, field string) (string, error) {
 	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
 	for i := 0; i < len(segments)-1; i++ {
-		if segments[i] == "users" {
-			return strconv.ParseUint(segments[i+1], 10, 64)
+		if segments[i] == name && segments[i+1] != "" {
+			return segments[i+1], nil
 		}
 	}
-	return 0, errors.New("用户ID缺失")
+	return "", errorx.InvalidField(field, "required")
 }
@@ -0,0 +1,36 @@
+package admin
+
+import (
+	"net/http"
+
+	adminlogic "usermgmt/internal/logic/admin"
+	"usermgmt/internal/logic/common"
+	"usermgmt/internal/svc"
+	"usermgmt/pkg/response"
+)
+
+func RevokeRoleHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := parseUserIDFromPath(r)
+		if err != nil {
+			svcCtx.Responder.Fail(w, r, err)
+			return
+		}
+
+		roleName, err := parseRoleFromPath(r)
+		if err != nil {
+			svcCtx.Responder.Fail(w, r, err)
+			return
+		}
+
+		logic := adminlogic.NewRevokeRoleLogic(r.Context(), svcCtx)
+		resp, err := logic.Revoke(uint(userID), roleName)
+		if err != nil {
+			svcCtx.Responder.Fail(w, r, err)
+			return
+		}
+
+		w.Header().Set("ETag", common.RoleETag(resp.User.RoleVersion))
+		response.Success(w, r, resp)
+	}
+}
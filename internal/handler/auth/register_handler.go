@@ -16,20 +16,19 @@ func RegisterHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.RegisterRequest
 		if err := httpx.Parse(r, &req); err != nil {
-			response.Error(w, r, http.StatusBadRequest, errorx.ErrValidation.Code, err.Error(), nil)
+			svcCtx.Responder.Fail(w, r, errorx.FromParseError(err))
 			return
 		}
 
 		if err := svcCtx.Validator.StructCtx(r.Context(), req); err != nil {
-			appErr := errorx.FromValidationError(err)
-			response.Error(w, r, appErr.Status, appErr.Code, appErr.Message, appErr.Details)
+			svcCtx.Responder.Fail(w, r, err)
 			return
 		}
 
 		logic := auth.NewRegisterLogic(r.Context(), svcCtx)
 		user, err := logic.Register(&req)
 		if err != nil {
-			handleError(w, r, err)
+			svcCtx.Responder.Fail(w, r, err)
 			return
 		}
 
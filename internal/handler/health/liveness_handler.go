@@ -0,0 +1,18 @@
+package health
+
+import (
+	"net/http"
+
+	"usermgmt/internal/logic/health"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/types"
+	"usermgmt/pkg/response"
+)
+
+// LivenessHandler answers as long as the process serves HTTP. It checks no dependency: a
+// database outage must not get the instance restarted, only taken out of rotation by /readyz.
+func LivenessHandler(_ *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response.Success(w, r, &types.HealthResponse{Status: health.StatusOK})
+	}
+}
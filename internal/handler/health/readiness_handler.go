@@ -0,0 +1,22 @@
+package health
+
+import (
+	"net/http"
+
+	"usermgmt/internal/logic/health"
+	"usermgmt/internal/svc"
+	"usermgmt/pkg/response"
+)
+
+func ReadinessHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logic := health.NewReadinessLogic(r.Context(), svcCtx)
+		resp := logic.Check()
+		if resp.Status != health.StatusOK {
+			response.JSON(w, r, http.StatusServiceUnavailable, resp)
+			return
+		}
+
+		response.Success(w, r, resp)
+	}
+}
@@ -0,0 +1,16 @@
+package health
+
+import (
+	"net/http"
+
+	"usermgmt/internal/logic/health"
+	"usermgmt/internal/svc"
+	"usermgmt/pkg/response"
+)
+
+func VersionHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logic := health.NewVersionLogic(r.Context(), svcCtx)
+		response.Success(w, r, logic.Version())
+	}
+}
@@ -0,0 +1,24 @@
+package org
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"usermgmt/internal/errorx"
+)
+
+// parseMemberIDFromPath reads the user ID from /api/v1/org/members/:id.
+func parseMemberIDFromPath(r *http.Request) (uint64, error) {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	for i := 0; i < len(segments)-1; i++ {
+		if segments[i] == "members" {
+			id, err := strconv.ParseUint(segments[i+1], 10, 64)
+			if err != nil {
+				return 0, errorx.InvalidField("id", "number")
+			}
+			return id, nil
+		}
+	}
+	return 0, errorx.InvalidField("id", "required")
+}
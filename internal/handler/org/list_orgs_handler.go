@@ -0,0 +1,22 @@
+package org
+
+import (
+	"net/http"
+
+	orglogic "usermgmt/internal/logic/org"
+	"usermgmt/internal/svc"
+	"usermgmt/pkg/response"
+)
+
+func ListOrgsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logic := orglogic.NewListOrgsLogic(r.Context(), svcCtx)
+		resp, err := logic.List()
+		if err != nil {
+			svcCtx.Responder.Fail(w, r, err)
+			return
+		}
+
+		response.Success(w, r, resp)
+	}
+}
@@ -0,0 +1,26 @@
+package org
+
+import (
+	"net/http"
+
+	orglogic "usermgmt/internal/logic/org"
+	"usermgmt/internal/svc"
+)
+
+func RemoveMemberHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := parseMemberIDFromPath(r)
+		if err != nil {
+			svcCtx.Responder.Fail(w, r, err)
+			return
+		}
+
+		logic := orglogic.NewRemoveMemberLogic(r.Context(), svcCtx)
+		if err := logic.Remove(uint(userID)); err != nil {
+			svcCtx.Responder.Fail(w, r, err)
+			return
+		}
+
+		svcCtx.Responder.Done(w, r, "memberRemoved")
+	}
+}
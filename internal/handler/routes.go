@@ -3,11 +3,16 @@ package handler
 import (
 	"net/http"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/zeromicro/go-zero/rest"
 
 	"usermgmt/internal/handler/admin"
 	"usermgmt/internal/handler/auth"
+	"usermgmt/internal/handler/health"
+	"usermgmt/internal/handler/org"
+	scimhandler "usermgmt/internal/handler/scim"
 	userhandler "usermgmt/internal/handler/user"
+	"usermgmt/internal/middleware"
 	"usermgmt/internal/svc"
 )
 
@@ -26,6 +31,24 @@ func RegisterHandlers(server *rest.Server, ctx *svc.ServiceContext) {
 		},
 	}
 
+	healthGroup := []rest.Route{
+		{
+			Method:  http.MethodGet,
+			Path:    "/healthz",
+			Handler: health.LivenessHandler(ctx),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/readyz",
+			Handler: health.ReadinessHandler(ctx),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/version",
+			Handler: health.VersionHandler(ctx),
+		},
+	}
+
 	userGroup := []rest.Route{
 		{
 			Method:  http.MethodGet,
@@ -42,27 +65,358 @@ func RegisterHandlers(server *rest.Server, ctx *svc.ServiceContext) {
 			Path:    "/api/v1/me/password",
 			Handler: ctx.AuthMiddleware(userhandler.ChangePasswordHandler(ctx)),
 		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/me/reviews",
+			Handler: ctx.AuthMiddleware(userhandler.ListReviewItemsHandler(ctx)),
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/api/v1/me/reviews/:id",
+			Handler: ctx.AuthMiddleware(userhandler.DecideReviewItemHandler(ctx)),
+		},
 	}
 
 	adminGroup := []rest.Route{
 		{
 			Method:  http.MethodGet,
 			Path:    "/api/v1/admin/users",
-			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(admin.ListUsersHandler(ctx))),
+			Handler: ctx.AuthMiddleware(ctx.TenantMiddleware(ctx.OrgAdminGuard()(admin.ListUsersHandler(ctx)))),
 		},
 		{
 			Method:  http.MethodPatch,
 			Path:    "/api/v1/admin/users/:id/status",
-			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(admin.UpdateUserStatusHandler(ctx))),
+			Handler: ctx.AuthMiddleware(ctx.TenantMiddleware(ctx.OrgAdminGuard("support")(admin.UpdateUserStatusHandler(ctx)))),
 		},
 		{
 			Method:  http.MethodPost,
 			Path:    "/api/v1/admin/users/:id/roles",
 			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(admin.AssignRolesHandler(ctx))),
 		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/api/v1/admin/users/:id/roles/:role",
+			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(admin.GrantRoleHandler(ctx))),
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/api/v1/admin/users/:id/roles/:role",
+			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(admin.RevokeRoleHandler(ctx))),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/api/v1/admin/users/bulk",
+			Handler: ctx.AuthMiddleware(ctx.TenantMiddleware(ctx.OrgAdminGuard()(admin.BulkUsersHandler(ctx)))),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/api/v1/admin/users/import",
+			Handler: ctx.AuthMiddleware(ctx.TenantMiddleware(ctx.RoleGuard("admin")(admin.ImportUsersHandler(ctx)))),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/admin/users/export",
+			Handler: ctx.AuthMiddleware(ctx.TenantMiddleware(ctx.OrgAdminGuard()(admin.ExportUsersHandler(ctx)))),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/admin/users/:id/permissions",
+			Handler: ctx.AuthMiddleware(ctx.TenantMiddleware(ctx.OrgAdminGuard()(admin.UserPermissionsHandler(ctx)))),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/admin/users/:id/permissions/:permission",
+			Handler: ctx.AuthMiddleware(ctx.TenantMiddleware(ctx.OrgAdminGuard()(admin.ExplainPermissionHandler(ctx)))),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/admin/roles",
+			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(admin.ListRolesHandler(ctx))),
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/api/v1/admin/roles/:role/parent",
+			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(admin.SetRoleParentHandler(ctx))),
+		},
+	}
+
+	policyGroup := []rest.Route{
+		{
+			Method:  http.MethodPost,
+			Path:    "/api/v1/admin/policy/explain",
+			Handler: ctx.AuthMiddleware(ctx.TenantMiddleware(ctx.RoleGuard("admin")(admin.ExplainPolicyHandler(ctx)))),
+		},
+	}
+
+	// Sensitive changes submitted by one admin wait here for a second admin.
+	approvalGroup := []rest.Route{
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/admin/approvals",
+			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(admin.ListApprovalsHandler(ctx))),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/admin/approvals/:id",
+			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(admin.GetApprovalHandler(ctx))),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/api/v1/admin/approvals/:id/approve",
+			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(admin.ApproveApprovalHandler(ctx))),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/api/v1/admin/approvals/:id/reject",
+			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(admin.RejectApprovalHandler(ctx))),
+		},
+	}
+
+	// Access review campaigns are managed by admins; reviewers decide through /api/v1/me/reviews.
+	reviewGroup := []rest.Route{
+		{
+			Method:  http.MethodPost,
+			Path:    "/api/v1/admin/reviews",
+			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(admin.CreateAccessReviewHandler(ctx))),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/admin/reviews",
+			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(admin.ListAccessReviewsHandler(ctx))),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/admin/reviews/:id",
+			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(admin.GetAccessReviewHandler(ctx))),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/api/v1/admin/reviews/:id/close",
+			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(admin.CloseAccessReviewHandler(ctx))),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/admin/reviews/:id/export",
+			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(admin.ExportAccessReviewHandler(ctx))),
+		},
+	}
+
+	webhookGroup := []rest.Route{
+		{
+			Method:  http.MethodPost,
+			Path:    "/api/v1/admin/webhooks",
+			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(admin.CreateWebhookHandler(ctx))),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/admin/webhooks",
+			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(admin.ListWebhooksHandler(ctx))),
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/api/v1/admin/webhooks/:id",
+			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(admin.UpdateWebhookHandler(ctx))),
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/api/v1/admin/webhooks/:id",
+			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(admin.DeleteWebhookHandler(ctx))),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/admin/webhooks/:id/deliveries",
+			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(admin.ListWebhookDeliveriesHandler(ctx))),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/api/v1/admin/webhooks/:id/deliveries/:deliveryId/redeliver",
+			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(admin.RedeliverWebhookHandler(ctx))),
+		},
+	}
+
+	groupGroup := []rest.Route{
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/admin/groups",
+			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(admin.ListGroupsHandler(ctx))),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/api/v1/admin/groups",
+			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(admin.CreateGroupHandler(ctx))),
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/api/v1/admin/groups/:group",
+			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(admin.DeleteGroupHandler(ctx))),
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/api/v1/admin/groups/:group/roles",
+			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(admin.SetGroupRolesHandler(ctx))),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/api/v1/admin/groups/:group/members",
+			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(admin.AddGroupMembersHandler(ctx))),
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/api/v1/admin/groups/:group/members/:id",
+			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(admin.RemoveGroupMemberHandler(ctx))),
+		},
+	}
+
+	// Organisations are created by super admins; members are managed inside the resolved tenant.
+	orgGroup := []rest.Route{
+		{
+			Method:  http.MethodPost,
+			Path:    "/api/v1/admin/orgs",
+			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(org.CreateOrgHandler(ctx))),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/admin/orgs",
+			Handler: ctx.AuthMiddleware(ctx.RoleGuard("admin")(org.ListOrgsHandler(ctx))),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/api/v1/org/members",
+			Handler: ctx.AuthMiddleware(ctx.TenantMiddleware(ctx.OrgAdminGuard()(org.ListMembersHandler(ctx)))),
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/api/v1/org/members/:id",
+			Handler: ctx.AuthMiddleware(ctx.TenantMiddleware(ctx.OrgAdminGuard()(org.UpsertMemberHandler(ctx)))),
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/api/v1/org/members/:id",
+			Handler: ctx.AuthMiddleware(ctx.TenantMiddleware(ctx.OrgAdminGuard()(org.RemoveMemberHandler(ctx)))),
+		},
+	}
+
+	// SCIM 2.0 provisioning for identity providers, authenticated by the static SCIM bearer token.
+	scimGroup := []rest.Route{
+		{
+			Method:  http.MethodGet,
+			Path:    "/scim/v2/ServiceProviderConfig",
+			Handler: ctx.SCIMMiddleware(scimhandler.ServiceProviderConfigHandler(ctx)),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/scim/v2/ResourceTypes",
+			Handler: ctx.SCIMMiddleware(scimhandler.ListResourceTypesHandler(ctx)),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/scim/v2/ResourceTypes/:id",
+			Handler: ctx.SCIMMiddleware(scimhandler.GetResourceTypeHandler(ctx)),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/scim/v2/Schemas",
+			Handler: ctx.SCIMMiddleware(scimhandler.ListSchemasHandler(ctx)),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/scim/v2/Schemas/:id",
+			Handler: ctx.SCIMMiddleware(scimhandler.GetSchemaHandler(ctx)),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/scim/v2/Users",
+			Handler: ctx.SCIMMiddleware(scimhandler.ListUsersHandler(ctx)),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/scim/v2/Users",
+			Handler: ctx.SCIMMiddleware(scimhandler.CreateUserHandler(ctx)),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/scim/v2/Users/:id",
+			Handler: ctx.SCIMMiddleware(scimhandler.GetUserHandler(ctx)),
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/scim/v2/Users/:id",
+			Handler: ctx.SCIMMiddleware(scimhandler.ReplaceUserHandler(ctx)),
+		},
+		{
+			Method:  http.MethodPatch,
+			Path:    "/scim/v2/Users/:id",
+			Handler: ctx.SCIMMiddleware(scimhandler.PatchUserHandler(ctx)),
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/scim/v2/Users/:id",
+			Handler: ctx.SCIMMiddleware(scimhandler.DeleteUserHandler(ctx)),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/scim/v2/Groups",
+			Handler: ctx.SCIMMiddleware(scimhandler.ListGroupsHandler(ctx)),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/scim/v2/Groups",
+			Handler: ctx.SCIMMiddleware(scimhandler.CreateGroupHandler(ctx)),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/scim/v2/Groups/:id",
+			Handler: ctx.SCIMMiddleware(scimhandler.GetGroupHandler(ctx)),
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    "/scim/v2/Groups/:id",
+			Handler: ctx.SCIMMiddleware(scimhandler.ReplaceGroupHandler(ctx)),
+		},
+		{
+			Method:  http.MethodPatch,
+			Path:    "/scim/v2/Groups/:id",
+			Handler: ctx.SCIMMiddleware(scimhandler.PatchGroupHandler(ctx)),
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/scim/v2/Groups/:id",
+			Handler: ctx.SCIMMiddleware(scimhandler.DeleteGroupHandler(ctx)),
+		},
 	}
 
-	server.AddRoutes(authGroup)
-	server.AddRoutes(userGroup)
-	server.AddRoutes(adminGroup)
+	server.AddRoutes(withRequestMiddleware(ctx, authGroup))
+	server.AddRoutes(withRequestMiddleware(ctx, healthGroup))
+	server.AddRoutes(withRequestMiddleware(ctx, userGroup))
+	server.AddRoutes(withRequestMiddleware(ctx, adminGroup))
+	server.AddRoutes(withRequestMiddleware(ctx, policyGroup))
+	server.AddRoutes(withRequestMiddleware(ctx, approvalGroup))
+	server.AddRoutes(withRequestMiddleware(ctx, reviewGroup))
+	server.AddRoutes(withRequestMiddleware(ctx, webhookGroup))
+	server.AddRoutes(withRequestMiddleware(ctx, groupGroup))
+	server.AddRoutes(withRequestMiddleware(ctx, orgGroup))
+	server.AddRoutes(withRequestMiddleware(ctx, scimGroup))
+
+	if ctx.Config.Metrics.Enabled {
+		server.AddRoutes(withRequestMiddleware(ctx, []rest.Route{
+			{
+				Method:  http.MethodGet,
+				Path:    ctx.Config.Metrics.Path,
+				Handler: promhttp.Handler().ServeHTTP,
+			},
+		}))
+	}
+}
+
+// withRequestMiddleware wraps each route in middleware.RequestMiddleware and
+// middleware.LocaleMiddleware. They are applied per route rather than with server.Use so that the
+// route knows its path template, and so that the routes returned by server.Routes (which
+// internal/apitest mounts) carry them.
+func withRequestMiddleware(ctx *svc.ServiceContext, routes []rest.Route) []rest.Route {
+	locale := middleware.NewLocaleMiddleware(ctx.I18n)
+	for i := range routes {
+		mw := middleware.NewRequestMiddleware(routes[i].Path, ctx.Config.AccessLog.SkipPaths)
+		routes[i].Handler = mw.Handle(locale.Handle(routes[i].Handler))
+	}
+	return routes
 }
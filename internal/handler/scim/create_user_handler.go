@@ -0,0 +1,29 @@
+package scim
+
+import (
+	"net/http"
+
+	adminlogic "usermgmt/internal/logic/admin"
+	scimapi "usermgmt/internal/scim"
+	"usermgmt/internal/svc"
+)
+
+func CreateUserHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req scimapi.User
+		if err := decodeBody(r, &req); err != nil {
+			badRequest(w, r, scimapi.ErrorInvalidSyntax, err)
+			return
+		}
+
+		logic := adminlogic.NewSCIMUsersLogic(r.Context(), svcCtx)
+		resp, err := logic.Create(&req)
+		if err != nil {
+			handleError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Location", resp.Meta.Location)
+		scimapi.WriteJSON(w, r, http.StatusCreated, resp)
+	}
+}
@@ -0,0 +1,27 @@
+package scim
+
+import (
+	"net/http"
+
+	adminlogic "usermgmt/internal/logic/admin"
+	scimapi "usermgmt/internal/scim"
+	"usermgmt/internal/svc"
+)
+
+func DeleteUserHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseSegmentAfter(r, "Users")
+		if err != nil {
+			badRequest(w, r, "", err)
+			return
+		}
+
+		logic := adminlogic.NewSCIMUsersLogic(r.Context(), svcCtx)
+		if err := logic.Delete(id); err != nil {
+			handleError(w, r, err)
+			return
+		}
+
+		scimapi.WriteJSON(w, r, http.StatusNoContent, nil)
+	}
+}
@@ -0,0 +1,28 @@
+package scim
+
+import (
+	"net/http"
+
+	adminlogic "usermgmt/internal/logic/admin"
+	scimapi "usermgmt/internal/scim"
+	"usermgmt/internal/svc"
+)
+
+func GetSchemaHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseSegmentAfter(r, "Schemas")
+		if err != nil {
+			badRequest(w, r, "", err)
+			return
+		}
+
+		logic := adminlogic.NewSCIMDiscoveryLogic(r.Context(), svcCtx)
+		resp, err := logic.Schema(id)
+		if err != nil {
+			handleError(w, r, err)
+			return
+		}
+
+		scimapi.WriteJSON(w, r, http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,112 @@
+package scim
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/i18n"
+	scimapi "usermgmt/internal/scim"
+)
+
+// scimTypes maps application error codes to the SCIM scimType of the error response.
+var scimTypes = map[string]string{
+	errorx.ErrInvalidFilter.Code: scimapi.ErrorInvalidFilter,
+	errorx.ErrInvalidPath.Code:   scimapi.ErrorInvalidPath,
+	errorx.ErrValidation.Code:    scimapi.ErrorInvalidValue,
+	errorx.ErrImmutable.Code:     scimapi.ErrorMutability,
+	errorx.ErrUserExists.Code:    scimapi.ErrorUniqueness,
+	errorx.ErrRoleExists.Code:    scimapi.ErrorUniqueness,
+	errorx.ErrConflict.Code:      scimapi.ErrorUniqueness,
+}
+
+// handleError answers with a SCIM error body instead of the usual {code, message}; the error is
+// mapped by errorx.From like everywhere else.
+func handleError(w http.ResponseWriter, r *http.Request, err error) {
+	if err == nil {
+		return
+	}
+	appErr := errorx.From(r.Context(), err)
+	if appErr.Code == errorx.ErrInternal.Code && !errorx.Is(err, errorx.ErrInternal) {
+		logx.WithContext(r.Context()).Errorf("unhandled error: %v", err)
+	}
+	scimapi.WriteError(w, r, appErr.Status, scimTypes[appErr.Code], errorDetail(i18n.FromContext(r.Context()), appErr))
+}
+
+// errorDetail is the message of appErr in the locale of localizer, followed by its details.
+func errorDetail(localizer *i18n.Localizer, appErr *errorx.AppError) string {
+	message := localizer.Message(appErr.Code, appErr.Message)
+	switch details := localizer.Details(appErr.Details).(type) {
+	case nil:
+		return message
+	case string:
+		return message + ": " + details
+	default:
+		encoded, err := json.Marshal(details)
+		if err != nil {
+			return message
+		}
+		return message + ": " + string(encoded)
+	}
+}
+
+// badRequest rejects a request that could not be parsed, with err in the locale of the request.
+func badRequest(w http.ResponseWriter, r *http.Request, scimType string, err error) {
+	scimapi.WriteError(w, r, http.StatusBadRequest, scimType, errorDetail(i18n.FromContext(r.Context()), errorx.From(r.Context(), err)))
+}
+
+// decodeBody reads a JSON body. httpx.Parse is not used because SCIM clients send
+// application/scim+json. The decoder's message quotes the input, so it is not passed on.
+func decodeBody(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return errorx.ErrValidation.WithDetails(errorx.Detail{Key: "scimBodyInvalid"})
+	}
+	return nil
+}
+
+// parseListQuery reads filter, startIndex, count and excludedAttributes.
+func parseListQuery(r *http.Request) (scimapi.ListQuery, error) {
+	values := r.URL.Query()
+	query := scimapi.ListQuery{Filter: values.Get("filter")}
+	if raw := values.Get("startIndex"); raw != "" {
+		start, err := strconv.Atoi(raw)
+		if err != nil {
+			return query, errorx.ErrValidation.WithDetails(errorx.Detail{Key: "scimParamNotInteger", Args: map[string]string{"param": "startIndex"}})
+		}
+		query.StartIndex = start
+	}
+	if raw := values.Get("count"); raw != "" {
+		count, err := strconv.Atoi(raw)
+		if err != nil {
+			return query, errorx.ErrValidation.WithDetails(errorx.Detail{Key: "scimParamNotInteger", Args: map[string]string{"param": "count"}})
+		}
+		query.Count = &count
+	}
+	query.ExcludedAttributes = parseExcluded(r)
+	return query, nil
+}
+
+func parseExcluded(r *http.Request) []string {
+	excluded := make([]string, 0)
+	for _, name := range strings.Split(r.URL.Query().Get("excludedAttributes"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			excluded = append(excluded, name)
+		}
+	}
+	return excluded
+}
+
+// parseSegmentAfter returns the path segment following the given static segment.
+func parseSegmentAfter(r *http.Request, name string) (string, error) {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	for i := 0; i < len(segments)-1; i++ {
+		if segments[i] == name && segments[i+1] != "" {
+			return segments[i+1], nil
+		}
+	}
+	return "", errorx.ErrValidation.WithDetails(errorx.Detail{Key: "pathParamMissing", Args: map[string]string{"param": name}})
+}
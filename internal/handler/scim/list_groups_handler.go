@@ -0,0 +1,28 @@
+package scim
+
+import (
+	"net/http"
+
+	adminlogic "usermgmt/internal/logic/admin"
+	scimapi "usermgmt/internal/scim"
+	"usermgmt/internal/svc"
+)
+
+func ListGroupsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query, err := parseListQuery(r)
+		if err != nil {
+			badRequest(w, r, scimapi.ErrorInvalidValue, err)
+			return
+		}
+
+		logic := adminlogic.NewSCIMGroupsLogic(r.Context(), svcCtx)
+		resp, err := logic.List(query)
+		if err != nil {
+			handleError(w, r, err)
+			return
+		}
+
+		scimapi.WriteJSON(w, r, http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,16 @@
+package scim
+
+import (
+	"net/http"
+
+	adminlogic "usermgmt/internal/logic/admin"
+	scimapi "usermgmt/internal/scim"
+	"usermgmt/internal/svc"
+)
+
+func ListResourceTypesHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logic := adminlogic.NewSCIMDiscoveryLogic(r.Context(), svcCtx)
+		scimapi.WriteJSON(w, r, http.StatusOK, logic.ResourceTypes())
+	}
+}
@@ -0,0 +1,33 @@
+package scim
+
+import (
+	"net/http"
+
+	adminlogic "usermgmt/internal/logic/admin"
+	scimapi "usermgmt/internal/scim"
+	"usermgmt/internal/svc"
+)
+
+func PatchGroupHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseSegmentAfter(r, "Groups")
+		if err != nil {
+			badRequest(w, r, "", err)
+			return
+		}
+		var req scimapi.PatchRequest
+		if err := decodeBody(r, &req); err != nil {
+			badRequest(w, r, scimapi.ErrorInvalidSyntax, err)
+			return
+		}
+
+		logic := adminlogic.NewSCIMGroupsLogic(r.Context(), svcCtx)
+		resp, err := logic.Patch(id, &req)
+		if err != nil {
+			handleError(w, r, err)
+			return
+		}
+
+		scimapi.WriteJSON(w, r, http.StatusOK, resp)
+	}
+}
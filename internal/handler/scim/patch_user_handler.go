@@ -0,0 +1,33 @@
+package scim
+
+import (
+	"net/http"
+
+	adminlogic "usermgmt/internal/logic/admin"
+	scimapi "usermgmt/internal/scim"
+	"usermgmt/internal/svc"
+)
+
+func PatchUserHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseSegmentAfter(r, "Users")
+		if err != nil {
+			badRequest(w, r, "", err)
+			return
+		}
+		var req scimapi.PatchRequest
+		if err := decodeBody(r, &req); err != nil {
+			badRequest(w, r, scimapi.ErrorInvalidSyntax, err)
+			return
+		}
+
+		logic := adminlogic.NewSCIMUsersLogic(r.Context(), svcCtx)
+		resp, err := logic.Patch(id, &req)
+		if err != nil {
+			handleError(w, r, err)
+			return
+		}
+
+		scimapi.WriteJSON(w, r, http.StatusOK, resp)
+	}
+}
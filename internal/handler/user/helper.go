@@ -2,19 +2,23 @@ package user
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
 
 	"usermgmt/internal/errorx"
-	"usermgmt/pkg/response"
 )
 
-// handleError unifies error responses for user handlers.
-func handleError(w http.ResponseWriter, r *http.Request, err error) {
-	if err == nil {
-		return
+// parseReviewItemIDFromPath reads the item ID from /api/v1/me/reviews/:id.
+func parseReviewItemIDFromPath(r *http.Request) (uint64, error) {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	for i := 0; i < len(segments)-1; i++ {
+		if segments[i] == "reviews" {
+			id, err := strconv.ParseUint(segments[i+1], 10, 64)
+			if err != nil {
+				return 0, errorx.InvalidField("id", "number")
+			}
+			return id, nil
+		}
 	}
-	if appErr, ok := err.(*errorx.AppError); ok {
-		response.Error(w, r, appErr.Status, appErr.Code, appErr.Message, appErr.Details)
-		return
-	}
-	response.Error(w, r, errorx.ErrInternal.Status, errorx.ErrInternal.Code, errorx.ErrInternal.Message, nil)
+	return 0, errorx.InvalidField("id", "required")
 }
@@ -13,7 +13,7 @@ func ProfileHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 		logic := user.NewProfileLogic(r.Context(), svcCtx)
 		resp, err := logic.Profile()
 		if err != nil {
-			handleError(w, r, err)
+			svcCtx.Responder.Fail(w, r, err)
 			return
 		}
 
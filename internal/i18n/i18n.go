@@ -0,0 +1,287 @@
+// Package i18n translates the messages of responses. A Bundle holds one Catalog per locale:
+// the built-in zh-CN and en catalogs (locales/*.yaml), merged with the <locale>.yaml files of an
+// optional directory, so that texts can be changed and locales added without recompiling. A
+// Localizer negotiated from Accept-Language travels in the request context.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/conf"
+	"golang.org/x/text/language"
+
+	"usermgmt/internal/errorx"
+)
+
+//go:embed locales/*.yaml
+var builtin embed.FS
+
+// DefaultLocale is the locale of the messages declared in package errorx.
+const DefaultLocale = "zh-CN"
+
+// Catalog holds the texts of one locale.
+type Catalog struct {
+	// Messages are keyed by AppError.Code.
+	Messages map[string]string `json:"messages,optional"`
+	// Validation texts are keyed by validator tag, optionally suffixed with the field kind
+	// (min.string, min.number, min.list), with "default" for unknown tags. {field} is replaced by
+	// the field label and {param} by the tag parameter.
+	Validation map[string]string `json:"validation,optional"`
+	// Fields are the labels of request fields, keyed by the name clients send.
+	Fields map[string]string `json:"fields,optional"`
+	// Texts are the error details named by errorx.Detail and the messages of successful responses.
+	// {name} placeholders are replaced by the argument of that name.
+	Texts map[string]string `json:"texts,optional"`
+}
+
+// merge copies the texts of other over those of c.
+func (c *Catalog) merge(other Catalog) {
+	for _, pair := range []struct{ dst, src *map[string]string }{
+		{&c.Messages, &other.Messages},
+		{&c.Validation, &other.Validation},
+		{&c.Fields, &other.Fields},
+		{&c.Texts, &other.Texts},
+	} {
+		if *pair.dst == nil {
+			*pair.dst = make(map[string]string, len(*pair.src))
+		}
+		for key, text := range *pair.src {
+			(*pair.dst)[key] = text
+		}
+	}
+}
+
+// Bundle is the set of catalogs of the service.
+type Bundle struct {
+	fallback string
+	locales  []string
+	catalogs map[string]*Catalog
+	matcher  language.Matcher
+}
+
+// NewBundle loads the built-in catalogs and then those of dir, if not empty, where a file named
+// after a BCP 47 tag (ja.yaml, en-GB.yaml) overrides texts of that locale or adds the locale.
+// Texts missing from a locale fall back to fallback, then to the errorx message.
+func NewBundle(fallback, dir string) (*Bundle, error) {
+	b := &Bundle{catalogs: make(map[string]*Catalog)}
+	entries, err := builtin.ReadDir("locales")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		content, err := builtin.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if err := b.add(entry.Name(), content); err != nil {
+			return nil, err
+		}
+	}
+	if dir != "" {
+		files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			if err := b.add(filepath.Base(file), content); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if fallback == "" {
+		fallback = DefaultLocale
+	}
+	fallbackTag, err := language.Parse(fallback)
+	if err != nil {
+		return nil, fmt.Errorf("default locale %q: %w", fallback, err)
+	}
+	b.fallback = fallbackTag.String()
+	if _, ok := b.catalogs[b.fallback]; !ok {
+		return nil, fmt.Errorf("default locale %s has no catalog", b.fallback)
+	}
+
+	// The matcher prefers its first tag when nothing matches, so the fallback goes first.
+	b.locales = append(b.locales, b.fallback)
+	for locale := range b.catalogs {
+		if locale != b.fallback {
+			b.locales = append(b.locales, locale)
+		}
+	}
+	sort.Strings(b.locales[1:])
+	tags := make([]language.Tag, len(b.locales))
+	for i, locale := range b.locales {
+		tags[i] = language.Make(locale)
+	}
+	b.matcher = language.NewMatcher(tags)
+	return b, nil
+}
+
+func (b *Bundle) add(name string, content []byte) error {
+	tag, err := language.Parse(strings.TrimSuffix(name, ".yaml"))
+	if err != nil {
+		return fmt.Errorf("locale file %s: %w", name, err)
+	}
+	var catalog Catalog
+	if err := conf.LoadFromYamlBytes(content, &catalog); err != nil {
+		return fmt.Errorf("locale file %s: %w", name, err)
+	}
+	locale := tag.String()
+	if b.catalogs[locale] == nil {
+		b.catalogs[locale] = &Catalog{}
+	}
+	b.catalogs[locale].merge(catalog)
+	return nil
+}
+
+// Locales lists the available locales, the default one first.
+func (b *Bundle) Locales() []string {
+	return append([]string(nil), b.locales...)
+}
+
+// Match picks the locale for an Accept-Language header, or the default one if nothing fits.
+func (b *Bundle) Match(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return b.fallback
+	}
+	_, index, confidence := b.matcher.Match(tags...)
+	if confidence == language.No {
+		return b.fallback
+	}
+	return b.locales[index]
+}
+
+// Localizer returns the localizer for locale, which should be one of Locales.
+func (b *Bundle) Localizer(locale string) *Localizer {
+	return &Localizer{bundle: b, locale: locale}
+}
+
+// Localizer translates into one locale. A nil Localizer keeps every text as given.
+type Localizer struct {
+	bundle *Bundle
+	locale string
+}
+
+// Locale is the negotiated locale, empty for a nil Localizer.
+func (l *Localizer) Locale() string {
+	if l == nil {
+		return ""
+	}
+	return l.locale
+}
+
+// lookup finds key in the locale's catalog, then in the default one.
+func (l *Localizer) lookup(key string, texts func(*Catalog) map[string]string) (string, bool) {
+	for _, locale := range []string{l.locale, l.bundle.fallback} {
+		if catalog := l.bundle.catalogs[locale]; catalog != nil {
+			if text, ok := texts(catalog)[key]; ok && text != "" {
+				return text, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Message translates the message of the error code, or returns fallback if no catalog has it.
+func (l *Localizer) Message(code, fallback string) string {
+	if l == nil {
+		return fallback
+	}
+	if text, ok := l.lookup(code, messages); ok {
+		return text
+	}
+	return fallback
+}
+
+// Validation describes one failed field check, e.g. "Username must be at least 3 characters long".
+func (l *Localizer) Validation(item errorx.ValidationErrorItem) string {
+	if l == nil {
+		return ""
+	}
+	template, ok := l.lookup(item.Tag+"."+item.Kind, validation)
+	if !ok {
+		if template, ok = l.lookup(item.Tag, validation); !ok {
+			template, _ = l.lookup("default", validation)
+		}
+	}
+	return strings.NewReplacer("{field}", l.field(item.Field), "{param}", item.Param).Replace(template)
+}
+
+// field labels a field, keeping the index of an element such as roles[0].
+func (l *Localizer) field(name string) string {
+	base, index, _ := strings.Cut(name, "[")
+	label, ok := l.lookup(base, fields)
+	if !ok {
+		return name
+	}
+	if index != "" {
+		return label + "[" + index
+	}
+	return label
+}
+
+// Text renders the text key with args in place of its {name} placeholders. A text no catalog has
+// renders as its key.
+func (l *Localizer) Text(key string, args map[string]string) string {
+	if l == nil {
+		return key
+	}
+	text, ok := l.lookup(key, texts)
+	if !ok {
+		return key
+	}
+	for name, value := range args {
+		text = strings.ReplaceAll(text, "{"+name+"}", value)
+	}
+	return text
+}
+
+// Details renders an errorx.Detail as its text and fills in the Message of validation items;
+// other details are returned as they are.
+func (l *Localizer) Details(details interface{}) interface{} {
+	if l == nil {
+		return details
+	}
+	switch details := details.(type) {
+	case errorx.Detail:
+		return l.Text(details.Key, details.Args)
+	case []errorx.ValidationErrorItem:
+		localized := make([]errorx.ValidationErrorItem, len(details))
+		for i, item := range details {
+			item.Message = l.Validation(item)
+			localized[i] = item
+		}
+		return localized
+	}
+	return details
+}
+
+func messages(c *Catalog) map[string]string   { return c.Messages }
+func validation(c *Catalog) map[string]string { return c.Validation }
+func fields(c *Catalog) map[string]string     { return c.Fields }
+func texts(c *Catalog) map[string]string      { return c.Texts }
+
+type localizerKey struct{}
+
+// WithLocalizer returns a copy of ctx carrying l.
+func WithLocalizer(ctx context.Context, l *Localizer) context.Context {
+	return context.WithValue(ctx, localizerKey{}, l)
+}
+
+// FromContext returns the localizer of the request, or nil, which leaves texts untranslated.
+func FromContext(ctx context.Context) *Localizer {
+	l, _ := ctx.Value(localizerKey{}).(*Localizer)
+	return l
+}
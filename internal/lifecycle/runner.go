@@ -0,0 +1,144 @@
+// Package lifecycle runs the API process: the REST server next to the background workers, and an
+// ordered graceful shutdown once go-zero's proc package catches SIGTERM or SIGINT.
+package lifecycle
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/proc"
+	"github.com/zeromicro/go-zero/core/service"
+	"github.com/zeromicro/go-zero/rest"
+
+	"usermgmt/internal/svc"
+)
+
+const (
+	defaultWrapUpTime        = time.Second
+	defaultDrainTimeout      = 10 * time.Second
+	defaultWorkerStopTimeout = 5 * time.Second
+	// forceQuitMargin is left for closing the database and flushing logs and spans.
+	forceQuitMargin = 2 * time.Second
+)
+
+// Runner starts the server and the workers and, on shutdown:
+//
+//  1. fails /readyz as soon as the signal arrives (go-zero's wrap-up phase);
+//  2. after Shutdown.WrapUpTime, stops accepting connections and waits up to
+//     Lifecycle.DrainTimeout for in-flight requests, then closes the connections left;
+//  3. stops the workers and waits up to Lifecycle.WorkerStopTimeout for their current run;
+//  4. closes the database pool.
+//
+// The HTTP server must be drained before the workers stop, as requests still write outbox events.
+type Runner struct {
+	svcCtx  *svc.ServiceContext
+	server  *rest.Server
+	workers []service.Service
+
+	mu         sync.Mutex
+	httpServer *http.Server
+	done       chan struct{}
+}
+
+// NewRunner creates a runner for server; its routes must already be registered.
+func NewRunner(svcCtx *svc.ServiceContext, server *rest.Server) *Runner {
+	return &Runner{
+		svcCtx: svcCtx,
+		server: server,
+		done:   make(chan struct{}),
+	}
+}
+
+// Add registers a background worker. Its Stop must make Start return.
+func (r *Runner) Add(worker service.Service) {
+	r.workers = append(r.workers, worker)
+}
+
+// Run blocks until the process has shut down.
+func (r *Runner) Run() {
+	wrapUp, drain, workerStop := r.timeouts()
+	// go-zero kills the process Shutdown.WaitTime after the signal; make sure the steps fit.
+	if need := wrapUp + drain + workerStop + forceQuitMargin; r.svcCtx.Config.Shutdown.WaitTime < need {
+		proc.SetTimeToForceQuit(need)
+	}
+	proc.AddWrapUpListener(r.svcCtx.StartDraining)
+
+	var workers sync.WaitGroup
+	for _, worker := range r.workers {
+		workers.Add(1)
+		go func(worker service.Service) {
+			defer workers.Done()
+			worker.Start()
+		}(worker)
+	}
+	proc.AddShutdownListener(func() {
+		r.shutdown(drain, workerStop, &workers)
+	})
+
+	go r.server.StartWithOpts(func(srv *http.Server) {
+		r.mu.Lock()
+		r.httpServer = srv
+		r.mu.Unlock()
+	})
+	<-r.done
+}
+
+func (r *Runner) shutdown(drain, workerStop time.Duration, workers *sync.WaitGroup) {
+	defer close(r.done)
+
+	r.mu.Lock()
+	srv := r.httpServer
+	r.mu.Unlock()
+	if srv != nil {
+		logx.Infof("draining in-flight requests for up to %s", drain)
+		ctx, cancel := context.WithTimeout(context.Background(), drain)
+		err := srv.Shutdown(ctx)
+		cancel()
+		if err != nil {
+			logx.Errorf("requests still running after %s, closing their connections: %v", drain, err)
+			srv.Close()
+		}
+	}
+
+	logx.Info("stopping background workers")
+	for _, worker := range r.workers {
+		worker.Stop()
+	}
+	stopped := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(workerStop):
+		logx.Errorf("background workers still running after %s, abandoning them", workerStop)
+	}
+
+	if err := r.svcCtx.Close(); err != nil {
+		logx.Errorf("close database: %v", err)
+	}
+	logx.Info("shutdown complete")
+}
+
+// timeouts returns the configured durations. Optional sections left out of the YAML keep their
+// zero values, so go-zero's and the Lifecycle defaults are applied here.
+func (r *Runner) timeouts() (wrapUp, drain, workerStop time.Duration) {
+	c := r.svcCtx.Config
+	wrapUp = c.Shutdown.WrapUpTime
+	if wrapUp <= 0 {
+		wrapUp = defaultWrapUpTime
+	}
+	drain = c.Lifecycle.DrainTimeout
+	if drain <= 0 {
+		drain = defaultDrainTimeout
+	}
+	workerStop = c.Lifecycle.WorkerStopTimeout
+	if workerStop <= 0 {
+		workerStop = defaultWorkerStopTimeout
+	}
+	return wrapUp, drain, workerStop
+}
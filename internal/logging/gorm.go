@@ -0,0 +1,102 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// GORM log levels accepted by ParseGormLevel.
+const (
+	GormSilent = "silent"
+	GormError  = "error"
+	GormWarn   = "warn"
+	GormInfo   = "info"
+)
+
+// GormLogger writes GORM's logs through logx with the statement's context, so that SQL lines carry
+// the request ID, user ID and trace of the request that ran them. Statements are logged with their
+// placeholders only: bound values (password hashes, emails…) never reach the log. Failed and slow
+// statements are logged from LevelWarn up, every statement at LevelInfo.
+type GormLogger struct {
+	Level         logger.LogLevel
+	SlowThreshold time.Duration
+}
+
+// NewGormLogger returns a GormLogger at level, one of the Gorm* names.
+func NewGormLogger(level string, slowThreshold time.Duration) (*GormLogger, error) {
+	parsed, err := ParseGormLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	return &GormLogger{Level: parsed, SlowThreshold: slowThreshold}, nil
+}
+
+// ParseGormLevel converts a level name; "" is GormWarn.
+func ParseGormLevel(level string) (logger.LogLevel, error) {
+	switch level {
+	case GormSilent:
+		return logger.Silent, nil
+	case GormError:
+		return logger.Error, nil
+	case "", GormWarn:
+		return logger.Warn, nil
+	case GormInfo:
+		return logger.Info, nil
+	default:
+		return 0, fmt.Errorf("unknown gorm log level %q", level)
+	}
+}
+
+func (l *GormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	copied := *l
+	copied.Level = level
+	return &copied
+}
+
+func (l *GormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.Level >= logger.Info {
+		logx.WithContext(ctx).Infof(msg, args...)
+	}
+}
+
+func (l *GormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.Level >= logger.Warn {
+		logx.WithContext(ctx).Infof(msg, args...)
+	}
+}
+
+func (l *GormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.Level >= logger.Error {
+		logx.WithContext(ctx).Errorf(msg, args...)
+	}
+}
+
+// ParamsFilter drops the bound values, so that fc in Trace renders placeholders only.
+func (l *GormLogger) ParamsFilter(_ context.Context, sql string, _ ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
+
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.Level <= logger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	log := logx.WithContext(ctx).WithDuration(elapsed)
+	switch {
+	case err != nil && l.Level >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		log.Errorw("sql failed", logx.Field("sql", sql), logx.Field("rows", rows), logx.Field("error", err.Error()))
+	case l.SlowThreshold > 0 && elapsed > l.SlowThreshold && l.Level >= logger.Warn:
+		sql, rows := fc()
+		log.Sloww("slow sql", logx.Field("sql", sql), logx.Field("rows", rows))
+	case l.Level >= logger.Info:
+		sql, rows := fc()
+		log.Infow("sql", logx.Field("sql", sql), logx.Field("rows", rows))
+	}
+}
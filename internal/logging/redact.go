@@ -0,0 +1,63 @@
+// Package logging keeps personal data and credentials out of the logs: every logx line goes
+// through Redact (see InstallRedaction), and GORM logs through logx without bound values.
+package logging
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces a secret value.
+const Redacted = "[REDACTED]"
+
+var (
+	// "password": "x", password=x, newPassword: x, accessToken="x", client_secret=x …
+	secretPairPattern = regexp.MustCompile(`(?i)("?[a-z_]*(?:password|passwd|secret|token)"?\s*[:=]\s*)("(?:[^"\\]|\\.)*"|[^\s,;&}]+)`)
+	bearerPattern     = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[a-z0-9\-._~+/]+=*`)
+	jwtPattern        = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)
+	bcryptPattern     = regexp.MustCompile(`\$2[abxy]?\$\d\d\$[./A-Za-z0-9]{53,}`)
+	emailPattern      = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+)
+
+// sensitiveKeys are the log field keys whose values are dropped whole.
+var sensitiveKeys = []string{"password", "passwd", "secret", "token", "authorization", "cookie"}
+
+// Redact masks credentials and email addresses in s: values of password/secret/token pairs,
+// bearer and basic credentials, JWTs and bcrypt hashes become [REDACTED], and emails keep only
+// their first character and domain (a***@example.com) so that lines stay useful for support.
+func Redact(s string) string {
+	if s == "" {
+		return s
+	}
+	s = secretPairPattern.ReplaceAllStringFunc(s, func(pair string) string {
+		m := secretPairPattern.FindStringSubmatch(pair)
+		if strings.HasPrefix(m[2], `"`) {
+			return m[1] + `"` + Redacted + `"`
+		}
+		return m[1] + Redacted
+	})
+	s = bearerPattern.ReplaceAllString(s, "$1 "+Redacted)
+	s = jwtPattern.ReplaceAllString(s, Redacted)
+	s = bcryptPattern.ReplaceAllString(s, Redacted)
+	return emailPattern.ReplaceAllStringFunc(s, MaskEmail)
+}
+
+// MaskEmail keeps the first character of the local part and the domain of email.
+func MaskEmail(email string) string {
+	at := strings.LastIndexByte(email, '@')
+	if at <= 0 {
+		return email
+	}
+	return email[:1] + "***" + email[at:]
+}
+
+// IsSensitiveKey reports whether a field called key holds a credential.
+func IsSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// InstallRedaction wraps the current logx writer so that every line is redacted. Call it after
+// logx.MustSetup; go-zero ignores later SetUp calls, so the wrapper stays in place.
+func InstallRedaction() {
+	if w := logx.Reset(); w != nil {
+		logx.SetWriter(redactingWriter{next: w})
+	}
+}
+
+// redactingWriter is a logx.Writer that redacts messages and field values before passing them on.
+type redactingWriter struct {
+	next logx.Writer
+}
+
+func (w redactingWriter) Alert(v any) {
+	w.next.Alert(redactValue(v))
+}
+
+func (w redactingWriter) Close() error {
+	return w.next.Close()
+}
+
+func (w redactingWriter) Debug(v any, fields ...logx.LogField) {
+	w.next.Debug(redactValue(v), redactFields(fields)...)
+}
+
+func (w redactingWriter) Error(v any, fields ...logx.LogField) {
+	w.next.Error(redactValue(v), redactFields(fields)...)
+}
+
+func (w redactingWriter) Info(v any, fields ...logx.LogField) {
+	w.next.Info(redactValue(v), redactFields(fields)...)
+}
+
+func (w redactingWriter) Severe(v any) {
+	w.next.Severe(redactValue(v))
+}
+
+func (w redactingWriter) Slow(v any, fields ...logx.LogField) {
+	w.next.Slow(redactValue(v), redactFields(fields)...)
+}
+
+func (w redactingWriter) Stack(v any) {
+	w.next.Stack(redactValue(v))
+}
+
+func (w redactingWriter) Stat(v any, fields ...logx.LogField) {
+	w.next.Stat(redactValue(v), redactFields(fields)...)
+}
+
+// redactValue redacts strings, errors and Stringers; anything else is redacted in its JSON form,
+// which is what logx would write for it.
+func redactValue(v any) any {
+	switch value := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return Redact(value)
+	case error:
+		return Redact(value.Error())
+	case fmt.Stringer:
+		return Redact(value.String())
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return v
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	return json.RawMessage(Redact(string(raw)))
+}
+
+func redactFields(fields []logx.LogField) []logx.LogField {
+	if len(fields) == 0 {
+		return fields
+	}
+	redacted := make([]logx.LogField, len(fields))
+	for i, field := range fields {
+		if IsSensitiveKey(field.Key) {
+			redacted[i] = logx.Field(field.Key, Redacted)
+			continue
+		}
+		redacted[i] = logx.Field(field.Key, redactValue(field.Value))
+	}
+	return redacted
+}
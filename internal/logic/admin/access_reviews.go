@@ -0,0 +1,63 @@
+package admin
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+
+	"usermgmt/internal/logic/common"
+	"usermgmt/internal/model"
+	"usermgmt/internal/types"
+)
+
+// reviewProgress counts the items of each review and how many already carry a decision.
+func reviewProgress(db *gorm.DB, reviewIDs []uint) (map[uint][2]int64, error) {
+	var rows []struct {
+		ReviewID uint
+		Total    int64
+		Decided  int64
+	}
+	progress := make(map[uint][2]int64, len(reviewIDs))
+	if len(reviewIDs) == 0 {
+		return progress, nil
+	}
+	if err := db.Model(&model.AccessReviewItem{}).
+		Select("review_id, COUNT(*) AS total, SUM(CASE WHEN decision <> '' THEN 1 ELSE 0 END) AS decided").
+		Where("review_id IN ?", reviewIDs).
+		Group("review_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		progress[row.ReviewID] = [2]int64{row.Total, row.Decided}
+	}
+	return progress, nil
+}
+
+func toAccessReviewDTO(review *model.AccessReview, progress [2]int64) types.AccessReviewDTO {
+	roles := make([]string, 0)
+	if review.Roles != "" {
+		roles = strings.Split(review.Roles, ",")
+	}
+	dto := types.AccessReviewDTO{
+		ID:         review.ID,
+		Name:       review.Name,
+		Roles:      roles,
+		Status:     review.Status,
+		Deadline:   review.Deadline,
+		AutoRevoke: review.AutoRevoke,
+		CreatedBy:  review.CreatedBy,
+		ClosedBy:   review.ClosedBy,
+		ClosedAt:   review.ClosedAt,
+		Total:      progress[0],
+		Decided:    progress[1],
+		CreatedAt:  review.CreatedAt,
+	}
+	if len(review.Items) > 0 {
+		dto.Items = make([]types.AccessReviewItemDTO, 0, len(review.Items))
+		for i := range review.Items {
+			dto.Items = append(dto.Items, common.ToAccessReviewItemDTO(&review.Items[i]))
+		}
+	}
+	return dto
+}
@@ -0,0 +1,95 @@
+package admin
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/model"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+	"usermgmt/internal/types"
+)
+
+// AddGroupMembersLogic adds users to a group; existing members are left as they are.
+type AddGroupMembersLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewAddGroupMembersLogic constructor.
+func NewAddGroupMembersLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AddGroupMembersLogic {
+	return &AddGroupMembersLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *AddGroupMembersLogic) Add(name string, req *types.AddGroupMembersRequest) (*types.GroupDTO, error) {
+	defer tracing.Logic(&l.ctx, "AddGroupMembersLogic.Add")()
+
+	ids := uniqueIDs(req.UserIDs)
+
+	var dto types.GroupDTO
+	err := l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		group, err := loadGroup(tx, name)
+		if err != nil {
+			return err
+		}
+
+		var existing []uint
+		if err := tx.Model(&model.User{}).Where("id IN ?", ids).Pluck("id", &existing).Error; err != nil {
+			return err
+		}
+		if len(existing) != len(ids) {
+			found := make(map[uint]struct{}, len(existing))
+			for _, id := range existing {
+				found[id] = struct{}{}
+			}
+			missing := make([]uint, 0)
+			for _, id := range ids {
+				if _, ok := found[id]; !ok {
+					missing = append(missing, id)
+				}
+			}
+			return errorx.ErrUserNotFound.WithDetails(map[string]interface{}{"missingUserIds": missing})
+		}
+
+		previous, err := snapshotRoles(tx, ids)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.GroupMember{GroupID: group.ID, UserID: id})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 && len(group.Roles) > 0 {
+				if err := bumpRoleVersion(tx, id, nil); err != nil {
+					return err
+				}
+			}
+		}
+		if err := recordRolesChangedFor(tx, previous); err != nil {
+			return err
+		}
+
+		dto, err = toGroupDTO(tx, group)
+		return err
+	})
+	if err != nil {
+		var appErr *errorx.AppError
+		if errors.As(err, &appErr) {
+			return nil, appErr
+		}
+		l.Errorf("add group members failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+	return &dto, nil
+}
@@ -0,0 +1,211 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/logic/common"
+	"usermgmt/internal/model"
+	"usermgmt/internal/policy"
+	"usermgmt/internal/repository"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/types"
+)
+
+// Role change modes stored in a change request payload.
+const (
+	changeModeReplace = "replace"
+	changeModeGrant   = "grant"
+)
+
+// rolesChange is the payload of a held user.roles.assign request. RoleVersion is the user's
+// role version at submission, so the change is refused if the roles were edited meanwhile.
+type rolesChange struct {
+	Mode        string     `json:"mode"`
+	Roles       []string   `json:"roles"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	RoleVersion uint       `json:"roleVersion"`
+}
+
+// statusChange is the payload of a held user.status.update request.
+type statusChange struct {
+	Status string `json:"status"`
+}
+
+func containsRole(names []string, role string) bool {
+	for _, name := range names {
+		if strings.EqualFold(strings.TrimSpace(name), role) {
+			return true
+		}
+	}
+	return false
+}
+
+// needsRoleApproval reports whether giving roles to user adds a sensitive role it does not
+// already hold directly.
+func needsRoleApproval(svcCtx *svc.ServiceContext, user *model.User, roles []model.Role) bool {
+	sensitive := svcCtx.Config.Approvals.SensitiveRoles
+	if len(sensitive) == 0 {
+		return false
+	}
+	held := make(map[uint]struct{}, len(user.RoleGrants))
+	for _, grant := range user.RoleGrants {
+		held[grant.RoleID] = struct{}{}
+	}
+	for _, role := range roles {
+		if _, ok := held[role.ID]; !ok && containsRole(sensitive, role.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// needsStatusApproval reports whether status disables a user holding a privileged role.
+func needsStatusApproval(svcCtx *svc.ServiceContext, user *model.User, status string) bool {
+	privileged := svcCtx.Config.Approvals.PrivilegedRoles
+	if status != model.UserStatusDisabled || user.Status == model.UserStatusDisabled || len(privileged) == 0 {
+		return false
+	}
+	for _, name := range common.ActiveRoleNames(user) {
+		if containsRole(privileged, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// submitChangeRequest stores the change as pending and returns ErrApprovalPending carrying the
+// request, so callers can return it as-is.
+func submitChangeRequest(ctx context.Context, svcCtx *svc.ServiceContext, requests repository.ChangeRequestRepository, action string, target *model.User, payload interface{}) error {
+	requester := actorID(ctx)
+	if requester == nil {
+		return errorx.ErrForbidden
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("encode change request failed: %v", err)
+		return errorx.ErrInternal
+	}
+
+	ttl := svcCtx.Config.Approvals.TTL
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	request := model.ChangeRequest{
+		Action:       action,
+		TargetUserID: target.ID,
+		Payload:      string(raw),
+		Status:       model.ChangeRequestPending,
+		RequestedBy:  *requester,
+		ExpiresAt:    time.Now().Add(ttl),
+	}
+	if err := requests.Create(ctx, &request); err != nil {
+		logx.WithContext(ctx).Errorf("create change request failed: %v", err)
+		return errorx.ErrInternal
+	}
+	logx.WithContext(ctx).Infof("change request %d (%s on user %d) awaits approval", request.ID, action, target.ID)
+	return errorx.ErrApprovalPending.WithDetails(toChangeRequestDTO(&request))
+}
+
+// applyChangeRequest performs an approved change inside tx on behalf of its requester, re-running
+// the safeguards against the current state of the target, which is loaded into user.
+func applyChangeRequest(tx *gorm.DB, svcCtx *svc.ServiceContext, request *model.ChangeRequest, user *model.User) error {
+	if err := tx.Scopes(common.PreloadActiveRoles).First(user, request.TargetUserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errorx.ErrUserNotFound
+		}
+		return err
+	}
+	guard := newSafeguardFor(svcCtx, request.RequestedBy)
+	requester := request.RequestedBy
+
+	switch request.Action {
+	case policy.ActionUserStatusUpdate:
+		var change statusChange
+		if err := json.Unmarshal([]byte(request.Payload), &change); err != nil {
+			return err
+		}
+		if err := guard.checkStatus(user, change.Status); err != nil {
+			return err
+		}
+		return guard.preserveAdmins(tx, func() error {
+			return tx.Model(&model.User{}).Where("id = ?", user.ID).Update("status", change.Status).Error
+		})
+
+	case policy.ActionUserRolesAssign:
+		var change rolesChange
+		if err := json.Unmarshal([]byte(request.Payload), &change); err != nil {
+			return err
+		}
+		roles := make([]model.Role, 0)
+		if len(change.Roles) > 0 {
+			found, missing, err := findRolesByName(tx, change.Roles)
+			if err != nil {
+				return err
+			}
+			if len(missing) > 0 {
+				return errorx.ErrRoleNotFound.WithDetails(map[string]interface{}{"missingRoles": missing})
+			}
+			roles = found
+		}
+
+		if change.Mode == changeModeGrant {
+			if err := bumpRoleVersion(tx, user.ID, &change.RoleVersion); err != nil {
+				return err
+			}
+			grants := make([]model.UserRole, 0, len(roles))
+			for _, role := range roles {
+				grants = append(grants, model.UserRole{UserID: user.ID, RoleID: role.ID, ExpiresAt: change.ExpiresAt, GrantedBy: &requester})
+			}
+			return tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "user_id"}, {Name: "role_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"expires_at", "granted_by"}),
+			}).Create(&grants).Error
+		}
+
+		if err := guard.checkRoleRemoval(user, removedRoles(user, common.ExtractRoleNames(roles))); err != nil {
+			return err
+		}
+		if err := bumpRoleVersion(tx, user.ID, &change.RoleVersion); err != nil {
+			return err
+		}
+		return guard.preserveAdmins(tx, func() error {
+			return replaceUserRoles(tx, user.ID, roles, &requester)
+		})
+	}
+	return errorx.ErrValidation.WithDetails(errorx.Detail{Key: "unknownChangeAction", Args: map[string]string{"action": request.Action}})
+}
+
+// expirePendingRequests marks pending requests past their deadline as expired.
+func expirePendingRequests(db *gorm.DB, now time.Time) error {
+	return db.Model(&model.ChangeRequest{}).
+		Where("status = ? AND expires_at <= ?", model.ChangeRequestPending, now).
+		Update("status", model.ChangeRequestExpired).Error
+}
+
+func toChangeRequestDTO(request *model.ChangeRequest) types.ChangeRequestDTO {
+	payload := make(map[string]interface{})
+	_ = json.Unmarshal([]byte(request.Payload), &payload)
+	return types.ChangeRequestDTO{
+		ID:           request.ID,
+		Action:       request.Action,
+		TargetUserID: request.TargetUserID,
+		Payload:      payload,
+		Status:       request.Status,
+		RequestedBy:  request.RequestedBy,
+		ReviewedBy:   request.ReviewedBy,
+		Comment:      request.Comment,
+		Error:        request.Error,
+		ExpiresAt:    request.ExpiresAt,
+		ReviewedAt:   request.ReviewedAt,
+		CreatedAt:    request.CreatedAt,
+	}
+}
@@ -0,0 +1,76 @@
+package admin
+
+import (
+	"testing"
+
+	"usermgmt/internal/config"
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/logic/common"
+	"usermgmt/internal/logic/logictest"
+	"usermgmt/internal/model"
+	"usermgmt/internal/policy"
+	"usermgmt/internal/types"
+)
+
+func TestSubmitChangeRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		submit     func(f *logictest.Fixture) error
+		target     string
+		wantAction string
+	}{
+		{
+			name: "grant sensitive role",
+			submit: func(f *logictest.Fixture) error {
+				_, err := NewGrantRoleLogic(f.As("root", "admin"), f.Svc).Grant(f.Users["alice"].ID, "admin", nil)
+				return err
+			},
+			target:     "alice",
+			wantAction: policy.ActionUserRolesAssign,
+		},
+		{
+			name: "assign sensitive role",
+			submit: func(f *logictest.Fixture) error {
+				_, err := NewAssignRolesLogic(f.As("root", "admin"), f.Svc).Assign(f.Users["alice"].ID, &types.AssignRolesRequest{Roles: []string{"admin", "viewer"}})
+				return err
+			},
+			target:     "alice",
+			wantAction: policy.ActionUserRolesAssign,
+		},
+		{
+			name: "disable privileged user",
+			submit: func(f *logictest.Fixture) error {
+				_, err := NewUpdateUserStatusLogic(f.As("root", "admin"), f.Svc).Update(f.Users["second"].ID, &types.UpdateUserStatusRequest{Status: model.UserStatusDisabled})
+				return err
+			},
+			target:     "second",
+			wantAction: policy.ActionUserStatusUpdate,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t, func(c *config.Config) {
+				c.Approvals = config.ApprovalConf{SensitiveRoles: []string{"admin"}, PrivilegedRoles: []string{"admin"}}
+			})
+			before := roleList(common.ActiveRoleNames(f.User(t, tt.target)))
+
+			logictest.ExpectCode(t, tt.submit(f), errorx.ErrApprovalPending)
+			requests := f.Store.ChangeRequestsSubmitted()
+			if len(requests) != 1 {
+				t.Fatalf("stored %d change requests, want 1", len(requests))
+			}
+			request := requests[0]
+			if request.Action != tt.wantAction || request.TargetUserID != f.Users[tt.target].ID ||
+				request.RequestedBy != f.Users["root"].ID || request.Status != model.ChangeRequestPending {
+				t.Fatalf("change request = %+v", request)
+			}
+			user := f.User(t, tt.target)
+			if got := roleList(common.ActiveRoleNames(user)); got != before || user.Status != model.UserStatusEnabled {
+				t.Fatalf("%s changed before approval: roles %q, status %s", tt.target, got, user.Status)
+			}
+			if events := f.Store.RecordedEvents(); len(events) != 0 {
+				t.Fatalf("events = %v, want none", logictest.EventTypes(events))
+			}
+		})
+	}
+}
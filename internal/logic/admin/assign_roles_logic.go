@@ -2,6 +2,7 @@ package admin
 
 import (
 	"context"
+	"errors"
 	"strings"
 
 	"github.com/zeromicro/go-zero/core/logx"
@@ -10,11 +11,15 @@ import (
 	"usermgmt/internal/errorx"
 	"usermgmt/internal/logic/common"
 	"usermgmt/internal/model"
+	"usermgmt/internal/policy"
+	"usermgmt/internal/repository"
 	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
 	"usermgmt/internal/types"
+	"usermgmt/pkg/contextx"
 )
 
-// AssignRolesLogic rebinds role memberships for a user.
+// AssignRolesLogic replaces the full role set of a user, guarded by the role version (If-Match).
 type AssignRolesLogic struct {
 	logx.Logger
 	ctx    context.Context
@@ -31,68 +36,83 @@ func NewAssignRolesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Assig
 }
 
 func (l *AssignRolesLogic) Assign(userID uint, req *types.AssignRolesRequest) (*types.ProfileResponse, error) {
-	db := l.svcCtx.DB.WithContext(l.ctx)
+	defer tracing.Logic(&l.ctx, "AssignRolesLogic.Assign")()
 
-	var user model.User
-	if err := db.First(&user, userID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
+	store := l.svcCtx.Store
+
+	user, err := store.Users().Get(l.ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
 			return nil, errorx.ErrUserNotFound
 		}
 		l.Errorf("load user for role assignment failed: %v", err)
 		return nil, errorx.ErrInternal
 	}
 
+	expected, ok := common.ParseRoleETag(req.IfMatch)
+	if !ok {
+		return nil, errorx.ErrValidation.WithDetails(errorx.Detail{Key: "ifMatchInvalid"})
+	}
+
+	// An empty list is allowed and removes every role from the user.
 	roleNames := normalizeRoles(req.Roles)
-	if len(roleNames) == 0 {
-		return nil, errorx.ErrValidation.WithDetails("角色列表不能为空")
+	roles := make([]model.Role, 0)
+	if len(roleNames) > 0 {
+		found, missing, err := findRoles(l.ctx, store.Roles(), roleNames)
+		if err != nil {
+			l.Errorf("load roles failed: %v", err)
+			return nil, errorx.ErrInternal
+		}
+		if len(missing) > 0 {
+			return nil, errorx.ErrValidation.WithDetails(map[string]interface{}{"missingRoles": missing})
+		}
+		roles = found
 	}
 
-	var roles []model.Role
-	if err := db.Where("name IN ?", roleNames).Find(&roles).Error; err != nil {
-		l.Errorf("load roles failed: %v", err)
-		return nil, errorx.ErrInternal
+	guard := newSafeguard(l.ctx, l.svcCtx)
+	if err := guard.checkRoleRemoval(user, removedRoles(user, common.ExtractRoleNames(roles))); err != nil {
+		return nil, err
 	}
 
-	if len(roles) != len(roleNames) {
-		existing := make(map[string]struct{})
-		for _, role := range roles {
-			existing[strings.ToLower(role.Name)] = struct{}{}
-		}
-		missing := make([]string, 0)
-		for _, role := range roleNames {
-			if _, ok := existing[strings.ToLower(role)]; !ok {
-				missing = append(missing, role)
-			}
-		}
-		return nil, errorx.ErrValidation.WithDetails(map[string]interface{}{"missingRoles": missing})
+	if err := authorize(l.ctx, l.svcCtx, policy.ActionUserRolesAssign, user, policy.Attributes{
+		"roles": roleNames,
+		"mode":  "replace",
+	}); err != nil {
+		return nil, err
 	}
 
-	if err := db.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Where("user_id = ?", userID).Delete(&model.UserRole{}).Error; err != nil {
-			return err
+	if needsRoleApproval(l.svcCtx, user, roles) {
+		if expected != nil && *expected != user.RoleVersion {
+			return nil, errorx.ErrVersionConflict
 		}
-		userRoles := make([]model.UserRole, 0, len(roles))
-		for _, role := range roles {
-			userRoles = append(userRoles, model.UserRole{UserID: userID, RoleID: role.ID})
+		return nil, submitChangeRequest(l.ctx, l.svcCtx, store.ChangeRequests(), policy.ActionUserRolesAssign, user, rolesChange{
+			Mode:        changeModeReplace,
+			Roles:       common.ExtractRoleNames(roles),
+			RoleVersion: user.RoleVersion,
+		})
+	}
+
+	previousRoles := common.ActiveRoleNames(user)
+	if err := store.Transaction(l.ctx, func(tx repository.Store) error {
+		if err := tx.Users().BumpRoleVersion(l.ctx, userID, expected); err != nil {
+			return versionError(err)
 		}
-		if len(userRoles) > 0 {
-			if err := tx.Create(&userRoles).Error; err != nil {
-				return err
-			}
+		if err := guard.preserveAdminsIn(l.ctx, tx, func() error {
+			return tx.Roles().ReplaceUserRoles(l.ctx, userID, roles, actorID(l.ctx))
+		}); err != nil {
+			return err
 		}
-		return nil
+		return recordRolesChangedIn(l.ctx, tx, user, previousRoles)
 	}); err != nil {
+		var appErr *errorx.AppError
+		if errors.As(err, &appErr) {
+			return nil, appErr
+		}
 		l.Errorf("assign roles transaction failed: %v", err)
 		return nil, errorx.ErrInternal
 	}
 
-	if err := db.Preload("Roles").First(&user, userID).Error; err != nil {
-		l.Errorf("reload user after role assignment failed: %v", err)
-		return nil, errorx.ErrInternal
-	}
-
-	dto := common.ToUserDTO(&user)
-	return &types.ProfileResponse{User: dto}, nil
+	return &types.ProfileResponse{User: common.ToUserDTO(user)}, nil
 }
 
 func normalizeRoles(roles []string) []string {
@@ -112,3 +132,60 @@ func normalizeRoles(roles []string) []string {
 	}
 	return result
 }
+
+// findRolesByName loads roles by name and reports the names that do not exist.
+func findRolesByName(db *gorm.DB, names []string) ([]model.Role, []string, error) {
+	return findRoles(db.Statement.Context, repository.NewGormStore(db).Roles(), names)
+}
+
+// findRoles is findRolesByName over a role repository.
+func findRoles(ctx context.Context, repo repository.RoleRepository, names []string) ([]model.Role, []string, error) {
+	roles, err := repo.FindByNames(ctx, names)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	missing := make([]string, 0)
+	if len(roles) != len(names) {
+		existing := make(map[string]struct{})
+		for _, role := range roles {
+			existing[strings.ToLower(role.Name)] = struct{}{}
+		}
+		for _, role := range names {
+			if _, ok := existing[strings.ToLower(role)]; !ok {
+				missing = append(missing, role)
+			}
+		}
+	}
+	return roles, missing, nil
+}
+
+// bumpRoleVersion increments the user's role version. When expected is set the update only applies
+// if the stored version still matches, which serialises concurrent editors on the user row.
+func bumpRoleVersion(tx *gorm.DB, userID uint, expected *uint) error {
+	return versionError(repository.NewGormStore(tx).Users().BumpRoleVersion(tx.Statement.Context, userID, expected))
+}
+
+// versionError maps the repository's version conflict to ErrVersionConflict.
+func versionError(err error) error {
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return errorx.ErrVersionConflict
+	}
+	return err
+}
+
+// replaceUserRoles makes roles the user's exact role set inside tx. Active grants that are kept
+// retain their expiry and grantor; expired ones are dropped and granted afresh.
+func replaceUserRoles(tx *gorm.DB, userID uint, roles []model.Role, grantedBy *uint) error {
+	return repository.NewGormStore(tx).Roles().ReplaceUserRoles(tx.Statement.Context, userID, roles, grantedBy)
+}
+
+// actorID returns the authenticated user performing the change, or nil for system/CLI callers.
+func actorID(ctx context.Context) *uint {
+	claims := contextx.MustGetClaims(ctx)
+	if claims == nil {
+		return nil
+	}
+	id := claims.UserID
+	return &id
+}
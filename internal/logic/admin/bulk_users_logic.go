@@ -0,0 +1,435 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/i18n"
+	"usermgmt/internal/logic/common"
+	"usermgmt/internal/model"
+	"usermgmt/internal/policy"
+	"usermgmt/internal/repository"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+	"usermgmt/internal/types"
+)
+
+// Bulk actions, modes and per-user outcomes.
+const (
+	BulkActionStatus      = "status"
+	BulkActionAddRoles    = "addRoles"
+	BulkActionRemoveRoles = "removeRoles"
+	BulkActionDelete      = "delete"
+
+	BulkModeAtomic     = "atomic"
+	BulkModeBestEffort = "bestEffort"
+
+	BulkOutcomeApplied    = "applied"
+	BulkOutcomeUnchanged  = "unchanged"
+	BulkOutcomePreview    = "preview"
+	BulkOutcomeFailed     = "failed"
+	BulkOutcomeRolledBack = "rolledBack"
+)
+
+// errBulkAborted signals that an atomic batch must be rolled back.
+var errBulkAborted = errors.New("bulk operation aborted")
+
+// BulkUsersLogic applies one action to many users, atomically or best-effort.
+type BulkUsersLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewBulkUsersLogic constructor.
+func NewBulkUsersLogic(ctx context.Context, svcCtx *svc.ServiceContext) *BulkUsersLogic {
+	return &BulkUsersLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *BulkUsersLogic) Apply(req *types.BulkUserRequest) (*types.BulkUserResponse, error) {
+	defer tracing.Logic(&l.ctx, "BulkUsersLogic.Apply")()
+
+	db := l.svcCtx.DB.WithContext(l.ctx)
+
+	if len(req.IDs) == 0 && req.Filter == nil {
+		return nil, errorx.ErrValidation.WithDetails(errorx.Detail{Key: "bulkTargetRequired"})
+	}
+	if req.Filter != nil && emptyFilter(req.Filter) {
+		return nil, errorx.ErrValidation.WithDetails(errorx.Detail{Key: "bulkFilterEmpty"})
+	}
+
+	// Org admins may only toggle status; role changes and deletion stay with super admins.
+	if common.IsOrgScoped(l.ctx) && req.Action != BulkActionStatus {
+		return nil, errorx.ErrForbidden
+	}
+
+	mode := req.Mode
+	if mode == "" {
+		mode = BulkModeAtomic
+	}
+
+	var roles []model.Role
+	if req.Action == BulkActionAddRoles || req.Action == BulkActionRemoveRoles {
+		roleNames := normalizeRoles(req.Roles)
+		if len(roleNames) == 0 {
+			return nil, errorx.ErrValidation.WithDetails(errorx.Detail{Key: "rolesRequired"})
+		}
+		found, missing, err := findRolesByName(db, roleNames)
+		if err != nil {
+			l.Errorf("load roles for bulk operation failed: %v", err)
+			return nil, errorx.ErrInternal
+		}
+		if len(missing) > 0 {
+			return nil, errorx.ErrValidation.WithDetails(map[string]interface{}{"missingRoles": missing})
+		}
+		roles = found
+	}
+
+	targets, missingIDs, err := l.resolveTargets(db, req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &types.BulkUserResponse{
+		Action:  req.Action,
+		Mode:    mode,
+		DryRun:  req.DryRun,
+		Results: make([]types.BulkUserResult, 0, len(targets)+len(missingIDs)),
+	}
+	for _, id := range missingIDs {
+		resp.Results = append(resp.Results, failedResult(types.BulkUserResult{UserID: id}, errorx.ErrUserNotFound))
+	}
+
+	guard := newSafeguard(l.ctx, l.svcCtx)
+
+	switch {
+	case req.DryRun:
+		for i := range targets {
+			if err := l.checkSafeguards(guard, &targets[i], req, roles); err != nil {
+				resp.Results = append(resp.Results, failedResult(types.BulkUserResult{UserID: targets[i].ID, Username: targets[i].Username}, err))
+				continue
+			}
+			resp.Results = append(resp.Results, types.BulkUserResult{
+				UserID:   targets[i].ID,
+				Username: targets[i].Username,
+				Outcome:  BulkOutcomePreview,
+				Changed:  wouldChange(&targets[i], req, roles),
+			})
+		}
+	case mode == BulkModeAtomic:
+		resp.Results = append(resp.Results, l.applyAtomic(db, guard, targets, req, roles, len(missingIDs) > 0)...)
+	default:
+		for i := range targets {
+			result := types.BulkUserResult{UserID: targets[i].ID, Username: targets[i].Username}
+			if err := db.Transaction(func(tx *gorm.DB) error {
+				return l.applyOne(tx, guard, &targets[i], req, roles)
+			}); err != nil {
+				l.Errorf("bulk %s for user %d failed: %v", req.Action, targets[i].ID, err)
+				resp.Results = append(resp.Results, failedResult(result, err))
+				continue
+			}
+			resp.Results = append(resp.Results, appliedResult(result, wouldChange(&targets[i], req, roles)))
+		}
+	}
+
+	resp.Total = len(resp.Results)
+	localizer := i18n.FromContext(l.ctx)
+	for i, result := range resp.Results {
+		if result.Outcome == BulkOutcomeFailed || result.Outcome == BulkOutcomeRolledBack {
+			resp.Failed++
+		} else {
+			resp.Succeeded++
+		}
+		if result.Code != "" {
+			resp.Results[i].Message = localizer.Message(result.Code, result.Message)
+		}
+	}
+	return resp, nil
+}
+
+// emptyFilter reports a filter without any criterion; selecting everyone has to be asked for
+// with all, so that a forgotten criterion cannot address every user.
+func emptyFilter(f *types.BulkUserFilter) bool {
+	return !f.All && strings.TrimSpace(f.Keyword) == "" && f.Status == "" && strings.TrimSpace(f.Role) == ""
+}
+
+// resolveTargets loads the users addressed by IDs and/or filter and reports unknown IDs.
+func (l *BulkUsersLogic) resolveTargets(db *gorm.DB, req *types.BulkUserRequest) ([]model.User, []uint, error) {
+	maxUsers := l.svcCtx.Config.Bulk.MaxUsers
+	if maxUsers <= 0 {
+		maxUsers = 500
+	}
+
+	ids := uniqueIDs(req.IDs)
+	if len(ids) > maxUsers {
+		return nil, nil, errorx.ErrValidation.WithDetails(map[string]interface{}{"maxUsers": maxUsers})
+	}
+
+	query := db.Model(&model.User{}).Scopes(common.PreloadActiveRoles, common.TenantScope(l.ctx))
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	if req.Filter != nil {
+		query = repository.FilterUsers(query, req.Filter.Keyword, req.Filter.Status)
+		if role := strings.TrimSpace(req.Filter.Role); role != "" {
+			query = query.Where(
+				"users.id IN (SELECT ur.user_id FROM user_roles ur JOIN roles r ON r.id = ur.role_id WHERE r.name = ? AND (ur.expires_at IS NULL OR ur.expires_at > ?))",
+				role, time.Now(),
+			)
+		}
+	}
+
+	// Fetch one row beyond the limit so oversized filters are rejected rather than truncated.
+	var users []model.User
+	if err := query.Order("id ASC").Limit(maxUsers + 1).Find(&users).Error; err != nil {
+		l.Errorf("load bulk targets failed: %v", err)
+		return nil, nil, errorx.ErrInternal
+	}
+	if len(users) > maxUsers {
+		return nil, nil, errorx.ErrValidation.WithDetails(map[string]interface{}{"maxUsers": maxUsers})
+	}
+
+	missing := make([]uint, 0)
+	if len(ids) > 0 && req.Filter == nil {
+		found := make(map[uint]struct{}, len(users))
+		for _, user := range users {
+			found[user.ID] = struct{}{}
+		}
+		for _, id := range ids {
+			if _, ok := found[id]; !ok {
+				missing = append(missing, id)
+			}
+		}
+	}
+	return users, missing, nil
+}
+
+// applyAtomic runs the whole batch in one transaction; any failure rolls back every change.
+func (l *BulkUsersLogic) applyAtomic(db *gorm.DB, guard *safeguard, targets []model.User, req *types.BulkUserRequest, roles []model.Role, abort bool) []types.BulkUserResult {
+	results := make([]types.BulkUserResult, 0, len(targets))
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if abort {
+			return errBulkAborted
+		}
+		for i := range targets {
+			result := types.BulkUserResult{UserID: targets[i].ID, Username: targets[i].Username}
+			if err := l.applyOne(tx, guard, &targets[i], req, roles); err != nil {
+				l.Errorf("bulk %s for user %d failed: %v", req.Action, targets[i].ID, err)
+				results = append(results, failedResult(result, err))
+				return errBulkAborted
+			}
+			results = append(results, appliedResult(result, wouldChange(&targets[i], req, roles)))
+		}
+		return nil
+	})
+	if err == nil {
+		return results
+	}
+	if !errors.Is(err, errBulkAborted) {
+		l.Errorf("bulk %s transaction failed: %v", req.Action, err)
+	}
+
+	// Everything that was not the failing row has been rolled back, including rows never reached.
+	done := make(map[uint]struct{}, len(results))
+	for i := range results {
+		done[results[i].UserID] = struct{}{}
+		if results[i].Outcome != BulkOutcomeFailed {
+			results[i].Outcome = BulkOutcomeRolledBack
+			results[i].Changed = false
+		}
+	}
+	for _, user := range targets {
+		if _, ok := done[user.ID]; ok {
+			continue
+		}
+		results = append(results, types.BulkUserResult{UserID: user.ID, Username: user.Username, Outcome: BulkOutcomeRolledBack})
+	}
+	return results
+}
+
+// checkSafeguards applies the admin lock-out invariants and the policy that can be decided per
+// user up front. Changes that need two-person approval are refused; they have to go through the
+// single-user endpoints.
+func (l *BulkUsersLogic) checkSafeguards(guard *safeguard, user *model.User, req *types.BulkUserRequest, roles []model.Role) error {
+	switch req.Action {
+	case BulkActionStatus:
+		if err := guard.checkStatus(user, req.Status); err != nil {
+			return err
+		}
+		if err := authorize(l.ctx, l.svcCtx, policy.ActionUserStatusUpdate, user, policy.Attributes{"status": req.Status}); err != nil {
+			return err
+		}
+		if needsStatusApproval(l.svcCtx, user, req.Status) {
+			return errorx.ErrApprovalRequired
+		}
+	case BulkActionAddRoles:
+		if err := authorize(l.ctx, l.svcCtx, policy.ActionUserRolesAssign, user, policy.Attributes{
+			"roles": common.ExtractRoleNames(roles),
+			"mode":  "grant",
+		}); err != nil {
+			return err
+		}
+		if needsRoleApproval(l.svcCtx, user, roles) {
+			return errorx.ErrApprovalRequired
+		}
+	case BulkActionRemoveRoles:
+		held := make(map[uint]struct{}, len(user.RoleGrants))
+		for _, grant := range user.RoleGrants {
+			held[grant.RoleID] = struct{}{}
+		}
+		removed := make([]string, 0, len(roles))
+		for _, role := range roles {
+			if _, ok := held[role.ID]; ok {
+				removed = append(removed, role.Name)
+			}
+		}
+		if err := guard.checkRoleRemoval(user, removed); err != nil {
+			return err
+		}
+		return authorize(l.ctx, l.svcCtx, policy.ActionUserRolesAssign, user, policy.Attributes{
+			"roles": common.ExtractRoleNames(roles),
+			"mode":  "revoke",
+		})
+	case BulkActionDelete:
+		return guard.checkDelete(user)
+	}
+	return nil
+}
+
+// applyOne executes the requested action for a single user inside tx, refusing changes that
+// would break the safeguards.
+func (l *BulkUsersLogic) applyOne(tx *gorm.DB, guard *safeguard, user *model.User, req *types.BulkUserRequest, roles []model.Role) error {
+	if err := l.checkSafeguards(guard, user, req, roles); err != nil {
+		return err
+	}
+	apply := func() error {
+		if err := l.applyAction(tx, user, req, roles); err != nil {
+			return err
+		}
+		return recordBulkChange(tx, user, req.Action)
+	}
+	if req.Action == BulkActionAddRoles {
+		return apply()
+	}
+	return guard.preserveAdmins(tx, apply)
+}
+
+// recordBulkChange records the status or roles event of the action applied to user. The event is
+// built from a reloaded copy, so user keeps the state the batch was planned with.
+func recordBulkChange(tx *gorm.DB, user *model.User, action string) error {
+	current := *user
+	switch action {
+	case BulkActionStatus:
+		return recordStatusChanged(tx, &current, user.Status)
+	case BulkActionAddRoles, BulkActionRemoveRoles:
+		return recordRolesChanged(tx, &current, common.ActiveRoleNames(user))
+	}
+	return nil
+}
+
+func (l *BulkUsersLogic) applyAction(tx *gorm.DB, user *model.User, req *types.BulkUserRequest, roles []model.Role) error {
+	switch req.Action {
+	case BulkActionStatus:
+		return tx.Model(&model.User{}).Where("id = ?", user.ID).Update("status", req.Status).Error
+	case BulkActionAddRoles:
+		if err := bumpRoleVersion(tx, user.ID, nil); err != nil {
+			return err
+		}
+		userRoles := make([]model.UserRole, 0, len(roles))
+		for _, role := range roles {
+			userRoles = append(userRoles, model.UserRole{UserID: user.ID, RoleID: role.ID, GrantedBy: actorID(l.ctx)})
+		}
+		// Re-adding an expired or time-bound grant makes it permanent.
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "role_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"expires_at": nil}),
+		}).Create(&userRoles).Error
+	case BulkActionRemoveRoles:
+		if err := bumpRoleVersion(tx, user.ID, nil); err != nil {
+			return err
+		}
+		return tx.Where("user_id = ? AND role_id IN ?", user.ID, roleIDs(roles)).Delete(&model.UserRole{}).Error
+	case BulkActionDelete:
+		if err := tx.Where("user_id = ?", user.ID).Delete(&model.UserRole{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.User{}, user.ID).Error
+	}
+	return errorx.ErrValidation
+}
+
+// wouldChange reports whether applying the action to user modifies any state.
+func wouldChange(user *model.User, req *types.BulkUserRequest, roles []model.Role) bool {
+	switch req.Action {
+	case BulkActionStatus:
+		return user.Status != req.Status
+	case BulkActionAddRoles, BulkActionRemoveRoles:
+		held := make(map[uint]struct{}, len(user.RoleGrants))
+		for _, grant := range user.RoleGrants {
+			held[grant.RoleID] = struct{}{}
+		}
+		for _, role := range roles {
+			_, ok := held[role.ID]
+			if ok == (req.Action == BulkActionRemoveRoles) {
+				return true
+			}
+		}
+		return false
+	case BulkActionDelete:
+		return true
+	}
+	return false
+}
+
+func appliedResult(result types.BulkUserResult, changed bool) types.BulkUserResult {
+	result.Outcome = BulkOutcomeApplied
+	if !changed {
+		result.Outcome = BulkOutcomeUnchanged
+	}
+	result.Changed = changed
+	return result
+}
+
+func failedResult(result types.BulkUserResult, err error) types.BulkUserResult {
+	result.Outcome = BulkOutcomeFailed
+	result.Changed = false
+	var appErr *errorx.AppError
+	if !errors.As(err, &appErr) {
+		appErr = errorx.ErrInternal
+	}
+	result.Code = appErr.Code
+	result.Message = appErr.Message
+	return result
+}
+
+func roleIDs(roles []model.Role) []uint {
+	ids := make([]uint, 0, len(roles))
+	for _, role := range roles {
+		ids = append(ids, role.ID)
+	}
+	return ids
+}
+
+func uniqueIDs(ids []uint) []uint {
+	result := make([]uint, 0, len(ids))
+	seen := make(map[uint]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		result = append(result, id)
+	}
+	return result
+}
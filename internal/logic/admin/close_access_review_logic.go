@@ -0,0 +1,196 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/logic/common"
+	"usermgmt/internal/model"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+	"usermgmt/internal/types"
+)
+
+// CloseAccessReviewLogic signs off a campaign: revoke decisions are applied, and once the deadline
+// has passed undecided grants are revoked too when the campaign has AutoRevoke set.
+type CloseAccessReviewLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewCloseAccessReviewLogic constructor.
+func NewCloseAccessReviewLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CloseAccessReviewLogic {
+	return &CloseAccessReviewLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Close closes the campaign on behalf of the caller.
+func (l *CloseAccessReviewLogic) Close(id uint) (*types.AccessReviewDTO, error) {
+	defer tracing.Logic(&l.ctx, "CloseAccessReviewLogic.Close")()
+
+	if err := l.close(id, actorID(l.ctx), time.Now()); err != nil {
+		var appErr *errorx.AppError
+		if errors.As(err, &appErr) {
+			return nil, appErr
+		}
+		l.Errorf("close access review %d failed: %v", id, err)
+		return nil, errorx.ErrInternal
+	}
+	return NewListAccessReviewsLogic(l.ctx, l.svcCtx).Get(id)
+}
+
+// CloseOverdue closes every open campaign whose deadline is at or before now and returns how many were closed.
+func (l *CloseAccessReviewLogic) CloseOverdue(now time.Time) (int, error) {
+	defer tracing.Logic(&l.ctx, "CloseAccessReviewLogic.CloseOverdue")()
+
+	db := l.svcCtx.DB.WithContext(l.ctx)
+
+	var ids []uint
+	if err := db.Model(&model.AccessReview{}).
+		Where("status = ? AND deadline <= ?", model.AccessReviewOpen, now).
+		Order("deadline").
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+
+	closed := 0
+	for _, id := range ids {
+		if err := l.close(id, nil, now); err != nil {
+			if errors.Is(err, errorx.ErrReviewClosed) {
+				continue
+			}
+			l.Errorf("close overdue access review %d failed: %v", id, err)
+			continue
+		}
+		closed++
+	}
+	return closed, nil
+}
+
+func (l *CloseAccessReviewLogic) close(id uint, closedBy *uint, now time.Time) error {
+	db := l.svcCtx.DB.WithContext(l.ctx)
+
+	var callerID uint
+	if closedBy != nil {
+		callerID = *closedBy
+	}
+	guard := newSafeguardFor(l.svcCtx, callerID)
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var review model.AccessReview
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&review, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errorx.ErrReviewNotFound
+			}
+			return err
+		}
+		if review.Status != model.AccessReviewOpen {
+			return errorx.ErrReviewClosed
+		}
+		autoRevoke := review.AutoRevoke && !now.Before(review.Deadline)
+
+		var items []model.AccessReviewItem
+		if err := tx.Where("review_id = ?", review.ID).Order("id").Find(&items).Error; err != nil {
+			return err
+		}
+		for i := range items {
+			item := &items[i]
+			switch {
+			case item.Decision == model.ReviewDecisionKeep:
+				item.Outcome = model.ReviewOutcomeKept
+			case item.Decision == model.ReviewDecisionRevoke || autoRevoke:
+				if err := l.revoke(tx, guard, &review, item, closedBy); err != nil {
+					return err
+				}
+			default:
+				item.Outcome = model.ReviewOutcomeUndecided
+			}
+			if err := tx.Model(item).Select("outcome", "error").Updates(item).Error; err != nil {
+				return err
+			}
+		}
+
+		review.Status = model.AccessReviewClosed
+		review.ClosedBy = closedBy
+		review.ClosedAt = &now
+		return tx.Model(&review).Select("status", "closed_by", "closed_at").Updates(&review).Error
+	})
+}
+
+// revoke removes the reviewed grant in a savepoint. A grant that no longer exists is recorded as
+// gone and one the safeguards refuse is recorded as skipped; neither aborts the sign-off.
+func (l *CloseAccessReviewLogic) revoke(tx *gorm.DB, guard *safeguard, review *model.AccessReview, item *model.AccessReviewItem, closedBy *uint) error {
+	err := tx.Transaction(func(tx *gorm.DB) error {
+		var user model.User
+		if err := tx.Scopes(common.PreloadActiveRoles).First(&user, item.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				item.Outcome = model.ReviewOutcomeGone
+				return nil
+			}
+			return err
+		}
+		held := false
+		for _, grant := range user.RoleGrants {
+			if grant.RoleID == item.RoleID {
+				held = true
+				break
+			}
+		}
+		if !held {
+			item.Outcome = model.ReviewOutcomeGone
+			return nil
+		}
+		if err := guard.checkRoleRemoval(&user, []string{item.RoleName}); err != nil {
+			return err
+		}
+
+		previousRoles := common.ActiveRoleNames(&user)
+		if err := guard.preserveAdmins(tx, func() error {
+			if err := tx.Where("user_id = ? AND role_id = ?", item.UserID, item.RoleID).Delete(&model.UserRole{}).Error; err != nil {
+				return err
+			}
+			return bumpRoleVersion(tx, item.UserID, nil)
+		}); err != nil {
+			return err
+		}
+		if err := recordRolesChanged(tx, &user, previousRoles); err != nil {
+			return err
+		}
+
+		details, _ := json.Marshal(map[string]interface{}{
+			"review":   review.ID,
+			"role":     item.RoleName,
+			"decision": item.Decision,
+		})
+		userID := item.UserID
+		if err := tx.Create(&model.AuditLog{
+			ActorID:      closedBy,
+			Action:       model.AuditActionReviewRevoked,
+			TargetUserID: &userID,
+			Details:      string(details),
+		}).Error; err != nil {
+			return err
+		}
+		item.Outcome = model.ReviewOutcomeRevoked
+		return nil
+	})
+
+	var appErr *errorx.AppError
+	if errors.As(err, &appErr) {
+		item.Outcome = model.ReviewOutcomeSkipped
+		item.Error = appErr.Code
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,83 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/model"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+	"usermgmt/internal/types"
+)
+
+// CreateGroupLogic creates a group, optionally with an initial role set.
+type CreateGroupLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewCreateGroupLogic constructor.
+func NewCreateGroupLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateGroupLogic {
+	return &CreateGroupLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateGroupLogic) Create(req *types.CreateGroupRequest) (*types.GroupDTO, error) {
+	defer tracing.Logic(&l.ctx, "CreateGroupLogic.Create")()
+
+	db := l.svcCtx.DB.WithContext(l.ctx)
+	name := strings.TrimSpace(req.Name)
+
+	var count int64
+	if err := db.Model(&model.Group{}).Where("name = ?", name).Count(&count).Error; err != nil {
+		l.Errorf("check group name failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+	if count > 0 {
+		return nil, errorx.ErrGroupExists
+	}
+
+	roles := make([]model.Role, 0)
+	if names := normalizeRoles(req.Roles); len(names) > 0 {
+		found, missing, err := findRolesByName(db, names)
+		if err != nil {
+			l.Errorf("query roles failed: %v", err)
+			return nil, errorx.ErrInternal
+		}
+		if len(missing) > 0 {
+			return nil, errorx.ErrValidation.WithDetails(map[string]interface{}{"missingRoles": missing})
+		}
+		roles = found
+	}
+
+	group := model.Group{Name: name, Description: strings.TrimSpace(req.Description)}
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Roles").Create(&group).Error; err != nil {
+			return err
+		}
+		return setGroupRoles(tx, group.ID, roles)
+	}); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, errorx.ErrGroupExists
+		}
+		l.Errorf("create group failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	group.Roles = roles
+	dto, err := toGroupDTO(db, &group)
+	if err != nil {
+		l.Errorf("count group members failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+	return &dto, nil
+}
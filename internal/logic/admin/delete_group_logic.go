@@ -0,0 +1,74 @@
+package admin
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/model"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+)
+
+// DeleteGroupLogic removes a group; its members lose the group-derived roles immediately.
+type DeleteGroupLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewDeleteGroupLogic constructor.
+func NewDeleteGroupLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteGroupLogic {
+	return &DeleteGroupLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeleteGroupLogic) Delete(name string) error {
+	defer tracing.Logic(&l.ctx, "DeleteGroupLogic.Delete")()
+
+	guard := newSafeguard(l.ctx, l.svcCtx)
+	err := l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		group, err := loadGroup(tx, name)
+		if err != nil {
+			return err
+		}
+		members, err := groupMemberIDs(tx, group.ID)
+		if err != nil {
+			return err
+		}
+		previous, err := snapshotRoles(tx, members)
+		if err != nil {
+			return err
+		}
+		if err := guard.preserveAdmins(tx, func() error {
+			if err := bumpGroupRoleVersions(tx, group.ID); err != nil {
+				return err
+			}
+			if err := tx.Where("group_id = ?", group.ID).Delete(&model.GroupMember{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("group_id = ?", group.ID).Delete(&model.GroupRole{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&model.Group{}, group.ID).Error
+		}); err != nil {
+			return err
+		}
+		return recordRolesChangedFor(tx, previous)
+	})
+	if err != nil {
+		var appErr *errorx.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		l.Errorf("delete group failed: %v", err)
+		return errorx.ErrInternal
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package admin
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/model"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+)
+
+// DeleteWebhookLogic removes a subscription together with its delivery log.
+type DeleteWebhookLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewDeleteWebhookLogic constructor.
+func NewDeleteWebhookLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteWebhookLogic {
+	return &DeleteWebhookLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeleteWebhookLogic) Delete(id uint) error {
+	defer tracing.Logic(&l.ctx, "DeleteWebhookLogic.Delete")()
+
+	result := l.svcCtx.DB.WithContext(l.ctx).Delete(&model.WebhookSubscription{}, id)
+	if result.Error != nil {
+		l.Errorf("delete webhook failed: %v", result.Error)
+		return errorx.ErrInternal
+	}
+	if result.RowsAffected == 0 {
+		return errorx.ErrWebhookNotFound
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"gorm.io/gorm"
+
+	"usermgmt/internal/event"
+	"usermgmt/internal/logic/common"
+	"usermgmt/internal/model"
+	"usermgmt/internal/repository"
+)
+
+// recordStatusChanged reloads user inside tx and records user.enabled or user.disabled when its
+// status differs from previous.
+func recordStatusChanged(tx *gorm.DB, user *model.User, previous string) error {
+	return recordStatusChangedIn(tx.Statement.Context, repository.NewGormStore(tx), user, previous)
+}
+
+// recordStatusChangedIn is recordStatusChanged inside the transaction of store.
+func recordStatusChangedIn(ctx context.Context, store repository.Store, user *model.User, previous string) error {
+	loaded, err := store.Users().Get(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	*user = *loaded
+	if user.Status == previous {
+		return nil
+	}
+	eventType := event.UserEnabled
+	if user.Status == model.UserStatusDisabled {
+		eventType = event.UserDisabled
+	}
+	return store.Events().Record(ctx, event.NewUserEvent(eventType, event.UserEventData{User: common.ToUserDTO(user)}))
+}
+
+// recordRolesChanged reloads user inside tx and records user.roles_changed when its effective
+// roles differ from previous.
+func recordRolesChanged(tx *gorm.DB, user *model.User, previous []string) error {
+	return recordRolesChangedIn(tx.Statement.Context, repository.NewGormStore(tx), user, previous)
+}
+
+// recordRolesChangedIn is recordRolesChanged inside the transaction of store.
+func recordRolesChangedIn(ctx context.Context, store repository.Store, user *model.User, previous []string) error {
+	loaded, err := store.Users().Get(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	*user = *loaded
+	dto := common.ToUserDTO(user)
+	if common.SameRoles(previous, dto.Roles) {
+		return nil
+	}
+	return store.Events().Record(ctx, event.NewUserEvent(event.UserRolesChanged, event.UserEventData{
+		User:          dto,
+		PreviousRoles: previous,
+	}))
+}
+
+// snapshotRoles loads the effective roles of users inside tx, keyed by user ID, so that
+// recordRolesChangedFor can compare them after a change that affects many users at once.
+func snapshotRoles(tx *gorm.DB, userIDs []uint) (map[uint][]string, error) {
+	snapshot := make(map[uint][]string, len(userIDs))
+	if len(userIDs) == 0 {
+		return snapshot, nil
+	}
+	var users []model.User
+	if err := tx.Scopes(common.PreloadActiveRoles).Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	for i := range users {
+		snapshot[users[i].ID] = common.ActiveRoleNames(&users[i])
+	}
+	return snapshot, nil
+}
+
+// recordRolesChangedFor records user.roles_changed for every user of previous whose effective
+// roles differ now; users deleted in the meantime are skipped.
+func recordRolesChangedFor(tx *gorm.DB, previous map[uint][]string) error {
+	ids := make([]uint, 0, len(previous))
+	for id := range previous {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		user := model.User{ID: id}
+		if err := recordRolesChanged(tx, &user, previous[id]); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// groupMemberIDs lists the users in the group.
+func groupMemberIDs(tx *gorm.DB, groupID uint) ([]uint, error) {
+	var ids []uint
+	err := tx.Model(&model.GroupMember{}).Where("group_id = ?", groupID).Pluck("user_id", &ids).Error
+	return ids, err
+}
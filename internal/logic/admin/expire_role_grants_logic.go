@@ -0,0 +1,99 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+
+	"usermgmt/internal/logic/common"
+	"usermgmt/internal/model"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+)
+
+// ExpireRoleGrantsLogic removes role grants whose expiry has passed and audits each removal.
+type ExpireRoleGrantsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewExpireRoleGrantsLogic constructor.
+func NewExpireRoleGrantsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ExpireRoleGrantsLogic {
+	return &ExpireRoleGrantsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Sweep removes up to limit grants that expired at or before now and returns how many were removed.
+func (l *ExpireRoleGrantsLogic) Sweep(now time.Time, limit int) (int, error) {
+	defer tracing.Logic(&l.ctx, "ExpireRoleGrantsLogic.Sweep")()
+
+	db := l.svcCtx.DB.WithContext(l.ctx)
+
+	var expired []model.UserRole
+	if err := db.Preload("Role").
+		Where("expires_at IS NOT NULL AND expires_at <= ?", now).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&expired).Error; err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, grant := range expired {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			// The expired grant is no longer loaded as active, so it is added back for the comparison.
+			var user model.User
+			if err := tx.Scopes(common.PreloadActiveRoles).First(&user, grant.UserID).Error; err != nil {
+				return err
+			}
+			previousRoles := common.ActiveRoleNames(&user)
+			if !containsRole(previousRoles, grant.Role.Name) {
+				previousRoles = append(previousRoles, grant.Role.Name)
+			}
+
+			// Re-check the expiry so a concurrent re-grant is not swept away.
+			result := tx.Where("user_id = ? AND role_id = ? AND expires_at <= ?", grant.UserID, grant.RoleID, now).
+				Delete(&model.UserRole{})
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			if err := bumpRoleVersion(tx, grant.UserID, nil); err != nil {
+				return err
+			}
+			if err := recordRolesChanged(tx, &user, previousRoles); err != nil {
+				return err
+			}
+
+			details, _ := json.Marshal(map[string]interface{}{
+				"role":      grant.Role.Name,
+				"expiresAt": grant.ExpiresAt,
+				"grantedBy": grant.GrantedBy,
+			})
+			userID := grant.UserID
+			if err := tx.Create(&model.AuditLog{
+				Action:       model.AuditActionRoleExpired,
+				TargetUserID: &userID,
+				Details:      string(details),
+			}).Error; err != nil {
+				return err
+			}
+			removed++
+			return nil
+		})
+		if err != nil {
+			l.Errorf("expire role %d for user %d failed: %v", grant.RoleID, grant.UserID, err)
+		}
+	}
+
+	if removed > 0 {
+		l.Infof("expired %d role grants", removed)
+	}
+	return removed, nil
+}
@@ -0,0 +1,71 @@
+package admin
+
+import (
+	"context"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/logic/common"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+	"usermgmt/internal/types"
+)
+
+// ExplainPermissionLogic reports which role chains give a user a permission.
+type ExplainPermissionLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewExplainPermissionLogic constructor.
+func NewExplainPermissionLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ExplainPermissionLogic {
+	return &ExplainPermissionLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ExplainPermissionLogic) Explain(userID uint, permission string) (*types.ExplainPermissionResponse, error) {
+	defer tracing.Logic(&l.ctx, "ExplainPermissionLogic.Explain")()
+
+	permission = strings.TrimSpace(permission)
+	if permission == "" {
+		return nil, errorx.ErrValidation.WithDetails(errorx.Detail{Key: "permissionRequired"})
+	}
+
+	db := l.svcCtx.DB.WithContext(l.ctx)
+
+	roleIDs, found, err := loadActiveRoleIDs(db.Scopes(common.TenantScope(l.ctx)), userID)
+	if err != nil {
+		l.Errorf("load user roles failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+	if !found {
+		return nil, errorx.ErrUserNotFound
+	}
+
+	hierarchy, err := loadHierarchy(db)
+	if err != nil {
+		l.Errorf("load role hierarchy failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	paths := make([]types.PermissionPathDTO, 0)
+	for _, path := range hierarchy.Explain(roleIDs, permission) {
+		paths = append(paths, types.PermissionPathDTO{
+			Role:  path.Chain[len(path.Chain)-1],
+			Chain: path.Chain,
+		})
+	}
+
+	return &types.ExplainPermissionResponse{
+		UserID:     userID,
+		Permission: permission,
+		Granted:    len(paths) > 0,
+		Paths:      paths,
+	}, nil
+}
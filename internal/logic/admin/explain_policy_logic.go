@@ -0,0 +1,125 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/logic/common"
+	"usermgmt/internal/model"
+	"usermgmt/internal/policy"
+	"usermgmt/internal/repository"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+	"usermgmt/internal/types"
+	"usermgmt/pkg/contextx"
+)
+
+// ExplainPolicyLogic evaluates a policy request without performing the action.
+type ExplainPolicyLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewExplainPolicyLogic constructor.
+func NewExplainPolicyLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ExplainPolicyLogic {
+	return &ExplainPolicyLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Explain reports the decision for subject (default: the caller) acting on the resource user.
+// Param values containing commas are treated as lists, e.g. {"roles": "admin,support"}.
+func (l *ExplainPolicyLogic) Explain(req *types.ExplainPolicyRequest) (*types.ExplainPolicyResponse, error) {
+	defer tracing.Logic(&l.ctx, "ExplainPolicyLogic.Explain")()
+
+	db := l.svcCtx.DB.WithContext(l.ctx)
+
+	subjectID := req.SubjectID
+	if subjectID == 0 {
+		claims := contextx.MustGetClaims(l.ctx)
+		if claims == nil {
+			return nil, errorx.ErrInvalidCredentials
+		}
+		subjectID = claims.UserID
+	}
+
+	subject, err := subjectAttributes(l.ctx, l.svcCtx.Store.Users(), subjectID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errorx.ErrUserNotFound.WithDetails(map[string]uint{"subjectId": subjectID})
+		}
+		l.Errorf("load policy subject failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	var target model.User
+	if err := db.Scopes(common.TenantScope(l.ctx), common.PreloadActiveRoles).First(&target, req.ResourceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorx.ErrUserNotFound.WithDetails(map[string]uint{"resourceId": req.ResourceID})
+		}
+		l.Errorf("load policy resource failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	params := make(policy.Attributes, len(req.Params))
+	for key, value := range req.Params {
+		if strings.Contains(value, ",") {
+			params[key] = splitList(value)
+		} else {
+			params[key] = value
+		}
+	}
+
+	action := strings.TrimSpace(req.Action)
+	resource := userAttributes(&target)
+	decision := l.svcCtx.Policy.Evaluate(policy.Request{
+		Action:   action,
+		Subject:  subject,
+		Resource: resource,
+		Params:   params,
+	})
+
+	matched := make([]types.PolicyRuleDTO, 0, len(decision.Matched))
+	for _, rule := range decision.Matched {
+		matched = append(matched, types.PolicyRuleDTO{
+			ID:          rule.ID,
+			Effect:      rule.Effect,
+			Description: rule.Description,
+		})
+	}
+
+	result := policy.EffectDeny
+	if decision.Allowed {
+		result = policy.EffectAllow
+	}
+	return &types.ExplainPolicyResponse{
+		Action:        action,
+		Decision:      result,
+		DecidingRule:  decision.DecidingRule,
+		Default:       decision.DecidingRule == "",
+		Matched:       matched,
+		PolicyVersion: decision.Version,
+		Subject:       subject,
+		Resource:      resource,
+		Params:        params,
+	}, nil
+}
+
+func splitList(value string) []string {
+	parts := strings.Split(value, ",")
+	result := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
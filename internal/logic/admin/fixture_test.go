@@ -0,0 +1,29 @@
+package admin
+
+import (
+	"testing"
+
+	"usermgmt/internal/config"
+	"usermgmt/internal/logic/logictest"
+)
+
+// newFixture seeds an admin "root", a second admin, a protected account "breakglass" and two
+// ordinary users.
+func newFixture(t *testing.T, configure ...func(*config.Config)) *logictest.Fixture {
+	t.Helper()
+	var c config.Config
+	c.Password.BcryptCost = 4
+	c.Safeguards = config.SafeguardConf{AdminRole: "admin", MinActiveAdmins: 1, ProtectedUsers: []string{"breakglass"}}
+	c.Tenancy = config.TenancyConf{SuperAdminRole: "admin", OrgAdminRole: "admin"}
+	for _, fn := range configure {
+		fn(&c)
+	}
+
+	return logictest.New(t, c, []string{"admin", "support", "viewer"},
+		logictest.Account{Username: "root", Roles: []string{"admin"}},
+		logictest.Account{Username: "second", Roles: []string{"admin"}},
+		logictest.Account{Username: "breakglass"},
+		logictest.Account{Username: "alice", Roles: []string{"viewer"}},
+		logictest.Account{Username: "helper", Roles: []string{"support"}},
+	)
+}
@@ -0,0 +1,142 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/logic/common"
+	"usermgmt/internal/model"
+	"usermgmt/internal/policy"
+	"usermgmt/internal/repository"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+	"usermgmt/internal/types"
+)
+
+// GrantRoleLogic adds a single, optionally time-bound, role to a user without touching the other grants.
+type GrantRoleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewGrantRoleLogic constructor.
+func NewGrantRoleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GrantRoleLogic {
+	return &GrantRoleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Grant is idempotent; re-granting a role the user already holds replaces its expiry.
+func (l *GrantRoleLogic) Grant(userID uint, roleName string, req *types.GrantRoleRequest) (*types.ProfileResponse, error) {
+	defer tracing.Logic(&l.ctx, "GrantRoleLogic.Grant")()
+
+	store := l.svcCtx.Store
+
+	expiresAt, err := grantExpiry(req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	user, role, err := loadUserAndRole(l.ctx, store, userID, roleName)
+	if err != nil {
+		if !errors.As(err, new(*errorx.AppError)) {
+			l.Errorf("load user/role for grant failed: %v", err)
+			return nil, errorx.ErrInternal
+		}
+		return nil, err
+	}
+
+	if err := authorize(l.ctx, l.svcCtx, policy.ActionUserRolesAssign, user, policy.Attributes{
+		"roles": []string{role.Name},
+		"mode":  "grant",
+	}); err != nil {
+		return nil, err
+	}
+
+	if needsRoleApproval(l.svcCtx, user, []model.Role{*role}) {
+		return nil, submitChangeRequest(l.ctx, l.svcCtx, store.ChangeRequests(), policy.ActionUserRolesAssign, user, rolesChange{
+			Mode:        changeModeGrant,
+			Roles:       []string{role.Name},
+			ExpiresAt:   expiresAt,
+			RoleVersion: user.RoleVersion,
+		})
+	}
+
+	previousRoles := common.ActiveRoleNames(user)
+	if err := store.Transaction(l.ctx, func(tx repository.Store) error {
+		grant := model.UserRole{UserID: user.ID, RoleID: role.ID, ExpiresAt: expiresAt, GrantedBy: actorID(l.ctx)}
+		if err := tx.Roles().Grant(l.ctx, grant); err != nil {
+			return err
+		}
+		if err := tx.Users().BumpRoleVersion(l.ctx, user.ID, nil); err != nil {
+			return versionError(err)
+		}
+		return recordRolesChangedIn(l.ctx, tx, user, previousRoles)
+	}); err != nil {
+		l.Errorf("grant role transaction failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	return &types.ProfileResponse{User: common.ToUserDTO(user)}, nil
+}
+
+// loadUserAndRole resolves the path parameters shared by the single-role endpoints.
+func loadUserAndRole(ctx context.Context, store repository.Store, userID uint, roleName string) (*model.User, *model.Role, error) {
+	user, err := store.Users().Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, errorx.ErrUserNotFound
+		}
+		return nil, nil, err
+	}
+
+	roleName = strings.TrimSpace(roleName)
+	if roleName == "" {
+		return nil, nil, errorx.ErrValidation.WithDetails(errorx.Detail{Key: "roleNameRequired"})
+	}
+	role, err := store.Roles().GetByName(ctx, roleName)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, errorx.ErrRoleNotFound
+		}
+		return nil, nil, err
+	}
+	return user, role, nil
+}
+
+// grantExpiry resolves the absolute expiry from either expiresAt (RFC3339) or ttl (Go duration).
+func grantExpiry(req *types.GrantRoleRequest, now time.Time) (*time.Time, error) {
+	if req == nil || (req.ExpiresAt == "" && req.TTL == "") {
+		return nil, nil
+	}
+	if req.ExpiresAt != "" && req.TTL != "" {
+		return nil, errorx.ErrValidation.WithDetails(errorx.Detail{Key: "expiryConflict"})
+	}
+
+	var expiresAt time.Time
+	if req.TTL != "" {
+		ttl, err := time.ParseDuration(req.TTL)
+		if err != nil || ttl <= 0 {
+			return nil, errorx.ErrValidation.WithDetails(errorx.Detail{Key: "ttlInvalid"})
+		}
+		expiresAt = now.Add(ttl)
+	} else {
+		parsed, err := time.Parse(time.RFC3339, req.ExpiresAt)
+		if err != nil {
+			return nil, errorx.ErrValidation.WithDetails(errorx.Detail{Key: "expiresAtInvalid"})
+		}
+		expiresAt = parsed
+	}
+	if !expiresAt.After(now) {
+		return nil, errorx.ErrValidation.WithDetails(errorx.Detail{Key: "expiryInPast"})
+	}
+	return &expiresAt, nil
+}
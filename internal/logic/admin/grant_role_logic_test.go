@@ -0,0 +1,125 @@
+package admin
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"usermgmt/internal/config"
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/logic/common"
+	"usermgmt/internal/logic/logictest"
+	"usermgmt/internal/types"
+)
+
+func TestGrantRole(t *testing.T) {
+	tests := []struct {
+		name      string
+		caller    string
+		roles     []string
+		target    string
+		role      string
+		req       *types.GrantRoleRequest
+		wantErr   *errorx.AppError
+		wantRoles string
+	}{
+		{name: "grant role", caller: "root", roles: []string{"admin"}, target: "alice", role: "support", wantRoles: "support,viewer"},
+		{name: "grant held role", caller: "root", roles: []string{"admin"}, target: "alice", role: "viewer", wantRoles: "viewer"},
+		{name: "grant with ttl", caller: "root", roles: []string{"admin"}, target: "alice", role: "support", req: &types.GrantRoleRequest{TTL: "8h"}, wantRoles: "support,viewer"},
+		{name: "negative ttl", caller: "root", roles: []string{"admin"}, target: "alice", role: "support", req: &types.GrantRoleRequest{TTL: "-1h"}, wantErr: errorx.ErrValidation},
+		{name: "ttl and expiry", caller: "root", roles: []string{"admin"}, target: "alice", role: "support", req: &types.GrantRoleRequest{TTL: "1h", ExpiresAt: "2099-01-01T00:00:00Z"}, wantErr: errorx.ErrValidation},
+		{name: "unknown role", caller: "root", roles: []string{"admin"}, target: "alice", role: "auditor", wantErr: errorx.ErrRoleNotFound},
+		{name: "blank role", caller: "root", roles: []string{"admin"}, target: "alice", role: " ", wantErr: errorx.ErrValidation},
+		{name: "unknown user", caller: "root", roles: []string{"admin"}, role: "support", wantErr: errorx.ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			targetID := f.Users[tt.target].ID
+			if tt.target == "" {
+				targetID = 999
+			}
+
+			_, err := NewGrantRoleLogic(f.As(tt.caller, tt.roles...), f.Svc).Grant(targetID, tt.role, tt.req)
+			if tt.wantErr != nil {
+				logictest.ExpectCode(t, err, tt.wantErr)
+				if events := f.Store.RecordedEvents(); len(events) != 0 {
+					t.Fatalf("events = %v, want none", logictest.EventTypes(events))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			user := f.User(t, tt.target)
+			if got := roleList(common.ActiveRoleNames(user)); got != tt.wantRoles {
+				t.Fatalf("roles = %q, want %q", got, tt.wantRoles)
+			}
+			if tt.req != nil && tt.req.TTL != "" {
+				for _, grant := range user.RoleGrants {
+					if grant.Role.Name == tt.role && (grant.ExpiresAt == nil || !grant.ExpiresAt.After(time.Now())) {
+						t.Fatalf("grant expiry = %v, want future", grant.ExpiresAt)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRevokeRole(t *testing.T) {
+	minTwoAdmins := func(c *config.Config) { c.Safeguards.MinActiveAdmins = 2 }
+
+	tests := []struct {
+		name      string
+		configure func(*config.Config)
+		caller    string
+		roles     []string
+		target    string
+		role      string
+		wantErr   *errorx.AppError
+		wantRoles string
+		wantEvent string
+	}{
+		{name: "revoke role", caller: "root", roles: []string{"admin"}, target: "alice", role: "viewer", wantEvent: "user.roles_changed"},
+		{name: "revoke missing role", caller: "root", roles: []string{"admin"}, target: "alice", role: "support", wantRoles: "viewer"},
+		{name: "revoke admin", caller: "root", roles: []string{"admin"}, target: "second", role: "admin", wantEvent: "user.roles_changed"},
+		{name: "revoke below admin floor", configure: minTwoAdmins, caller: "root", roles: []string{"admin"}, target: "second", role: "admin", wantErr: errorx.ErrMinAdmins},
+		{name: "unknown role", caller: "root", roles: []string{"admin"}, target: "alice", role: "auditor", wantErr: errorx.ErrRoleNotFound},
+		{name: "revoke own admin", caller: "root", roles: []string{"admin"}, target: "root", role: "admin", wantErr: errorx.ErrSelfDemotion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var configure []func(*config.Config)
+			if tt.configure != nil {
+				configure = append(configure, tt.configure)
+			}
+			f := newFixture(t, configure...)
+			before := roleList(common.ActiveRoleNames(f.User(t, tt.target)))
+
+			_, err := NewRevokeRoleLogic(f.As(tt.caller, tt.roles...), f.Svc).Revoke(f.Users[tt.target].ID, tt.role)
+			if tt.wantErr != nil {
+				logictest.ExpectCode(t, err, tt.wantErr)
+				if got := roleList(common.ActiveRoleNames(f.User(t, tt.target))); got != before {
+					t.Fatalf("roles = %q, want unchanged %q", got, before)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if got := roleList(common.ActiveRoleNames(f.User(t, tt.target))); got != tt.wantRoles {
+				t.Fatalf("roles = %q, want %q", got, tt.wantRoles)
+			}
+			if got := strings.Join(logictest.EventTypes(f.Store.RecordedEvents()), ","); got != tt.wantEvent {
+				t.Fatalf("events = %q, want %q", got, tt.wantEvent)
+			}
+		})
+	}
+}
+
+func roleList(names []string) string {
+	sorted := append([]string(nil), names...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
@@ -0,0 +1,62 @@
+package admin
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/model"
+	"usermgmt/internal/types"
+)
+
+// loadGroup finds a group by name together with its roles.
+func loadGroup(db *gorm.DB, name string) (*model.Group, error) {
+	var group model.Group
+	if err := db.Preload("Roles").Where("name = ?", name).First(&group).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorx.ErrGroupNotFound
+		}
+		return nil, err
+	}
+	return &group, nil
+}
+
+// setGroupRoles replaces the group's role set inside tx.
+func setGroupRoles(tx *gorm.DB, groupID uint, roles []model.Role) error {
+	if err := tx.Where("group_id = ?", groupID).Delete(&model.GroupRole{}).Error; err != nil {
+		return err
+	}
+	for _, role := range roles {
+		if err := tx.Create(&model.GroupRole{GroupID: groupID, RoleID: role.ID}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bumpGroupRoleVersions increments role_version for every member, since their effective roles changed.
+func bumpGroupRoleVersions(tx *gorm.DB, groupID uint) error {
+	return tx.Model(&model.User{}).
+		Where("id IN (?)", tx.Model(&model.GroupMember{}).Select("user_id").Where("group_id = ?", groupID)).
+		Update("role_version", gorm.Expr("role_version + 1")).Error
+}
+
+func toGroupDTO(db *gorm.DB, group *model.Group) (types.GroupDTO, error) {
+	var count int64
+	if err := db.Model(&model.GroupMember{}).Where("group_id = ?", group.ID).Count(&count).Error; err != nil {
+		return types.GroupDTO{}, err
+	}
+	roles := make([]string, 0, len(group.Roles))
+	for _, role := range group.Roles {
+		roles = append(roles, role.Name)
+	}
+	return types.GroupDTO{
+		ID:          group.ID,
+		Name:        group.Name,
+		Description: group.Description,
+		Roles:       roles,
+		MemberCount: count,
+		CreatedAt:   group.CreatedAt,
+	}, nil
+}
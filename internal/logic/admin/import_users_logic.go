@@ -0,0 +1,321 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/i18n"
+	"usermgmt/internal/logic/common"
+	"usermgmt/internal/model"
+	"usermgmt/internal/policy"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+	"usermgmt/internal/types"
+	"usermgmt/pkg/security"
+)
+
+// Import modes.
+const (
+	ImportModeCreate = "create"
+	ImportModeUpsert = "upsert"
+)
+
+// errImportDryRun rolls back the import transaction once every row has been checked.
+var errImportDryRun = errors.New("import dry run")
+
+// ImportUsersLogic creates or updates users from CSV/NDJSON input with row-level reporting.
+type ImportUsersLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewImportUsersLogic constructor.
+func NewImportUsersLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ImportUsersLogic {
+	return &ImportUsersLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Import reads rows from src and applies them in one transaction, each row isolated by a savepoint
+// so that a bad row is reported without discarding the others. Dry runs roll everything back.
+func (l *ImportUsersLogic) Import(src io.Reader, req *types.ImportUsersRequest) (*types.ImportUsersResponse, error) {
+	defer tracing.Logic(&l.ctx, "ImportUsersLogic.Import")()
+
+	format := ResolveFormat(req.Format, "")
+	mode := req.Mode
+	if mode == "" {
+		mode = ImportModeCreate
+	}
+
+	reader, err := newUserRowReader(format, src)
+	if err != nil {
+		return nil, errorx.ErrValidation.WithDetails(err.Error())
+	}
+
+	maxRows := l.svcCtx.Config.Bulk.MaxImportRows
+	if maxRows <= 0 {
+		maxRows = 10000
+	}
+
+	resp := &types.ImportUsersResponse{
+		Format: format,
+		Mode:   mode,
+		DryRun: req.DryRun,
+		Errors: make([]types.ImportRowError, 0),
+	}
+
+	db := l.svcCtx.DB.WithContext(l.ctx)
+	var roles []model.Role
+	if err := db.Find(&roles).Error; err != nil {
+		l.Errorf("load roles for import failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+	roleByName := make(map[string]model.Role, len(roles))
+	for _, role := range roles {
+		roleByName[strings.ToLower(role.Name)] = role
+	}
+
+	guard := newSafeguard(l.ctx, l.svcCtx)
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for {
+			row, line, err := reader.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			var syntaxErr *rowSyntaxError
+			if errors.As(err, &syntaxErr) {
+				resp.Total++
+				resp.Errors = append(resp.Errors, types.ImportRowError{
+					Row:     line,
+					Code:    errorx.ErrValidation.Code,
+					Message: errorx.ErrValidation.Message,
+					Details: syntaxErr.Error(),
+				})
+				continue
+			}
+			if err != nil {
+				return errorx.ErrValidation.WithDetails(err.Error())
+			}
+
+			resp.Total++
+			if resp.Total > maxRows {
+				return errorx.ErrValidation.WithDetails(map[string]interface{}{"maxImportRows": maxRows})
+			}
+
+			var created bool
+			if err := tx.Transaction(func(rowTx *gorm.DB) error {
+				var rowErr error
+				created, rowErr = l.importRow(rowTx, guard, &row, mode, roleByName)
+				return rowErr
+			}); err != nil {
+				resp.Errors = append(resp.Errors, rowError(line, &row, err))
+				continue
+			}
+			if created {
+				resp.Created++
+			} else {
+				resp.Updated++
+			}
+		}
+
+		if req.DryRun {
+			return errImportDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportDryRun) {
+		var appErr *errorx.AppError
+		if errors.As(err, &appErr) {
+			return nil, appErr
+		}
+		l.Errorf("import users transaction failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	resp.Failed = len(resp.Errors)
+	localizer := i18n.FromContext(l.ctx)
+	for i, rowErr := range resp.Errors {
+		resp.Errors[i].Message = localizer.Message(rowErr.Code, rowErr.Message)
+		resp.Errors[i].Details = localizer.Details(rowErr.Details)
+	}
+	return resp, nil
+}
+
+// importRow validates a single row and writes it; it reports whether a new user was created.
+// Updates of existing users are held to the same safeguards, policy and approval rules as the
+// single-user status and role endpoints.
+func (l *ImportUsersLogic) importRow(tx *gorm.DB, guard *safeguard, row *types.ImportUserRow, mode string, roleByName map[string]model.Role) (bool, error) {
+	row.Username = strings.TrimSpace(row.Username)
+	row.Email = strings.ToLower(strings.TrimSpace(row.Email))
+	row.FullName = strings.TrimSpace(row.FullName)
+	row.Department = strings.TrimSpace(row.Department)
+	row.Status = strings.TrimSpace(row.Status)
+
+	if err := l.svcCtx.Validator.StructCtx(l.ctx, row); err != nil {
+		return false, errorx.FromValidationError(err)
+	}
+	if row.PasswordHash != "" && !security.IsBcryptHash(row.PasswordHash) {
+		return false, errorx.ErrValidation.WithDetails([]errorx.ValidationErrorItem{{Field: "passwordHash", Tag: "bcrypt"}})
+	}
+
+	roleNames := normalizeRoles(row.Roles)
+	roles := make([]model.Role, 0, len(roleNames))
+	missing := make([]string, 0)
+	for _, name := range roleNames {
+		role, ok := roleByName[strings.ToLower(name)]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		roles = append(roles, role)
+	}
+	if len(missing) > 0 {
+		return false, errorx.ErrValidation.WithDetails(map[string]interface{}{"missingRoles": missing})
+	}
+
+	var existing model.User
+	err := tx.Scopes(common.PreloadActiveRoles).Where("username = ?", row.Username).First(&existing).Error
+	switch {
+	case err == nil && mode != ImportModeUpsert:
+		return false, errorx.ErrUserExists
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		return false, err
+	}
+	found := err == nil
+
+	var count int64
+	emailQuery := tx.Model(&model.User{}).Where("email = ?", row.Email)
+	if found {
+		emailQuery = emailQuery.Where("id <> ?", existing.ID)
+	}
+	if err := emailQuery.Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return false, errorx.ErrUserExists
+	}
+
+	status := row.Status
+	if status == "" && !found {
+		status = model.UserStatusEnabled
+	}
+
+	if !found {
+		hash := row.PasswordHash
+		if hash == "" {
+			hash = security.UnusablePasswordHash
+		}
+		user := model.User{
+			Username:     row.Username,
+			Email:        row.Email,
+			PasswordHash: hash,
+			FullName:     row.FullName,
+			Department:   row.Department,
+			Status:       status,
+		}
+		if err := tx.Create(&user).Error; err != nil {
+			return false, err
+		}
+		return true, replaceUserRoles(tx, user.ID, roles, actorID(l.ctx))
+	}
+
+	if err := l.checkUpdate(guard, &existing, status, roleNames, roles); err != nil {
+		return false, err
+	}
+
+	updates := map[string]interface{}{
+		"email":     row.Email,
+		"full_name": row.FullName,
+	}
+	if status != "" {
+		updates["status"] = status
+	}
+	if row.Department != "" {
+		updates["department"] = row.Department
+	}
+	if row.PasswordHash != "" {
+		updates["password_hash"] = row.PasswordHash
+	}
+	previousStatus := existing.Status
+	previousRoles := common.ActiveRoleNames(&existing)
+	if err := guard.preserveAdmins(tx, func() error {
+		if err := tx.Model(&model.User{}).Where("id = ?", existing.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+		// An empty roles cell leaves existing assignments untouched on upsert.
+		if len(roles) == 0 {
+			return nil
+		}
+		if err := bumpRoleVersion(tx, existing.ID, nil); err != nil {
+			return err
+		}
+		return replaceUserRoles(tx, existing.ID, roles, actorID(l.ctx))
+	}); err != nil {
+		return false, err
+	}
+	if err := recordStatusChanged(tx, &existing, previousStatus); err != nil {
+		return false, err
+	}
+	return false, recordRolesChanged(tx, &existing, previousRoles)
+}
+
+// checkUpdate applies the safeguards, the policy and the approval rules to the status and role
+// changes an upsert row makes to user. Changes that need two-person approval are refused; they
+// have to go through the single-user endpoints.
+func (l *ImportUsersLogic) checkUpdate(guard *safeguard, user *model.User, status string, roleNames []string, roles []model.Role) error {
+	if status != "" && status != user.Status {
+		if err := guard.checkStatus(user, status); err != nil {
+			return err
+		}
+		if err := authorize(l.ctx, l.svcCtx, policy.ActionUserStatusUpdate, user, policy.Attributes{"status": status}); err != nil {
+			return err
+		}
+		if needsStatusApproval(l.svcCtx, user, status) {
+			return errorx.ErrApprovalRequired
+		}
+	}
+	if len(roles) == 0 {
+		return nil
+	}
+	if err := guard.checkRoleRemoval(user, removedRoles(user, roleNames)); err != nil {
+		return err
+	}
+	if err := authorize(l.ctx, l.svcCtx, policy.ActionUserRolesAssign, user, policy.Attributes{
+		"roles": roleNames,
+		"mode":  "replace",
+	}); err != nil {
+		return err
+	}
+	if needsRoleApproval(l.svcCtx, user, roles) {
+		return errorx.ErrApprovalRequired
+	}
+	return nil
+}
+
+func rowError(line int, row *types.ImportUserRow, err error) types.ImportRowError {
+	var appErr *errorx.AppError
+	switch {
+	case errors.As(err, &appErr):
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		appErr = errorx.ErrUserExists
+	default:
+		logx.Errorf("import row %d failed: %v", line, err)
+		appErr = errorx.ErrInternal
+	}
+	return types.ImportRowError{
+		Row:      line,
+		Username: row.Username,
+		Code:     appErr.Code,
+		Message:  appErr.Message,
+		Details:  appErr.Details,
+	}
+}
@@ -0,0 +1,86 @@
+package admin
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/model"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+	"usermgmt/internal/types"
+)
+
+// ListAccessReviewsLogic lists campaigns with their progress and shows a single campaign in full.
+type ListAccessReviewsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewListAccessReviewsLogic constructor.
+func NewListAccessReviewsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListAccessReviewsLogic {
+	return &ListAccessReviewsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListAccessReviewsLogic) List() (*types.ListAccessReviewsResponse, error) {
+	defer tracing.Logic(&l.ctx, "ListAccessReviewsLogic.List")()
+
+	db := l.svcCtx.DB.WithContext(l.ctx)
+
+	var reviews []model.AccessReview
+	if err := db.Order("id DESC").Find(&reviews).Error; err != nil {
+		l.Errorf("list access reviews failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	ids := make([]uint, 0, len(reviews))
+	for _, review := range reviews {
+		ids = append(ids, review.ID)
+	}
+	progress, err := reviewProgress(db, ids)
+	if err != nil {
+		l.Errorf("count access review items failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	data := make([]types.AccessReviewDTO, 0, len(reviews))
+	for i := range reviews {
+		data = append(data, toAccessReviewDTO(&reviews[i], progress[reviews[i].ID]))
+	}
+	return &types.ListAccessReviewsResponse{Data: data}, nil
+}
+
+// Get returns the campaign with every item.
+func (l *ListAccessReviewsLogic) Get(id uint) (*types.AccessReviewDTO, error) {
+	defer tracing.Logic(&l.ctx, "ListAccessReviewsLogic.Get")()
+
+	db := l.svcCtx.DB.WithContext(l.ctx)
+
+	var review model.AccessReview
+	if err := db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).First(&review, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorx.ErrReviewNotFound
+		}
+		l.Errorf("load access review failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	var decided int64
+	for _, item := range review.Items {
+		if item.Decision != "" {
+			decided++
+		}
+	}
+	dto := toAccessReviewDTO(&review, [2]int64{int64(len(review.Items)), decided})
+	return &dto, nil
+}
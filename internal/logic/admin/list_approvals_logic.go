@@ -0,0 +1,82 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/model"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+	"usermgmt/internal/types"
+)
+
+// ListApprovalsLogic lists change requests, newest first.
+type ListApprovalsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewListApprovalsLogic constructor.
+func NewListApprovalsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListApprovalsLogic {
+	return &ListApprovalsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListApprovalsLogic) List(req *types.ListApprovalsRequest) (*types.ListApprovalsResponse, error) {
+	defer tracing.Logic(&l.ctx, "ListApprovalsLogic.List")()
+
+	db := l.svcCtx.DB.WithContext(l.ctx)
+
+	if err := expirePendingRequests(db, time.Now()); err != nil {
+		l.Errorf("expire change requests failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	query := db.Model(&model.ChangeRequest{})
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	var requests []model.ChangeRequest
+	if err := query.Order("id DESC").Limit(500).Find(&requests).Error; err != nil {
+		l.Errorf("list change requests failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	data := make([]types.ChangeRequestDTO, 0, len(requests))
+	for i := range requests {
+		data = append(data, toChangeRequestDTO(&requests[i]))
+	}
+	return &types.ListApprovalsResponse{Data: data}, nil
+}
+
+// Get returns a single change request.
+func (l *ListApprovalsLogic) Get(id uint) (*types.ChangeRequestDTO, error) {
+	defer tracing.Logic(&l.ctx, "ListApprovalsLogic.Get")()
+
+	db := l.svcCtx.DB.WithContext(l.ctx)
+
+	if err := expirePendingRequests(db.Where("id = ?", id), time.Now()); err != nil {
+		l.Errorf("expire change request failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	var request model.ChangeRequest
+	if err := db.First(&request, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorx.ErrApprovalNotFound
+		}
+		l.Errorf("load change request failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+	dto := toChangeRequestDTO(&request)
+	return &dto, nil
+}
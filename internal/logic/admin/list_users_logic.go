@@ -3,15 +3,16 @@ package admin
 import (
 	"context"
 	"math"
-	"strings"
 
 	"github.com/zeromicro/go-zero/core/logx"
 
 	"usermgmt/internal/errorx"
 	"usermgmt/internal/logic/common"
-	"usermgmt/internal/model"
+	"usermgmt/internal/repository"
 	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
 	"usermgmt/internal/types"
+	"usermgmt/pkg/contextx"
 )
 
 // ListUsersLogic encapsulates pagination & filtering of users.
@@ -31,7 +32,7 @@ func NewListUsersLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListUse
 }
 
 func (l *ListUsersLogic) List(req *types.ListUsersRequest) (*types.ListUsersResponse, error) {
-	db := l.svcCtx.DB.WithContext(l.ctx)
+	defer tracing.Logic(&l.ctx, "ListUsersLogic.List")()
 
 	page := req.Page
 	if page < 1 {
@@ -54,30 +55,19 @@ func (l *ListUsersLogic) List(req *types.ListUsersRequest) (*types.ListUsersResp
 	}
 	offset := (page - 1) * pageSize
 
-	baseQuery := db.Model(&model.User{})
-
-	if status := strings.TrimSpace(req.Status); status != "" {
-		baseQuery = baseQuery.Where("status = ?", status)
-	}
-
-	if keyword := strings.TrimSpace(req.Keyword); keyword != "" {
-		kw := "%" + strings.ToLower(keyword) + "%"
-		baseQuery = baseQuery.Where("LOWER(username) LIKE ? OR LOWER(email) LIKE ? OR LOWER(full_name) LIKE ?", kw, kw, kw)
+	query := repository.UserQuery{
+		Keyword: req.Keyword,
+		Status:  req.Status,
+		Group:   req.Group,
+		Offset:  offset,
+		Limit:   pageSize,
 	}
-
-	var total int64
-	if err := baseQuery.Count(&total).Error; err != nil {
-		l.Errorf("count users failed: %v", err)
-		return nil, errorx.ErrInternal
+	if tenant := contextx.TenantFromContext(l.ctx); tenant != nil {
+		query.OrgID = &tenant.OrgID
 	}
 
-	var users []model.User
-	if err := baseQuery.
-		Preload("Roles").
-		Order("created_at DESC").
-		Offset(offset).
-		Limit(pageSize).
-		Find(&users).Error; err != nil {
+	users, total, err := l.svcCtx.Store.Users().List(l.ctx, query)
+	if err != nil {
 		l.Errorf("list users failed: %v", err)
 		return nil, errorx.ErrInternal
 	}
@@ -0,0 +1,81 @@
+package admin
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/model"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+	"usermgmt/internal/types"
+)
+
+// ListWebhooksLogic lists subscriptions and their delivery log.
+type ListWebhooksLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewListWebhooksLogic constructor.
+func NewListWebhooksLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListWebhooksLogic {
+	return &ListWebhooksLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListWebhooksLogic) List() (*types.ListWebhooksResponse, error) {
+	defer tracing.Logic(&l.ctx, "ListWebhooksLogic.List")()
+
+	var subscriptions []model.WebhookSubscription
+	if err := l.svcCtx.DB.WithContext(l.ctx).Order("id").Find(&subscriptions).Error; err != nil {
+		l.Errorf("list webhooks failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	data := make([]types.WebhookDTO, 0, len(subscriptions))
+	for i := range subscriptions {
+		data = append(data, toWebhookDTO(&subscriptions[i], false))
+	}
+	return &types.ListWebhooksResponse{Data: data}, nil
+}
+
+// Deliveries returns the most recent deliveries of a subscription, newest first.
+func (l *ListWebhooksLogic) Deliveries(id uint, req *types.ListWebhookDeliveriesRequest) (*types.ListWebhookDeliveriesResponse, error) {
+	defer tracing.Logic(&l.ctx, "ListWebhooksLogic.Deliveries")()
+
+	db := l.svcCtx.DB.WithContext(l.ctx)
+
+	if _, err := loadWebhook(db, id); err != nil {
+		if errors.As(err, new(*errorx.AppError)) {
+			return nil, err
+		}
+		l.Errorf("load webhook failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	query := db.Where("subscription_id = ?", id)
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	var deliveries []model.WebhookDelivery
+	if err := query.Order("id DESC").Limit(limit).Find(&deliveries).Error; err != nil {
+		l.Errorf("list webhook deliveries failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	data := make([]types.WebhookDeliveryDTO, 0, len(deliveries))
+	for i := range deliveries {
+		data = append(data, toWebhookDeliveryDTO(&deliveries[i]))
+	}
+	return &types.ListWebhookDeliveriesResponse{Data: data}, nil
+}
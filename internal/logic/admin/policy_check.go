@@ -0,0 +1,80 @@
+package admin
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/logic/common"
+	"usermgmt/internal/model"
+	"usermgmt/internal/policy"
+	"usermgmt/internal/repository"
+	"usermgmt/internal/svc"
+	"usermgmt/pkg/contextx"
+)
+
+// userAttributes exposes a user to policies; user must be loaded with common.PreloadActiveRoles.
+func userAttributes(user *model.User) policy.Attributes {
+	return policy.Attributes{
+		"id":         user.ID,
+		"username":   user.Username,
+		"department": user.Department,
+		"status":     user.Status,
+		"roles":      common.ActiveRoleNames(user),
+		"groups":     common.GroupNames(user),
+	}
+}
+
+// subjectAttributes loads the acting user's attributes, adding the organisation scope when present.
+func subjectAttributes(ctx context.Context, users repository.UserRepository, subjectID uint) (policy.Attributes, error) {
+	subject, err := users.Get(ctx, subjectID)
+	if err != nil {
+		return nil, err
+	}
+	attrs := userAttributes(subject)
+	if tenant := contextx.TenantFromContext(ctx); tenant != nil {
+		attrs["orgId"] = tenant.OrgID
+		attrs["orgRoles"] = tenant.Roles
+	}
+	return attrs, nil
+}
+
+// authorize evaluates action by the caller on target and maps a deny to ErrPolicyDenied.
+// It only returns *errorx.AppError values.
+func authorize(ctx context.Context, svcCtx *svc.ServiceContext, action string, target *model.User, params policy.Attributes) error {
+	if svcCtx.Policy == nil {
+		return nil
+	}
+	claims := contextx.MustGetClaims(ctx)
+	if claims == nil {
+		return errorx.ErrForbidden
+	}
+
+	subject, err := subjectAttributes(ctx, svcCtx.Store.Users(), claims.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return errorx.ErrForbidden
+		}
+		logx.WithContext(ctx).Errorf("load policy subject failed: %v", err)
+		return errorx.ErrInternal
+	}
+
+	decision := svcCtx.Policy.Evaluate(policy.Request{
+		Action:   action,
+		Subject:  subject,
+		Resource: userAttributes(target),
+		Params:   params,
+	})
+	if decision.Allowed {
+		return nil
+	}
+
+	logx.WithContext(ctx).Infof("policy %s denied %s by user %d on user %d (rule %q)",
+		decision.Version, action, claims.UserID, target.ID, decision.DecidingRule)
+	return errorx.ErrPolicyDenied.WithDetails(map[string]string{
+		"action": action,
+		"rule":   decision.DecidingRule,
+	})
+}
@@ -0,0 +1,57 @@
+package admin
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/model"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+	"usermgmt/internal/types"
+)
+
+// RedeliverWebhookLogic queues another attempt of a past delivery.
+type RedeliverWebhookLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewRedeliverWebhookLogic constructor.
+func NewRedeliverWebhookLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RedeliverWebhookLogic {
+	return &RedeliverWebhookLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Redeliver queues a new delivery with the original event ID and payload, so receivers that
+// de-duplicate on the event ID treat it as the same event.
+func (l *RedeliverWebhookLogic) Redeliver(webhookID, deliveryID uint) (*types.WebhookDeliveryDTO, error) {
+	defer tracing.Logic(&l.ctx, "RedeliverWebhookLogic.Redeliver")()
+
+	db := l.svcCtx.DB.WithContext(l.ctx)
+
+	var original model.WebhookDelivery
+	if err := db.Where("subscription_id = ?", webhookID).First(&original, deliveryID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorx.ErrDeliveryNotFound
+		}
+		l.Errorf("load webhook delivery failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	delivery, err := l.svcCtx.Webhooks.Redeliver(l.ctx, &original)
+	if err != nil {
+		l.Errorf("queue webhook redelivery failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	dto := toWebhookDeliveryDTO(delivery)
+	return &dto, nil
+}
@@ -0,0 +1,76 @@
+package admin
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/model"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+	"usermgmt/internal/types"
+)
+
+// RemoveGroupMemberLogic takes a user out of a group; removing a non-member is a no-op.
+type RemoveGroupMemberLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewRemoveGroupMemberLogic constructor.
+func NewRemoveGroupMemberLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RemoveGroupMemberLogic {
+	return &RemoveGroupMemberLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *RemoveGroupMemberLogic) Remove(name string, userID uint) (*types.GroupDTO, error) {
+	defer tracing.Logic(&l.ctx, "RemoveGroupMemberLogic.Remove")()
+
+	guard := newSafeguard(l.ctx, l.svcCtx)
+	var dto types.GroupDTO
+	err := l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		group, err := loadGroup(tx, name)
+		if err != nil {
+			return err
+		}
+
+		previous, err := snapshotRoles(tx, []uint{userID})
+		if err != nil {
+			return err
+		}
+		if err := guard.preserveAdmins(tx, func() error {
+			result := tx.Where("group_id = ? AND user_id = ?", group.ID, userID).Delete(&model.GroupMember{})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 && len(group.Roles) > 0 {
+				return bumpRoleVersion(tx, userID, nil)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		if err := recordRolesChangedFor(tx, previous); err != nil {
+			return err
+		}
+
+		dto, err = toGroupDTO(tx, group)
+		return err
+	})
+	if err != nil {
+		var appErr *errorx.AppError
+		if errors.As(err, &appErr) {
+			return nil, appErr
+		}
+		l.Errorf("remove group member failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+	return &dto, nil
+}
@@ -0,0 +1,157 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/logic/common"
+	"usermgmt/internal/model"
+	"usermgmt/internal/policy"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+	"usermgmt/internal/types"
+)
+
+// ReviewApprovalLogic approves or rejects a pending change request.
+type ReviewApprovalLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewReviewApprovalLogic constructor.
+func NewReviewApprovalLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ReviewApprovalLogic {
+	return &ReviewApprovalLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Approve applies the change and closes the request in one transaction. The approver must be
+// neither the requester nor the target. If the change can no longer be applied (e.g. the roles
+// were edited meanwhile or a safeguard now refuses it) the request is closed as failed.
+func (l *ReviewApprovalLogic) Approve(id uint, req *types.ReviewApprovalRequest) (*types.ChangeRequestDTO, error) {
+	defer tracing.Logic(&l.ctx, "ReviewApprovalLogic.Approve")()
+
+	return l.review(id, req, true)
+}
+
+// Reject closes the request without applying it. The requester may reject to withdraw it.
+func (l *ReviewApprovalLogic) Reject(id uint, req *types.ReviewApprovalRequest) (*types.ChangeRequestDTO, error) {
+	defer tracing.Logic(&l.ctx, "ReviewApprovalLogic.Reject")()
+
+	return l.review(id, req, false)
+}
+
+func (l *ReviewApprovalLogic) review(id uint, req *types.ReviewApprovalRequest, approve bool) (*types.ChangeRequestDTO, error) {
+	reviewer := actorID(l.ctx)
+	if reviewer == nil {
+		return nil, errorx.ErrForbidden
+	}
+	db := l.svcCtx.DB.WithContext(l.ctx)
+
+	var request model.ChangeRequest
+	var before model.User
+	var closedErr error
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errorx.ErrApprovalNotFound
+			}
+			return err
+		}
+
+		now := time.Now()
+		if request.Status == model.ChangeRequestPending && !request.ExpiresAt.After(now) {
+			// Commit the expiry, then report the request as closed.
+			request.Status = model.ChangeRequestExpired
+			closedErr = errorx.ErrApprovalClosed.WithDetails(map[string]string{"status": request.Status})
+			return tx.Model(&request).Update("status", request.Status).Error
+		}
+		if request.Status != model.ChangeRequestPending {
+			return errorx.ErrApprovalClosed.WithDetails(map[string]string{"status": request.Status})
+		}
+		if *reviewer == request.TargetUserID || (approve && *reviewer == request.RequestedBy) {
+			return errorx.ErrSelfApproval
+		}
+
+		request.Status = model.ChangeRequestRejected
+		auditAction := model.AuditActionApprovalRejected
+		if approve {
+			request.Status = model.ChangeRequestApproved
+			auditAction = model.AuditActionApprovalApproved
+			// A savepoint keeps a failed apply from discarding the status update below.
+			if err := tx.Transaction(func(apply *gorm.DB) error {
+				if err := applyChangeRequest(apply, l.svcCtx, &request, &before); err != nil {
+					return err
+				}
+				return recordApplied(apply, &request, &before)
+			}); err != nil {
+				var appErr *errorx.AppError
+				if !errors.As(err, &appErr) {
+					return err
+				}
+				request.Status = model.ChangeRequestFailed
+				request.Error = appErr.Code
+			}
+		}
+
+		request.ReviewedBy = reviewer
+		request.ReviewedAt = &now
+		request.Comment = strings.TrimSpace(req.Comment)
+		if err := tx.Model(&request).Select("status", "reviewed_by", "reviewed_at", "comment", "error").Updates(&request).Error; err != nil {
+			return err
+		}
+
+		details, _ := json.Marshal(map[string]interface{}{
+			"changeRequest": request.ID,
+			"action":        request.Action,
+			"requestedBy":   request.RequestedBy,
+			"status":        request.Status,
+			"comment":       request.Comment,
+		})
+		targetID := request.TargetUserID
+		return tx.Create(&model.AuditLog{
+			ActorID:      reviewer,
+			Action:       auditAction,
+			TargetUserID: &targetID,
+			Details:      string(details),
+		}).Error
+	})
+	if err == nil {
+		err = closedErr
+	}
+	if err != nil {
+		var appErr *errorx.AppError
+		if errors.As(err, &appErr) {
+			return nil, appErr
+		}
+		l.Errorf("review change request %d failed: %v", id, err)
+		return nil, errorx.ErrInternal
+	}
+
+	l.Infof("change request %d %s by user %d", request.ID, request.Status, *reviewer)
+	dto := toChangeRequestDTO(&request)
+	return &dto, nil
+}
+
+// recordApplied records the event for the change an approved request made to before.
+func recordApplied(tx *gorm.DB, request *model.ChangeRequest, before *model.User) error {
+	after := model.User{ID: before.ID}
+	switch request.Action {
+	case policy.ActionUserStatusUpdate:
+		return recordStatusChanged(tx, &after, before.Status)
+	case policy.ActionUserRolesAssign:
+		return recordRolesChanged(tx, &after, common.ActiveRoleNames(before))
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package admin
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/logic/common"
+	"usermgmt/internal/policy"
+	"usermgmt/internal/repository"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+	"usermgmt/internal/types"
+)
+
+// RevokeRoleLogic removes a single role from a user without touching the other grants.
+type RevokeRoleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewRevokeRoleLogic constructor.
+func NewRevokeRoleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RevokeRoleLogic {
+	return &RevokeRoleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Revoke is idempotent: revoking a role the user does not hold leaves the role version unchanged.
+func (l *RevokeRoleLogic) Revoke(userID uint, roleName string) (*types.ProfileResponse, error) {
+	defer tracing.Logic(&l.ctx, "RevokeRoleLogic.Revoke")()
+
+	store := l.svcCtx.Store
+
+	user, role, err := loadUserAndRole(l.ctx, store, userID, roleName)
+	if err != nil {
+		if !errors.As(err, new(*errorx.AppError)) {
+			l.Errorf("load user/role for revoke failed: %v", err)
+			return nil, errorx.ErrInternal
+		}
+		return nil, err
+	}
+
+	guard := newSafeguard(l.ctx, l.svcCtx)
+	removed := make([]string, 0, 1)
+	for _, grant := range user.RoleGrants {
+		if grant.RoleID == role.ID {
+			removed = append(removed, role.Name)
+		}
+	}
+	if err := guard.checkRoleRemoval(user, removed); err != nil {
+		return nil, err
+	}
+
+	if err := authorize(l.ctx, l.svcCtx, policy.ActionUserRolesAssign, user, policy.Attributes{
+		"roles": []string{role.Name},
+		"mode":  "revoke",
+	}); err != nil {
+		return nil, err
+	}
+
+	previousRoles := common.ActiveRoleNames(user)
+	if err := store.Transaction(l.ctx, func(tx repository.Store) error {
+		if err := guard.preserveAdminsIn(l.ctx, tx, func() error {
+			revoked, err := tx.Roles().Revoke(l.ctx, user.ID, role.ID)
+			if err != nil || !revoked {
+				return err
+			}
+			return versionError(tx.Users().BumpRoleVersion(l.ctx, user.ID, nil))
+		}); err != nil {
+			return err
+		}
+		return recordRolesChangedIn(l.ctx, tx, user, previousRoles)
+	}); err != nil {
+		var appErr *errorx.AppError
+		if errors.As(err, &appErr) {
+			return nil, appErr
+		}
+		l.Errorf("revoke role transaction failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	return &types.ProfileResponse{User: common.ToUserDTO(user)}, nil
+}
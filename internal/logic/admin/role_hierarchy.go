@@ -0,0 +1,77 @@
+package admin
+
+import (
+	"sort"
+
+	"gorm.io/gorm"
+
+	"usermgmt/internal/logic/common"
+	"usermgmt/internal/model"
+	"usermgmt/internal/rbac"
+	"usermgmt/internal/types"
+)
+
+// loadHierarchy loads every role with its direct permissions into an rbac.Hierarchy.
+func loadHierarchy(db *gorm.DB) (*rbac.Hierarchy, error) {
+	var roles []model.Role
+	if err := db.Preload("Permissions").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return rbac.NewHierarchy(roles), nil
+}
+
+// loadActiveRoleIDs returns the IDs of the roles a user currently holds, directly or via groups; found is false for unknown users.
+func loadActiveRoleIDs(db *gorm.DB, userID uint) (ids []uint, found bool, err error) {
+	var user model.User
+	if err := db.Scopes(common.PreloadActiveRoles).Limit(1).Find(&user, userID).Error; err != nil {
+		return nil, false, err
+	}
+	if user.ID == 0 {
+		return nil, false, nil
+	}
+	seen := make(map[uint]struct{})
+	ids = make([]uint, 0, len(user.RoleGrants))
+	add := func(id uint) {
+		if _, ok := seen[id]; !ok {
+			seen[id] = struct{}{}
+			ids = append(ids, id)
+		}
+	}
+	for _, grant := range user.RoleGrants {
+		add(grant.RoleID)
+	}
+	for _, member := range user.Groups {
+		for _, role := range member.Group.Roles {
+			add(role.ID)
+		}
+	}
+	return ids, true, nil
+}
+
+// toRoleDTO describes a role together with its inherited permissions.
+func toRoleDTO(h *rbac.Hierarchy, role *model.Role) types.RoleDTO {
+	ancestors := make([]string, 0)
+	for _, ancestor := range h.Chain(role.ID) {
+		if ancestor.ID != role.ID {
+			ancestors = append(ancestors, ancestor.Name)
+		}
+	}
+
+	direct := make([]string, 0, len(role.Permissions))
+	for _, perm := range role.Permissions {
+		direct = append(direct, perm.Code)
+	}
+	sort.Strings(direct)
+
+	dto := types.RoleDTO{
+		Name:                 role.Name,
+		Description:          role.Description,
+		Ancestors:            ancestors,
+		Permissions:          direct,
+		EffectivePermissions: h.EffectivePermissions([]uint{role.ID}),
+	}
+	if len(ancestors) > 0 {
+		dto.Parent = ancestors[0]
+	}
+	return dto
+}
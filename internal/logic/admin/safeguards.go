@@ -0,0 +1,148 @@
+package admin
+
+import (
+	"context"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"usermgmt/internal/config"
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/logic/common"
+	"usermgmt/internal/middleware"
+	"usermgmt/internal/model"
+	"usermgmt/internal/repository"
+	"usermgmt/internal/svc"
+	"usermgmt/pkg/contextx"
+)
+
+// safeguard enforces the invariants that keep administrators from locking everyone out:
+// no self-disable/delete, no self-demotion, protected break-glass accounts and a minimum
+// number of active admins. Thresholds come from the Safeguards config.
+type safeguard struct {
+	callerID  uint
+	adminRole string
+	conf      config.SafeguardConf
+}
+
+func newSafeguard(ctx context.Context, svcCtx *svc.ServiceContext) *safeguard {
+	var callerID uint
+	if claims := contextx.MustGetClaims(ctx); claims != nil {
+		callerID = claims.UserID
+	}
+	return newSafeguardFor(svcCtx, callerID)
+}
+
+// newSafeguardFor builds the guard on behalf of callerID, e.g. the requester of an approved change.
+func newSafeguardFor(svcCtx *svc.ServiceContext, callerID uint) *safeguard {
+	conf := svcCtx.Config.Safeguards
+	return &safeguard{
+		callerID:  callerID,
+		adminRole: common.AdminRole(conf),
+		conf:      conf,
+	}
+}
+
+func (g *safeguard) isSelf(user *model.User) bool {
+	return g.callerID != 0 && user.ID == g.callerID
+}
+
+func (g *safeguard) isProtected(user *model.User) bool {
+	return common.IsProtectedUser(g.conf, user.Username)
+}
+
+// checkDelegated keeps callers that were admitted only through an extra global role (such as
+// support on the status route) away from administrators and protected accounts, whatever the
+// loaded policy allows; super admins and org admins of the resolved organisation pass.
+func checkDelegated(ctx context.Context, svcCtx *svc.ServiceContext, guard *safeguard, user *model.User) error {
+	tenancy := svcCtx.Config.Tenancy
+	if middleware.HasActiveRole(contextx.MustGetClaims(ctx), tenancy.SuperAdminRole) {
+		return nil
+	}
+	if tenant := contextx.TenantFromContext(ctx); tenant != nil {
+		for _, role := range tenant.Roles {
+			if strings.EqualFold(role, tenancy.OrgAdminRole) {
+				return nil
+			}
+		}
+	}
+	if guard.isProtected(user) {
+		return errorx.ErrProtectedAccount
+	}
+	for _, name := range common.ActiveRoleNames(user) {
+		if strings.EqualFold(name, guard.adminRole) || strings.EqualFold(name, tenancy.SuperAdminRole) {
+			return errorx.ErrForbidden
+		}
+	}
+	return nil
+}
+
+// checkStatus rejects disabling oneself or a protected account.
+func (g *safeguard) checkStatus(user *model.User, status string) error {
+	if status != model.UserStatusDisabled {
+		return nil
+	}
+	if g.isSelf(user) {
+		return errorx.ErrSelfDisable
+	}
+	if g.isProtected(user) {
+		return errorx.ErrProtectedAccount
+	}
+	return nil
+}
+
+// checkRoleRemoval rejects removing any role from a protected account and the admin role from oneself.
+func (g *safeguard) checkRoleRemoval(user *model.User, removed []string) error {
+	if len(removed) == 0 {
+		return nil
+	}
+	if g.isProtected(user) {
+		return errorx.ErrProtectedAccount
+	}
+	if g.isSelf(user) {
+		for _, name := range removed {
+			if strings.EqualFold(name, g.adminRole) {
+				return errorx.ErrSelfDemotion
+			}
+		}
+	}
+	return nil
+}
+
+// checkDelete rejects deleting oneself or a protected account.
+func (g *safeguard) checkDelete(user *model.User) error {
+	if g.isSelf(user) {
+		return errorx.ErrSelfDelete
+	}
+	if g.isProtected(user) {
+		return errorx.ErrProtectedAccount
+	}
+	return nil
+}
+
+// preserveAdmins runs fn inside tx and fails with ErrMinAdmins if it lowered the number of active
+// admins below the threshold; see preserveAdminsIn.
+func (g *safeguard) preserveAdmins(tx *gorm.DB, fn func() error) error {
+	return g.preserveAdminsIn(tx.Statement.Context, repository.NewGormStore(tx), fn)
+}
+
+// preserveAdminsIn runs fn inside the transaction of store and fails with ErrMinAdmins if it
+// lowered the number of active admins below the threshold; see common.PreserveAdmins.
+func (g *safeguard) preserveAdminsIn(ctx context.Context, store repository.Store, fn func() error) error {
+	return common.PreserveAdmins(ctx, g.conf, store, fn)
+}
+
+// removedRoles lists the user's directly held roles that are absent from keep.
+func removedRoles(user *model.User, keep []string) []string {
+	kept := make(map[string]struct{}, len(keep))
+	for _, name := range keep {
+		kept[strings.ToLower(name)] = struct{}{}
+	}
+	removed := make([]string, 0)
+	for _, grant := range user.RoleGrants {
+		if _, ok := kept[strings.ToLower(grant.Role.Name)]; !ok {
+			removed = append(removed, grant.Role.Name)
+		}
+	}
+	return removed
+}
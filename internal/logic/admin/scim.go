@@ -0,0 +1,179 @@
+package admin
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/model"
+	"usermgmt/internal/scim"
+	"usermgmt/internal/svc"
+)
+
+// SCIM PATCH operations.
+const (
+	scimOpAdd     = "add"
+	scimOpReplace = "replace"
+	scimOpRemove  = "remove"
+)
+
+// scimUserAttributes maps the filterable User attributes onto the users table.
+var scimUserAttributes = scim.Attributes{
+	"id":             {Column: "users.id", Type: scim.TypeInteger},
+	"externalid":     {Column: "users.external_id", CaseExact: true},
+	"username":       {Column: "users.username"},
+	"displayname":    {Column: "users.full_name"},
+	"name.formatted": {Column: "users.full_name"},
+	"emails":         {Column: "users.email"},
+	"emails.value":   {Column: "users.email"},
+	"active": {Column: "users.status", Type: scim.TypeBoolean, Bool: func(active bool) interface{} {
+		return userStatus(active)
+	}},
+	"meta.created":      {Column: "users.created_at", Type: scim.TypeDateTime},
+	"meta.lastmodified": {Column: "users.updated_at", Type: scim.TypeDateTime},
+	"groups":            {Member: "users.id IN (SELECT user_id FROM user_roles WHERE role_id = ? AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP))"},
+	"groups.value":      {Member: "users.id IN (SELECT user_id FROM user_roles WHERE role_id = ? AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP))"},
+	strings.ToLower(scim.SchemaEnterpriseUser) + ":department": {Column: "users.department"},
+}
+
+// scimGroupAttributes maps the filterable Group attributes onto the roles table.
+var scimGroupAttributes = scim.Attributes{
+	"id":                {Column: "roles.id", Type: scim.TypeInteger},
+	"externalid":        {Column: "roles.external_id", CaseExact: true},
+	"displayname":       {Column: "roles.name"},
+	"meta.created":      {Column: "roles.created_at", Type: scim.TypeDateTime},
+	"meta.lastmodified": {Column: "roles.updated_at", Type: scim.TypeDateTime},
+	"members":           {Member: "roles.id IN (SELECT role_id FROM user_roles WHERE user_id = ? AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP))"},
+	"members.value":     {Member: "roles.id IN (SELECT role_id FROM user_roles WHERE user_id = ? AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP))"},
+}
+
+// userStatus maps the SCIM active flag to a user status.
+func userStatus(active bool) string {
+	if active {
+		return model.UserStatusEnabled
+	}
+	return model.UserStatusDisabled
+}
+
+// scimBaseURL is the prefix of resource locations; without SCIM.BaseURL they are server-relative.
+func scimBaseURL(svcCtx *svc.ServiceContext) string {
+	if base := strings.TrimRight(svcCtx.Config.SCIM.BaseURL, "/"); base != "" {
+		return base
+	}
+	return "/scim/v2"
+}
+
+// scimPage clamps the 1-based startIndex and count of a list request.
+func scimPage(svcCtx *svc.ServiceContext, query scim.ListQuery) (int, int) {
+	maxResults := svcCtx.Config.SCIM.MaxResults
+	if maxResults <= 0 {
+		maxResults = 100
+	}
+	start := query.StartIndex
+	if start < 1 {
+		start = 1
+	}
+	count := maxResults
+	if query.Count != nil && *query.Count < count {
+		count = *query.Count
+	}
+	if count < 0 {
+		count = 0
+	}
+	return start, count
+}
+
+// applySCIMFilter narrows query by a SCIM filter expression.
+func applySCIMFilter(query *gorm.DB, filter string, attrs scim.Attributes) (*gorm.DB, error) {
+	if strings.TrimSpace(filter) == "" {
+		return query, nil
+	}
+	expr, err := scim.ParseFilter(filter)
+	if err != nil {
+		return nil, errorx.ErrInvalidFilter.WithDetails(err.Error())
+	}
+	condition, args, err := scim.ToSQL(expr, attrs)
+	if err != nil {
+		return nil, errorx.ErrInvalidFilter.WithDetails(err.Error())
+	}
+	return query.Where(condition, args...), nil
+}
+
+// parseSCIMID converts a resource id; ids that are not numbers cannot exist.
+func parseSCIMID(id string) (uint, bool) {
+	value, err := strconv.ParseUint(id, 10, 64)
+	if err != nil || value == 0 {
+		return 0, false
+	}
+	return uint(value), true
+}
+
+func scimOp(op string) (string, error) {
+	switch normalized := strings.ToLower(strings.TrimSpace(op)); normalized {
+	case scimOpAdd, scimOpReplace, scimOpRemove:
+		return normalized, nil
+	}
+	return "", errorx.ErrValidation.WithDetails(errorx.Detail{Key: "scimOpUnsupported", Args: map[string]string{"op": op}})
+}
+
+func decodeSCIMString(raw json.RawMessage) (string, error) {
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", errorx.ErrValidation.WithDetails(errorx.Detail{Key: "scimValueNotString"})
+	}
+	return strings.TrimSpace(value), nil
+}
+
+// decodeSCIMBool accepts JSON booleans and the "True"/"False" strings some IdPs send.
+func decodeSCIMBool(raw json.RawMessage) (bool, error) {
+	var flag bool
+	if err := json.Unmarshal(raw, &flag); err == nil {
+		return flag, nil
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		if parsed, err := strconv.ParseBool(strings.ToLower(text)); err == nil {
+			return parsed, nil
+		}
+	}
+	return false, errorx.ErrValidation.WithDetails(errorx.Detail{Key: "scimValueNotBoolean"})
+}
+
+// decodeSCIMMembers reads a members value: a list of {"value": id} entries or a single one.
+func decodeSCIMMembers(raw json.RawMessage) ([]string, error) {
+	var members []scim.MultiValue
+	if err := json.Unmarshal(raw, &members); err != nil {
+		var member scim.MultiValue
+		if err := json.Unmarshal(raw, &member); err != nil {
+			return nil, errorx.ErrValidation.WithDetails(errorx.Detail{Key: "scimMembersInvalid"})
+		}
+		members = []scim.MultiValue{member}
+	}
+	ids := make([]string, 0, len(members))
+	for _, member := range members {
+		ids = append(ids, member.Value)
+	}
+	return ids, nil
+}
+
+// decodeSCIMObject reads the value of a PATCH operation without a path, whose keys are
+// attribute paths.
+func decodeSCIMObject(raw json.RawMessage) (map[string]json.RawMessage, error) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return nil, errorx.ErrValidation.WithDetails(errorx.Detail{Key: "scimValueNotObject"})
+	}
+	return object, nil
+}
+
+// isSCIMMetaKey reports keys of a resource body that are not attributes to apply.
+func isSCIMMetaKey(key string) bool {
+	switch strings.ToLower(key) {
+	case "schemas", "id", "meta":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package admin
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/scim"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+)
+
+// SCIMDiscoveryLogic serves the read-only ServiceProviderConfig, ResourceTypes and Schemas
+// endpoints.
+type SCIMDiscoveryLogic struct {
+	logx.Logger
+	ctx       context.Context
+	svcCtx    *svc.ServiceContext
+	discovery scim.Discovery
+}
+
+// NewSCIMDiscoveryLogic constructor.
+func NewSCIMDiscoveryLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SCIMDiscoveryLogic {
+	_, maxResults := scimPage(svcCtx, scim.ListQuery{})
+	return &SCIMDiscoveryLogic{
+		Logger:    logx.WithContext(ctx),
+		ctx:       ctx,
+		svcCtx:    svcCtx,
+		discovery: scim.Discovery{BaseURL: scimBaseURL(svcCtx), MaxResults: maxResults},
+	}
+}
+
+func (l *SCIMDiscoveryLogic) ServiceProviderConfig() scim.ServiceProviderConfig {
+	defer tracing.Logic(&l.ctx, "SCIMDiscoveryLogic.ServiceProviderConfig")()
+
+	return l.discovery.ServiceProviderConfig()
+}
+
+func (l *SCIMDiscoveryLogic) ResourceTypes() scim.ListResponse {
+	defer tracing.Logic(&l.ctx, "SCIMDiscoveryLogic.ResourceTypes")()
+
+	types := l.discovery.ResourceTypes()
+	return scim.NewListResponse(types, int64(len(types)), 1, len(types))
+}
+
+func (l *SCIMDiscoveryLogic) ResourceType(id string) (*scim.ResourceType, error) {
+	defer tracing.Logic(&l.ctx, "SCIMDiscoveryLogic.ResourceType")()
+
+	for _, resourceType := range l.discovery.ResourceTypes() {
+		if resourceType.ID == id {
+			return &resourceType, nil
+		}
+	}
+	return nil, errorx.ErrResourceNotFound
+}
+
+func (l *SCIMDiscoveryLogic) Schemas() scim.ListResponse {
+	defer tracing.Logic(&l.ctx, "SCIMDiscoveryLogic.Schemas")()
+
+	schemas := l.discovery.Schemas()
+	return scim.NewListResponse(schemas, int64(len(schemas)), 1, len(schemas))
+}
+
+func (l *SCIMDiscoveryLogic) Schema(id string) (*scim.Schema, error) {
+	defer tracing.Logic(&l.ctx, "SCIMDiscoveryLogic.Schema")()
+
+	for _, schema := range l.discovery.Schemas() {
+		if schema.ID == id {
+			return &schema, nil
+		}
+	}
+	return nil, errorx.ErrResourceNotFound
+}
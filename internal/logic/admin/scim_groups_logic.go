@@ -0,0 +1,636 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/logic/common"
+	"usermgmt/internal/model"
+	"usermgmt/internal/scim"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+)
+
+// SCIMGroupsLogic serves the SCIM /Groups resource. A group is a role and its members are the
+// users holding that role directly; group-inherited and expired grants are not members. Like
+// SCIMUsersLogic it bypasses policies but not the safeguards. Granting one of
+// Approvals.SensitiveRoles needs a second admin, so SCIM cannot add members to such a role.
+type SCIMGroupsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewSCIMGroupsLogic constructor.
+func NewSCIMGroupsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SCIMGroupsLogic {
+	return &SCIMGroupsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// scimMember is a direct holder of a role.
+type scimMember struct {
+	RoleID   uint
+	UserID   uint
+	Username string
+}
+
+// scimGroupState is the part of a role that SCIM can write; Members holds user IDs.
+type scimGroupState struct {
+	Name       string
+	ExternalID *string
+	Members    map[uint]struct{}
+}
+
+func (l *SCIMGroupsLogic) List(query scim.ListQuery) (*scim.ListResponse, error) {
+	defer tracing.Logic(&l.ctx, "SCIMGroupsLogic.List")()
+
+	db := l.svcCtx.DB.WithContext(l.ctx)
+	start, count := scimPage(l.svcCtx, query)
+
+	base, err := applySCIMFilter(db.Model(&model.Role{}), query.Filter, scimGroupAttributes)
+	if err != nil {
+		return nil, err
+	}
+	var total int64
+	if err := base.Count(&total).Error; err != nil {
+		l.Errorf("count scim groups failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	roles := make([]model.Role, 0)
+	if count > 0 {
+		if err := base.Order("roles.id").Offset(start - 1).Limit(count).Find(&roles).Error; err != nil {
+			l.Errorf("list scim groups failed: %v", err)
+			return nil, errorx.ErrInternal
+		}
+	}
+
+	withMembers := !scim.Excludes(query.ExcludedAttributes, "members")
+	members := make(map[uint][]scimMember)
+	if withMembers && len(roles) > 0 {
+		ids := make([]uint, 0, len(roles))
+		for _, role := range roles {
+			ids = append(ids, role.ID)
+		}
+		if members, err = loadSCIMMembers(db, ids); err != nil {
+			l.Errorf("load scim group members failed: %v", err)
+			return nil, errorx.ErrInternal
+		}
+	}
+
+	baseURL := scimBaseURL(l.svcCtx)
+	resources := make([]scim.Group, 0, len(roles))
+	for i := range roles {
+		resources = append(resources, toSCIMGroup(&roles[i], members[roles[i].ID], withMembers, baseURL))
+	}
+	resp := scim.NewListResponse(resources, total, start, len(resources))
+	return &resp, nil
+}
+
+func (l *SCIMGroupsLogic) Get(id string, excluded []string) (*scim.Group, error) {
+	defer tracing.Logic(&l.ctx, "SCIMGroupsLogic.Get")()
+
+	role, err := l.load(l.svcCtx.DB.WithContext(l.ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	return l.render(role, !scim.Excludes(excluded, "members"))
+}
+
+// Create adds a role with the given members.
+func (l *SCIMGroupsLogic) Create(resource *scim.Group) (*scim.Group, error) {
+	defer tracing.Logic(&l.ctx, "SCIMGroupsLogic.Create")()
+
+	state, err := newSCIMGroupState(resource)
+	if err != nil {
+		return nil, err
+	}
+	role := model.Role{}
+	if err := l.save(&role, state, map[uint]struct{}{}); err != nil {
+		return nil, err
+	}
+	l.Infof("scim created group %d (%s)", role.ID, role.Name)
+	return l.render(&role, true)
+}
+
+// Replace overwrites the role name, externalId and full member list (PUT).
+func (l *SCIMGroupsLogic) Replace(id string, resource *scim.Group) (*scim.Group, error) {
+	defer tracing.Logic(&l.ctx, "SCIMGroupsLogic.Replace")()
+
+	db := l.svcCtx.DB.WithContext(l.ctx)
+	role, err := l.load(db, id)
+	if err != nil {
+		return nil, err
+	}
+	current, err := l.memberSet(db, role.ID)
+	if err != nil {
+		return nil, err
+	}
+	state, err := newSCIMGroupState(resource)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.save(role, state, current); err != nil {
+		return nil, err
+	}
+	return l.render(role, true)
+}
+
+// Patch applies the operations in order and saves the result atomically.
+func (l *SCIMGroupsLogic) Patch(id string, req *scim.PatchRequest) (*scim.Group, error) {
+	defer tracing.Logic(&l.ctx, "SCIMGroupsLogic.Patch")()
+
+	db := l.svcCtx.DB.WithContext(l.ctx)
+	role, err := l.load(db, id)
+	if err != nil {
+		return nil, err
+	}
+	current, err := l.memberSet(db, role.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &scimGroupState{Name: role.Name, ExternalID: role.ExternalID, Members: make(map[uint]struct{}, len(current))}
+	for userID := range current {
+		state.Members[userID] = struct{}{}
+	}
+	for _, operation := range req.Operations {
+		if err := state.patch(operation); err != nil {
+			return nil, err
+		}
+	}
+	if err := l.save(role, state, current); err != nil {
+		return nil, err
+	}
+	return l.render(role, true)
+}
+
+// Delete removes the role after revoking it from its members. The safeguard admin role cannot
+// be deleted.
+func (l *SCIMGroupsLogic) Delete(id string) error {
+	defer tracing.Logic(&l.ctx, "SCIMGroupsLogic.Delete")()
+
+	db := l.svcCtx.DB.WithContext(l.ctx)
+	role, err := l.load(db, id)
+	if err != nil {
+		return err
+	}
+	guard := newSafeguardFor(l.svcCtx, 0)
+	if strings.EqualFold(role.Name, guard.adminRole) {
+		return errorx.ErrImmutable.WithDetails(errorx.Detail{Key: "scimAdminRoleDelete"})
+	}
+	current, err := l.memberSet(db, role.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := guard.preserveAdmins(tx, func() error {
+			return l.changeMembers(tx, guard, role, nil, sortedIDs(current))
+		}); err != nil {
+			return err
+		}
+		if err := tx.Where("role_id = ?", role.ID).Delete(&model.RolePermission{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("role_id = ?", role.ID).Delete(&model.GroupRole{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("role_id = ?", role.ID).Delete(&model.OrgMemberRole{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("role_id = ?", role.ID).Delete(&model.UserRole{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Role{}).Where("parent_id = ?", role.ID).Update("parent_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Role{}, role.ID).Error
+	}); err != nil {
+		var appErr *errorx.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		l.Errorf("delete scim group %d failed: %v", role.ID, err)
+		return errorx.ErrInternal
+	}
+	l.Infof("scim deleted group %d (%s)", role.ID, role.Name)
+	return nil
+}
+
+func newSCIMGroupState(resource *scim.Group) (*scimGroupState, error) {
+	state := &scimGroupState{
+		Name:       strings.TrimSpace(resource.DisplayName),
+		ExternalID: optionalString(resource.ExternalID),
+		Members:    make(map[uint]struct{}, len(resource.Members)),
+	}
+	ids := make([]string, 0, len(resource.Members))
+	for _, member := range resource.Members {
+		ids = append(ids, member.Value)
+	}
+	return state, state.addMembers(ids)
+}
+
+func (s *scimGroupState) addMembers(ids []string) error {
+	invalid := make([]string, 0)
+	for _, raw := range ids {
+		id, ok := parseSCIMID(raw)
+		if !ok {
+			invalid = append(invalid, raw)
+			continue
+		}
+		s.Members[id] = struct{}{}
+	}
+	if len(invalid) > 0 {
+		return errorx.ErrValidation.WithDetails(map[string]interface{}{"unknownMembers": invalid})
+	}
+	return nil
+}
+
+func (s *scimGroupState) removeMembers(ids []string) {
+	for _, raw := range ids {
+		if id, ok := parseSCIMID(raw); ok {
+			delete(s.Members, id)
+		}
+	}
+}
+
+func (s *scimGroupState) patch(operation scim.PatchOperation) error {
+	op, err := scimOp(operation.Op)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(operation.Path) == "" {
+		if op == scimOpRemove {
+			return errorx.ErrInvalidPath.WithDetails(errorx.Detail{Key: "scimRemoveWithoutPath"})
+		}
+		object, err := decodeSCIMObject(operation.Value)
+		if err != nil {
+			return err
+		}
+		for key, value := range object {
+			if isSCIMMetaKey(key) {
+				continue
+			}
+			path, err := scim.ParsePath(key)
+			if err != nil {
+				return errorx.ErrInvalidPath.WithDetails(err.Error())
+			}
+			if err := s.set(op, path, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	path, err := scim.ParsePath(operation.Path)
+	if err != nil {
+		return errorx.ErrInvalidPath.WithDetails(err.Error())
+	}
+	if op != scimOpRemove && len(operation.Value) == 0 {
+		return errorx.ErrValidation.WithDetails(errorx.Detail{Key: "scimValueMissing"})
+	}
+	return s.set(op, path, operation.Value)
+}
+
+func (s *scimGroupState) set(op string, path scim.Path, raw json.RawMessage) error {
+	switch path.Attr {
+	case "displayname":
+		if op == scimOpRemove {
+			return errorx.ErrImmutable.WithDetails(errorx.Detail{Key: "scimRequiredAttribute", Args: map[string]string{"attr": "displayName"}})
+		}
+		name, err := decodeSCIMString(raw)
+		s.Name = name
+		return err
+
+	case "externalid":
+		if op == scimOpRemove {
+			s.ExternalID = nil
+			return nil
+		}
+		value, err := decodeSCIMString(raw)
+		s.ExternalID = optionalString(value)
+		return err
+
+	case "members":
+		if path.Sub != "" && path.Sub != "value" {
+			return errorx.ErrInvalidPath.WithDetails(errorx.Detail{Key: "scimMemberAttributeUnsupported", Args: map[string]string{"attr": path.Sub}})
+		}
+		if op == scimOpRemove {
+			// members[value eq "1"] removes the matching members, a value lists the members
+			// to remove (as some IdPs send), and a bare "members" removes everyone.
+			if path.Filter != nil {
+				ids, ok := scim.EqualValues(path.Filter, "value")
+				if !ok {
+					return errorx.ErrInvalidFilter.WithDetails(errorx.Detail{Key: "scimMemberFilterUnsupported"})
+				}
+				s.removeMembers(ids)
+				return nil
+			}
+			if len(raw) > 0 {
+				ids, err := decodeSCIMMembers(raw)
+				if err != nil {
+					return err
+				}
+				s.removeMembers(ids)
+				return nil
+			}
+			s.Members = make(map[uint]struct{})
+			return nil
+		}
+
+		ids, err := decodeSCIMMembers(raw)
+		if err != nil {
+			return err
+		}
+		if op == scimOpReplace {
+			s.Members = make(map[uint]struct{}, len(ids))
+		}
+		return s.addMembers(ids)
+	}
+	return errorx.ErrInvalidPath.WithDetails(errorx.Detail{Key: "scimAttributeUnsupported", Args: map[string]string{"attr": path.Attr}})
+}
+
+func (l *SCIMGroupsLogic) load(db *gorm.DB, id string) (*model.Role, error) {
+	roleID, ok := parseSCIMID(id)
+	if !ok {
+		return nil, errorx.ErrRoleNotFound
+	}
+	var role model.Role
+	if err := db.First(&role, roleID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorx.ErrRoleNotFound
+		}
+		l.Errorf("load scim group %d failed: %v", roleID, err)
+		return nil, errorx.ErrInternal
+	}
+	return &role, nil
+}
+
+func (l *SCIMGroupsLogic) memberSet(db *gorm.DB, roleID uint) (map[uint]struct{}, error) {
+	members, err := loadSCIMMembers(db, []uint{roleID})
+	if err != nil {
+		l.Errorf("load scim group %d members failed: %v", roleID, err)
+		return nil, errorx.ErrInternal
+	}
+	set := make(map[uint]struct{}, len(members[roleID]))
+	for _, member := range members[roleID] {
+		set[member.UserID] = struct{}{}
+	}
+	return set, nil
+}
+
+func (l *SCIMGroupsLogic) render(role *model.Role, withMembers bool) (*scim.Group, error) {
+	var members []scimMember
+	if withMembers {
+		loaded, err := loadSCIMMembers(l.svcCtx.DB.WithContext(l.ctx), []uint{role.ID})
+		if err != nil {
+			l.Errorf("load scim group %d members failed: %v", role.ID, err)
+			return nil, errorx.ErrInternal
+		}
+		members = loaded[role.ID]
+	}
+	resource := toSCIMGroup(role, members, withMembers, scimBaseURL(l.svcCtx))
+	return &resource, nil
+}
+
+// save creates role (ID 0) or updates it to state, then grants and revokes the role so that its
+// members match state.Members. current is the member set state was derived from.
+func (l *SCIMGroupsLogic) save(role *model.Role, state *scimGroupState, current map[uint]struct{}) error {
+	db := l.svcCtx.DB.WithContext(l.ctx)
+	if err := l.svcCtx.Validator.VarCtx(l.ctx, state.Name, "required,max=50"); err != nil {
+		return errorx.ErrValidation.WithDetails(map[string]string{"field": "displayName", "rule": "required,max=50"})
+	}
+	if state.ExternalID != nil && len(*state.ExternalID) > 255 {
+		return errorx.ErrValidation.WithDetails(map[string]string{"field": "externalId", "rule": "max=255"})
+	}
+
+	guard := newSafeguardFor(l.svcCtx, 0)
+	if role.ID != 0 && role.Name != state.Name && strings.EqualFold(role.Name, guard.adminRole) {
+		return errorx.ErrImmutable.WithDetails(errorx.Detail{Key: "scimAdminRoleRename"})
+	}
+	if err := l.checkUnique(db, state, role.ID); err != nil {
+		return err
+	}
+
+	add := make([]uint, 0)
+	for userID := range state.Members {
+		if _, ok := current[userID]; !ok {
+			add = append(add, userID)
+		}
+	}
+	remove := make([]uint, 0)
+	for userID := range current {
+		if _, ok := state.Members[userID]; !ok {
+			remove = append(remove, userID)
+		}
+	}
+	sort.Slice(add, func(i, j int) bool { return add[i] < add[j] })
+	sort.Slice(remove, func(i, j int) bool { return remove[i] < remove[j] })
+	if l.grantsSensitiveRole(role, state, add) {
+		return errorx.ErrApprovalRequired
+	}
+	if err := l.checkUsersExist(db, add); err != nil {
+		return err
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		role.Name = state.Name
+		role.ExternalID = state.ExternalID
+		if role.ID == 0 {
+			if err := tx.Create(role).Error; err != nil {
+				return err
+			}
+		} else if err := tx.Model(role).Select("name", "external_id").Updates(role).Error; err != nil {
+			return err
+		}
+		if len(add) == 0 && len(remove) == 0 {
+			return nil
+		}
+		return guard.preserveAdmins(tx, func() error {
+			return l.changeMembers(tx, guard, role, add, remove)
+		})
+	}); err != nil {
+		var appErr *errorx.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return errorx.ErrRoleExists
+		}
+		l.Errorf("save scim group %q failed: %v", state.Name, err)
+		return errorx.ErrInternal
+	}
+	return nil
+}
+
+// grantsSensitiveRole reports whether saving state would give a sensitive role to someone: by
+// adding members, or by naming a role that has members after a sensitive role. Removing members
+// only takes the role away and stays allowed.
+func (l *SCIMGroupsLogic) grantsSensitiveRole(role *model.Role, state *scimGroupState, add []uint) bool {
+	if !containsRole(l.svcCtx.Config.Approvals.SensitiveRoles, state.Name) {
+		return false
+	}
+	renamed := !strings.EqualFold(role.Name, state.Name)
+	return len(add) > 0 || (renamed && len(state.Members) > 0)
+}
+
+// changeMembers grants role to add and revokes it from remove inside tx, bumping each user's
+// role version and recording user.roles_changed. Grants made here never expire.
+func (l *SCIMGroupsLogic) changeMembers(tx *gorm.DB, guard *safeguard, role *model.Role, add, remove []uint) error {
+	for _, userID := range remove {
+		var user model.User
+		if err := tx.Scopes(common.PreloadActiveRoles).First(&user, userID).Error; err != nil {
+			return err
+		}
+		if err := guard.checkRoleRemoval(&user, []string{role.Name}); err != nil {
+			return err
+		}
+		previous := common.ActiveRoleNames(&user)
+		if err := tx.Where("user_id = ? AND role_id = ?", userID, role.ID).Delete(&model.UserRole{}).Error; err != nil {
+			return err
+		}
+		if err := bumpRoleVersion(tx, userID, nil); err != nil {
+			return err
+		}
+		if err := recordRolesChanged(tx, &user, previous); err != nil {
+			return err
+		}
+	}
+
+	for _, userID := range add {
+		var user model.User
+		if err := tx.Scopes(common.PreloadActiveRoles).First(&user, userID).Error; err != nil {
+			return err
+		}
+		previous := common.ActiveRoleNames(&user)
+		grant := model.UserRole{UserID: userID, RoleID: role.ID}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "role_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"expires_at": nil}),
+		}).Create(&grant).Error; err != nil {
+			return err
+		}
+		if err := bumpRoleVersion(tx, userID, nil); err != nil {
+			return err
+		}
+		if err := recordRolesChanged(tx, &user, previous); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkUnique rejects a displayName or externalId already used by another role.
+func (l *SCIMGroupsLogic) checkUnique(db *gorm.DB, state *scimGroupState, selfID uint) error {
+	query := db.Model(&model.Role{}).Where("id <> ?", selfID)
+	if state.ExternalID != nil {
+		query = query.Where("name = ? OR external_id = ?", state.Name, *state.ExternalID)
+	} else {
+		query = query.Where("name = ?", state.Name)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		l.Errorf("check scim group unique failed: %v", err)
+		return errorx.ErrInternal
+	}
+	if count > 0 {
+		return errorx.ErrRoleExists
+	}
+	return nil
+}
+
+func (l *SCIMGroupsLogic) checkUsersExist(db *gorm.DB, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	var existing []uint
+	if err := db.Model(&model.User{}).Where("id IN ?", ids).Pluck("id", &existing).Error; err != nil {
+		l.Errorf("check scim group members failed: %v", err)
+		return errorx.ErrInternal
+	}
+	if len(existing) == len(ids) {
+		return nil
+	}
+	found := make(map[uint]struct{}, len(existing))
+	for _, id := range existing {
+		found[id] = struct{}{}
+	}
+	missing := make([]string, 0)
+	for _, id := range ids {
+		if _, ok := found[id]; !ok {
+			missing = append(missing, strconv.FormatUint(uint64(id), 10))
+		}
+	}
+	return errorx.ErrValidation.WithDetails(map[string]interface{}{"unknownMembers": missing})
+}
+
+// loadSCIMMembers lists the unexpired direct grants of roleIDs, keyed by role.
+func loadSCIMMembers(db *gorm.DB, roleIDs []uint) (map[uint][]scimMember, error) {
+	var rows []scimMember
+	if err := db.Table("user_roles").
+		Select("user_roles.role_id, user_roles.user_id, users.username").
+		Joins("JOIN users ON users.id = user_roles.user_id").
+		Where("user_roles.role_id IN ? AND (user_roles.expires_at IS NULL OR user_roles.expires_at > ?)", roleIDs, time.Now()).
+		Order("user_roles.user_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	members := make(map[uint][]scimMember, len(roleIDs))
+	for _, row := range rows {
+		members[row.RoleID] = append(members[row.RoleID], row)
+	}
+	return members, nil
+}
+
+func toSCIMGroup(role *model.Role, members []scimMember, withMembers bool, baseURL string) scim.Group {
+	id := strconv.FormatUint(uint64(role.ID), 10)
+	resource := scim.Group{
+		Schemas:     []string{scim.SchemaGroup},
+		ID:          id,
+		DisplayName: role.Name,
+		Meta: &scim.Meta{
+			ResourceType: scim.ResourceGroup,
+			Created:      role.CreatedAt,
+			LastModified: role.UpdatedAt,
+			Location:     baseURL + "/Groups/" + id,
+		},
+	}
+	if role.ExternalID != nil {
+		resource.ExternalID = *role.ExternalID
+	}
+	if withMembers {
+		resource.Members = make([]scim.MultiValue, 0, len(members))
+		for _, member := range members {
+			userID := strconv.FormatUint(uint64(member.UserID), 10)
+			resource.Members = append(resource.Members, scim.MultiValue{
+				Value:   userID,
+				Display: member.Username,
+				Ref:     baseURL + "/Users/" + userID,
+			})
+		}
+	}
+	return resource
+}
+
+func sortedIDs(set map[uint]struct{}) []uint {
+	ids := make([]uint, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -0,0 +1,639 @@
+package admin
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/event"
+	"usermgmt/internal/logic/common"
+	"usermgmt/internal/model"
+	"usermgmt/internal/scim"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+)
+
+// SCIMUsersLogic serves the SCIM /Users resource for an identity provider. The IdP is trusted
+// as the source of truth, so changes skip the ABAC policies and two-person approval, but the
+// protected-account and minimum-admin safeguards still apply.
+type SCIMUsersLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewSCIMUsersLogic constructor.
+func NewSCIMUsersLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SCIMUsersLogic {
+	return &SCIMUsersLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// scimUserState is the part of a user that SCIM can write. An empty Password keeps the current one.
+type scimUserState struct {
+	Username   string
+	Email      string
+	Name       scim.Name
+	Department string
+	ExternalID *string
+	Active     bool
+	Password   string
+}
+
+func newSCIMUserState(user *model.User) scimUserState {
+	state := scimUserState{
+		Username:   user.Username,
+		Email:      user.Email,
+		Department: user.Department,
+		ExternalID: user.ExternalID,
+		Active:     user.Status != model.UserStatusDisabled,
+	}
+	state.setFormatted(user.FullName)
+	return state
+}
+
+// fullName is name.formatted, else the given and family names joined.
+func (s *scimUserState) fullName() string {
+	if s.Name.Formatted != "" {
+		return s.Name.Formatted
+	}
+	return strings.TrimSpace(s.Name.GivenName + " " + s.Name.FamilyName)
+}
+
+func (s *scimUserState) setFormatted(fullName string) {
+	given, family := splitFullName(fullName)
+	s.Name = scim.Name{Formatted: fullName, GivenName: given, FamilyName: family}
+}
+
+// replace overwrites the state with a full resource (POST/PUT). Active and Password are kept
+// when the resource omits them; groups are read-only and ignored.
+func (s *scimUserState) replace(resource *scim.User) {
+	s.Username = strings.TrimSpace(resource.UserName)
+	s.Email = primaryEmail(resource.Emails)
+	s.Name = scim.Name{}
+	if resource.Name != nil {
+		s.Name = *resource.Name
+	}
+	if s.fullName() == "" {
+		s.setFormatted(strings.TrimSpace(resource.DisplayName))
+	}
+	s.ExternalID = optionalString(resource.ExternalID)
+	s.Department = ""
+	if resource.Enterprise != nil {
+		s.Department = strings.TrimSpace(resource.Enterprise.Department)
+	}
+	if resource.Active != nil {
+		s.Active = *resource.Active
+	}
+	s.Password = resource.Password
+}
+
+// patch applies one PATCH operation. Attributes the service does not store are ignored, as they
+// are on POST and PUT.
+func (s *scimUserState) patch(operation scim.PatchOperation) error {
+	op, err := scimOp(operation.Op)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(operation.Path) == "" {
+		if op == scimOpRemove {
+			return errorx.ErrInvalidPath.WithDetails(errorx.Detail{Key: "scimRemoveWithoutPath"})
+		}
+		object, err := decodeSCIMObject(operation.Value)
+		if err != nil {
+			return err
+		}
+		for key, value := range object {
+			if isSCIMMetaKey(key) {
+				continue
+			}
+			path, err := scim.ParsePath(key)
+			if err != nil {
+				return errorx.ErrInvalidPath.WithDetails(err.Error())
+			}
+			if err := s.set(path, value, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	path, err := scim.ParsePath(operation.Path)
+	if err != nil {
+		return errorx.ErrInvalidPath.WithDetails(err.Error())
+	}
+	if op != scimOpRemove && len(operation.Value) == 0 {
+		return errorx.ErrValidation.WithDetails(errorx.Detail{Key: "scimValueMissing"})
+	}
+	return s.set(path, operation.Value, op == scimOpRemove)
+}
+
+func (s *scimUserState) set(path scim.Path, raw json.RawMessage, remove bool) error {
+	var err error
+	switch path.Attr {
+	case "username":
+		if remove {
+			return errorx.ErrImmutable.WithDetails(errorx.Detail{Key: "scimRequiredAttribute", Args: map[string]string{"attr": "userName"}})
+		}
+		s.Username, err = decodeSCIMString(raw)
+
+	case "externalid":
+		if remove {
+			s.ExternalID = nil
+			return nil
+		}
+		var value string
+		value, err = decodeSCIMString(raw)
+		s.ExternalID = optionalString(value)
+
+	case "displayname":
+		if remove {
+			s.Name = scim.Name{}
+			return nil
+		}
+		var value string
+		value, err = decodeSCIMString(raw)
+		s.setFormatted(value)
+
+	case "name":
+		return s.setName(path.Sub, raw, remove)
+
+	case "emails":
+		if remove {
+			return errorx.ErrImmutable.WithDetails(errorx.Detail{Key: "scimRequiredAttribute", Args: map[string]string{"attr": "emails"}})
+		}
+		switch path.Sub {
+		case "value":
+			s.Email, err = decodeSCIMString(raw)
+		case "":
+			var emails []scim.MultiValue
+			if json.Unmarshal(raw, &emails) != nil {
+				var email scim.MultiValue
+				if json.Unmarshal(raw, &email) != nil {
+					return errorx.ErrValidation.WithDetails(errorx.Detail{Key: "scimAttributeInvalid", Args: map[string]string{"attr": "emails"}})
+				}
+				emails = []scim.MultiValue{email}
+			}
+			s.Email = primaryEmail(emails)
+		}
+
+	case "active":
+		if remove {
+			return errorx.ErrImmutable.WithDetails(errorx.Detail{Key: "scimAttributeNotRemovable", Args: map[string]string{"attr": "active"}})
+		}
+		s.Active, err = decodeSCIMBool(raw)
+
+	case "password":
+		if remove {
+			return errorx.ErrImmutable.WithDetails(errorx.Detail{Key: "scimAttributeNotRemovable", Args: map[string]string{"attr": "password"}})
+		}
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return errorx.ErrValidation.WithDetails(errorx.Detail{Key: "scimAttributeNotString", Args: map[string]string{"attr": "password"}})
+		}
+		s.Password = value
+
+	case strings.ToLower(scim.SchemaEnterpriseUser):
+		switch path.Sub {
+		case "department":
+			if remove {
+				s.Department = ""
+				return nil
+			}
+			s.Department, err = decodeSCIMString(raw)
+		case "":
+			if remove {
+				s.Department = ""
+				return nil
+			}
+			var extension scim.EnterpriseUser
+			if json.Unmarshal(raw, &extension) != nil {
+				return errorx.ErrValidation.WithDetails(errorx.Detail{Key: "scimEnterpriseInvalid"})
+			}
+			s.Department = strings.TrimSpace(extension.Department)
+		}
+
+	case "groups":
+		return errorx.ErrImmutable.WithDetails(errorx.Detail{Key: "scimGroupsReadOnly"})
+	}
+	return err
+}
+
+func (s *scimUserState) setName(sub string, raw json.RawMessage, remove bool) error {
+	if remove {
+		switch sub {
+		case "":
+			s.Name = scim.Name{}
+		case "formatted":
+			s.Name.Formatted = ""
+		case "givenname":
+			s.Name.GivenName, s.Name.Formatted = "", ""
+		case "familyname":
+			s.Name.FamilyName, s.Name.Formatted = "", ""
+		}
+		return nil
+	}
+
+	if sub == "" {
+		var name scim.Name
+		if err := json.Unmarshal(raw, &name); err != nil {
+			return errorx.ErrValidation.WithDetails(errorx.Detail{Key: "scimAttributeInvalid", Args: map[string]string{"attr": "name"}})
+		}
+		s.Name = name
+		return nil
+	}
+	value, err := decodeSCIMString(raw)
+	if err != nil {
+		return err
+	}
+	// Changing one part drops formatted so that the full name is recomposed from the parts.
+	switch sub {
+	case "formatted":
+		s.setFormatted(value)
+	case "givenname":
+		s.Name.GivenName, s.Name.Formatted = value, ""
+	case "familyname":
+		s.Name.FamilyName, s.Name.Formatted = value, ""
+	}
+	return nil
+}
+
+func (l *SCIMUsersLogic) List(query scim.ListQuery) (*scim.ListResponse, error) {
+	defer tracing.Logic(&l.ctx, "SCIMUsersLogic.List")()
+
+	db := l.svcCtx.DB.WithContext(l.ctx)
+	start, count := scimPage(l.svcCtx, query)
+
+	base, err := applySCIMFilter(db.Model(&model.User{}), query.Filter, scimUserAttributes)
+	if err != nil {
+		return nil, err
+	}
+	var total int64
+	if err := base.Count(&total).Error; err != nil {
+		l.Errorf("count scim users failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	users := make([]model.User, 0)
+	if count > 0 {
+		if err := base.Scopes(common.PreloadActiveRoles).
+			Order("users.id").
+			Offset(start - 1).
+			Limit(count).
+			Find(&users).Error; err != nil {
+			l.Errorf("list scim users failed: %v", err)
+			return nil, errorx.ErrInternal
+		}
+	}
+
+	baseURL := scimBaseURL(l.svcCtx)
+	resources := make([]scim.User, 0, len(users))
+	for i := range users {
+		resources = append(resources, toSCIMUser(&users[i], baseURL))
+	}
+	resp := scim.NewListResponse(resources, total, start, len(resources))
+	return &resp, nil
+}
+
+func (l *SCIMUsersLogic) Get(id string) (*scim.User, error) {
+	defer tracing.Logic(&l.ctx, "SCIMUsersLogic.Get")()
+
+	user, err := l.load(l.svcCtx.DB.WithContext(l.ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	resource := toSCIMUser(user, scimBaseURL(l.svcCtx))
+	return &resource, nil
+}
+
+// Create provisions a user. Without a password the account gets a random one, so it can only
+// sign in through the IdP until a password is set.
+func (l *SCIMUsersLogic) Create(resource *scim.User) (*scim.User, error) {
+	defer tracing.Logic(&l.ctx, "SCIMUsersLogic.Create")()
+
+	db := l.svcCtx.DB.WithContext(l.ctx)
+
+	state := scimUserState{Active: true}
+	state.replace(resource)
+	if err := l.validate(&state); err != nil {
+		return nil, err
+	}
+	if err := l.checkUnique(db, &state, 0); err != nil {
+		return nil, err
+	}
+
+	password := state.Password
+	if password == "" {
+		password = randomPassword()
+	}
+	hash, err := tracing.HashPassword(l.ctx, password, l.svcCtx.Config.Password.BcryptCost)
+	if err != nil {
+		l.Errorf("hash password failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	user := model.User{
+		Username:     state.Username,
+		ExternalID:   state.ExternalID,
+		Email:        state.Email,
+		PasswordHash: hash,
+		FullName:     state.fullName(),
+		Department:   state.Department,
+		Status:       userStatus(state.Active),
+	}
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		return common.RecordUserEvent(tx, event.UserRegistered, event.UserEventData{User: common.ToUserDTO(&user)})
+	}); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, errorx.ErrUserExists
+		}
+		l.Errorf("create scim user failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	l.Infof("scim provisioned user %d (%s)", user.ID, user.Username)
+	created := toSCIMUser(&user, scimBaseURL(l.svcCtx))
+	return &created, nil
+}
+
+// Replace overwrites the user's attributes with resource (PUT).
+func (l *SCIMUsersLogic) Replace(id string, resource *scim.User) (*scim.User, error) {
+	defer tracing.Logic(&l.ctx, "SCIMUsersLogic.Replace")()
+
+	user, err := l.load(l.svcCtx.DB.WithContext(l.ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	state := newSCIMUserState(user)
+	state.replace(resource)
+	return l.save(user, &state)
+}
+
+// Patch applies the operations in order and saves the result atomically.
+func (l *SCIMUsersLogic) Patch(id string, req *scim.PatchRequest) (*scim.User, error) {
+	defer tracing.Logic(&l.ctx, "SCIMUsersLogic.Patch")()
+
+	user, err := l.load(l.svcCtx.DB.WithContext(l.ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	state := newSCIMUserState(user)
+	for _, operation := range req.Operations {
+		if err := state.patch(operation); err != nil {
+			return nil, err
+		}
+	}
+	return l.save(user, &state)
+}
+
+// Delete removes the user and its direct role grants.
+func (l *SCIMUsersLogic) Delete(id string) error {
+	defer tracing.Logic(&l.ctx, "SCIMUsersLogic.Delete")()
+
+	db := l.svcCtx.DB.WithContext(l.ctx)
+	user, err := l.load(db, id)
+	if err != nil {
+		return err
+	}
+
+	guard := newSafeguardFor(l.svcCtx, 0)
+	if err := guard.checkDelete(user); err != nil {
+		return err
+	}
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		return guard.preserveAdmins(tx, func() error {
+			if err := tx.Where("user_id = ?", user.ID).Delete(&model.UserRole{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&model.User{}, user.ID).Error
+		})
+	}); err != nil {
+		var appErr *errorx.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		l.Errorf("delete scim user %d failed: %v", user.ID, err)
+		return errorx.ErrInternal
+	}
+	l.Infof("scim deprovisioned user %d (%s)", user.ID, user.Username)
+	return nil
+}
+
+func (l *SCIMUsersLogic) load(db *gorm.DB, id string) (*model.User, error) {
+	userID, ok := parseSCIMID(id)
+	if !ok {
+		return nil, errorx.ErrUserNotFound
+	}
+	var user model.User
+	if err := db.Scopes(common.PreloadActiveRoles).First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorx.ErrUserNotFound
+		}
+		l.Errorf("load scim user %d failed: %v", userID, err)
+		return nil, errorx.ErrInternal
+	}
+	return &user, nil
+}
+
+// save writes state to user under the safeguards and records the resulting events.
+func (l *SCIMUsersLogic) save(user *model.User, state *scimUserState) (*scim.User, error) {
+	db := l.svcCtx.DB.WithContext(l.ctx)
+	if err := l.validate(state); err != nil {
+		return nil, err
+	}
+	if err := l.checkUnique(db, state, user.ID); err != nil {
+		return nil, err
+	}
+
+	status := userStatus(state.Active)
+	guard := newSafeguardFor(l.svcCtx, 0)
+	if err := guard.checkStatus(user, status); err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{
+		"username":    state.Username,
+		"external_id": state.ExternalID,
+		"email":       state.Email,
+		"full_name":   state.fullName(),
+		"department":  state.Department,
+		"status":      status,
+	}
+	if state.Password != "" {
+		hash, err := tracing.HashPassword(l.ctx, state.Password, l.svcCtx.Config.Password.BcryptCost)
+		if err != nil {
+			l.Errorf("hash password failed: %v", err)
+			return nil, errorx.ErrInternal
+		}
+		updates["password_hash"] = hash
+	}
+
+	previousEmail, previousStatus := user.Email, user.Status
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := guard.preserveAdmins(tx, func() error {
+			return tx.Model(&model.User{}).Where("id = ?", user.ID).Updates(updates).Error
+		}); err != nil {
+			return err
+		}
+		if err := recordStatusChanged(tx, user, previousStatus); err != nil {
+			return err
+		}
+		if user.Email == previousEmail {
+			return nil
+		}
+		return common.RecordUserEvent(tx, event.UserEmailChanged, event.UserEventData{
+			User:          common.ToUserDTO(user),
+			PreviousEmail: previousEmail,
+		})
+	}); err != nil {
+		var appErr *errorx.AppError
+		if errors.As(err, &appErr) {
+			return nil, appErr
+		}
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, errorx.ErrUserExists
+		}
+		l.Errorf("update scim user %d failed: %v", user.ID, err)
+		return nil, errorx.ErrInternal
+	}
+
+	resource := toSCIMUser(user, scimBaseURL(l.svcCtx))
+	return &resource, nil
+}
+
+func (l *SCIMUsersLogic) validate(state *scimUserState) error {
+	state.Email = strings.ToLower(strings.TrimSpace(state.Email))
+	if state.fullName() == "" {
+		state.setFormatted(state.Username)
+	}
+	type fieldCheck struct {
+		field string
+		value interface{}
+		tag   string
+	}
+	checks := []fieldCheck{
+		{"userName", state.Username, "required,max=50"},
+		{"emails", state.Email, "required,email,max=255"},
+		{"name", state.fullName(), "max=100"},
+		{"department", state.Department, "max=100"},
+		{"password", state.Password, "omitempty,min=8,max=64"},
+	}
+	if state.ExternalID != nil {
+		checks = append(checks, fieldCheck{"externalId", *state.ExternalID, "max=255"})
+	}
+	for _, check := range checks {
+		if err := l.svcCtx.Validator.VarCtx(l.ctx, check.value, check.tag); err != nil {
+			return errorx.ErrValidation.WithDetails(map[string]string{"field": check.field, "rule": check.tag})
+		}
+	}
+	return nil
+}
+
+// checkUnique rejects a userName, email or externalId already used by another user.
+func (l *SCIMUsersLogic) checkUnique(db *gorm.DB, state *scimUserState, selfID uint) error {
+	query := db.Model(&model.User{}).Where("id <> ?", selfID)
+	if state.ExternalID != nil {
+		query = query.Where("username = ? OR email = ? OR external_id = ?", state.Username, state.Email, *state.ExternalID)
+	} else {
+		query = query.Where("username = ? OR email = ?", state.Username, state.Email)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		l.Errorf("check scim user unique failed: %v", err)
+		return errorx.ErrInternal
+	}
+	if count > 0 {
+		return errorx.ErrUserExists
+	}
+	return nil
+}
+
+func toSCIMUser(user *model.User, baseURL string) scim.User {
+	id := strconv.FormatUint(uint64(user.ID), 10)
+	active := user.Status != model.UserStatusDisabled
+	given, family := splitFullName(user.FullName)
+	resource := scim.User{
+		Schemas:     []string{scim.SchemaUser, scim.SchemaEnterpriseUser},
+		ID:          id,
+		UserName:    user.Username,
+		Name:        &scim.Name{Formatted: user.FullName, GivenName: given, FamilyName: family},
+		DisplayName: user.FullName,
+		Emails:      []scim.MultiValue{{Value: user.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta: &scim.Meta{
+			ResourceType: scim.ResourceUser,
+			Created:      user.CreatedAt,
+			LastModified: user.UpdatedAt,
+			Location:     baseURL + "/Users/" + id,
+		},
+	}
+	if user.ExternalID != nil {
+		resource.ExternalID = *user.ExternalID
+	}
+	if user.Department != "" {
+		resource.Enterprise = &scim.EnterpriseUser{Department: user.Department}
+	}
+	now := time.Now()
+	for _, grant := range user.RoleGrants {
+		if grant.ExpiresAt != nil && !grant.ExpiresAt.After(now) {
+			continue
+		}
+		roleID := strconv.FormatUint(uint64(grant.RoleID), 10)
+		resource.Groups = append(resource.Groups, scim.MultiValue{
+			Value:   roleID,
+			Display: grant.Role.Name,
+			Ref:     baseURL + "/Groups/" + roleID,
+		})
+	}
+	return resource
+}
+
+// primaryEmail picks the primary email, else the first one.
+func primaryEmail(emails []scim.MultiValue) string {
+	for _, email := range emails {
+		if email.Primary {
+			return strings.TrimSpace(email.Value)
+		}
+	}
+	if len(emails) > 0 {
+		return strings.TrimSpace(emails[0].Value)
+	}
+	return ""
+}
+
+// splitFullName treats the first word as the given name and the rest as the family name.
+func splitFullName(fullName string) (string, string) {
+	fields := strings.Fields(fullName)
+	if len(fields) == 0 {
+		return "", ""
+	}
+	return fields[0], strings.Join(fields[1:], " ")
+}
+
+func optionalString(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func randomPassword() string {
+	buf := make([]byte, 24)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,86 @@
+package admin
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+	"usermgmt/internal/types"
+)
+
+// SetGroupRolesLogic replaces the roles a group grants to its members.
+type SetGroupRolesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewSetGroupRolesLogic constructor.
+func NewSetGroupRolesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SetGroupRolesLogic {
+	return &SetGroupRolesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *SetGroupRolesLogic) Set(name string, req *types.SetGroupRolesRequest) (*types.GroupDTO, error) {
+	defer tracing.Logic(&l.ctx, "SetGroupRolesLogic.Set")()
+
+	db := l.svcCtx.DB.WithContext(l.ctx)
+
+	names := normalizeRoles(req.Roles)
+	roles, missing, err := findRolesByName(db, names)
+	if err != nil {
+		l.Errorf("query roles failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+	if len(missing) > 0 {
+		return nil, errorx.ErrValidation.WithDetails(map[string]interface{}{"missingRoles": missing})
+	}
+
+	guard := newSafeguard(l.ctx, l.svcCtx)
+	var dto types.GroupDTO
+	err = db.Transaction(func(tx *gorm.DB) error {
+		group, err := loadGroup(tx, name)
+		if err != nil {
+			return err
+		}
+		members, err := groupMemberIDs(tx, group.ID)
+		if err != nil {
+			return err
+		}
+		previous, err := snapshotRoles(tx, members)
+		if err != nil {
+			return err
+		}
+		if err := guard.preserveAdmins(tx, func() error {
+			if err := setGroupRoles(tx, group.ID, roles); err != nil {
+				return err
+			}
+			return bumpGroupRoleVersions(tx, group.ID)
+		}); err != nil {
+			return err
+		}
+		if err := recordRolesChangedFor(tx, previous); err != nil {
+			return err
+		}
+		group.Roles = roles
+		dto, err = toGroupDTO(tx, group)
+		return err
+	})
+	if err != nil {
+		var appErr *errorx.AppError
+		if errors.As(err, &appErr) {
+			return nil, appErr
+		}
+		l.Errorf("set group roles failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+	return &dto, nil
+}
@@ -2,13 +2,17 @@ package admin
 
 import (
 	"context"
+	"errors"
 
 	"github.com/zeromicro/go-zero/core/logx"
 
 	"usermgmt/internal/errorx"
 	"usermgmt/internal/logic/common"
 	"usermgmt/internal/model"
+	"usermgmt/internal/policy"
+	"usermgmt/internal/repository"
 	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
 	"usermgmt/internal/types"
 )
 
@@ -29,25 +33,71 @@ func NewUpdateUserStatusLogic(ctx context.Context, svcCtx *svc.ServiceContext) *
 }
 
 func (l *UpdateUserStatusLogic) Update(userID uint, req *types.UpdateUserStatusRequest) (*types.ProfileResponse, error) {
-	db := l.svcCtx.DB.WithContext(l.ctx)
+	defer tracing.Logic(&l.ctx, "UpdateUserStatusLogic.Update")()
 
-	result := db.Model(&model.User{}).
-		Where("id = ?", userID).
-		Update("status", req.Status)
-	if result.Error != nil {
-		l.Errorf("update status failed: %v", result.Error)
-		return nil, errorx.ErrInternal
+	store := l.svcCtx.Store
+
+	user, err := loadScopedUser(l.ctx, store.Users(), userID)
+	if err != nil {
+		if !errors.As(err, new(*errorx.AppError)) {
+			l.Errorf("load user for status update failed: %v", err)
+			return nil, errorx.ErrInternal
+		}
+		return nil, err
 	}
-	if result.RowsAffected == 0 {
-		return nil, errorx.ErrUserNotFound
+
+	guard := newSafeguard(l.ctx, l.svcCtx)
+	if err := checkDelegated(l.ctx, l.svcCtx, guard, user); err != nil {
+		return nil, err
+	}
+	if err := guard.checkStatus(user, req.Status); err != nil {
+		return nil, err
+	}
+
+	if err := authorize(l.ctx, l.svcCtx, policy.ActionUserStatusUpdate, user, policy.Attributes{"status": req.Status}); err != nil {
+		return nil, err
 	}
 
-	var user model.User
-	if err := db.Preload("Roles").First(&user, userID).Error; err != nil {
-		l.Errorf("load user after status update failed: %v", err)
+	if needsStatusApproval(l.svcCtx, user, req.Status) {
+		return nil, submitChangeRequest(l.ctx, l.svcCtx, store.ChangeRequests(), policy.ActionUserStatusUpdate, user, statusChange{Status: req.Status})
+	}
+
+	previousStatus := user.Status
+	if err := store.Transaction(l.ctx, func(tx repository.Store) error {
+		if err := guard.preserveAdminsIn(l.ctx, tx, func() error {
+			return tx.Users().UpdateStatus(l.ctx, userID, req.Status)
+		}); err != nil {
+			return err
+		}
+		return recordStatusChangedIn(l.ctx, tx, user, previousStatus)
+	}); err != nil {
+		var appErr *errorx.AppError
+		if errors.As(err, &appErr) {
+			return nil, appErr
+		}
+		l.Errorf("update status failed: %v", err)
 		return nil, errorx.ErrInternal
 	}
 
-	dto := common.ToUserDTO(&user)
-	return &types.ProfileResponse{User: dto}, nil
+	return &types.ProfileResponse{User: common.ToUserDTO(user)}, nil
+}
+
+// loadScopedUser loads a user of the request's organisation, mapping a missing or foreign user
+// to ErrUserNotFound.
+func loadScopedUser(ctx context.Context, users repository.UserRepository, id uint) (*model.User, error) {
+	user, err := users.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errorx.ErrUserNotFound
+		}
+		return nil, err
+	}
+	ok, err := common.InTenant(ctx, users, id)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errorx.ErrUserNotFound
+	}
+	return user, nil
 }
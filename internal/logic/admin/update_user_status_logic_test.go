@@ -0,0 +1,91 @@
+package admin
+
+import (
+	"strings"
+	"testing"
+
+	"usermgmt/internal/config"
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/logic/logictest"
+	"usermgmt/internal/model"
+	"usermgmt/internal/types"
+	"usermgmt/pkg/contextx"
+)
+
+func TestUpdateUserStatus(t *testing.T) {
+	minTwoAdmins := func(c *config.Config) { c.Safeguards.MinActiveAdmins = 2 }
+
+	tests := []struct {
+		name      string
+		configure func(*config.Config)
+		caller    string
+		roles     []string
+		tenant    *types.Tenant
+		target    string
+		status    string
+		wantErr   *errorx.AppError
+		wantEvent string
+	}{
+		{name: "disable user", caller: "root", roles: []string{"admin"}, target: "alice", status: model.UserStatusDisabled, wantEvent: "user.disabled"},
+		{name: "enable enabled user", caller: "root", roles: []string{"admin"}, target: "alice", status: model.UserStatusEnabled},
+		{name: "disable self", caller: "root", roles: []string{"admin"}, target: "root", status: model.UserStatusDisabled, wantErr: errorx.ErrSelfDisable},
+		{name: "disable protected", caller: "root", roles: []string{"admin"}, target: "breakglass", status: model.UserStatusDisabled, wantErr: errorx.ErrProtectedAccount},
+		{name: "disable below admin floor", configure: minTwoAdmins, caller: "root", roles: []string{"admin"}, target: "second", status: model.UserStatusDisabled, wantErr: errorx.ErrMinAdmins},
+		{name: "support disables user", caller: "helper", roles: []string{"support"}, target: "alice", status: model.UserStatusDisabled, wantEvent: "user.disabled"},
+		{name: "support disables admin", caller: "helper", roles: []string{"support"}, target: "second", status: model.UserStatusDisabled, wantErr: errorx.ErrForbidden},
+		{name: "support disables protected", caller: "helper", roles: []string{"support"}, target: "breakglass", status: model.UserStatusDisabled, wantErr: errorx.ErrProtectedAccount},
+		{name: "user of another org", caller: "root", roles: []string{"admin"}, tenant: &types.Tenant{OrgID: 7, SuperAdmin: true}, target: "alice", status: model.UserStatusDisabled, wantErr: errorx.ErrUserNotFound},
+		{name: "unknown user", caller: "root", roles: []string{"admin"}, status: model.UserStatusDisabled, wantErr: errorx.ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var configure []func(*config.Config)
+			if tt.configure != nil {
+				configure = append(configure, tt.configure)
+			}
+			f := newFixture(t, configure...)
+			ctx := f.As(tt.caller, tt.roles...)
+			if tt.tenant != nil {
+				ctx = contextx.WithTenant(ctx, tt.tenant)
+			}
+			targetID := f.Users[tt.target].ID
+			if tt.target == "" {
+				targetID = 999
+			}
+
+			resp, err := NewUpdateUserStatusLogic(ctx, f.Svc).Update(targetID, &types.UpdateUserStatusRequest{Status: tt.status})
+			if tt.wantErr != nil {
+				logictest.ExpectCode(t, err, tt.wantErr)
+				if tt.target != "" && f.User(t, tt.target).Status != model.UserStatusEnabled {
+					t.Fatalf("%s changed despite %s", tt.target, tt.wantErr.Code)
+				}
+				if events := f.Store.RecordedEvents(); len(events) != 0 {
+					t.Fatalf("events = %v, want none", logictest.EventTypes(events))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if resp.User.Status != tt.status || f.User(t, tt.target).Status != tt.status {
+				t.Fatalf("status = %s, stored %s, want %s", resp.User.Status, f.User(t, tt.target).Status, tt.status)
+			}
+			if got := strings.Join(logictest.EventTypes(f.Store.RecordedEvents()), ","); got != tt.wantEvent {
+				t.Fatalf("events = %q, want %q", got, tt.wantEvent)
+			}
+		})
+	}
+}
+
+func TestUpdateUserStatusInOrg(t *testing.T) {
+	f := newFixture(t)
+	f.Store.AddOrgMember(7, f.Users["alice"].ID)
+	ctx := contextx.WithTenant(f.As("root", "admin"), &types.Tenant{OrgID: 7, SuperAdmin: true})
+
+	if _, err := NewUpdateUserStatusLogic(ctx, f.Svc).Update(f.Users["alice"].ID, &types.UpdateUserStatusRequest{Status: model.UserStatusDisabled}); err != nil {
+		t.Fatalf("disable org member: %v", err)
+	}
+	if f.User(t, "alice").Status != model.UserStatusDisabled {
+		t.Fatalf("org member was not disabled")
+	}
+}
@@ -0,0 +1,70 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+	"usermgmt/internal/types"
+	"usermgmt/internal/webhook"
+)
+
+// UpdateWebhookLogic replaces a subscription's target, events and state, optionally rotating its secret.
+type UpdateWebhookLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewUpdateWebhookLogic constructor.
+func NewUpdateWebhookLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateWebhookLogic {
+	return &UpdateWebhookLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Update keeps the current active flag when req.Active is omitted. Pending deliveries are signed
+// with the secret current at send time.
+func (l *UpdateWebhookLogic) Update(id uint, req *types.UpdateWebhookRequest) (*types.WebhookDTO, error) {
+	defer tracing.Logic(&l.ctx, "UpdateWebhookLogic.Update")()
+
+	db := l.svcCtx.DB.WithContext(l.ctx)
+
+	subscription, err := loadWebhook(db, id)
+	if err != nil {
+		if errors.As(err, new(*errorx.AppError)) {
+			return nil, err
+		}
+		l.Errorf("load webhook failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	events, err := normalizeWebhookTarget(req.URL, req.Events)
+	if err != nil {
+		return nil, err
+	}
+	subscription.Name = strings.TrimSpace(req.Name)
+	subscription.URL = strings.TrimSpace(req.URL)
+	subscription.Events = events
+	if req.Active != nil {
+		subscription.Active = *req.Active
+	}
+	if req.RotateSecret {
+		subscription.Secret = webhook.NewSecret()
+	}
+
+	if err := db.Save(subscription).Error; err != nil {
+		l.Errorf("update webhook failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	dto := toWebhookDTO(subscription, req.RotateSecret)
+	return &dto, nil
+}
@@ -0,0 +1,246 @@
+package admin
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"usermgmt/internal/types"
+)
+
+// Supported import/export formats.
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// ContentType returns the MIME type used when streaming the given format.
+func ContentType(format string) string {
+	if format == FormatNDJSON {
+		return "application/x-ndjson"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// ResolveFormat picks the explicit format, falling back to a content-type or file-name hint and then CSV.
+func ResolveFormat(explicit, hint string) string {
+	if explicit = strings.ToLower(strings.TrimSpace(explicit)); explicit != "" {
+		return explicit
+	}
+	if strings.Contains(strings.ToLower(hint), "json") {
+		return FormatNDJSON
+	}
+	return FormatCSV
+}
+
+// rowSyntaxError marks a single malformed input row; the reader can continue past it.
+type rowSyntaxError struct {
+	err error
+}
+
+func (e *rowSyntaxError) Error() string {
+	return e.err.Error()
+}
+
+// userRowReader yields import rows one at a time together with their source line.
+type userRowReader interface {
+	Next() (types.ImportUserRow, int, error)
+}
+
+func newUserRowReader(format string, r io.Reader) (userRowReader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVRowReader(r)
+	case FormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		return &ndjsonRowReader{scanner: scanner}, nil
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+// csvColumns maps accepted header spellings to ImportUserRow fields.
+var csvColumns = map[string]string{
+	"username":      "username",
+	"email":         "email",
+	"fullname":      "fullName",
+	"full_name":     "fullName",
+	"department":    "department",
+	"roles":         "roles",
+	"status":        "status",
+	"passwordhash":  "passwordHash",
+	"password_hash": "passwordHash",
+}
+
+type csvRowReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVRowReader(r io.Reader) (*csvRowReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("csv header missing")
+		}
+		return nil, err
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if field, ok := csvColumns[key]; ok {
+			columns[field] = i
+		}
+	}
+	for _, required := range []string{"username", "email", "fullName"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("csv header missing column %q", required)
+		}
+	}
+	return &csvRowReader{reader: reader, columns: columns}, nil
+}
+
+func (c *csvRowReader) Next() (types.ImportUserRow, int, error) {
+	record, err := c.reader.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return types.ImportUserRow{}, parseErr.StartLine, &rowSyntaxError{err: err}
+		}
+		return types.ImportUserRow{}, 0, err
+	}
+	line, _ := c.reader.FieldPos(0)
+
+	value := func(field string) string {
+		idx, ok := c.columns[field]
+		if !ok || idx >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[idx])
+	}
+
+	return types.ImportUserRow{
+		Username:     value("username"),
+		Email:        value("email"),
+		FullName:     value("fullName"),
+		Department:   value("department"),
+		Roles:        splitRoles(value("roles")),
+		Status:       value("status"),
+		PasswordHash: value("passwordHash"),
+	}, line, nil
+}
+
+type ndjsonRowReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (n *ndjsonRowReader) Next() (types.ImportUserRow, int, error) {
+	for n.scanner.Scan() {
+		n.line++
+		text := strings.TrimSpace(n.scanner.Text())
+		if text == "" {
+			continue
+		}
+		var row types.ImportUserRow
+		if err := json.Unmarshal([]byte(text), &row); err != nil {
+			return types.ImportUserRow{}, n.line, &rowSyntaxError{err: err}
+		}
+		return row, n.line, nil
+	}
+	if err := n.scanner.Err(); err != nil {
+		return types.ImportUserRow{}, n.line, err
+	}
+	return types.ImportUserRow{}, n.line, io.EOF
+}
+
+func splitRoles(value string) []string {
+	if value == "" {
+		return nil
+	}
+	parts := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ';' || r == '|'
+	})
+	roles := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			roles = append(roles, part)
+		}
+	}
+	return roles
+}
+
+// userRowWriter serialises exported users one row at a time.
+type userRowWriter interface {
+	Write(user *types.UserDTO) error
+	Flush() error
+}
+
+func newUserRowWriter(format string, w io.Writer) (userRowWriter, error) {
+	switch format {
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write([]string{"id", "username", "email", "fullName", "department", "roles", "status", "createdAt", "updatedAt"}); err != nil {
+			return nil, err
+		}
+		return &csvRowWriter{writer: writer}, nil
+	case FormatNDJSON:
+		return &ndjsonRowWriter{encoder: json.NewEncoder(w)}, nil
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+type csvRowWriter struct {
+	writer *csv.Writer
+}
+
+func (c *csvRowWriter) Write(user *types.UserDTO) error {
+	return c.writer.Write([]string{
+		strconv.FormatUint(uint64(user.ID), 10),
+		user.Username,
+		user.Email,
+		user.FullName,
+		user.Department,
+		strings.Join(directRoles(user), ";"),
+		user.Status,
+		user.CreatedAt.Format(time.RFC3339),
+		user.UpdatedAt.Format(time.RFC3339),
+	})
+}
+
+// directRoles lists the user's own grants; group-derived roles are left out so an export re-imports cleanly.
+func directRoles(user *types.UserDTO) []string {
+	roles := make([]string, 0, len(user.RoleGrants))
+	for _, grant := range user.RoleGrants {
+		roles = append(roles, grant.Role)
+	}
+	return roles
+}
+
+func (c *csvRowWriter) Flush() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+type ndjsonRowWriter struct {
+	encoder *json.Encoder
+}
+
+func (n *ndjsonRowWriter) Write(user *types.UserDTO) error {
+	return n.encoder.Encode(user)
+}
+
+func (n *ndjsonRowWriter) Flush() error {
+	return nil
+}
@@ -0,0 +1,63 @@
+package admin
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/logic/common"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+	"usermgmt/internal/types"
+)
+
+// UserPermissionsLogic resolves a user's effective permissions through the role hierarchy.
+type UserPermissionsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewUserPermissionsLogic constructor.
+func NewUserPermissionsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserPermissionsLogic {
+	return &UserPermissionsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UserPermissionsLogic) Permissions(userID uint) (*types.UserPermissionsResponse, error) {
+	defer tracing.Logic(&l.ctx, "UserPermissionsLogic.Permissions")()
+
+	db := l.svcCtx.DB.WithContext(l.ctx)
+
+	roleIDs, found, err := loadActiveRoleIDs(db.Scopes(common.TenantScope(l.ctx)), userID)
+	if err != nil {
+		l.Errorf("load user roles failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+	if !found {
+		return nil, errorx.ErrUserNotFound
+	}
+
+	hierarchy, err := loadHierarchy(db)
+	if err != nil {
+		l.Errorf("load role hierarchy failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	roles := make([]string, 0, len(roleIDs))
+	for _, id := range roleIDs {
+		if role, ok := hierarchy.RoleByID(id); ok {
+			roles = append(roles, role.Name)
+		}
+	}
+
+	return &types.UserPermissionsResponse{
+		UserID:      userID,
+		Roles:       roles,
+		Permissions: hierarchy.EffectivePermissions(roleIDs),
+	}, nil
+}
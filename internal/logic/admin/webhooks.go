@@ -0,0 +1,93 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/event"
+	"usermgmt/internal/model"
+	"usermgmt/internal/types"
+	"usermgmt/internal/webhook"
+)
+
+// normalizeWebhookTarget validates the endpoint URL and event names of a subscription and
+// returns the events joined for storage.
+func normalizeWebhookTarget(rawURL string, events []string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", errorx.ErrValidation.WithDetails(errorx.Detail{Key: "webhookURLInvalid"})
+	}
+
+	names := make([]string, 0, len(events))
+	unknown := make([]string, 0)
+	seen := make(map[string]struct{}, len(events))
+	for _, name := range events {
+		name = strings.TrimSpace(name)
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		if !webhook.IsKnownEvent(name) {
+			unknown = append(unknown, name)
+			continue
+		}
+		names = append(names, name)
+	}
+	if len(unknown) > 0 {
+		return "", errorx.ErrValidation.WithDetails(map[string]interface{}{
+			"unknownEvents": unknown,
+			"knownEvents":   event.Types,
+		})
+	}
+	return strings.Join(names, ","), nil
+}
+
+func loadWebhook(db *gorm.DB, id uint) (*model.WebhookSubscription, error) {
+	var subscription model.WebhookSubscription
+	if err := db.First(&subscription, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorx.ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// toWebhookDTO maps a subscription; the secret is only included when withSecret is set.
+func toWebhookDTO(subscription *model.WebhookSubscription, withSecret bool) types.WebhookDTO {
+	dto := types.WebhookDTO{
+		ID:        subscription.ID,
+		Name:      subscription.Name,
+		URL:       subscription.URL,
+		Events:    strings.Split(subscription.Events, ","),
+		Active:    subscription.Active,
+		CreatedAt: subscription.CreatedAt,
+		UpdatedAt: subscription.UpdatedAt,
+	}
+	if withSecret {
+		dto.Secret = subscription.Secret
+	}
+	return dto
+}
+
+func toWebhookDeliveryDTO(delivery *model.WebhookDelivery) types.WebhookDeliveryDTO {
+	return types.WebhookDeliveryDTO{
+		ID:             delivery.ID,
+		SubscriptionID: delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		Event:          delivery.Event,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+		Payload:        json.RawMessage(delivery.Payload),
+	}
+}
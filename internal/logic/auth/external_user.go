@@ -0,0 +1,274 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"usermgmt/internal/authn"
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/event"
+	"usermgmt/internal/logic/common"
+	"usermgmt/internal/model"
+	"usermgmt/internal/repository"
+	"usermgmt/internal/tracing"
+)
+
+// syncExternalUser provisions the account of a directory identity on its first login and
+// refreshes the profile and the provider-managed roles on every login, in one transaction. An
+// existing account owned by another provider is never taken over.
+func (l *LoginLogic) syncExternalUser(identity *authn.Identity) (*model.User, error) {
+	db := l.svcCtx.DB.WithContext(l.ctx)
+
+	var user model.User
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("username = ?", identity.Username).First(&user).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if err := l.provisionUser(tx, identity, &user); err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		case user.AuthSource != identity.Provider:
+			l.Infof("refused %s login of %q: account belongs to %s", identity.Provider, identity.Username, user.AuthSource)
+			return errorx.ErrInvalidCredentials
+		default:
+			if err := l.refreshProfile(tx, identity, &user); err != nil {
+				return err
+			}
+		}
+
+		if identity.ManagedRoles != nil {
+			if err := l.syncManagedRoles(tx, identity, &user); err != nil {
+				return err
+			}
+		}
+		return tx.Scopes(common.PreloadActiveRoles).First(&user, user.ID).Error
+	}); err != nil {
+		var appErr *errorx.AppError
+		if errors.As(err, &appErr) {
+			return nil, appErr
+		}
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, errorx.ErrUserExists
+		}
+		l.Errorf("sync %s user %q failed: %v", identity.Provider, identity.Username, err)
+		return nil, errorx.ErrInternal
+	}
+	return &user, nil
+}
+
+// provisionUser creates the account with an unusable random password: it only ever logs in
+// through its provider.
+func (l *LoginLogic) provisionUser(tx *gorm.DB, identity *authn.Identity, user *model.User) error {
+	if identity.Email == "" {
+		return errorx.ErrValidation.WithDetails(errorx.Detail{Key: "directoryEmailMissing"})
+	}
+	if err := checkEmailFree(tx, identity.Email, 0); err != nil {
+		return err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+	hash, err := tracing.HashPassword(l.ctx, hex.EncodeToString(secret), l.svcCtx.Config.Password.BcryptCost)
+	if err != nil {
+		return err
+	}
+
+	*user = model.User{
+		Username:     identity.Username,
+		Email:        identity.Email,
+		PasswordHash: hash,
+		FullName:     identity.FullName,
+		AuthSource:   identity.Provider,
+		Status:       model.UserStatusEnabled,
+	}
+	if err := tx.Create(user).Error; err != nil {
+		return err
+	}
+	l.Infof("provisioned %s user %d (%s)", identity.Provider, user.ID, user.Username)
+	return common.RecordUserEvent(tx, event.UserRegistered, event.UserEventData{User: common.ToUserDTO(user)})
+}
+
+// refreshProfile copies the directory email and name onto the account when they changed.
+func (l *LoginLogic) refreshProfile(tx *gorm.DB, identity *authn.Identity, user *model.User) error {
+	updates := map[string]interface{}{}
+	if identity.Email != "" && identity.Email != user.Email {
+		if err := checkEmailFree(tx, identity.Email, user.ID); err != nil {
+			return err
+		}
+		updates["email"] = identity.Email
+	}
+	if identity.FullName != "" && identity.FullName != user.FullName {
+		updates["full_name"] = identity.FullName
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	previousEmail := user.Email
+	if err := tx.Model(user).Updates(updates).Error; err != nil {
+		return err
+	}
+	if _, ok := updates["email"]; !ok {
+		return nil
+	}
+	if err := tx.Scopes(common.PreloadActiveRoles).First(user, user.ID).Error; err != nil {
+		return err
+	}
+	return common.RecordUserEvent(tx, event.UserEmailChanged, event.UserEventData{
+		User:          common.ToUserDTO(user),
+		PreviousEmail: previousEmail,
+	})
+}
+
+// syncManagedRoles makes the user's direct grants of the provider-managed roles match
+// identity.Roles. Grants of other roles are left alone; managed roles missing from the roles
+// table are skipped. Revokes go through the safeguards, see revokeManagedRoles.
+func (l *LoginLogic) syncManagedRoles(tx *gorm.DB, identity *authn.Identity, user *model.User) error {
+	if len(identity.ManagedRoles) == 0 {
+		return nil
+	}
+	var roles []model.Role
+	if err := tx.Where("name IN ?", identity.ManagedRoles).Find(&roles).Error; err != nil {
+		return err
+	}
+	if len(roles) < len(identity.ManagedRoles) {
+		l.Infof("some mapped roles of %s do not exist: %v", identity.Provider, identity.ManagedRoles)
+	}
+	wanted := make(map[string]bool, len(identity.Roles))
+	for _, name := range identity.Roles {
+		wanted[name] = true
+	}
+	roleIDs := make([]uint, 0, len(roles))
+	for _, role := range roles {
+		roleIDs = append(roleIDs, role.ID)
+	}
+	if len(roleIDs) == 0 {
+		return nil
+	}
+
+	var grants []model.UserRole
+	if err := tx.Where("user_id = ? AND role_id IN ?", user.ID, roleIDs).Find(&grants).Error; err != nil {
+		return err
+	}
+	held := make(map[uint]model.UserRole, len(grants))
+	for _, grant := range grants {
+		held[grant.RoleID] = grant
+	}
+
+	var grant, revoke []uint
+	for _, role := range roles {
+		current, ok := held[role.ID]
+		switch {
+		case wanted[role.Name] && (!ok || current.ExpiresAt != nil):
+			grant = append(grant, role.ID)
+		case !wanted[role.Name] && ok:
+			revoke = append(revoke, role.ID)
+		}
+	}
+	if len(grant) == 0 && len(revoke) == 0 {
+		return nil
+	}
+
+	if err := tx.Scopes(common.PreloadActiveRoles).First(user, user.ID).Error; err != nil {
+		return err
+	}
+	previous := common.ActiveRoleNames(user)
+	if err := l.revokeManagedRoles(tx, user, roles, revoke); err != nil {
+		return err
+	}
+	for _, roleID := range grant {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "role_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"expires_at": nil}),
+		}).Create(&model.UserRole{UserID: user.ID, RoleID: roleID}).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Model(&model.User{}).Where("id = ?", user.ID).
+		Update("role_version", gorm.Expr("role_version + 1")).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Scopes(common.PreloadActiveRoles).First(user, user.ID).Error; err != nil {
+		return err
+	}
+	dto := common.ToUserDTO(user)
+	if common.SameRoles(previous, dto.Roles) {
+		return nil
+	}
+	return common.RecordUserEvent(tx, event.UserRolesChanged, event.UserEventData{
+		User:          dto,
+		PreviousRoles: previous,
+	})
+}
+
+// revokeManagedRoles deletes the user's grants of the revoke role IDs within the safeguards: a
+// protected account keeps its roles, and the admin role stays while removing it would leave fewer
+// than Safeguards.MinActiveAdmins active admins. The login goes ahead either way and a kept grant
+// is revoked on a later login once the safeguards allow it.
+func (l *LoginLogic) revokeManagedRoles(tx *gorm.DB, user *model.User, roles []model.Role, revoke []uint) error {
+	if len(revoke) == 0 {
+		return nil
+	}
+	conf := l.svcCtx.Config.Safeguards
+	if common.IsProtectedUser(conf, user.Username) {
+		l.Infof("kept directory-revoked roles %v of protected account %q", revoke, user.Username)
+		return nil
+	}
+
+	var adminID uint
+	for _, role := range roles {
+		if strings.EqualFold(role.Name, common.AdminRole(conf)) {
+			adminID = role.ID
+		}
+	}
+	others := make([]uint, 0, len(revoke))
+	revokeAdmin := false
+	for _, roleID := range revoke {
+		if adminID != 0 && roleID == adminID {
+			revokeAdmin = true
+			continue
+		}
+		others = append(others, roleID)
+	}
+	if len(others) > 0 {
+		if err := tx.Where("user_id = ? AND role_id IN ?", user.ID, others).Delete(&model.UserRole{}).Error; err != nil {
+			return err
+		}
+	}
+	if !revokeAdmin {
+		return nil
+	}
+
+	// The savepoint undoes the revoke alone when it would break the admin floor.
+	err := tx.Transaction(func(sp *gorm.DB) error {
+		return common.PreserveAdmins(l.ctx, conf, repository.NewGormStore(sp), func() error {
+			return sp.Where("user_id = ? AND role_id = ?", user.ID, adminID).Delete(&model.UserRole{}).Error
+		})
+	})
+	if errorx.Is(err, errorx.ErrMinAdmins) {
+		l.Infof("kept directory-revoked role %s of %q: too few active admins would remain", common.AdminRole(conf), user.Username)
+		return nil
+	}
+	return err
+}
+
+func checkEmailFree(tx *gorm.DB, email string, selfID uint) error {
+	var count int64
+	if err := tx.Model(&model.User{}).Where("email = ? AND id <> ?", email, selfID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errorx.ErrUserExists
+	}
+	return nil
+}
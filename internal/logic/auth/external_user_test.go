@@ -0,0 +1,209 @@
+package auth_test
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"usermgmt/internal/apitest"
+	"usermgmt/internal/authn"
+	"usermgmt/internal/config"
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/logic/auth"
+	"usermgmt/internal/logic/common"
+	"usermgmt/internal/model"
+	"usermgmt/internal/types"
+)
+
+// directory stands in for the LDAP provider: every login is accepted as identity.
+type directory struct {
+	identity authn.Identity
+}
+
+func (d directory) Name() string {
+	return model.AuthSourceLDAP
+}
+
+func (d directory) Authenticate(ctx context.Context, username, password string) (*authn.Identity, error) {
+	identity := d.identity
+	return &identity, nil
+}
+
+// account is a user seeded before the login.
+type account struct {
+	username string
+	email    string
+	source   string
+	roles    []string
+}
+
+// TestExternalLogin covers how a directory login provisions and updates the account. Accounts are
+// synchronised with SQL inside one transaction, so it runs against the SQLite database of an API
+// harness rather than the in-memory store.
+func TestExternalLogin(t *testing.T) {
+	managed := []string{"admin", "support"}
+	protectCarol := func(c *config.Config) { c.Safeguards.ProtectedUsers = []string{"carol"} }
+
+	tests := []struct {
+		name       string
+		configure  func(*config.Config)
+		accounts   []account
+		identity   authn.Identity
+		wantErr    *errorx.AppError
+		wantEmail  string
+		wantName   string
+		wantRoles  string
+		wantEvents string
+	}{
+		{
+			name:       "first login provisions",
+			identity:   authn.Identity{Username: "jdoe", Email: "jdoe@example.com", FullName: "Jane Doe", Roles: []string{"support"}, ManagedRoles: managed},
+			wantEmail:  "jdoe@example.com",
+			wantName:   "Jane Doe",
+			wantRoles:  "support",
+			wantEvents: "user.registered,user.roles_changed",
+		},
+		{
+			name:     "first login without email",
+			identity: authn.Identity{Username: "jdoe"},
+			wantErr:  errorx.ErrValidation,
+		},
+		{
+			name:       "profile refresh",
+			accounts:   []account{{username: "carol", email: "old@example.com", source: model.AuthSourceLDAP, roles: []string{"viewer"}}},
+			identity:   authn.Identity{Username: "carol", Email: "carol@example.com", FullName: "Carol New"},
+			wantEmail:  "carol@example.com",
+			wantName:   "Carol New",
+			wantRoles:  "viewer",
+			wantEvents: "user.email_changed",
+		},
+		{
+			name:      "unchanged profile",
+			accounts:  []account{{username: "carol", email: "carol@example.com", source: model.AuthSourceLDAP}},
+			identity:  authn.Identity{Username: "carol", Email: "carol@example.com"},
+			wantEmail: "carol@example.com",
+			wantName:  "User carol",
+		},
+		{
+			name: "email owned by another account",
+			accounts: []account{
+				{username: "carol", email: "carol@example.com", source: model.AuthSourceLDAP},
+				{username: "dave", email: "dave@example.com", source: model.AuthSourceLocal},
+			},
+			identity: authn.Identity{Username: "carol", Email: "dave@example.com"},
+			wantErr:  errorx.ErrUserExists,
+		},
+		{
+			name:     "local account is not taken over",
+			accounts: []account{{username: "carol", email: "carol@example.com", source: model.AuthSourceLocal}},
+			identity: authn.Identity{Username: "carol", Email: "carol@example.com"},
+			wantErr:  errorx.ErrInvalidCredentials,
+		},
+		{
+			name: "managed roles follow the directory",
+			accounts: []account{
+				{username: "root", email: "root@example.com", source: model.AuthSourceLocal, roles: []string{"admin"}},
+				{username: "carol", email: "carol@example.com", source: model.AuthSourceLDAP, roles: []string{"admin", "viewer"}},
+			},
+			identity:   authn.Identity{Username: "carol", Email: "carol@example.com", Roles: []string{"support"}, ManagedRoles: managed},
+			wantEmail:  "carol@example.com",
+			wantName:   "User carol",
+			wantRoles:  "support,viewer",
+			wantEvents: "user.roles_changed",
+		},
+		{
+			name:      "protected account keeps revoked roles",
+			configure: protectCarol,
+			accounts: []account{
+				{username: "root", email: "root@example.com", source: model.AuthSourceLocal, roles: []string{"admin"}},
+				{username: "carol", email: "carol@example.com", source: model.AuthSourceLDAP, roles: []string{"admin"}},
+			},
+			identity:   authn.Identity{Username: "carol", Email: "carol@example.com", Roles: []string{"support"}, ManagedRoles: managed},
+			wantEmail:  "carol@example.com",
+			wantName:   "User carol",
+			wantRoles:  "admin,support",
+			wantEvents: "user.roles_changed",
+		},
+		{
+			name:       "last admin keeps the admin role",
+			accounts:   []account{{username: "carol", email: "carol@example.com", source: model.AuthSourceLDAP, roles: []string{"admin", "support"}}},
+			identity:   authn.Identity{Username: "carol", Email: "carol@example.com", Roles: []string{}, ManagedRoles: managed},
+			wantEmail:  "carol@example.com",
+			wantName:   "User carol",
+			wantRoles:  "admin",
+			wantEvents: "user.roles_changed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var options []apitest.Option
+			if tt.configure != nil {
+				options = append(options, apitest.WithConfig(tt.configure))
+			}
+			h := apitest.MustNew(t, options...)
+			defer h.Close()
+			for _, a := range tt.accounts {
+				seedAccount(t, h, a)
+			}
+			tt.identity.Provider = model.AuthSourceLDAP
+			h.Svc.Authenticator = authn.NewChain(directory{identity: tt.identity})
+
+			_, err := auth.NewLoginLogic(context.Background(), h.Svc).Login(&types.LoginRequest{Username: tt.identity.Username, Password: "directory-pass"})
+			if tt.wantErr != nil {
+				if got := errorx.From(context.Background(), err); got == nil || got.Code != tt.wantErr.Code {
+					t.Fatalf("err = %v, want %s", err, tt.wantErr.Code)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+
+			var user model.User
+			if err := h.Svc.DB.Scopes(common.PreloadActiveRoles).Where("username = ?", tt.identity.Username).First(&user).Error; err != nil {
+				t.Fatal(err)
+			}
+			if user.AuthSource != model.AuthSourceLDAP || user.Email != tt.wantEmail || user.FullName != tt.wantName {
+				t.Fatalf("user = {%s %s %q}, want {ldap %s %q}", user.AuthSource, user.Email, user.FullName, tt.wantEmail, tt.wantName)
+			}
+			roles := common.ActiveRoleNames(&user)
+			sort.Strings(roles)
+			if got := strings.Join(roles, ","); got != tt.wantRoles {
+				t.Fatalf("roles = %q, want %q", got, tt.wantRoles)
+			}
+			var events []string
+			if err := h.Svc.DB.Model(&model.OutboxEvent{}).Order("id").Pluck("type", &events).Error; err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(events, ","); got != tt.wantEvents {
+				t.Fatalf("events = %q, want %q", got, tt.wantEvents)
+			}
+		})
+	}
+}
+
+// seedAccount inserts a directly into the database, so that no event is recorded for it.
+func seedAccount(t *testing.T, h *apitest.Harness, a account) {
+	t.Helper()
+	user := model.User{
+		Username:     a.username,
+		Email:        a.email,
+		PasswordHash: "-",
+		FullName:     "User " + a.username,
+		AuthSource:   a.source,
+		Status:       model.UserStatusEnabled,
+	}
+	if err := h.Svc.DB.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range a.roles {
+		var role model.Role
+		if err := h.Svc.DB.Where("name = ?", name).First(&role).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := h.Svc.DB.Create(&model.UserRole{UserID: user.ID, RoleID: role.ID}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
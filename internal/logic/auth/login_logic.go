@@ -7,12 +7,16 @@ import (
 	"time"
 
 	"github.com/zeromicro/go-zero/core/logx"
-	"gorm.io/gorm"
 
+	"usermgmt/internal/authn"
 	"usermgmt/internal/errorx"
 	"usermgmt/internal/logic/common"
+	"usermgmt/internal/metrics"
+	"usermgmt/internal/middleware"
 	"usermgmt/internal/model"
+	"usermgmt/internal/repository"
 	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
 	"usermgmt/internal/types"
 	"usermgmt/pkg/security"
 )
@@ -33,29 +37,64 @@ func NewLoginLogic(ctx context.Context, svcCtx *svc.ServiceContext) *LoginLogic
 	}
 }
 
+// Login authenticates the user and counts the attempt by outcome.
 func (l *LoginLogic) Login(req *types.LoginRequest) (*types.LoginResponse, error) {
-	db := l.svcCtx.DB.WithContext(l.ctx)
+	defer tracing.Logic(&l.ctx, "LoginLogic.Login")()
+
+	resp, err := l.login(req)
+	metrics.ObserveLogin(err)
+	return resp, err
+}
+
+func (l *LoginLogic) login(req *types.LoginRequest) (*types.LoginResponse, error) {
 	username := strings.TrimSpace(req.Username)
 
-	var user model.User
-	if err := db.Preload("Roles").Where("username = ?", username).First(&user).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+	identity, err := l.svcCtx.Authenticator.Authenticate(l.ctx, username, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, authn.ErrInvalidCredentials):
 			return nil, errorx.ErrInvalidCredentials
+		case errors.Is(err, authn.ErrUnavailable):
+			return nil, errorx.ErrAuthUnavailable
 		}
-		l.Errorf("query user failed: %v", err)
+		l.Errorf("authenticate failed: %v", err)
 		return nil, errorx.ErrInternal
 	}
 
+	var user *model.User
+	if identity.Provider == model.AuthSourceLocal {
+		user, err = l.loadLocalUser(identity.UserID)
+	} else {
+		user, err = l.syncExternalUser(identity)
+	}
+	if err != nil {
+		return nil, err
+	}
+
 	if user.Status == model.UserStatusDisabled {
 		return nil, errorx.ErrUserDisabled
 	}
 
-	if err := security.VerifyPassword(user.PasswordHash, req.Password); err != nil {
-		return nil, errorx.ErrInvalidCredentials
+	claims := types.JwtClaims{
+		UserID:        user.ID,
+		Roles:         common.ActiveRoleNames(user),
+		RoleExpiresAt: common.RoleExpiries(user),
+	}
+	if org := strings.TrimSpace(req.Org); org != "" {
+		superAdmin := middleware.HasActiveRole(&claims, l.svcCtx.Config.Tenancy.SuperAdminRole)
+		tenant, err := l.svcCtx.Tenants.Resolve(l.ctx, user.ID, org, superAdmin)
+		if err != nil {
+			var appErr *errorx.AppError
+			if errors.As(err, &appErr) {
+				return nil, appErr
+			}
+			l.Errorf("resolve login org failed: %v", err)
+			return nil, errorx.ErrInternal
+		}
+		claims.OrgID = tenant.OrgID
 	}
 
-	roleNames := common.ExtractRoleNames(user.Roles)
-	accessToken, accessExpire, err := security.GenerateToken(user.ID, roleNames, l.svcCtx.Config.JWT.AccessSecret, l.svcCtx.Config.JWT.AccessExpire)
+	accessToken, accessExpire, err := security.GenerateToken(claims, l.svcCtx.Config.JWT.AccessSecret, l.svcCtx.Config.JWT.AccessExpire)
 	if err != nil {
 		l.Errorf("generate access token failed: %v", err)
 		return nil, errorx.ErrInternal
@@ -63,7 +102,7 @@ func (l *LoginLogic) Login(req *types.LoginRequest) (*types.LoginResponse, error
 
 	refreshToken := ""
 	if l.svcCtx.Config.JWT.RefreshExpire > 0 {
-		if refreshTokenValue, _, err := security.GenerateToken(user.ID, roleNames, l.svcCtx.Config.JWT.AccessSecret, l.svcCtx.Config.JWT.RefreshExpire); err != nil {
+		if refreshTokenValue, _, err := security.GenerateToken(claims, l.svcCtx.Config.JWT.AccessSecret, l.svcCtx.Config.JWT.RefreshExpire); err != nil {
 			l.Errorf("generate refresh token failed: %v", err)
 			return nil, errorx.ErrInternal
 		} else {
@@ -71,13 +110,11 @@ func (l *LoginLogic) Login(req *types.LoginRequest) (*types.LoginResponse, error
 		}
 	}
 
-	if err := db.Model(&model.User{}).
-		Where("id = ?", user.ID).
-		Update("last_login_at", time.Now()).Error; err != nil {
+	if err := l.svcCtx.Store.Users().TouchLogin(l.ctx, user.ID, time.Now()); err != nil {
 		l.Errorf("update last login failed: %v", err)
 	}
 
-	dto := common.ToUserDTO(&user)
+	dto := common.ToUserDTO(user)
 
 	return &types.LoginResponse{
 		AccessToken:  accessToken,
@@ -86,3 +123,15 @@ func (l *LoginLogic) Login(req *types.LoginRequest) (*types.LoginResponse, error
 		User:         dto,
 	}, nil
 }
+
+func (l *LoginLogic) loadLocalUser(id uint) (*model.User, error) {
+	user, err := l.svcCtx.Store.Users().Get(l.ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errorx.ErrInvalidCredentials
+		}
+		l.Errorf("query user failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+	return user, nil
+}
@@ -2,16 +2,20 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"strings"
 
 	"github.com/zeromicro/go-zero/core/logx"
 
 	"usermgmt/internal/errorx"
+	"usermgmt/internal/event"
 	"usermgmt/internal/logic/common"
+	"usermgmt/internal/metrics"
 	"usermgmt/internal/model"
+	"usermgmt/internal/repository"
 	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
 	"usermgmt/internal/types"
-	"usermgmt/pkg/security"
 )
 
 // RegisterLogic handles user sign-up, including validation and hashing.
@@ -30,26 +34,31 @@ func NewRegisterLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Register
 	}
 }
 
+// Register creates a local account and counts the attempt by outcome.
 func (l *RegisterLogic) Register(req *types.RegisterRequest) (*types.UserDTO, error) {
-	db := l.svcCtx.DB.WithContext(l.ctx)
+	defer tracing.Logic(&l.ctx, "RegisterLogic.Register")()
 
+	dto, err := l.register(req)
+	metrics.ObserveRegistration(err)
+	return dto, err
+}
+
+func (l *RegisterLogic) register(req *types.RegisterRequest) (*types.UserDTO, error) {
 	username := strings.TrimSpace(req.Username)
 	email := strings.ToLower(strings.TrimSpace(req.Email))
 	fullName := strings.TrimSpace(req.FullName)
 
-	var count int64
-	if err := db.Model(&model.User{}).
-		Where("username = ? OR email = ?", username, email).
-		Count(&count).Error; err != nil {
+	exists, err := l.svcCtx.Store.Users().Exists(l.ctx, username, email)
+	if err != nil {
 		l.Errorf("check user exists failed: %v", err)
 		return nil, errorx.ErrInternal
 	}
 
-	if count > 0 {
+	if exists {
 		return nil, errorx.ErrUserExists
 	}
 
-	hash, err := security.HashPassword(req.Password, l.svcCtx.Config.Password.BcryptCost)
+	hash, err := tracing.HashPassword(l.ctx, req.Password, l.svcCtx.Config.Password.BcryptCost)
 	if err != nil {
 		l.Errorf("hash password failed: %v", err)
 		return nil, errorx.ErrInternal
@@ -63,11 +72,20 @@ func (l *RegisterLogic) Register(req *types.RegisterRequest) (*types.UserDTO, er
 		Status:       model.UserStatusEnabled,
 	}
 
-	if err := db.Create(&user).Error; err != nil {
+	var dto types.UserDTO
+	if err := l.svcCtx.Store.Transaction(l.ctx, func(store repository.Store) error {
+		if err := store.Users().Create(l.ctx, &user); err != nil {
+			return err
+		}
+		dto = common.ToUserDTO(&user)
+		return store.Events().Record(l.ctx, event.NewUserEvent(event.UserRegistered, event.UserEventData{User: dto}))
+	}); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			// Another registration took the username or email after the Exists check.
+			return nil, errorx.ErrUserExists
+		}
 		l.Errorf("create user failed: %v", err)
 		return nil, errorx.ErrInternal
 	}
-
-	dto := common.ToUserDTO(&user)
 	return &dto, nil
 }
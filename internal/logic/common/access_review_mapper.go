@@ -0,0 +1,25 @@
+package common
+
+import (
+	"usermgmt/internal/model"
+	"usermgmt/internal/types"
+)
+
+// ToAccessReviewItemDTO maps a review item to its API DTO.
+func ToAccessReviewItemDTO(item *model.AccessReviewItem) types.AccessReviewItemDTO {
+	return types.AccessReviewItemDTO{
+		ID:             item.ID,
+		ReviewID:       item.ReviewID,
+		UserID:         item.UserID,
+		Username:       item.Username,
+		Role:           item.RoleName,
+		GrantExpiresAt: item.GrantExpiresAt,
+		ReviewerID:     item.ReviewerID,
+		Decision:       item.Decision,
+		DecidedBy:      item.DecidedBy,
+		DecidedAt:      item.DecidedAt,
+		Comment:        item.Comment,
+		Outcome:        item.Outcome,
+		Error:          item.Error,
+	}
+}
@@ -0,0 +1,31 @@
+package common
+
+import (
+	"gorm.io/gorm"
+
+	"usermgmt/internal/event"
+)
+
+// RecordUserEvent writes a user lifecycle event to the outbox inside tx, so it is published if and
+// only if the change it describes commits.
+func RecordUserEvent(tx *gorm.DB, eventType string, data event.UserEventData) error {
+	return event.Record(tx, event.NewUserEvent(eventType, data))
+}
+
+// SameRoles reports whether two role name lists hold the same names, ignoring order.
+func SameRoles(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[string]int, len(a))
+	for _, name := range a {
+		seen[name]++
+	}
+	for _, name := range b {
+		if seen[name] == 0 {
+			return false
+		}
+		seen[name]--
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package common
+
+import (
+	"context"
+	"strings"
+
+	"usermgmt/internal/config"
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/repository"
+)
+
+// AdminRole returns the role whose active holders Safeguards.MinActiveAdmins counts.
+func AdminRole(conf config.SafeguardConf) string {
+	if conf.AdminRole == "" {
+		return "admin"
+	}
+	return conf.AdminRole
+}
+
+// IsProtectedUser reports whether username is one of Safeguards.ProtectedUsers, whose roles and
+// status no one may take away.
+func IsProtectedUser(conf config.SafeguardConf, username string) bool {
+	for _, protected := range conf.ProtectedUsers {
+		if strings.EqualFold(strings.TrimSpace(protected), username) {
+			return true
+		}
+	}
+	return false
+}
+
+// PreserveAdmins runs fn inside the transaction of store and fails with ErrMinAdmins if it
+// lowered the number of active admins below Safeguards.MinActiveAdmins. Locking the admin role row
+// serialises every such change, so two admins cannot demote each other concurrently. A system
+// already below the threshold is not blocked by changes that do not reduce it further.
+func PreserveAdmins(ctx context.Context, conf config.SafeguardConf, store repository.Store, fn func() error) error {
+	minAdmins := int64(conf.MinActiveAdmins)
+	if minAdmins <= 0 {
+		return fn()
+	}
+	adminRole := AdminRole(conf)
+	if err := store.Roles().Lock(ctx, adminRole); err != nil {
+		return err
+	}
+
+	users := store.Users()
+	before, err := users.CountActiveHolders(ctx, adminRole)
+	if err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	after, err := users.CountActiveHolders(ctx, adminRole)
+	if err != nil {
+		return err
+	}
+	if after < before && after < minAdmins {
+		return errorx.ErrMinAdmins.WithDetails(map[string]int64{"minActiveAdmins": minAdmins})
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package common
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"usermgmt/internal/repository"
+	"usermgmt/pkg/contextx"
+)
+
+// TenantScope limits a users query to members of the request's organisation.
+// Requests without a tenant (super admins in global scope) are left unfiltered.
+func TenantScope(ctx context.Context) func(*gorm.DB) *gorm.DB {
+	tenant := contextx.TenantFromContext(ctx)
+	return func(db *gorm.DB) *gorm.DB {
+		if tenant == nil {
+			return db
+		}
+		return db.Where("users.id IN (SELECT user_id FROM org_members WHERE org_id = ?)", tenant.OrgID)
+	}
+}
+
+// IsOrgScoped reports whether the caller acts as an org admin rather than a super admin.
+func IsOrgScoped(ctx context.Context) bool {
+	tenant := contextx.TenantFromContext(ctx)
+	return tenant != nil && !tenant.SuperAdmin
+}
+
+// InTenant reports whether user id is a member of the request's organisation, the repository
+// counterpart of TenantScope. Requests without a tenant see every user.
+func InTenant(ctx context.Context, users repository.UserRepository, id uint) (bool, error) {
+	tenant := contextx.TenantFromContext(ctx)
+	if tenant == nil {
+		return true, nil
+	}
+	return users.InOrg(ctx, id, tenant.OrgID)
+}
@@ -1,24 +1,36 @@
 package common
 
 import (
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
 	"usermgmt/internal/model"
+	"usermgmt/internal/repository"
 	"usermgmt/internal/types"
 )
 
 // ToUserDTO maps model.User to API DTO.
+// Roles are the effective roles: preloaded direct grants (see PreloadActiveRoles), else Roles, plus group roles.
 func ToUserDTO(user *model.User) types.UserDTO {
 	if user == nil {
 		return types.UserDTO{}
 	}
 	return types.UserDTO{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		FullName:  user.FullName,
-		Status:    user.Status,
-		Roles:     ExtractRoleNames(user.Roles),
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		ID:          user.ID,
+		Username:    user.Username,
+		Email:       user.Email,
+		FullName:    user.FullName,
+		Department:  user.Department,
+		Status:      user.Status,
+		Roles:       ActiveRoleNames(user),
+		RoleGrants:  toRoleGrantDTOs(user.RoleGrants),
+		Groups:      GroupNames(user),
+		RoleVersion: user.RoleVersion,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
 	}
 }
 
@@ -33,3 +45,109 @@ func ExtractRoleNames(roles []model.Role) []string {
 	}
 	return result
 }
+
+// PreloadActiveRoles is repository.PreloadActiveRoles, for logic that still queries GORM directly.
+func PreloadActiveRoles(db *gorm.DB) *gorm.DB {
+	return repository.PreloadActiveRoles(db)
+}
+
+// ActiveRoleNames lists the roles a user currently holds, directly or through groups,
+// skipping direct grants that expired after loading.
+func ActiveRoleNames(user *model.User) []string {
+	var direct []string
+	if user.RoleGrants == nil {
+		direct = ExtractRoleNames(user.Roles)
+	} else {
+		now := time.Now()
+		direct = make([]string, 0, len(user.RoleGrants))
+		for _, grant := range user.RoleGrants {
+			if grant.ExpiresAt != nil && !grant.ExpiresAt.After(now) {
+				continue
+			}
+			direct = append(direct, grant.Role.Name)
+		}
+	}
+
+	seen := make(map[string]struct{}, len(direct))
+	result := make([]string, 0, len(direct))
+	for _, name := range append(direct, groupRoleNames(user)...) {
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		result = append(result, name)
+	}
+	return result
+}
+
+// GroupNames lists the groups a user belongs to.
+func GroupNames(user *model.User) []string {
+	result := make([]string, 0, len(user.Groups))
+	for _, member := range user.Groups {
+		result = append(result, member.Group.Name)
+	}
+	return result
+}
+
+func groupRoleNames(user *model.User) []string {
+	result := make([]string, 0)
+	for _, member := range user.Groups {
+		result = append(result, ExtractRoleNames(member.Group.Roles)...)
+	}
+	return result
+}
+
+// RoleExpiries maps role name to unix expiry for time-bound grants, for embedding in tokens.
+// A role also inherited from a group never expires, so it is left out.
+func RoleExpiries(user *model.User) map[string]int64 {
+	permanent := make(map[string]struct{})
+	for _, name := range groupRoleNames(user) {
+		permanent[name] = struct{}{}
+	}
+	result := make(map[string]int64)
+	for _, grant := range user.RoleGrants {
+		if _, ok := permanent[grant.Role.Name]; ok {
+			continue
+		}
+		if grant.ExpiresAt != nil {
+			result[grant.Role.Name] = grant.ExpiresAt.Unix()
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+func toRoleGrantDTOs(grants []model.UserRole) []types.RoleGrantDTO {
+	result := make([]types.RoleGrantDTO, 0, len(grants))
+	for _, grant := range grants {
+		result = append(result, types.RoleGrantDTO{
+			Role:      grant.Role.Name,
+			ExpiresAt: grant.ExpiresAt,
+			GrantedBy: grant.GrantedBy,
+			GrantedAt: grant.CreatedAt,
+		})
+	}
+	return result
+}
+
+// RoleETag renders a user's role version as a strong HTTP entity tag.
+func RoleETag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// ParseRoleETag parses an If-Match value; "*" or an empty value yields nil (no precondition).
+func ParseRoleETag(value string) (*uint, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "*" {
+		return nil, true
+	}
+	value = strings.Trim(strings.TrimPrefix(value, "W/"), `"`)
+	version, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	v := uint(version)
+	return &v, true
+}
@@ -0,0 +1,38 @@
+package health
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"usermgmt/internal/buildinfo"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/types"
+)
+
+// VersionLogic reports the build metadata of the running binary.
+type VersionLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewVersionLogic constructor.
+func NewVersionLogic(ctx context.Context, svcCtx *svc.ServiceContext) *VersionLogic {
+	return &VersionLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *VersionLogic) Version() *types.VersionResponse {
+	info := buildinfo.Get()
+	return &types.VersionResponse{
+		Version:   info.Version,
+		Commit:    info.Commit,
+		BuildTime: info.BuildTime,
+		GoVersion: info.GoVersion,
+		Modified:  info.Modified,
+	}
+}
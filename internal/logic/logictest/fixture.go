@@ -0,0 +1,89 @@
+// Package logictest holds the fixture shared by the unit tests of the logic packages: a service
+// context over repository.NewMemory() seeded with roles and accounts.
+package logictest
+
+import (
+	"context"
+	"testing"
+
+	"usermgmt/internal/config"
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/event"
+	"usermgmt/internal/model"
+	"usermgmt/internal/repository"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/types"
+	"usermgmt/pkg/contextx"
+)
+
+// Account is a user seeded into a Fixture. Its email is "<username>@example.com" and its full
+// name "User <username>"; it holds Roles directly without expiry.
+type Account struct {
+	Username     string
+	AuthSource   string
+	PasswordHash string
+	Roles        []string
+}
+
+// Fixture is a service over the in-memory store. Users holds the seeded accounts by username as
+// they were created.
+type Fixture struct {
+	Store *repository.Memory
+	Svc   *svc.ServiceContext
+	Users map[string]model.User
+}
+
+// New builds the service from c over a store holding roles and accounts.
+func New(t testing.TB, c config.Config, roles []string, accounts ...Account) *Fixture {
+	t.Helper()
+	store := repository.NewMemory()
+	for _, role := range roles {
+		store.AddRole(role)
+	}
+	f := &Fixture{Store: store, Svc: svc.NewServiceContextWithStore(c, store), Users: make(map[string]model.User)}
+	for _, a := range accounts {
+		f.Users[a.Username] = store.AddUser(model.User{
+			Username:     a.Username,
+			Email:        a.Username + "@example.com",
+			FullName:     "User " + a.Username,
+			PasswordHash: a.PasswordHash,
+			AuthSource:   a.AuthSource,
+		}, a.Roles...)
+	}
+	return f
+}
+
+// As returns a request context authenticated as username with the token roles given.
+func (f *Fixture) As(username string, roles ...string) context.Context {
+	return contextx.WithClaims(context.Background(), &types.JwtClaims{UserID: f.Users[username].ID, Roles: roles})
+}
+
+// User loads the current state of username from the store.
+func (f *Fixture) User(t testing.TB, username string) *model.User {
+	t.Helper()
+	user, err := f.Store.Users().Get(context.Background(), f.Users[username].ID)
+	if err != nil {
+		t.Fatalf("load %s: %v", username, err)
+	}
+	return user
+}
+
+// ExpectCode fails unless err maps to the error code of want.
+func ExpectCode(t testing.TB, err error, want *errorx.AppError) {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("err = nil, want %s", want.Code)
+	}
+	if got := errorx.From(context.Background(), err); got.Code != want.Code {
+		t.Fatalf("err = %v, want %s", err, want.Code)
+	}
+}
+
+// EventTypes lists the types of events in order.
+func EventTypes(events []event.Event) []string {
+	names := make([]string, 0, len(events))
+	for _, e := range events {
+		names = append(names, e.Type)
+	}
+	return names
+}
@@ -0,0 +1,44 @@
+package org
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+	"usermgmt/internal/types"
+)
+
+// ListMembersLogic lists the members of the current organisation with their org roles.
+type ListMembersLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewListMembersLogic constructor.
+func NewListMembersLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListMembersLogic {
+	return &ListMembersLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListMembersLogic) List() (*types.ListOrgMembersResponse, error) {
+	defer tracing.Logic(&l.ctx, "ListMembersLogic.List")()
+
+	tenant, err := requireTenant(l.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := loadMembers(l.svcCtx.DB.WithContext(l.ctx), tenant.OrgID)
+	if err != nil {
+		l.Errorf("list org members failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+	return &types.ListOrgMembersResponse{Data: data}, nil
+}
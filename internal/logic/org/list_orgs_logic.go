@@ -0,0 +1,45 @@
+package org
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/model"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+	"usermgmt/internal/types"
+)
+
+// ListOrgsLogic lists every organisation for super admins.
+type ListOrgsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewListOrgsLogic constructor.
+func NewListOrgsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListOrgsLogic {
+	return &ListOrgsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ListOrgsLogic) List() (*types.ListOrgsResponse, error) {
+	defer tracing.Logic(&l.ctx, "ListOrgsLogic.List")()
+
+	var orgs []model.Organization
+	if err := l.svcCtx.DB.WithContext(l.ctx).Order("slug").Find(&orgs).Error; err != nil {
+		l.Errorf("list orgs failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	data := make([]types.OrgDTO, 0, len(orgs))
+	for i := range orgs {
+		data = append(data, toOrgDTO(&orgs[i]))
+	}
+	return &types.ListOrgsResponse{Data: data}, nil
+}
@@ -0,0 +1,61 @@
+package org
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/model"
+	"usermgmt/internal/types"
+	"usermgmt/pkg/contextx"
+)
+
+// requireTenant returns the organisation the request is scoped to; member management has no global form.
+func requireTenant(ctx context.Context) (*types.Tenant, error) {
+	tenant := contextx.TenantFromContext(ctx)
+	if tenant == nil {
+		return nil, errorx.ErrValidation.WithDetails(errorx.Detail{Key: "orgRequired"})
+	}
+	return tenant, nil
+}
+
+// loadMembers returns members of orgID with their org roles, optionally restricted to userIDs.
+func loadMembers(db *gorm.DB, orgID uint, userIDs ...uint) ([]types.OrgMemberDTO, error) {
+	query := db.Preload("User").Where("org_id = ?", orgID)
+	if len(userIDs) > 0 {
+		query = query.Where("user_id IN ?", userIDs)
+	}
+	var members []model.OrgMember
+	if err := query.Order("user_id").Find(&members).Error; err != nil {
+		return nil, err
+	}
+
+	var grants []model.OrgMemberRole
+	grantQuery := db.Preload("Role").Where("org_id = ?", orgID)
+	if len(userIDs) > 0 {
+		grantQuery = grantQuery.Where("user_id IN ?", userIDs)
+	}
+	if err := grantQuery.Find(&grants).Error; err != nil {
+		return nil, err
+	}
+	rolesByUser := make(map[uint][]string)
+	for _, grant := range grants {
+		rolesByUser[grant.UserID] = append(rolesByUser[grant.UserID], grant.Role.Name)
+	}
+
+	data := make([]types.OrgMemberDTO, 0, len(members))
+	for _, member := range members {
+		roles := rolesByUser[member.UserID]
+		if roles == nil {
+			roles = make([]string, 0)
+		}
+		data = append(data, types.OrgMemberDTO{
+			OrgID:    member.OrgID,
+			UserID:   member.UserID,
+			Username: member.User.Username,
+			Roles:    roles,
+		})
+	}
+	return data, nil
+}
@@ -0,0 +1,56 @@
+package org
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/model"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+)
+
+// RemoveMemberLogic removes a user and their org roles from the current organisation.
+type RemoveMemberLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewRemoveMemberLogic constructor.
+func NewRemoveMemberLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RemoveMemberLogic {
+	return &RemoveMemberLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *RemoveMemberLogic) Remove(userID uint) error {
+	defer tracing.Logic(&l.ctx, "RemoveMemberLogic.Remove")()
+
+	tenant, err := requireTenant(l.ctx)
+	if err != nil {
+		return err
+	}
+
+	var removed int64
+	err = l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("org_id = ? AND user_id = ?", tenant.OrgID, userID).Delete(&model.OrgMemberRole{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("org_id = ? AND user_id = ?", tenant.OrgID, userID).Delete(&model.OrgMember{})
+		removed = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		l.Errorf("remove org member failed: %v", err)
+		return errorx.ErrInternal
+	}
+	if removed == 0 {
+		return errorx.ErrUserNotFound
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package org
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/model"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+	"usermgmt/internal/types"
+)
+
+// UpsertMemberLogic adds a user to the current organisation or replaces their org roles.
+type UpsertMemberLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewUpsertMemberLogic constructor.
+func NewUpsertMemberLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpsertMemberLogic {
+	return &UpsertMemberLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UpsertMemberLogic) Upsert(userID uint, req *types.UpsertOrgMemberRequest) (*types.OrgMemberDTO, error) {
+	defer tracing.Logic(&l.ctx, "UpsertMemberLogic.Upsert")()
+
+	tenant, err := requireTenant(l.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(req.Roles))
+	seen := make(map[string]struct{})
+	for _, name := range req.Roles {
+		name = strings.TrimSpace(name)
+		key := strings.ToLower(name)
+		if _, ok := seen[key]; ok || name == "" {
+			continue
+		}
+		seen[key] = struct{}{}
+		names = append(names, name)
+	}
+
+	db := l.svcCtx.DB.WithContext(l.ctx)
+
+	var user model.User
+	if err := db.Select("id").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorx.ErrUserNotFound
+		}
+		l.Errorf("load user for org member failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	roles := make([]model.Role, 0)
+	if len(names) > 0 {
+		if err := db.Where("name IN ?", names).Find(&roles).Error; err != nil {
+			l.Errorf("load org roles failed: %v", err)
+			return nil, errorx.ErrInternal
+		}
+		if len(roles) != len(names) {
+			found := make(map[string]struct{}, len(roles))
+			for _, role := range roles {
+				found[strings.ToLower(role.Name)] = struct{}{}
+			}
+			missing := make([]string, 0)
+			for _, name := range names {
+				if _, ok := found[strings.ToLower(name)]; !ok {
+					missing = append(missing, name)
+				}
+			}
+			return nil, errorx.ErrValidation.WithDetails(map[string]interface{}{"missingRoles": missing})
+		}
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		member := model.OrgMember{OrgID: tenant.OrgID, UserID: userID}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&member).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("org_id = ? AND user_id = ?", tenant.OrgID, userID).Delete(&model.OrgMemberRole{}).Error; err != nil {
+			return err
+		}
+		for _, role := range roles {
+			grant := model.OrgMemberRole{OrgID: tenant.OrgID, UserID: userID, RoleID: role.ID}
+			if err := tx.Create(&grant).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, errorx.ErrConflict
+		}
+		l.Errorf("upsert org member failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	members, err := loadMembers(db, tenant.OrgID, userID)
+	if err != nil || len(members) == 0 {
+		l.Errorf("reload org member failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+	return &members[0], nil
+}
@@ -2,15 +2,17 @@ package user
 
 import (
 	"context"
+	"time"
 
 	"github.com/zeromicro/go-zero/core/logx"
 
 	"usermgmt/internal/errorx"
+	"usermgmt/internal/metrics"
 	"usermgmt/internal/model"
 	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
 	"usermgmt/internal/types"
 	"usermgmt/pkg/contextx"
-	"usermgmt/pkg/security"
 )
 
 // ChangePasswordLogic verifies old password then updates new hash.
@@ -30,32 +32,39 @@ func NewChangePasswordLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Ch
 }
 
 func (l *ChangePasswordLogic) Change(req *types.ChangePasswordRequest) error {
+	defer tracing.Logic(&l.ctx, "ChangePasswordLogic.Change")()
+
 	claims := contextx.MustGetClaims(l.ctx)
 	if claims == nil {
 		return errorx.ErrInvalidCredentials
 	}
 
-	db := l.svcCtx.DB.WithContext(l.ctx)
+	users := l.svcCtx.Store.Users()
 
-	var user model.User
-	if err := db.First(&user, claims.UserID).Error; err != nil {
+	user, err := users.Get(l.ctx, claims.UserID)
+	if err != nil {
 		l.Errorf("load user failed: %v", err)
 		return errorx.ErrInternal
 	}
 
-	if err := security.VerifyPassword(user.PasswordHash, req.OldPassword); err != nil {
+	if user.AuthSource != "" && user.AuthSource != model.AuthSourceLocal {
+		return errorx.ErrExternalAccount
+	}
+
+	start := time.Now()
+	err = tracing.VerifyPassword(l.ctx, user.PasswordHash, req.OldPassword)
+	metrics.ObservePasswordVerify(start, err)
+	if err != nil {
 		return errorx.ErrInvalidCredentials
 	}
 
-	hash, err := security.HashPassword(req.NewPassword, l.svcCtx.Config.Password.BcryptCost)
+	hash, err := tracing.HashPassword(l.ctx, req.NewPassword, l.svcCtx.Config.Password.BcryptCost)
 	if err != nil {
 		l.Errorf("hash new password failed: %v", err)
 		return errorx.ErrInternal
 	}
 
-	if err := db.Model(&model.User{}).
-		Where("id = ?", user.ID).
-		Update("password_hash", hash).Error; err != nil {
+	if err := users.UpdatePasswordHash(l.ctx, user.ID, hash); err != nil {
 		l.Errorf("update password failed: %v", err)
 		return errorx.ErrInternal
 	}
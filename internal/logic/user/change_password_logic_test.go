@@ -0,0 +1,45 @@
+package user
+
+import (
+	"testing"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/logic/logictest"
+	"usermgmt/internal/types"
+	"usermgmt/pkg/security"
+)
+
+func TestChangePassword(t *testing.T) {
+	tests := []struct {
+		name    string
+		caller  string
+		old     string
+		wantErr *errorx.AppError
+	}{
+		{name: "change password", caller: "alice", old: testPassword},
+		{name: "wrong old password", caller: "alice", old: "Wrong#123", wantErr: errorx.ErrInvalidCredentials},
+		{name: "directory account", caller: "carol", old: testPassword, wantErr: errorx.ErrExternalAccount},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			const newPassword = "Changed#456"
+
+			err := NewChangePasswordLogic(f.As(tt.caller), f.Svc).Change(&types.ChangePasswordRequest{OldPassword: tt.old, NewPassword: newPassword})
+			hash := f.User(t, tt.caller).PasswordHash
+			if tt.wantErr != nil {
+				logictest.ExpectCode(t, err, tt.wantErr)
+				if hash != f.Users[tt.caller].PasswordHash {
+					t.Fatalf("password hash changed despite %s", tt.wantErr.Code)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if err := security.VerifyPassword(hash, newPassword); err != nil {
+				t.Fatalf("new password does not verify: %v", err)
+			}
+		})
+	}
+}
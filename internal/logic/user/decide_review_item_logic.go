@@ -0,0 +1,87 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/logic/common"
+	"usermgmt/internal/middleware"
+	"usermgmt/internal/model"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+	"usermgmt/internal/types"
+	"usermgmt/pkg/contextx"
+)
+
+// DecideReviewItemLogic records a keep/revoke decision. Decisions take effect when the campaign
+// closes and may be changed until then.
+type DecideReviewItemLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewDecideReviewItemLogic constructor.
+func NewDecideReviewItemLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DecideReviewItemLogic {
+	return &DecideReviewItemLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Decide is open to the assigned reviewer and to admins, but never to the holder of the grant.
+func (l *DecideReviewItemLogic) Decide(itemID uint, req *types.DecideReviewItemRequest) (*types.AccessReviewItemDTO, error) {
+	defer tracing.Logic(&l.ctx, "DecideReviewItemLogic.Decide")()
+
+	claims := contextx.MustGetClaims(l.ctx)
+	if claims == nil {
+		return nil, errorx.ErrInvalidCredentials
+	}
+	db := l.svcCtx.DB.WithContext(l.ctx)
+
+	var item model.AccessReviewItem
+	if err := db.First(&item, itemID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorx.ErrReviewItemNotFound
+		}
+		l.Errorf("load review item failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+	if item.UserID == claims.UserID {
+		return nil, errorx.ErrSelfReview
+	}
+	assigned := item.ReviewerID != nil && *item.ReviewerID == claims.UserID
+	if !assigned && !middleware.HasActiveRole(claims, "admin") {
+		return nil, errorx.ErrNotReviewer
+	}
+
+	now := time.Now()
+	decidedBy := claims.UserID
+	item.Decision = req.Decision
+	item.DecidedBy = &decidedBy
+	item.DecidedAt = &now
+	item.Comment = strings.TrimSpace(req.Comment)
+
+	// Only record the decision while the campaign is open and before its deadline.
+	result := db.Model(&item).
+		Where("review_id IN (?)", db.Model(&model.AccessReview{}).Select("id").Where("status = ? AND deadline > ?", model.AccessReviewOpen, now)).
+		Select("decision", "decided_by", "decided_at", "comment").
+		Updates(&item)
+	if result.Error != nil {
+		l.Errorf("record review decision failed: %v", result.Error)
+		return nil, errorx.ErrInternal
+	}
+	if result.RowsAffected == 0 {
+		return nil, errorx.ErrReviewClosed
+	}
+
+	dto := common.ToAccessReviewItemDTO(&item)
+	return &dto, nil
+}
@@ -0,0 +1,30 @@
+package user
+
+import (
+	"testing"
+
+	"usermgmt/internal/config"
+	"usermgmt/internal/logic/logictest"
+	"usermgmt/internal/model"
+	"usermgmt/pkg/security"
+)
+
+const testPassword = "Secret#123"
+
+// newFixture seeds a local account "alice", a second local account "bob" and a directory
+// account "carol", all sharing testPassword.
+func newFixture(t *testing.T) *logictest.Fixture {
+	t.Helper()
+	var c config.Config
+	c.Password.BcryptCost = 4
+
+	hash, err := security.HashPassword(testPassword, c.Password.BcryptCost)
+	if err != nil {
+		t.Fatalf("hash password: %v", err)
+	}
+	return logictest.New(t, c, nil,
+		logictest.Account{Username: "alice", AuthSource: model.AuthSourceLocal, PasswordHash: hash},
+		logictest.Account{Username: "bob", AuthSource: model.AuthSourceLocal, PasswordHash: hash},
+		logictest.Account{Username: "carol", AuthSource: model.AuthSourceLDAP, PasswordHash: hash},
+	)
+}
@@ -0,0 +1,64 @@
+package user
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/logic/common"
+	"usermgmt/internal/model"
+	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
+	"usermgmt/internal/types"
+	"usermgmt/pkg/contextx"
+)
+
+// ListReviewItemsLogic lists the access review items assigned to the current user.
+type ListReviewItemsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewListReviewItemsLogic constructor.
+func NewListReviewItemsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListReviewItemsLogic {
+	return &ListReviewItemsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// List returns the caller's items in open campaigns; only undecided ones unless req.All is set.
+func (l *ListReviewItemsLogic) List(req *types.ListReviewItemsRequest) (*types.ListReviewItemsResponse, error) {
+	defer tracing.Logic(&l.ctx, "ListReviewItemsLogic.List")()
+
+	claims := contextx.MustGetClaims(l.ctx)
+	if claims == nil {
+		return nil, errorx.ErrInvalidCredentials
+	}
+
+	query := l.svcCtx.DB.WithContext(l.ctx).
+		Model(&model.AccessReviewItem{}).
+		Where("reviewer_id = ?", claims.UserID).
+		Where("review_id IN (?)", l.svcCtx.DB.Model(&model.AccessReview{}).Select("id").Where("status = ?", model.AccessReviewOpen))
+	if req.ReviewID != 0 {
+		query = query.Where("review_id = ?", req.ReviewID)
+	}
+	if !req.All {
+		query = query.Where("decision = ''")
+	}
+
+	var items []model.AccessReviewItem
+	if err := query.Order("review_id, id").Find(&items).Error; err != nil {
+		l.Errorf("list review items failed: %v", err)
+		return nil, errorx.ErrInternal
+	}
+
+	data := make([]types.AccessReviewItemDTO, 0, len(items))
+	for i := range items {
+		data = append(data, common.ToAccessReviewItemDTO(&items[i]))
+	}
+	return &types.ListReviewItemsResponse{Data: data}, nil
+}
@@ -5,12 +5,12 @@ import (
 	"errors"
 
 	"github.com/zeromicro/go-zero/core/logx"
-	"gorm.io/gorm"
 
 	"usermgmt/internal/errorx"
 	"usermgmt/internal/logic/common"
-	"usermgmt/internal/model"
+	"usermgmt/internal/repository"
 	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
 	"usermgmt/internal/types"
 	"usermgmt/pkg/contextx"
 )
@@ -32,22 +32,22 @@ func NewProfileLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ProfileLo
 }
 
 func (l *ProfileLogic) Profile() (*types.ProfileResponse, error) {
+	defer tracing.Logic(&l.ctx, "ProfileLogic.Profile")()
+
 	claims := contextx.MustGetClaims(l.ctx)
 	if claims == nil {
 		return nil, errorx.ErrInvalidCredentials
 	}
 
-	var user model.User
-	if err := l.svcCtx.DB.WithContext(l.ctx).
-		Preload("Roles").
-		First(&user, claims.UserID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+	user, err := l.svcCtx.Store.Users().Get(l.ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
 			return nil, errorx.ErrInvalidCredentials
 		}
 		l.Errorf("load profile failed: %v", err)
 		return nil, errorx.ErrInternal
 	}
 
-	dto := common.ToUserDTO(&user)
+	dto := common.ToUserDTO(user)
 	return &types.ProfileResponse{User: dto}, nil
 }
@@ -2,14 +2,17 @@ package user
 
 import (
 	"context"
+	"errors"
 	"strings"
 
 	"github.com/zeromicro/go-zero/core/logx"
 
 	"usermgmt/internal/errorx"
+	"usermgmt/internal/event"
 	"usermgmt/internal/logic/common"
-	"usermgmt/internal/model"
+	"usermgmt/internal/repository"
 	"usermgmt/internal/svc"
+	"usermgmt/internal/tracing"
 	"usermgmt/internal/types"
 	"usermgmt/pkg/contextx"
 )
@@ -31,6 +34,8 @@ func NewUpdateProfileLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Upd
 }
 
 func (l *UpdateProfileLogic) Update(req *types.UpdateProfileRequest) (*types.ProfileResponse, error) {
+	defer tracing.Logic(&l.ctx, "UpdateProfileLogic.Update")()
+
 	claims := contextx.MustGetClaims(l.ctx)
 	if claims == nil {
 		return nil, errorx.ErrInvalidCredentials
@@ -39,35 +44,38 @@ func (l *UpdateProfileLogic) Update(req *types.UpdateProfileRequest) (*types.Pro
 	email := strings.ToLower(strings.TrimSpace(req.Email))
 	fullName := strings.TrimSpace(req.FullName)
 
-	db := l.svcCtx.DB.WithContext(l.ctx)
+	store := l.svcCtx.Store
 
-	var count int64
-	if err := db.Model(&model.User{}).
-		Where("email = ? AND id <> ?", email, claims.UserID).
-		Count(&count).Error; err != nil {
-		l.Errorf("check email unique failed: %v", err)
+	previous, err := store.Users().Get(l.ctx, claims.UserID)
+	if err != nil {
+		l.Errorf("load current profile failed: %v", err)
 		return nil, errorx.ErrInternal
 	}
-	if count > 0 {
-		return nil, errorx.ErrUserExists
-	}
 
-	if err := db.Model(&model.User{}).
-		Where("id = ?", claims.UserID).
-		Updates(map[string]interface{}{
-			"email":     email,
-			"full_name": fullName,
-		}).Error; err != nil {
+	var dto types.UserDTO
+	if err := store.Transaction(l.ctx, func(tx repository.Store) error {
+		if err := tx.Users().UpdateProfile(l.ctx, claims.UserID, email, fullName); err != nil {
+			return err
+		}
+		user, err := tx.Users().Get(l.ctx, claims.UserID)
+		if err != nil {
+			return err
+		}
+		dto = common.ToUserDTO(user)
+		if previous.Email == dto.Email {
+			return nil
+		}
+		return tx.Events().Record(l.ctx, event.NewUserEvent(event.UserEmailChanged, event.UserEventData{
+			User:          dto,
+			PreviousEmail: previous.Email,
+		}))
+	}); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, errorx.ErrUserExists
+		}
 		l.Errorf("update profile failed: %v", err)
 		return nil, errorx.ErrInternal
 	}
 
-	var user model.User
-	if err := db.Preload("Roles").First(&user, claims.UserID).Error; err != nil {
-		l.Errorf("load updated user failed: %v", err)
-		return nil, errorx.ErrInternal
-	}
-
-	dto := common.ToUserDTO(&user)
 	return &types.ProfileResponse{User: dto}, nil
 }
@@ -0,0 +1,58 @@
+package user
+
+import (
+	"testing"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/event"
+	"usermgmt/internal/logic/logictest"
+	"usermgmt/internal/types"
+)
+
+func TestUpdateProfile(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       types.UpdateProfileRequest
+		wantErr   *errorx.AppError
+		wantEmail string
+		wantEvent bool
+	}{
+		{name: "change name", req: types.UpdateProfileRequest{Email: "alice@example.com", FullName: "Alice Liddell"}, wantEmail: "alice@example.com"},
+		{name: "change email", req: types.UpdateProfileRequest{Email: "alice@corp.example", FullName: "User alice"}, wantEmail: "alice@corp.example", wantEvent: true},
+		{name: "normalize email", req: types.UpdateProfileRequest{Email: "  Alice@Corp.Example ", FullName: "User alice"}, wantEmail: "alice@corp.example", wantEvent: true},
+		{name: "email taken", req: types.UpdateProfileRequest{Email: "bob@example.com", FullName: "User alice"}, wantErr: errorx.ErrUserExists},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+
+			resp, err := NewUpdateProfileLogic(f.As("alice"), f.Svc).Update(&tt.req)
+			if tt.wantErr != nil {
+				logictest.ExpectCode(t, err, tt.wantErr)
+				if got := f.User(t, "alice").Email; got != "alice@example.com" {
+					t.Fatalf("email = %q, want unchanged", got)
+				}
+				if events := f.Store.RecordedEvents(); len(events) != 0 {
+					t.Fatalf("recorded %d events, want none", len(events))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if resp.User.Email != tt.wantEmail || f.User(t, "alice").Email != tt.wantEmail {
+				t.Fatalf("email = %q, stored %q, want %q", resp.User.Email, f.User(t, "alice").Email, tt.wantEmail)
+			}
+			events := f.Store.RecordedEvents()
+			if !tt.wantEvent {
+				if len(events) != 0 {
+					t.Fatalf("recorded %d events, want none", len(events))
+				}
+				return
+			}
+			if len(events) != 1 || events[0].Type != event.UserEmailChanged {
+				t.Fatalf("events = %v, want one %s", events, event.UserEmailChanged)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+
+	"usermgmt/internal/event"
+	"usermgmt/internal/model"
+)
+
+// SubscribeUserEvents counts role and status changes from the outbox events on bus, so that only
+// committed changes are counted whichever path made them (admin API, bulk, approvals, SCIM, LDAP
+// sync, reviews). Events are delivered at least once, so a redelivered event is counted again.
+func SubscribeUserEvents(bus *event.Bus) {
+	bus.Subscribe(event.UserRolesChanged, observeRolesChanged)
+	bus.Subscribe(event.UserEnabled, observeStatusChanged(model.UserStatusEnabled))
+	bus.Subscribe(event.UserDisabled, observeStatusChanged(model.UserStatusDisabled))
+}
+
+func observeRolesChanged(_ context.Context, msg event.Message) error {
+	var envelope struct {
+		Data event.UserEventData `json:"data"`
+	}
+	if err := json.Unmarshal(msg.Body, &envelope); err != nil {
+		// Retrying would not make the payload readable; metrics must not hold up the bus.
+		return nil
+	}
+	previous := make(map[string]bool, len(envelope.Data.PreviousRoles))
+	for _, role := range envelope.Data.PreviousRoles {
+		previous[role] = true
+	}
+	for _, role := range envelope.Data.User.Roles {
+		if previous[role] {
+			delete(previous, role)
+			continue
+		}
+		roleChanges.Inc(role, "granted")
+	}
+	for role := range previous {
+		roleChanges.Inc(role, "revoked")
+	}
+	return nil
+}
+
+func observeStatusChanged(status string) event.Handler {
+	return func(context.Context, event.Message) error {
+		statusChanges.Inc(status)
+		return nil
+	}
+}
@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
+)
+
+const startedAtKey = "metrics:started_at"
+
+// GormPlugin times every statement GORM runs into usermgmt_db_query_duration_seconds.
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string {
+	return "metrics"
+}
+
+// Initialize hooks the plugin around the create, query, update, delete, row and raw callbacks.
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("metrics:before_create", startTimer),
+		cb.Create().After("gorm:create").Register("metrics:after_create", observeQuery("create")),
+		cb.Query().Before("gorm:query").Register("metrics:before_query", startTimer),
+		cb.Query().After("gorm:query").Register("metrics:after_query", observeQuery("query")),
+		cb.Update().Before("gorm:update").Register("metrics:before_update", startTimer),
+		cb.Update().After("gorm:update").Register("metrics:after_update", observeQuery("update")),
+		cb.Delete().Before("gorm:delete").Register("metrics:before_delete", startTimer),
+		cb.Delete().After("gorm:delete").Register("metrics:after_delete", observeQuery("delete")),
+		cb.Row().Before("gorm:row").Register("metrics:before_row", startTimer),
+		cb.Row().After("gorm:row").Register("metrics:after_row", observeQuery("row")),
+		cb.Raw().Before("gorm:raw").Register("metrics:before_raw", startTimer),
+		cb.Raw().After("gorm:raw").Register("metrics:after_raw", observeQuery("raw")),
+	)
+}
+
+func startTimer(db *gorm.DB) {
+	db.InstanceSet(startedAtKey, time.Now())
+}
+
+func observeQuery(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(startedAtKey)
+		if !ok {
+			return
+		}
+		started, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+		result := OutcomeSuccess
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			result = OutcomeError
+		}
+		dbQueries.ObserveFloat(time.Since(started).Seconds(), operation, db.Statement.Table, result)
+	}
+}
+
+var (
+	poolMu        sync.Mutex
+	poolCollector prom.Collector
+)
+
+// WatchPool exports the connection pool statistics of db (sql.DB.Stats) as go_sql_* gauges and
+// counters labelled db_name=name. A later call replaces the pool being watched.
+func WatchPool(db *sql.DB, name string) {
+	poolMu.Lock()
+	defer poolMu.Unlock()
+	if poolCollector != nil {
+		prom.Unregister(poolCollector)
+	}
+	poolCollector = collectors.NewDBStatsCollector(db, name)
+	prom.MustRegister(poolCollector)
+}
@@ -0,0 +1,119 @@
+// Package metrics defines the Prometheus metrics of the service. They are go-zero metric vectors,
+// so nothing is recorded until prometheus.Enable is called (see svc.NewServiceContext), and they are
+// served with go-zero's own HTTP metrics at Metrics.Path.
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/metric"
+
+	"usermgmt/internal/errorx"
+)
+
+const namespace = "usermgmt"
+
+// Outcome labels. Failures are labelled with their errorx code, e.g. INVALID_CREDENTIALS.
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+var (
+	logins = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "logins_total",
+		Help:      "Login attempts by outcome.",
+		Labels:    []string{"outcome"},
+	})
+	registrations = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "registrations_total",
+		Help:      "Self-service registrations by outcome.",
+		Labels:    []string{"outcome"},
+	})
+	tokenFailures = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "token_failures_total",
+		Help:      "Requests rejected by AuthMiddleware, by reason.",
+		Labels:    []string{"reason"},
+	})
+	passwordVerify = metric.NewHistogramVec(&metric.HistogramVecOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "password_verify_duration_seconds",
+		Help:      "Time spent comparing a password with its bcrypt hash.",
+		Labels:    []string{"match"},
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	})
+	roleChanges = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: namespace,
+		Subsystem: "users",
+		Name:      "role_changes_total",
+		Help:      "Committed role grants and revocations, by role.",
+		Labels:    []string{"role", "change"},
+	})
+	statusChanges = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: namespace,
+		Subsystem: "users",
+		Name:      "status_changes_total",
+		Help:      "Committed account status changes, by new status.",
+		Labels:    []string{"status"},
+	})
+	dbQueries = metric.NewHistogramVec(&metric.HistogramVecOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Duration of GORM statements, by operation, table and outcome.",
+		Labels:    []string{"operation", "table", "outcome"},
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	})
+)
+
+// Token failure reasons.
+const (
+	TokenMissing   = "missing"
+	TokenMalformed = "malformed"
+	TokenExpired   = "expired"
+	TokenInvalid   = "invalid"
+)
+
+// ObserveLogin counts a login attempt that ended with err.
+func ObserveLogin(err error) {
+	logins.Inc(outcome(err))
+}
+
+// ObserveRegistration counts a registration that ended with err.
+func ObserveRegistration(err error) {
+	registrations.Inc(outcome(err))
+}
+
+// ObserveTokenFailure counts a request AuthMiddleware rejected for reason.
+func ObserveTokenFailure(reason string) {
+	tokenFailures.Inc(reason)
+}
+
+// ObservePasswordVerify records a bcrypt comparison that started at start; err is its result.
+func ObservePasswordVerify(start time.Time, err error) {
+	match := "true"
+	if err != nil {
+		match = "false"
+	}
+	passwordVerify.ObserveFloat(time.Since(start).Seconds(), match)
+}
+
+// outcome is OutcomeSuccess for nil, the code of an AppError, or OutcomeError otherwise.
+func outcome(err error) string {
+	if err == nil {
+		return OutcomeSuccess
+	}
+	var appErr *errorx.AppError
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+	return OutcomeError
+}
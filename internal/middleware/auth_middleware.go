@@ -1,25 +1,30 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/zeromicro/go-zero/core/logx"
 
 	"usermgmt/internal/errorx"
+	"usermgmt/internal/metrics"
+	"usermgmt/internal/responder"
+	"usermgmt/internal/tracing"
 	"usermgmt/pkg/contextx"
-	"usermgmt/pkg/response"
 	"usermgmt/pkg/security"
 )
 
 // AuthMiddleware validates JWT tokens from the Authorization header.
 type AuthMiddleware struct {
-	secret string
+	secret    string
+	responder *responder.Responder
 }
 
 // NewAuthMiddleware creates a JWT middleware with the provided secret.
-func NewAuthMiddleware(secret string) *AuthMiddleware {
-	return &AuthMiddleware{secret: secret}
+func NewAuthMiddleware(secret string, responder *responder.Responder) *AuthMiddleware {
+	return &AuthMiddleware{secret: secret, responder: responder}
 }
 
 // Handle enforces bearer tokens and injects claims into the request context.
@@ -28,13 +33,15 @@ func (m *AuthMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
-			writeUnauthorized(w, r)
+			metrics.ObserveTokenFailure(metrics.TokenMissing)
+			m.responder.Fail(w, r, errorx.ErrInvalidCredentials)
 			return
 		}
 
 		parts := strings.SplitN(authHeader, " ", 2)
 		if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
-			writeUnauthorized(w, r)
+			metrics.ObserveTokenFailure(metrics.TokenMalformed)
+			m.responder.Fail(w, r, errorx.ErrInvalidCredentials)
 			return
 		}
 
@@ -42,15 +49,21 @@ func (m *AuthMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
 
 		if err != nil {
 			logx.WithContext(r.Context()).Errorf("parse token failed: %v", err)
-			writeUnauthorized(w, r)
+			if errors.Is(err, jwt.ErrTokenExpired) {
+				metrics.ObserveTokenFailure(metrics.TokenExpired)
+			} else {
+				metrics.ObserveTokenFailure(metrics.TokenInvalid)
+			}
+			m.responder.Fail(w, r, errorx.ErrInvalidCredentials)
 			return
 		}
 
+		tracing.SetUserID(r.Context(), claims.UserID)
+		if info := contextx.RequestInfoFromContext(r.Context()); info != nil {
+			info.UserID = claims.UserID
+		}
 		ctx := contextx.WithClaims(r.Context(), claims)
+		ctx = logx.ContextWithFields(ctx, logx.Field("userId", claims.UserID))
 		next(w, r.WithContext(ctx))
 	}
 }
-
-func writeUnauthorized(w http.ResponseWriter, r *http.Request) {
-	response.Error(w, r, errorx.ErrInvalidCredentials.Status, errorx.ErrInvalidCredentials.Code, errorx.ErrInvalidCredentials.Message, nil)
-}
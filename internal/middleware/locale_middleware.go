@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+
+	"usermgmt/internal/i18n"
+)
+
+// LocaleMiddleware negotiates the language of error messages from Accept-Language and puts the
+// matching i18n.Localizer in the request context.
+type LocaleMiddleware struct {
+	bundle *i18n.Bundle
+}
+
+// NewLocaleMiddleware creates the middleware for the locales of bundle.
+func NewLocaleMiddleware(bundle *i18n.Bundle) *LocaleMiddleware {
+	return &LocaleMiddleware{bundle: bundle}
+}
+
+// Handle attaches the localizer. Responses may differ by language, hence Vary.
+func (m *LocaleMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Language")
+		localizer := m.bundle.Localizer(m.bundle.Match(r.Header.Get("Accept-Language")))
+		next(w, r.WithContext(i18n.WithLocalizer(r.Context(), localizer)))
+	}
+}
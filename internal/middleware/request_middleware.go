@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	"usermgmt/pkg/contextx"
+)
+
+// RequestIDHeader carries the request ID: a valid one sent by the client or a proxy is kept,
+// otherwise one is generated, and it is always echoed in the response.
+const RequestIDHeader = "X-Request-ID"
+
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:\-]{1,128}$`)
+
+// RequestMiddleware tags a request with its ID, adds the ID to every logx line written with the
+// request context, and writes one JSON access log line per request.
+type RequestMiddleware struct {
+	route string
+	quiet bool
+}
+
+// NewRequestMiddleware creates the middleware for route, the path template it is mounted on.
+// Successful requests to a route in skipPaths are not logged.
+func NewRequestMiddleware(route string, skipPaths []string) *RequestMiddleware {
+	m := &RequestMiddleware{route: route}
+	for _, path := range skipPaths {
+		if path == route {
+			m.quiet = true
+		}
+	}
+	return m
+}
+
+// Handle wraps next with the request ID and the access log.
+func (m *RequestMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		id := r.Header.Get(RequestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = uuid.NewString()
+		}
+		w.Header().Set(RequestIDHeader, id)
+
+		info := &contextx.RequestInfo{ID: id}
+		ctx := contextx.WithRequestInfo(r.Context(), info)
+		ctx = logx.ContextWithFields(ctx, logx.Field("requestId", id))
+		recorder := &statusRecorder{ResponseWriter: w}
+		next(recorder, r.WithContext(ctx))
+
+		status := recorder.statusCode()
+		if m.quiet && status < http.StatusBadRequest {
+			return
+		}
+		fields := []logx.LogField{
+			logx.Field("method", r.Method),
+			logx.Field("route", m.route),
+			logx.Field("path", r.URL.Path),
+			logx.Field("status", status),
+			logx.Field("latencyMs", float64(time.Since(start).Microseconds())/1000),
+			logx.Field("bytes", recorder.bytes),
+			logx.Field("ip", httpx.GetRemoteAddr(r)),
+		}
+		// AuthMiddleware runs further down the chain and reports the user through info.
+		if info.UserID != 0 {
+			fields = append(fields, logx.Field("userId", info.UserID))
+		}
+		logger := logx.WithContext(ctx)
+		if status >= http.StatusInternalServerError {
+			logger.Errorw("access", fields...)
+			return
+		}
+		logger.Infow("access", fields...)
+	}
+}
+
+// statusRecorder captures the status and size of a response. It keeps streaming (Flush) and
+// http.ResponseController working for the handlers below it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(p []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	n, err := s.ResponseWriter.Write(p)
+	s.bytes += n
+	return n, err
+}
+
+func (s *statusRecorder) Flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (s *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := s.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, errors.New("response writer does not support hijacking")
+}
+
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+func (s *statusRecorder) statusCode() int {
+	if s.status == 0 {
+		return http.StatusOK
+	}
+	return s.status
+}
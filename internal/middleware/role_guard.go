@@ -3,14 +3,15 @@ package middleware
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"usermgmt/internal/errorx"
+	"usermgmt/internal/responder"
 	"usermgmt/pkg/contextx"
-	"usermgmt/pkg/response"
 )
 
 // NewRoleGuard creates a middleware that ensures the user has one of the required roles.
-func NewRoleGuard(roles ...string) func(http.HandlerFunc) http.HandlerFunc {
+func NewRoleGuard(responder *responder.Responder, roles ...string) func(http.HandlerFunc) http.HandlerFunc {
 	required := make(map[string]struct{})
 	for _, role := range roles {
 		role = strings.TrimSpace(role)
@@ -29,18 +30,23 @@ func NewRoleGuard(roles ...string) func(http.HandlerFunc) http.HandlerFunc {
 
 			claims := contextx.MustGetClaims(r.Context())
 			if claims == nil {
-				response.Error(w, r, errorx.ErrInvalidCredentials.Status, errorx.ErrInvalidCredentials.Code, errorx.ErrInvalidCredentials.Message, nil)
+				responder.Fail(w, r, errorx.ErrInvalidCredentials)
 				return
 			}
 
+			now := time.Now().Unix()
 			for _, role := range claims.Roles {
+				// Time-bound grants stop counting once expired, even if the token itself is still valid.
+				if exp, ok := claims.RoleExpiresAt[role]; ok && now >= exp {
+					continue
+				}
 				if _, ok := required[strings.ToLower(role)]; ok {
 					next(w, r)
 					return
 				}
 			}
 
-			response.Error(w, r, errorx.ErrForbidden.Status, errorx.ErrForbidden.Code, errorx.ErrForbidden.Message, nil)
+			responder.Fail(w, r, errorx.ErrForbidden)
 		}
 	}
 }
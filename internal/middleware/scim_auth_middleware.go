@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"usermgmt/internal/scim"
+)
+
+// SCIMAuthMiddleware admits SCIM clients presenting the configured static bearer token.
+type SCIMAuthMiddleware struct {
+	token string
+}
+
+// NewSCIMAuthMiddleware creates the middleware; an empty token rejects every request.
+func NewSCIMAuthMiddleware(token string) *SCIMAuthMiddleware {
+	return &SCIMAuthMiddleware{token: token}
+}
+
+// Handle compares the bearer token in constant time and answers failures with a SCIM error.
+func (m *SCIMAuthMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+		if m.token == "" || len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") ||
+			subtle.ConstantTimeCompare([]byte(parts[1]), []byte(m.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="scim"`)
+			scim.WriteError(w, r, http.StatusUnauthorized, "", "invalid or missing bearer token")
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/responder"
+	"usermgmt/internal/types"
+	"usermgmt/pkg/contextx"
+)
+
+// TenantResolver resolves the organisation scope for an authenticated user.
+type TenantResolver interface {
+	Resolve(ctx context.Context, userID uint, ref string, superAdmin bool) (*types.Tenant, error)
+}
+
+// TenantMiddleware picks the organisation from the configured header or the token's orgId claim.
+// Requests without either run in global scope, which only super admins can use for admin routes.
+type TenantMiddleware struct {
+	resolver       TenantResolver
+	header         string
+	superAdminRole string
+	responder      *responder.Responder
+}
+
+// NewTenantMiddleware creates the tenant middleware; it must run after AuthMiddleware.
+func NewTenantMiddleware(resolver TenantResolver, header, superAdminRole string, responder *responder.Responder) *TenantMiddleware {
+	return &TenantMiddleware{resolver: resolver, header: header, superAdminRole: superAdminRole, responder: responder}
+}
+
+// Handle resolves the tenant and injects it into the request context.
+func (m *TenantMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims := contextx.MustGetClaims(r.Context())
+		if claims == nil {
+			m.responder.Fail(w, r, errorx.ErrInvalidCredentials)
+			return
+		}
+
+		ref := strings.TrimSpace(r.Header.Get(m.header))
+		if ref == "" && claims.OrgID != 0 {
+			ref = strconv.FormatUint(uint64(claims.OrgID), 10)
+		}
+		if ref == "" {
+			next(w, r)
+			return
+		}
+
+		tenant, err := m.resolver.Resolve(r.Context(), claims.UserID, ref, HasActiveRole(claims, m.superAdminRole))
+		if err != nil {
+			m.responder.Fail(w, r, fmt.Errorf("resolve tenant: %w", err))
+			return
+		}
+
+		next(w, r.WithContext(contextx.WithTenant(r.Context(), tenant)))
+	}
+}
+
+// NewOrgAdminGuard admits super admins (and any extra global roles) in any scope and org admins
+// inside their resolved organisation. Extra roles are typically narrowed further by ABAC policies.
+func NewOrgAdminGuard(responder *responder.Responder, superAdminRole, orgAdminRole string, globalRoles ...string) func(http.HandlerFunc) http.HandlerFunc {
+	admitted := append([]string{superAdminRole}, globalRoles...)
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			claims := contextx.MustGetClaims(r.Context())
+			if claims == nil {
+				responder.Fail(w, r, errorx.ErrInvalidCredentials)
+				return
+			}
+			for _, role := range admitted {
+				if HasActiveRole(claims, role) {
+					next(w, r)
+					return
+				}
+			}
+			if tenant := contextx.TenantFromContext(r.Context()); tenant != nil {
+				for _, role := range tenant.Roles {
+					if strings.EqualFold(role, orgAdminRole) {
+						next(w, r)
+						return
+					}
+				}
+			}
+			responder.Fail(w, r, errorx.ErrForbidden)
+		}
+	}
+}
+
+// HasActiveRole reports whether the claims carry role and its time-bound grant, if any, has not expired.
+func HasActiveRole(claims *types.JwtClaims, role string) bool {
+	if claims == nil || role == "" {
+		return false
+	}
+	now := time.Now().Unix()
+	for _, name := range claims.Roles {
+		if !strings.EqualFold(name, role) {
+			continue
+		}
+		if exp, ok := claims.RoleExpiresAt[name]; ok && now >= exp {
+			continue
+		}
+		return true
+	}
+	return false
+}
@@ -7,23 +7,39 @@ const (
 	UserStatusDisabled = "disabled"
 )
 
+// Authentication sources; an account only logs in through the provider that owns it.
+const (
+	AuthSourceLocal = "local"
+	AuthSourceLDAP  = "ldap"
+)
+
 type User struct {
 	ID           uint       `gorm:"primaryKey"`
-	Username     string     `gorm:"size=50;uniqueIndex;not null"`
-	Email        string     `gorm:"size=255;uniqueIndex;not null"`
-	PasswordHash string     `gorm:"size=255;not null"`
-	FullName     string     `gorm:"size=100"`
-	Status       string     `gorm:"size=20;default:'enabled'"`
+	Username     string     `gorm:"size:50;uniqueIndex;not null"`
+	ExternalID   *string    `gorm:"size:255;uniqueIndex"`
+	Email        string     `gorm:"size:255;uniqueIndex;not null"`
+	PasswordHash string     `gorm:"size:255;not null"`
+	AuthSource   string     `gorm:"size:20;not null;default:'local'"`
+	FullName     string     `gorm:"size:100"`
+	Department   string     `gorm:"size:100;index"`
+	Status       string     `gorm:"size:20;default:'enabled'"`
 	LastLoginAt  *time.Time `gorm:"index"`
+	RoleVersion  uint       `gorm:"not null;default:0"`
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
-	Roles        []Role `gorm:"many2many:user_roles"`
+	Roles        []Role        `gorm:"many2many:user_roles"`
+	RoleGrants   []UserRole    `gorm:"foreignKey:UserID"`
+	Groups       []GroupMember `gorm:"foreignKey:UserID"`
 }
 
+// Role inherits every permission of its parent role (ParentID), recursively.
 type Role struct {
-	ID          uint   `gorm:"primaryKey"`
-	Name        string `gorm:"size=50;uniqueIndex;not null"`
-	Description string `gorm:"size=255"`
+	ID          uint    `gorm:"primaryKey"`
+	Name        string  `gorm:"size:50;uniqueIndex;not null"`
+	ExternalID  *string `gorm:"size:255;uniqueIndex"`
+	Description string  `gorm:"size:255"`
+	ParentID    *uint   `gorm:"index"`
+	Parent      *Role   `gorm:"foreignKey:ParentID;constraint:OnDelete:SET NULL"`
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 	Permissions []Permission `gorm:"many2many:role_permissions"`
@@ -31,16 +47,20 @@ type Role struct {
 
 type Permission struct {
 	ID          uint   `gorm:"primaryKey"`
-	Code        string `gorm:"size=100;uniqueIndex;not null"`
-	Description string `gorm:"size=255"`
+	Code        string `gorm:"size:100;uniqueIndex;not null"`
+	Description string `gorm:"size:255"`
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
 
+// UserRole is a role grant; grants with ExpiresAt set stop counting once it passes.
 type UserRole struct {
-	UserID    uint `gorm:"primaryKey"`
-	RoleID    uint `gorm:"primaryKey"`
+	UserID    uint       `gorm:"primaryKey"`
+	RoleID    uint       `gorm:"primaryKey"`
+	ExpiresAt *time.Time `gorm:"index"`
+	GrantedBy *uint
 	CreatedAt time.Time
+	Role      Role `gorm:"foreignKey:RoleID"`
 }
 
 type RolePermission struct {
@@ -48,3 +68,239 @@ type RolePermission struct {
 	PermissionID uint `gorm:"primaryKey"`
 	CreatedAt    time.Time
 }
+
+// Group bundles users so that roles can be granted to a whole team; members inherit the group's roles.
+// Tables are prefixed with user_ because GROUPS is a reserved word in MySQL 8.
+type Group struct {
+	ID          uint   `gorm:"primaryKey"`
+	Name        string `gorm:"size:50;uniqueIndex;not null"`
+	Description string `gorm:"size:255"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Roles       []Role `gorm:"many2many:user_group_roles"`
+}
+
+func (Group) TableName() string {
+	return "user_groups"
+}
+
+type GroupMember struct {
+	GroupID   uint `gorm:"primaryKey"`
+	UserID    uint `gorm:"primaryKey;index"`
+	CreatedAt time.Time
+	Group     Group `gorm:"foreignKey:GroupID;constraint:OnDelete:CASCADE"`
+}
+
+func (GroupMember) TableName() string {
+	return "user_group_members"
+}
+
+type GroupRole struct {
+	GroupID   uint `gorm:"primaryKey"`
+	RoleID    uint `gorm:"primaryKey;index"`
+	CreatedAt time.Time
+}
+
+func (GroupRole) TableName() string {
+	return "user_group_roles"
+}
+
+// Organization is a tenant; users join organisations through OrgMember and hold per-org roles.
+type Organization struct {
+	ID        uint   `gorm:"primaryKey"`
+	Slug      string `gorm:"size:50;uniqueIndex;not null"`
+	Name      string `gorm:"size:100;not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type OrgMember struct {
+	OrgID     uint `gorm:"primaryKey"`
+	UserID    uint `gorm:"primaryKey;index"`
+	CreatedAt time.Time
+	User      User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// OrgMemberRole is a role held only inside one organisation.
+type OrgMemberRole struct {
+	OrgID     uint `gorm:"primaryKey"`
+	UserID    uint `gorm:"primaryKey"`
+	RoleID    uint `gorm:"primaryKey;index"`
+	CreatedAt time.Time
+	Role      Role `gorm:"foreignKey:RoleID;constraint:OnDelete:CASCADE"`
+}
+
+const (
+	AuditActionRoleExpired      = "role.expired"
+	AuditActionApprovalApproved = "approval.approved"
+	AuditActionApprovalRejected = "approval.rejected"
+	AuditActionReviewRevoked    = "role.review_revoked"
+)
+
+// Access review campaign states.
+const (
+	AccessReviewOpen   = "open"
+	AccessReviewClosed = "closed"
+)
+
+// Reviewer decisions on an access review item.
+const (
+	ReviewDecisionKeep   = "keep"
+	ReviewDecisionRevoke = "revoke"
+)
+
+// Outcomes recorded on each item when its campaign closes.
+const (
+	ReviewOutcomeKept      = "kept"
+	ReviewOutcomeRevoked   = "revoked"
+	ReviewOutcomeUndecided = "undecided"
+	ReviewOutcomeGone      = "gone"
+	ReviewOutcomeSkipped   = "skipped"
+)
+
+// AccessReview is a certification campaign over the direct grants of Roles (comma-separated)
+// taken when the campaign was created.
+type AccessReview struct {
+	ID         uint      `gorm:"primaryKey"`
+	Name       string    `gorm:"size:100;not null"`
+	Roles      string    `gorm:"size:500;not null"`
+	Status     string    `gorm:"size:20;index;not null"`
+	Deadline   time.Time `gorm:"index;not null"`
+	AutoRevoke bool      `gorm:"not null;default:false"`
+	CreatedBy  *uint
+	ClosedBy   *uint
+	ClosedAt   *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	Items      []AccessReviewItem `gorm:"foreignKey:ReviewID;constraint:OnDelete:CASCADE"`
+}
+
+// AccessReviewItem is one snapshotted grant awaiting a keep/revoke decision. Username and
+// RoleName are copied so the signed-off record survives later renames or deletions.
+type AccessReviewItem struct {
+	ID             uint   `gorm:"primaryKey"`
+	ReviewID       uint   `gorm:"uniqueIndex:idx_access_review_item;not null"`
+	UserID         uint   `gorm:"uniqueIndex:idx_access_review_item;not null"`
+	RoleID         uint   `gorm:"uniqueIndex:idx_access_review_item;not null"`
+	Username       string `gorm:"size:50;not null"`
+	RoleName       string `gorm:"size:50;not null"`
+	GrantExpiresAt *time.Time
+	ReviewerID     *uint  `gorm:"index"`
+	Decision       string `gorm:"size:10;not null;default:''"`
+	DecidedBy      *uint
+	DecidedAt      *time.Time
+	Comment        string `gorm:"size:500"`
+	Outcome        string `gorm:"size:20"`
+	Error          string `gorm:"size:64"`
+}
+
+// Change request states.
+const (
+	ChangeRequestPending  = "pending"
+	ChangeRequestApproved = "approved"
+	ChangeRequestRejected = "rejected"
+	ChangeRequestExpired  = "expired"
+	ChangeRequestFailed   = "failed"
+)
+
+// ChangeRequest is a sensitive admin change held until a second admin approves it.
+// Payload is the JSON-encoded parameters of Action.
+type ChangeRequest struct {
+	ID           uint   `gorm:"primaryKey"`
+	Action       string `gorm:"size:64;not null"`
+	TargetUserID uint   `gorm:"index;not null"`
+	Payload      string `gorm:"type:text;not null"`
+	Status       string `gorm:"size:20;index;not null"`
+	RequestedBy  uint   `gorm:"not null"`
+	ReviewedBy   *uint
+	Comment      string    `gorm:"size:500"`
+	Error        string    `gorm:"size:255"`
+	ExpiresAt    time.Time `gorm:"index;not null"`
+	ReviewedAt   *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Webhook delivery states.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookSubscription receives the events listed in Events (comma-separated, "*" for all),
+// signed with Secret.
+type WebhookSubscription struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"size:100;not null"`
+	URL       string `gorm:"size:500;not null"`
+	Secret    string `gorm:"size:100;not null"`
+	Events    string `gorm:"size:500;not null"`
+	Active    bool   `gorm:"not null;default:true"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// WebhookDelivery is one event queued for one subscription, with the outcome of its last attempt.
+// Payload holds the exact JSON body that is signed and sent on every attempt.
+type WebhookDelivery struct {
+	ID             uint      `gorm:"primaryKey"`
+	SubscriptionID uint      `gorm:"index;not null"`
+	EventID        string    `gorm:"size:64;index;not null"`
+	Event          string    `gorm:"size:64;not null"`
+	Payload        string    `gorm:"type:text;not null"`
+	Status         string    `gorm:"size:20;index;not null"`
+	Attempts       int       `gorm:"not null;default:0"`
+	NextAttemptAt  time.Time `gorm:"index;not null"`
+	LastStatusCode int
+	LastError      string `gorm:"size:500"`
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Subscription   WebhookSubscription `gorm:"foreignKey:SubscriptionID;constraint:OnDelete:CASCADE"`
+}
+
+// Outbox event states.
+const (
+	OutboxPending   = "pending"
+	OutboxPublished = "published"
+)
+
+// OutboxEvent is a domain event written in the same transaction as the change it describes and
+// published to the sinks after commit. Sinks lists (comma-separated) the sinks that already
+// accepted it, so a retry only goes to the ones that failed.
+type OutboxEvent struct {
+	ID            uint       `gorm:"primaryKey"`
+	EventID       string     `gorm:"size:64;uniqueIndex;not null"`
+	Type          string     `gorm:"size:64;not null"`
+	AggregateKey  string     `gorm:"size:64"`
+	Payload       string     `gorm:"type:text;not null"`
+	Status        string     `gorm:"size:20;index;not null"`
+	Attempts      int        `gorm:"not null;default:0"`
+	NextAttemptAt time.Time  `gorm:"index;not null"`
+	Sinks         string     `gorm:"size:255;not null;default:''"`
+	LastError     string     `gorm:"size:500"`
+	PublishedAt   *time.Time `gorm:"index"`
+	CreatedAt     time.Time
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox"
+}
+
+// AuditLog records administrative and system changes; ActorID is nil for system actions.
+type AuditLog struct {
+	ID           uint   `gorm:"primaryKey"`
+	ActorID      *uint  `gorm:"index"`
+	Action       string `gorm:"size:64;index;not null"`
+	TargetUserID *uint  `gorm:"index"`
+	Details      string `gorm:"type:text"`
+	CreatedAt    time.Time
+}
+
+// SchemaMigration records a migration under db/migrations that has been applied; Version is the
+// numeric prefix of its file name.
+type SchemaMigration struct {
+	Version   uint      `gorm:"primaryKey;autoIncrement:false"`
+	AppliedAt time.Time `gorm:"not null"`
+}
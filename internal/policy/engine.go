@@ -0,0 +1,117 @@
+package policy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Decision is the outcome of evaluating a request, with enough detail to explain it.
+type Decision struct {
+	Allowed bool
+	// DecidingRule is the rule that produced the effect; empty when the default effect applied.
+	DecidingRule string
+	Matched      []Rule
+	Version      string
+}
+
+type snapshot struct {
+	set     *Set
+	version string
+}
+
+// Engine evaluates requests against the current policy set; the set is swapped atomically on reload.
+type Engine struct {
+	path    string
+	current atomic.Pointer[snapshot]
+
+	mu      sync.Mutex
+	modTime time.Time
+}
+
+// NewEngine loads the policy file. An empty path yields an engine that allows everything.
+func NewEngine(path string) (*Engine, error) {
+	e := &Engine{path: path}
+	e.current.Store(&snapshot{set: &Set{DefaultEffect: EffectAllow}, version: "none"})
+	if path == "" {
+		return e, nil
+	}
+	if _, err := e.Reload(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Reload re-reads the policy file when its modification time changed. A file that fails to
+// parse leaves the previous set in force and returns the error.
+func (e *Engine) Reload() (bool, error) {
+	if e.path == "" {
+		return false, nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	info, err := os.Stat(e.path)
+	if err != nil {
+		return false, err
+	}
+	if info.ModTime().Equal(e.modTime) {
+		return false, nil
+	}
+
+	content, err := os.ReadFile(e.path)
+	if err != nil {
+		return false, err
+	}
+	set, err := Parse(content)
+	if err != nil {
+		return false, err
+	}
+
+	sum := sha256.Sum256(content)
+	e.current.Store(&snapshot{set: set, version: hex.EncodeToString(sum[:6])})
+	e.modTime = info.ModTime()
+	return true, nil
+}
+
+// Version identifies the policy set in force.
+func (e *Engine) Version() string {
+	return e.current.Load().version
+}
+
+// Evaluate applies deny-overrides: any matching deny rule denies, else any matching allow rule
+// allows, else the default effect decides.
+func (e *Engine) Evaluate(req Request) Decision {
+	snap := e.current.Load()
+	decision := Decision{Version: snap.version, Matched: make([]Rule, 0)}
+
+	var allowRule, denyRule string
+	for i := range snap.set.Rules {
+		rule := &snap.set.Rules[i]
+		if !rule.matchesAction(req.Action) || !rule.holds(&req) {
+			continue
+		}
+		decision.Matched = append(decision.Matched, *rule)
+		if rule.Effect == EffectDeny && denyRule == "" {
+			denyRule = rule.ID
+		}
+		if rule.Effect == EffectAllow && allowRule == "" {
+			allowRule = rule.ID
+		}
+	}
+
+	switch {
+	case denyRule != "":
+		decision.DecidingRule = denyRule
+	case allowRule != "":
+		decision.Allowed = true
+		decision.DecidingRule = allowRule
+	default:
+		decision.Allowed = snap.set.DefaultEffect == EffectAllow
+	}
+	return decision
+}
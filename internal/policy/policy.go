@@ -0,0 +1,243 @@
+package policy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/conf"
+)
+
+// Effects a rule can produce.
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+// Actions evaluated by the admin logic.
+const (
+	ActionUserStatusUpdate = "user.status.update"
+	ActionUserRolesAssign  = "user.roles.assign"
+)
+
+// Condition operators.
+const (
+	OpEq          = "eq"
+	OpNe          = "ne"
+	OpIn          = "in"
+	OpNotIn       = "notIn"
+	OpContains    = "contains"
+	OpNotContains = "notContains"
+	OpEmpty       = "empty"
+	OpNotEmpty    = "notEmpty"
+)
+
+// Set is the declarative policy document.
+type Set struct {
+	DefaultEffect string `json:"defaultEffect,default=allow"`
+	Rules         []Rule `json:"rules,optional"`
+}
+
+// Rule applies Effect when the action matches and every condition holds.
+type Rule struct {
+	ID          string      `json:"id"`
+	Description string      `json:"description,optional"`
+	Effect      string      `json:"effect"`
+	Actions     []string    `json:"actions"`
+	Conditions  []Condition `json:"conditions,optional"`
+}
+
+// Condition compares the attribute at Attr with a literal (Value/Values) or another attribute (Ref).
+// Attribute paths are prefixed with subject., resource. or action.
+type Condition struct {
+	Attr   string   `json:"attr"`
+	Op     string   `json:"op"`
+	Value  string   `json:"value,optional"`
+	Values []string `json:"values,optional"`
+	Ref    string   `json:"ref,optional"`
+}
+
+// Attributes holds the attributes of one side of a request: strings, numbers, bools or string lists.
+type Attributes map[string]interface{}
+
+// Request is one authorisation question.
+type Request struct {
+	Action   string
+	Subject  Attributes
+	Resource Attributes
+	Params   Attributes
+}
+
+// Parse loads a policy document from YAML and validates it.
+func Parse(content []byte) (*Set, error) {
+	var set Set
+	if err := conf.LoadFromYamlBytes(content, &set); err != nil {
+		return nil, err
+	}
+	if err := set.validate(); err != nil {
+		return nil, err
+	}
+	return &set, nil
+}
+
+func (s *Set) validate() error {
+	if s.DefaultEffect != EffectAllow && s.DefaultEffect != EffectDeny {
+		return fmt.Errorf("defaultEffect must be allow or deny, got %q", s.DefaultEffect)
+	}
+	seen := make(map[string]struct{}, len(s.Rules))
+	for _, rule := range s.Rules {
+		if rule.ID == "" {
+			return fmt.Errorf("rule without id")
+		}
+		if _, ok := seen[rule.ID]; ok {
+			return fmt.Errorf("duplicate rule id %q", rule.ID)
+		}
+		seen[rule.ID] = struct{}{}
+		if rule.Effect != EffectAllow && rule.Effect != EffectDeny {
+			return fmt.Errorf("rule %q: effect must be allow or deny", rule.ID)
+		}
+		if len(rule.Actions) == 0 {
+			return fmt.Errorf("rule %q: actions missing", rule.ID)
+		}
+		for _, cond := range rule.Conditions {
+			if err := cond.validate(); err != nil {
+				return fmt.Errorf("rule %q: %w", rule.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Condition) validate() error {
+	if !validPath(c.Attr) {
+		return fmt.Errorf("invalid attr %q", c.Attr)
+	}
+	if c.Ref != "" && !validPath(c.Ref) {
+		return fmt.Errorf("invalid ref %q", c.Ref)
+	}
+	switch c.Op {
+	case OpEq, OpNe, OpContains, OpNotContains, OpIn, OpNotIn, OpEmpty, OpNotEmpty:
+		return nil
+	}
+	return fmt.Errorf("unknown op %q", c.Op)
+}
+
+func validPath(path string) bool {
+	prefix, name, ok := strings.Cut(path, ".")
+	if !ok || name == "" {
+		return false
+	}
+	return prefix == "subject" || prefix == "resource" || prefix == "action"
+}
+
+// matchesAction reports whether the rule covers action; "*" covers every action.
+func (r *Rule) matchesAction(action string) bool {
+	for _, candidate := range r.Actions {
+		if candidate == "*" || candidate == action {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) holds(req *Request) bool {
+	for i := range r.Conditions {
+		if !r.Conditions[i].holds(req) {
+			return false
+		}
+	}
+	return true
+}
+
+// holds evaluates the condition. A missing attribute never satisfies eq/in/contains and always
+// satisfies their negations, so deny rules written with ne/notIn fail closed.
+func (c *Condition) holds(req *Request) bool {
+	left, present := lookup(req, c.Attr)
+
+	var operand []string
+	operandPresent := true
+	switch {
+	case c.Ref != "":
+		operand, operandPresent = lookup(req, c.Ref)
+	case len(c.Values) > 0:
+		operand = c.Values
+	default:
+		operand = []string{c.Value}
+	}
+
+	switch c.Op {
+	case OpEmpty:
+		return !present || len(left) == 0
+	case OpNotEmpty:
+		return present && len(left) > 0
+	case OpEq:
+		return present && operandPresent && len(left) == 1 && len(operand) == 1 && strings.EqualFold(left[0], operand[0])
+	case OpNe:
+		return !(present && operandPresent && len(left) == 1 && len(operand) == 1 && strings.EqualFold(left[0], operand[0]))
+	case OpIn:
+		return present && operandPresent && len(left) == 1 && containsFold(operand, left[0])
+	case OpNotIn:
+		return !(present && operandPresent && len(left) == 1 && containsFold(operand, left[0]))
+	case OpContains:
+		return present && operandPresent && containsAll(left, operand)
+	case OpNotContains:
+		return !(present && operandPresent && containsAll(left, operand))
+	}
+	return false
+}
+
+// lookup resolves a dotted attribute path to its string values; present is false for missing or empty scalars.
+func lookup(req *Request, path string) ([]string, bool) {
+	prefix, name, _ := strings.Cut(path, ".")
+	var attrs Attributes
+	switch prefix {
+	case "subject":
+		attrs = req.Subject
+	case "resource":
+		attrs = req.Resource
+	case "action":
+		if name == "name" {
+			return []string{req.Action}, true
+		}
+		attrs = req.Params
+	}
+
+	value, ok := attrs[name]
+	if !ok || value == nil {
+		return nil, false
+	}
+	switch v := value.(type) {
+	case []string:
+		return v, true
+	case string:
+		if v == "" {
+			return nil, false
+		}
+		return []string{v}, true
+	case uint:
+		if v == 0 {
+			return nil, false
+		}
+		return []string{strconv.FormatUint(uint64(v), 10)}, true
+	default:
+		return []string{fmt.Sprint(v)}, true
+	}
+}
+
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAll(list, values []string) bool {
+	for _, value := range values {
+		if !containsFold(list, value) {
+			return false
+		}
+	}
+	return true
+}
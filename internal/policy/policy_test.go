@@ -0,0 +1,222 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testPolicy = `
+defaultEffect: allow
+rules:
+  - id: no-self-status
+    effect: deny
+    actions: [user.status.update]
+    conditions:
+      - attr: subject.id
+        op: eq
+        ref: resource.id
+  - id: support-own-tenant
+    effect: allow
+    actions: [user.roles.assign]
+    conditions:
+      - attr: subject.roles
+        op: contains
+        value: support
+      - attr: subject.tenant
+        op: eq
+        ref: resource.tenant
+  - id: protect-admins
+    effect: deny
+    actions: ["*"]
+    conditions:
+      - attr: resource.roles
+        op: contains
+        value: admin
+      - attr: subject.roles
+        op: notContains
+        value: admin
+`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		valid   bool
+		wantErr string
+	}{
+		{name: "valid", content: testPolicy, valid: true},
+		{name: "empty document", content: "rules: []", valid: true},
+		{name: "bad default", content: "defaultEffect: maybe", wantErr: "defaultEffect"},
+		{name: "missing id", content: "rules:\n  - id: ''\n    effect: deny\n    actions: ['*']", wantErr: "without id"},
+		{name: "duplicate id", content: "rules:\n  - {id: a, effect: deny, actions: ['*']}\n  - {id: a, effect: allow, actions: ['*']}", wantErr: "duplicate"},
+		{name: "bad effect", content: "rules:\n  - {id: a, effect: permit, actions: ['*']}", wantErr: "effect"},
+		{name: "no actions", content: "rules:\n  - {id: a, effect: deny, actions: []}", wantErr: "actions"},
+		{name: "bad attr prefix", content: "rules:\n  - id: a\n    effect: deny\n    actions: ['*']\n    conditions:\n      - {attr: user.id, op: eq, value: '1'}", wantErr: "invalid attr"},
+		{name: "bad ref", content: "rules:\n  - id: a\n    effect: deny\n    actions: ['*']\n    conditions:\n      - {attr: subject.id, op: eq, ref: subject}", wantErr: "invalid ref"},
+		{name: "unknown op", content: "rules:\n  - id: a\n    effect: deny\n    actions: ['*']\n    conditions:\n      - {attr: subject.id, op: like, value: '1'}", wantErr: "unknown op"},
+		{name: "malformed yaml", content: "rules: ["},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := Parse([]byte(tt.content))
+			if tt.valid {
+				if err != nil {
+					t.Fatalf("Parse err = %v", err)
+				}
+				if set.DefaultEffect != EffectAllow {
+					t.Fatalf("DefaultEffect = %q, want %q", set.DefaultEffect, EffectAllow)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Parse err = nil, want error %q", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Parse err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	engine := newTestEngine(t, testPolicy)
+	tests := []struct {
+		name     string
+		req      Request
+		allowed  bool
+		deciding string
+		matched  int
+	}{
+		{
+			name:     "self status change denied",
+			req:      Request{Action: ActionUserStatusUpdate, Subject: Attributes{"id": uint(7)}, Resource: Attributes{"id": uint(7)}},
+			deciding: "no-self-status",
+			matched:  1,
+		},
+		{
+			name:    "default effect applies",
+			req:     Request{Action: ActionUserStatusUpdate, Subject: Attributes{"id": uint(7)}, Resource: Attributes{"id": uint(8)}},
+			allowed: true,
+		},
+		{
+			name:    "missing ref never equals",
+			req:     Request{Action: ActionUserStatusUpdate, Subject: Attributes{"id": uint(7)}, Resource: Attributes{}},
+			allowed: true,
+		},
+		{
+			name:     "allow rule decides",
+			req:      Request{Action: ActionUserRolesAssign, Subject: Attributes{"roles": []string{"Support"}, "tenant": "acme"}, Resource: Attributes{"tenant": "ACME"}},
+			allowed:  true,
+			deciding: "support-own-tenant",
+			matched:  1,
+		},
+		{
+			name: "deny overrides allow",
+			req: Request{
+				Action:   ActionUserRolesAssign,
+				Subject:  Attributes{"roles": []string{"support"}, "tenant": "acme"},
+				Resource: Attributes{"tenant": "acme", "roles": []string{"admin"}},
+			},
+			deciding: "protect-admins",
+			matched:  2,
+		},
+		{
+			name:    "wildcard deny skipped for admins",
+			req:     Request{Action: ActionUserStatusUpdate, Subject: Attributes{"id": uint(1), "roles": []string{"admin"}}, Resource: Attributes{"id": uint(2), "roles": []string{"admin"}}},
+			allowed: true,
+		},
+		{
+			name:     "missing subject roles fail closed",
+			req:      Request{Action: ActionUserStatusUpdate, Subject: Attributes{"id": uint(1)}, Resource: Attributes{"id": uint(2), "roles": []string{"admin"}}},
+			deciding: "protect-admins",
+			matched:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := engine.Evaluate(tt.req)
+			if decision.Allowed != tt.allowed || decision.DecidingRule != tt.deciding || len(decision.Matched) != tt.matched {
+				t.Fatalf("Evaluate = {allowed %v, rule %q, matched %d}, want {allowed %v, rule %q, matched %d}",
+					decision.Allowed, decision.DecidingRule, len(decision.Matched), tt.allowed, tt.deciding, tt.matched)
+			}
+			if decision.Version != engine.Version() {
+				t.Fatalf("Version = %q, want %q", decision.Version, engine.Version())
+			}
+		})
+	}
+}
+
+func TestConditionOperators(t *testing.T) {
+	req := &Request{
+		Action:   ActionUserRolesAssign,
+		Subject:  Attributes{"roles": []string{"admin", "support"}, "status": "active", "mfa": true},
+		Resource: Attributes{"status": ""},
+		Params:   Attributes{"role": "viewer"},
+	}
+	tests := []struct {
+		cond Condition
+		want bool
+	}{
+		{cond: Condition{Attr: "subject.status", Op: OpEq, Value: "ACTIVE"}, want: true},
+		{cond: Condition{Attr: "subject.status", Op: OpNe, Value: "active"}, want: false},
+		{cond: Condition{Attr: "subject.mfa", Op: OpEq, Value: "true"}, want: true},
+		{cond: Condition{Attr: "action.name", Op: OpEq, Value: ActionUserRolesAssign}, want: true},
+		{cond: Condition{Attr: "action.role", Op: OpIn, Values: []string{"viewer", "support"}}, want: true},
+		{cond: Condition{Attr: "action.role", Op: OpNotIn, Values: []string{"admin"}}, want: true},
+		{cond: Condition{Attr: "subject.roles", Op: OpContains, Values: []string{"admin", "support"}}, want: true},
+		{cond: Condition{Attr: "subject.roles", Op: OpNotContains, Value: "auditor"}, want: true},
+		{cond: Condition{Attr: "resource.status", Op: OpEmpty}, want: true},
+		{cond: Condition{Attr: "resource.missing", Op: OpNotEmpty}, want: false},
+		{cond: Condition{Attr: "resource.missing", Op: OpEq, Value: ""}, want: false},
+		{cond: Condition{Attr: "resource.missing", Op: OpNe, Value: "x"}, want: true},
+		{cond: Condition{Attr: "subject.roles", Op: OpEq, Value: "admin"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cond.Attr+" "+tt.cond.Op, func(t *testing.T) {
+			if got := tt.cond.holds(req); got != tt.want {
+				t.Fatalf("%+v holds = %v, want %v", tt.cond, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngineReloadKeepsLastGoodSet(t *testing.T) {
+	engine := newTestEngine(t, testPolicy)
+	version := engine.Version()
+
+	if err := os.WriteFile(engine.path, []byte("defaultEffect: maybe"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	engine.modTime = engine.modTime.Add(-1)
+	if _, err := engine.Reload(); err == nil {
+		t.Fatal("Reload err = nil, want parse error")
+	}
+	if engine.Version() != version {
+		t.Fatalf("Version = %q after failed reload, want %q", engine.Version(), version)
+	}
+}
+
+func TestEmptyEngineAllows(t *testing.T) {
+	engine, err := NewEngine("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decision := engine.Evaluate(Request{Action: ActionUserStatusUpdate}); !decision.Allowed || decision.DecidingRule != "" {
+		t.Fatalf("Evaluate = %+v, want default allow", decision)
+	}
+}
+
+func newTestEngine(t *testing.T, content string) *Engine {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	engine, err := NewEngine(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return engine
+}
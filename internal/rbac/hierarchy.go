@@ -0,0 +1,128 @@
+package rbac
+
+import (
+	"sort"
+	"strings"
+
+	"usermgmt/internal/model"
+)
+
+// Hierarchy is an in-memory view of roles where each role inherits every permission of its parent.
+// It is built from a full role list with Permissions preloaded and is safe for concurrent reads.
+type Hierarchy struct {
+	byID   map[uint]*model.Role
+	byName map[string]*model.Role
+}
+
+// NewHierarchy indexes roles by ID and case-insensitive name.
+func NewHierarchy(roles []model.Role) *Hierarchy {
+	h := &Hierarchy{
+		byID:   make(map[uint]*model.Role, len(roles)),
+		byName: make(map[string]*model.Role, len(roles)),
+	}
+	for i := range roles {
+		role := &roles[i]
+		h.byID[role.ID] = role
+		h.byName[strings.ToLower(role.Name)] = role
+	}
+	return h
+}
+
+// Role looks a role up by name.
+func (h *Hierarchy) Role(name string) (*model.Role, bool) {
+	role, ok := h.byName[strings.ToLower(strings.TrimSpace(name))]
+	return role, ok
+}
+
+// RoleByID looks a role up by ID.
+func (h *Hierarchy) RoleByID(id uint) (*model.Role, bool) {
+	role, ok := h.byID[id]
+	return role, ok
+}
+
+// Chain returns the role followed by its ancestors, nearest first.
+// A corrupt cycle in stored data terminates the walk instead of looping.
+func (h *Hierarchy) Chain(roleID uint) []*model.Role {
+	chain := make([]*model.Role, 0, 4)
+	seen := make(map[uint]struct{})
+	for current, ok := h.byID[roleID]; ok; {
+		if _, dup := seen[current.ID]; dup {
+			break
+		}
+		seen[current.ID] = struct{}{}
+		chain = append(chain, current)
+		if current.ParentID == nil {
+			break
+		}
+		current, ok = h.byID[*current.ParentID]
+	}
+	return chain
+}
+
+// WouldCycle reports whether making parentID the parent of roleID creates a cycle.
+func (h *Hierarchy) WouldCycle(roleID, parentID uint) bool {
+	for _, ancestor := range h.Chain(parentID) {
+		if ancestor.ID == roleID {
+			return true
+		}
+	}
+	return false
+}
+
+// EffectivePermissions returns the sorted union of permissions granted by roleIDs and their ancestors.
+func (h *Hierarchy) EffectivePermissions(roleIDs []uint) []string {
+	set := make(map[string]struct{})
+	for _, id := range roleIDs {
+		for _, role := range h.Chain(id) {
+			for _, perm := range role.Permissions {
+				set[perm.Code] = struct{}{}
+			}
+		}
+	}
+	result := make([]string, 0, len(set))
+	for code := range set {
+		result = append(result, code)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Path explains one way a permission is obtained: Chain runs from the held role up to the role
+// that carries the permission directly (the last element).
+type Path struct {
+	Chain []string
+}
+
+// Explain lists every held role whose chain grants code, with the chain that grants it.
+func (h *Hierarchy) Explain(roleIDs []uint, code string) []Path {
+	paths := make([]Path, 0)
+	for _, id := range roleIDs {
+		names := make([]string, 0, 4)
+		for _, role := range h.Chain(id) {
+			names = append(names, role.Name)
+			if hasPermission(role, code) {
+				paths = append(paths, Path{Chain: append([]string(nil), names...)})
+				break
+			}
+		}
+	}
+	return paths
+}
+
+func hasPermission(role *model.Role, code string) bool {
+	for _, perm := range role.Permissions {
+		if strings.EqualFold(perm.Code, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// Roles returns every role in the hierarchy in no particular order.
+func (h *Hierarchy) Roles() []*model.Role {
+	roles := make([]*model.Role, 0, len(h.byID))
+	for _, role := range h.byID {
+		roles = append(roles, role)
+	}
+	return roles
+}
@@ -0,0 +1,108 @@
+package rbac
+
+import (
+	"reflect"
+	"testing"
+
+	"usermgmt/internal/model"
+)
+
+func parent(id uint) *uint { return &id }
+
+// testRoles is admin(1) <- editor(2) <- viewer(3), plus a standalone auditor(4) and a corrupt
+// loop loopA(5) <-> loopB(6).
+func testRoles() []model.Role {
+	return []model.Role{
+		{ID: 1, Name: "admin", Permissions: []model.Permission{{Code: "users.delete"}}},
+		{ID: 2, Name: "editor", ParentID: parent(1), Permissions: []model.Permission{{Code: "users.write"}}},
+		{ID: 3, Name: "viewer", ParentID: parent(2), Permissions: []model.Permission{{Code: "users.read"}}},
+		{ID: 4, Name: "auditor", Permissions: []model.Permission{{Code: "audit.read"}, {Code: "users.read"}}},
+		{ID: 5, Name: "loopA", ParentID: parent(6)},
+		{ID: 6, Name: "loopB", ParentID: parent(5)},
+	}
+}
+
+func chainNames(roles []*model.Role) []string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	return names
+}
+
+func TestChain(t *testing.T) {
+	h := NewHierarchy(testRoles())
+	tests := []struct {
+		name   string
+		roleID uint
+		want   []string
+	}{
+		{name: "root role", roleID: 1, want: []string{"admin"}},
+		{name: "nearest first", roleID: 3, want: []string{"viewer", "editor", "admin"}},
+		{name: "corrupt cycle terminates", roleID: 5, want: []string{"loopA", "loopB"}},
+		{name: "unknown role", roleID: 99, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := chainNames(h.Chain(tt.roleID)); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Chain(%d) = %v, want %v", tt.roleID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWouldCycle(t *testing.T) {
+	h := NewHierarchy(testRoles())
+	tests := []struct {
+		name             string
+		roleID, parentID uint
+		want             bool
+	}{
+		{name: "new branch", roleID: 4, parentID: 1, want: false},
+		{name: "descendant as parent", roleID: 1, parentID: 3, want: true},
+		{name: "self as parent", roleID: 2, parentID: 2, want: true},
+		{name: "keep existing parent", roleID: 3, parentID: 2, want: false},
+		{name: "unknown parent", roleID: 1, parentID: 99, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := h.WouldCycle(tt.roleID, tt.parentID); got != tt.want {
+				t.Fatalf("WouldCycle(%d, %d) = %v, want %v", tt.roleID, tt.parentID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEffectivePermissions(t *testing.T) {
+	h := NewHierarchy(testRoles())
+	tests := []struct {
+		name    string
+		roleIDs []uint
+		want    []string
+	}{
+		{name: "inherits ancestors", roleIDs: []uint{3}, want: []string{"users.delete", "users.read", "users.write"}},
+		{name: "union without duplicates", roleIDs: []uint{4, 3}, want: []string{"audit.read", "users.delete", "users.read", "users.write"}},
+		{name: "no roles", roleIDs: nil, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := h.EffectivePermissions(tt.roleIDs); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("EffectivePermissions(%v) = %v, want %v", tt.roleIDs, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExplain(t *testing.T) {
+	h := NewHierarchy(testRoles())
+	got := h.Explain([]uint{3, 4}, "USERS.READ")
+	want := []Path{{Chain: []string{"viewer"}}, {Chain: []string{"auditor"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Explain = %v, want %v", got, want)
+	}
+	got = h.Explain([]uint{3}, "users.delete")
+	want = []Path{{Chain: []string{"viewer", "editor", "admin"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Explain inherited = %v, want %v", got, want)
+	}
+}
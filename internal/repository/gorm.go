@@ -0,0 +1,287 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"usermgmt/internal/dialect"
+	"usermgmt/internal/event"
+	"usermgmt/internal/model"
+)
+
+// PreloadActiveRoles is a query scope loading only unexpired grants together with their roles,
+// plus group memberships and the roles those groups carry.
+func PreloadActiveRoles(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("RoleGrants", "expires_at IS NULL OR expires_at > ?", time.Now()).
+		Preload("RoleGrants.Role").
+		Preload("Groups.Group.Roles")
+}
+
+// FilterUsers narrows a users query by status and by a keyword matched against username, email
+// and full name.
+func FilterUsers(query *gorm.DB, keyword, status string) *gorm.DB {
+	if status = strings.TrimSpace(status); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if keyword = strings.TrimSpace(keyword); keyword != "" {
+		query = dialect.ContainsFold(query, keyword, "username", "email", "full_name")
+	}
+	return query
+}
+
+// GormStore is the Store backed by a GORM connection or transaction.
+type GormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore wraps db; passing a transaction makes every repository use it.
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{db: db}
+}
+
+func (s *GormStore) Users() UserRepository {
+	return gormUsers{db: s.db}
+}
+
+func (s *GormStore) Roles() RoleRepository {
+	return gormRoles{db: s.db}
+}
+
+func (s *GormStore) ChangeRequests() ChangeRequestRepository {
+	return gormChangeRequests{db: s.db}
+}
+
+func (s *GormStore) Events() EventRecorder {
+	return gormEvents{db: s.db}
+}
+
+func (s *GormStore) Transaction(ctx context.Context, fn func(Store) error) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(NewGormStore(tx))
+	})
+}
+
+type gormUsers struct {
+	db *gorm.DB
+}
+
+func (r gormUsers) Get(ctx context.Context, id uint) (*model.User, error) {
+	var user model.User
+	if err := r.db.WithContext(ctx).Scopes(PreloadActiveRoles).First(&user, id).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &user, nil
+}
+
+func (r gormUsers) GetByUsername(ctx context.Context, username string) (*model.User, error) {
+	var user model.User
+	if err := r.db.WithContext(ctx).Scopes(PreloadActiveRoles).Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &user, nil
+}
+
+func (r gormUsers) List(ctx context.Context, q UserQuery) ([]model.User, int64, error) {
+	query := FilterUsers(r.db.WithContext(ctx).Model(&model.User{}), q.Keyword, q.Status)
+	if q.OrgID != nil {
+		query = query.Where("users.id IN (SELECT user_id FROM org_members WHERE org_id = ?)", *q.OrgID)
+	}
+	if group := strings.TrimSpace(q.Group); group != "" {
+		query = query.Where(
+			"users.id IN (SELECT m.user_id FROM user_group_members m JOIN user_groups g ON g.id = m.group_id WHERE g.name = ?)",
+			group,
+		)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = query.Scopes(PreloadActiveRoles).Order("created_at DESC").Offset(q.Offset)
+	if q.Limit > 0 {
+		query = query.Limit(q.Limit)
+	}
+	var users []model.User
+	if err := query.Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+func (r gormUsers) Exists(ctx context.Context, username, email string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.User{}).
+		Where("username = ? OR email = ?", username, email).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r gormUsers) InOrg(ctx context.Context, id, orgID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.OrgMember{}).
+		Where("org_id = ? AND user_id = ?", orgID, id).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r gormUsers) Create(ctx context.Context, user *model.User) error {
+	return translate(r.db.WithContext(ctx).Create(user).Error)
+}
+
+func (r gormUsers) UpdateStatus(ctx context.Context, id uint, status string) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("status", status).Error
+}
+
+func (r gormUsers) UpdateProfile(ctx context.Context, id uint, email, fullName string) error {
+	return translate(r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"email":     email,
+		"full_name": fullName,
+	}).Error)
+}
+
+func (r gormUsers) UpdatePasswordHash(ctx context.Context, id uint, hash string) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("password_hash", hash).Error
+}
+
+func (r gormUsers) TouchLogin(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("last_login_at", at).Error
+}
+
+func (r gormUsers) BumpRoleVersion(ctx context.Context, id uint, expected *uint) error {
+	query := r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id)
+	if expected != nil {
+		query = query.Where("role_version = ?", *expected)
+	}
+	result := query.Update("role_version", gorm.Expr("role_version + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+func (r gormUsers) CountActiveHolders(ctx context.Context, role string) (int64, error) {
+	db := r.db.WithContext(ctx)
+	var count int64
+	err := db.Model(&model.User{}).
+		Where("status = ?", model.UserStatusEnabled).
+		Where(
+			db.Where("id IN (?)", db.Table("user_roles AS ur").
+				Select("ur.user_id").
+				Joins("JOIN roles r ON r.id = ur.role_id").
+				Where("r.name = ? AND (ur.expires_at IS NULL OR ur.expires_at > ?)", role, time.Now())).
+				Or("id IN (?)", db.Table("user_group_members AS m").
+					Select("m.user_id").
+					Joins("JOIN user_group_roles gr ON gr.group_id = m.group_id").
+					Joins("JOIN roles r ON r.id = gr.role_id").
+					Where("r.name = ?", role)),
+		).
+		Count(&count).Error
+	return count, err
+}
+
+type gormRoles struct {
+	db *gorm.DB
+}
+
+func (r gormRoles) List(ctx context.Context) ([]model.Role, error) {
+	var roles []model.Role
+	err := r.db.WithContext(ctx).Order("name").Find(&roles).Error
+	return roles, err
+}
+
+func (r gormRoles) GetByName(ctx context.Context, name string) (*model.Role, error) {
+	var role model.Role
+	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&role).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &role, nil
+}
+
+func (r gormRoles) FindByNames(ctx context.Context, names []string) ([]model.Role, error) {
+	var roles []model.Role
+	if len(names) == 0 {
+		return roles, nil
+	}
+	err := r.db.WithContext(ctx).Where("name IN ?", names).Find(&roles).Error
+	return roles, err
+}
+
+func (r gormRoles) Lock(ctx context.Context, name string) error {
+	return r.db.WithContext(ctx).Model(&model.Role{}).
+		Select("id").
+		Where("name = ?", name).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Find(&[]model.Role{}).Error
+}
+
+func (r gormRoles) ReplaceUserRoles(ctx context.Context, userID uint, roles []model.Role, grantedBy *uint) error {
+	db := r.db.WithContext(ctx)
+	stale := db.Where("user_id = ?", userID)
+	if len(roles) > 0 {
+		keep := make([]uint, 0, len(roles))
+		for _, role := range roles {
+			keep = append(keep, role.ID)
+		}
+		stale = stale.Where("role_id NOT IN ? OR expires_at <= ?", keep, time.Now())
+	}
+	if err := stale.Delete(&model.UserRole{}).Error; err != nil {
+		return err
+	}
+	if len(roles) == 0 {
+		return nil
+	}
+	grants := make([]model.UserRole, 0, len(roles))
+	for _, role := range roles {
+		grants = append(grants, model.UserRole{UserID: userID, RoleID: role.ID, GrantedBy: grantedBy})
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&grants).Error
+}
+
+func (r gormRoles) Grant(ctx context.Context, grant model.UserRole) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "role_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"expires_at", "granted_by"}),
+	}).Create(&grant).Error
+}
+
+func (r gormRoles) Revoke(ctx context.Context, userID, roleID uint) (bool, error) {
+	result := r.db.WithContext(ctx).Where("user_id = ? AND role_id = ?", userID, roleID).Delete(&model.UserRole{})
+	return result.RowsAffected > 0, result.Error
+}
+
+type gormChangeRequests struct {
+	db *gorm.DB
+}
+
+func (r gormChangeRequests) Create(ctx context.Context, request *model.ChangeRequest) error {
+	return r.db.WithContext(ctx).Create(request).Error
+}
+
+type gormEvents struct {
+	db *gorm.DB
+}
+
+func (r gormEvents) Record(ctx context.Context, e event.Event) error {
+	return event.Record(r.db.WithContext(ctx), e)
+}
+
+func translate(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return ErrDuplicate
+	}
+	return err
+}
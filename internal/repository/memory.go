@@ -0,0 +1,563 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"usermgmt/internal/event"
+	"usermgmt/internal/model"
+)
+
+// Memory is an in-process Store for unit tests. It keeps users, roles, direct grants, groups,
+// organisation memberships and change requests, and collects recorded events instead of writing
+// an outbox.
+// Transactions are serialised and roll back by restoring a snapshot.
+type Memory struct {
+	txMu sync.Mutex
+	mu   sync.Mutex
+	data memoryData
+}
+
+type memoryData struct {
+	users    map[uint]model.User
+	roles    map[uint]model.Role
+	grants   map[[2]uint]model.UserRole
+	groups   map[uint]model.Group
+	members  map[[2]uint]struct{} // group ID, user ID
+	orgs     map[[2]uint]struct{} // org ID, user ID
+	requests []model.ChangeRequest
+	events   []event.Event
+	nextID   uint
+}
+
+// NewMemory creates an empty in-memory store.
+func NewMemory() *Memory {
+	return &Memory{data: memoryData{
+		users:   make(map[uint]model.User),
+		roles:   make(map[uint]model.Role),
+		grants:  make(map[[2]uint]model.UserRole),
+		groups:  make(map[uint]model.Group),
+		members: make(map[[2]uint]struct{}),
+		orgs:    make(map[[2]uint]struct{}),
+	}}
+}
+
+func (m *Memory) Users() UserRepository {
+	return memoryUsers{m}
+}
+
+func (m *Memory) Roles() RoleRepository {
+	return memoryRoles{m}
+}
+
+func (m *Memory) ChangeRequests() ChangeRequestRepository {
+	return memoryChangeRequests{m}
+}
+
+func (m *Memory) Events() EventRecorder {
+	return memoryEvents{m}
+}
+
+func (m *Memory) Transaction(ctx context.Context, fn func(Store) error) error {
+	m.txMu.Lock()
+	defer m.txMu.Unlock()
+	return memoryTx{m}.Transaction(ctx, fn)
+}
+
+// memoryTx is the store handed to a transaction; nested transactions roll back on their own
+// like savepoints.
+type memoryTx struct {
+	*Memory
+}
+
+func (t memoryTx) Transaction(_ context.Context, fn func(Store) error) error {
+	t.mu.Lock()
+	snapshot := t.data.clone()
+	t.mu.Unlock()
+
+	if err := fn(t); err != nil {
+		t.mu.Lock()
+		t.data = snapshot
+		t.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (d memoryData) clone() memoryData {
+	c := memoryData{
+		users:    make(map[uint]model.User, len(d.users)),
+		roles:    make(map[uint]model.Role, len(d.roles)),
+		grants:   make(map[[2]uint]model.UserRole, len(d.grants)),
+		groups:   make(map[uint]model.Group, len(d.groups)),
+		members:  make(map[[2]uint]struct{}, len(d.members)),
+		orgs:     make(map[[2]uint]struct{}, len(d.orgs)),
+		requests: append([]model.ChangeRequest(nil), d.requests...),
+		events:   append([]event.Event(nil), d.events...),
+		nextID:   d.nextID,
+	}
+	for k, v := range d.users {
+		c.users[k] = v
+	}
+	for k, v := range d.roles {
+		c.roles[k] = v
+	}
+	for k, v := range d.grants {
+		c.grants[k] = v
+	}
+	for k, v := range d.groups {
+		c.groups[k] = v
+	}
+	for k := range d.members {
+		c.members[k] = struct{}{}
+	}
+	for k := range d.orgs {
+		c.orgs[k] = struct{}{}
+	}
+	return c
+}
+
+func (m *Memory) newID() uint {
+	m.data.nextID++
+	return m.data.nextID
+}
+
+// AddRole seeds a role and returns it with its ID.
+func (m *Memory) AddRole(name string) model.Role {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	role := model.Role{ID: m.newID(), Name: name, CreatedAt: now, UpdatedAt: now}
+	m.data.roles[role.ID] = role
+	return role
+}
+
+// AddUser seeds user, defaulting its status and auth source, grants it the named roles without
+// expiry and returns it with its ID. Unknown role names are ignored.
+func (m *Memory) AddUser(user model.User, roles ...string) model.User {
+	if err := m.Users().Create(context.Background(), &user); err != nil {
+		panic(err)
+	}
+	for _, name := range roles {
+		m.Grant(user.ID, name, nil)
+	}
+	return user
+}
+
+// Grant gives userID the named role directly until expiresAt (nil: no expiry).
+func (m *Memory) Grant(userID uint, role string, expiresAt *time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if found, ok := m.roleByName(role); ok {
+		m.data.grants[[2]uint{userID, found.ID}] = model.UserRole{
+			UserID: userID, RoleID: found.ID, ExpiresAt: expiresAt, CreatedAt: time.Now(),
+		}
+	}
+}
+
+// AddGroup seeds a group carrying the named roles with the given members and returns its ID.
+func (m *Memory) AddGroup(name string, roles []string, members ...uint) uint {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	group := model.Group{ID: m.newID(), Name: name, CreatedAt: time.Now()}
+	for _, role := range roles {
+		if found, ok := m.roleByName(role); ok {
+			group.Roles = append(group.Roles, found)
+		}
+	}
+	m.data.groups[group.ID] = group
+	for _, userID := range members {
+		m.data.members[[2]uint{group.ID, userID}] = struct{}{}
+	}
+	return group.ID
+}
+
+// AddOrgMember makes userID a member of organisation orgID.
+func (m *Memory) AddOrgMember(orgID, userID uint) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data.orgs[[2]uint{orgID, userID}] = struct{}{}
+}
+
+// ChangeRequestsSubmitted returns the stored change requests, oldest first.
+func (m *Memory) ChangeRequestsSubmitted() []model.ChangeRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]model.ChangeRequest(nil), m.data.requests...)
+}
+
+// RecordedEvents returns the events recorded by committed changes, oldest first.
+func (m *Memory) RecordedEvents() []event.Event {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]event.Event(nil), m.data.events...)
+}
+
+func (m *Memory) roleByName(name string) (model.Role, bool) {
+	for _, role := range m.data.roles {
+		if role.Name == name {
+			return role, true
+		}
+	}
+	return model.Role{}, false
+}
+
+// load assembles a copy of the stored user with the associations PreloadActiveRoles loads.
+func (m *Memory) load(user model.User) model.User {
+	now := time.Now()
+	user.RoleGrants = make([]model.UserRole, 0)
+	for _, grant := range m.data.grants {
+		if grant.UserID != user.ID || (grant.ExpiresAt != nil && !grant.ExpiresAt.After(now)) {
+			continue
+		}
+		grant.Role = m.data.roles[grant.RoleID]
+		user.RoleGrants = append(user.RoleGrants, grant)
+	}
+	sort.Slice(user.RoleGrants, func(i, j int) bool { return user.RoleGrants[i].RoleID < user.RoleGrants[j].RoleID })
+
+	user.Groups = make([]model.GroupMember, 0)
+	for key := range m.data.members {
+		if key[1] == user.ID {
+			group := m.data.groups[key[0]]
+			group.Roles = append([]model.Role(nil), group.Roles...)
+			user.Groups = append(user.Groups, model.GroupMember{GroupID: key[0], UserID: user.ID, Group: group})
+		}
+	}
+	sort.Slice(user.Groups, func(i, j int) bool { return user.Groups[i].GroupID < user.Groups[j].GroupID })
+	return user
+}
+
+func (m *Memory) holds(userID uint, role string) bool {
+	now := time.Now()
+	for _, grant := range m.data.grants {
+		if grant.UserID == userID && m.data.roles[grant.RoleID].Name == role &&
+			(grant.ExpiresAt == nil || grant.ExpiresAt.After(now)) {
+			return true
+		}
+	}
+	for key := range m.data.members {
+		if key[1] != userID {
+			continue
+		}
+		for _, groupRole := range m.data.groups[key[0]].Roles {
+			if groupRole.Name == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type memoryUsers struct {
+	m *Memory
+}
+
+func (r memoryUsers) Get(_ context.Context, id uint) (*model.User, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	user, ok := r.m.data.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	loaded := r.m.load(user)
+	return &loaded, nil
+}
+
+func (r memoryUsers) GetByUsername(_ context.Context, username string) (*model.User, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	for _, user := range r.m.data.users {
+		if user.Username == username {
+			loaded := r.m.load(user)
+			return &loaded, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r memoryUsers) List(_ context.Context, q UserQuery) ([]model.User, int64, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	status := strings.TrimSpace(q.Status)
+	keyword := strings.ToLower(strings.TrimSpace(q.Keyword))
+	group := strings.TrimSpace(q.Group)
+	matches := make([]model.User, 0)
+	for _, user := range r.m.data.users {
+		if status != "" && user.Status != status {
+			continue
+		}
+		if keyword != "" && !strings.Contains(strings.ToLower(user.Username), keyword) &&
+			!strings.Contains(strings.ToLower(user.Email), keyword) &&
+			!strings.Contains(strings.ToLower(user.FullName), keyword) {
+			continue
+		}
+		if q.OrgID != nil {
+			if _, ok := r.m.data.orgs[[2]uint{*q.OrgID, user.ID}]; !ok {
+				continue
+			}
+		}
+		loaded := r.m.load(user)
+		if group != "" && !inGroup(&loaded, group) {
+			continue
+		}
+		matches = append(matches, loaded)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if !matches[i].CreatedAt.Equal(matches[j].CreatedAt) {
+			return matches[i].CreatedAt.After(matches[j].CreatedAt)
+		}
+		return matches[i].ID > matches[j].ID
+	})
+
+	total := int64(len(matches))
+	if q.Offset >= len(matches) {
+		return []model.User{}, total, nil
+	}
+	matches = matches[q.Offset:]
+	if q.Limit > 0 && q.Limit < len(matches) {
+		matches = matches[:q.Limit]
+	}
+	return matches, total, nil
+}
+
+func inGroup(user *model.User, name string) bool {
+	for _, member := range user.Groups {
+		if member.Group.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r memoryUsers) Exists(_ context.Context, username, email string) (bool, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	for _, user := range r.m.data.users {
+		if user.Username == username || user.Email == email {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r memoryUsers) InOrg(_ context.Context, id, orgID uint) (bool, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	_, ok := r.m.data.orgs[[2]uint{orgID, id}]
+	return ok, nil
+}
+
+func (r memoryUsers) Create(_ context.Context, user *model.User) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	for _, existing := range r.m.data.users {
+		if existing.Username == user.Username || existing.Email == user.Email {
+			return ErrDuplicate
+		}
+	}
+	if user.Status == "" {
+		user.Status = model.UserStatusEnabled
+	}
+	if user.AuthSource == "" {
+		user.AuthSource = model.AuthSourceLocal
+	}
+	now := time.Now()
+	user.ID = r.m.newID()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
+	stored := *user
+	stored.Roles, stored.RoleGrants, stored.Groups = nil, nil, nil
+	r.m.data.users[user.ID] = stored
+	return nil
+}
+
+// update applies fn to the stored user; like an UPDATE matching no row, an unknown id is not an error.
+func (r memoryUsers) update(id uint, fn func(*model.User)) {
+	if user, ok := r.m.data.users[id]; ok {
+		fn(&user)
+		user.UpdatedAt = time.Now()
+		r.m.data.users[id] = user
+	}
+}
+
+func (r memoryUsers) UpdateStatus(_ context.Context, id uint, status string) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	r.update(id, func(user *model.User) { user.Status = status })
+	return nil
+}
+
+func (r memoryUsers) UpdateProfile(_ context.Context, id uint, email, fullName string) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	for _, existing := range r.m.data.users {
+		if existing.ID != id && existing.Email == email {
+			return ErrDuplicate
+		}
+	}
+	r.update(id, func(user *model.User) {
+		user.Email = email
+		user.FullName = fullName
+	})
+	return nil
+}
+
+func (r memoryUsers) UpdatePasswordHash(_ context.Context, id uint, hash string) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	r.update(id, func(user *model.User) { user.PasswordHash = hash })
+	return nil
+}
+
+func (r memoryUsers) TouchLogin(_ context.Context, id uint, at time.Time) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	if user, ok := r.m.data.users[id]; ok {
+		user.LastLoginAt = &at
+		r.m.data.users[id] = user
+	}
+	return nil
+}
+
+func (r memoryUsers) BumpRoleVersion(_ context.Context, id uint, expected *uint) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	user, ok := r.m.data.users[id]
+	if !ok || (expected != nil && user.RoleVersion != *expected) {
+		return ErrVersionConflict
+	}
+	user.RoleVersion++
+	user.UpdatedAt = time.Now()
+	r.m.data.users[id] = user
+	return nil
+}
+
+func (r memoryUsers) CountActiveHolders(_ context.Context, role string) (int64, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	var count int64
+	for _, user := range r.m.data.users {
+		if user.Status == model.UserStatusEnabled && r.m.holds(user.ID, role) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+type memoryRoles struct {
+	m *Memory
+}
+
+func (r memoryRoles) List(_ context.Context) ([]model.Role, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	roles := make([]model.Role, 0, len(r.m.data.roles))
+	for _, role := range r.m.data.roles {
+		roles = append(roles, role)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+func (r memoryRoles) GetByName(_ context.Context, name string) (*model.Role, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	role, ok := r.m.roleByName(name)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &role, nil
+}
+
+func (r memoryRoles) FindByNames(_ context.Context, names []string) ([]model.Role, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	roles := make([]model.Role, 0, len(names))
+	for _, name := range names {
+		if role, ok := r.m.roleByName(name); ok {
+			roles = append(roles, role)
+		}
+	}
+	return roles, nil
+}
+
+// Lock is a no-op: Memory already serialises transactions.
+func (r memoryRoles) Lock(context.Context, string) error {
+	return nil
+}
+
+func (r memoryRoles) ReplaceUserRoles(_ context.Context, userID uint, roles []model.Role, grantedBy *uint) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	now := time.Now()
+	keep := make(map[uint]bool, len(roles))
+	for _, role := range roles {
+		keep[role.ID] = true
+	}
+	for key, grant := range r.m.data.grants {
+		if key[0] == userID && (!keep[key[1]] || (grant.ExpiresAt != nil && !grant.ExpiresAt.After(now))) {
+			delete(r.m.data.grants, key)
+		}
+	}
+	for _, role := range roles {
+		key := [2]uint{userID, role.ID}
+		if _, ok := r.m.data.grants[key]; !ok {
+			r.m.data.grants[key] = model.UserRole{UserID: userID, RoleID: role.ID, GrantedBy: grantedBy, CreatedAt: now}
+		}
+	}
+	return nil
+}
+
+func (r memoryRoles) Grant(_ context.Context, grant model.UserRole) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	key := [2]uint{grant.UserID, grant.RoleID}
+	if existing, ok := r.m.data.grants[key]; ok {
+		grant.CreatedAt = existing.CreatedAt
+	} else {
+		grant.CreatedAt = time.Now()
+	}
+	grant.Role = model.Role{}
+	r.m.data.grants[key] = grant
+	return nil
+}
+
+func (r memoryRoles) Revoke(_ context.Context, userID, roleID uint) (bool, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	key := [2]uint{userID, roleID}
+	_, ok := r.m.data.grants[key]
+	delete(r.m.data.grants, key)
+	return ok, nil
+}
+
+type memoryChangeRequests struct {
+	m *Memory
+}
+
+func (r memoryChangeRequests) Create(_ context.Context, request *model.ChangeRequest) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	now := time.Now()
+	request.ID = r.m.newID()
+	request.CreatedAt = now
+	request.UpdatedAt = now
+	r.m.data.requests = append(r.m.data.requests, *request)
+	return nil
+}
+
+type memoryEvents struct {
+	m *Memory
+}
+
+func (r memoryEvents) Record(_ context.Context, e event.Event) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	r.m.data.events = append(r.m.data.events, e)
+	return nil
+}
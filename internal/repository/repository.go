@@ -0,0 +1,98 @@
+// Package repository hides persistence behind interfaces so logic can run against PostgreSQL
+// (NewGormStore) or an in-memory store (NewMemory) in unit tests.
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"usermgmt/internal/event"
+	"usermgmt/internal/model"
+)
+
+// ErrNotFound and ErrDuplicate wrap the GORM errors the database store returns in their place, so
+// that errorx.From maps both stores alike.
+var (
+	// ErrNotFound is returned when the requested record does not exist.
+	ErrNotFound = fmt.Errorf("repository: %w", gorm.ErrRecordNotFound)
+	// ErrVersionConflict is returned when a conditional update found a different version.
+	ErrVersionConflict = errors.New("repository: version conflict")
+	// ErrDuplicate is returned for rows that violate a unique index.
+	ErrDuplicate = fmt.Errorf("repository: %w", gorm.ErrDuplicatedKey)
+)
+
+// UserQuery filters UserRepository.List; zero fields do not filter.
+type UserQuery struct {
+	Keyword string
+	Status  string
+	Group   string
+	// OrgID limits the result to members of one organisation.
+	OrgID  *uint
+	Offset int
+	Limit  int
+}
+
+// UserRepository stores users. Loaded users carry their unexpired direct grants with roles and
+// their group memberships with the groups' roles, as common.ActiveRoleNames expects.
+type UserRepository interface {
+	Get(ctx context.Context, id uint) (*model.User, error)
+	GetByUsername(ctx context.Context, username string) (*model.User, error)
+	// List returns one page of users, newest first, and the total number of matches.
+	List(ctx context.Context, query UserQuery) ([]model.User, int64, error)
+	// Exists reports whether a user holds username or email.
+	Exists(ctx context.Context, username, email string) (bool, error)
+	// InOrg reports whether the user is a member of organisation orgID.
+	InOrg(ctx context.Context, id, orgID uint) (bool, error)
+	Create(ctx context.Context, user *model.User) error
+	UpdateStatus(ctx context.Context, id uint, status string) error
+	// UpdateProfile changes the email and full name; an email held by another user is ErrDuplicate.
+	UpdateProfile(ctx context.Context, id uint, email, fullName string) error
+	UpdatePasswordHash(ctx context.Context, id uint, hash string) error
+	TouchLogin(ctx context.Context, id uint, at time.Time) error
+	// BumpRoleVersion increments the role version; a non-nil expected must match the stored one.
+	BumpRoleVersion(ctx context.Context, id uint, expected *uint) error
+	// CountActiveHolders counts enabled users holding role directly (unexpired) or via a group.
+	CountActiveHolders(ctx context.Context, role string) (int64, error)
+}
+
+// RoleRepository stores roles and direct grants.
+type RoleRepository interface {
+	List(ctx context.Context) ([]model.Role, error)
+	GetByName(ctx context.Context, name string) (*model.Role, error)
+	// FindByNames returns the roles among names that exist, in no particular order.
+	FindByNames(ctx context.Context, names []string) ([]model.Role, error)
+	// Lock serialises transactions on the named role; it is a no-op outside a transaction.
+	Lock(ctx context.Context, name string) error
+	// ReplaceUserRoles makes roles the user's exact direct role set. Active grants that are kept
+	// retain their expiry and grantor; expired ones are dropped and granted afresh.
+	ReplaceUserRoles(ctx context.Context, userID uint, roles []model.Role, grantedBy *uint) error
+	// Grant stores a direct grant; granting a role the user holds replaces its expiry and grantor.
+	Grant(ctx context.Context, grant model.UserRole) error
+	// Revoke removes a direct grant and reports whether there was one.
+	Revoke(ctx context.Context, userID, roleID uint) (bool, error)
+}
+
+// ChangeRequestRepository stores changes held for a second approver.
+type ChangeRequestRepository interface {
+	Create(ctx context.Context, request *model.ChangeRequest) error
+}
+
+// EventRecorder writes events to the outbox of the current transaction.
+type EventRecorder interface {
+	Record(ctx context.Context, e event.Event) error
+}
+
+// Store groups the repositories of one unit of work.
+type Store interface {
+	Users() UserRepository
+	Roles() RoleRepository
+	ChangeRequests() ChangeRequestRepository
+	Events() EventRecorder
+	// Transaction runs fn with a store whose changes commit together when fn returns nil and
+	// are discarded otherwise.
+	Transaction(ctx context.Context, fn func(Store) error) error
+}
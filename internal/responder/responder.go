@@ -0,0 +1,60 @@
+// Package responder writes the error responses of handlers and middleware: errors are mapped by
+// errorx.From, translated into the locale negotiated for the request and written by pkg/response.
+// It also writes the translated confirmations of requests that return no data.
+package responder
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"usermgmt/internal/config"
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/i18n"
+	"usermgmt/pkg/response"
+)
+
+// Responder answers failed requests in the format configured by Errors.
+type Responder struct {
+	writer *response.ErrorWriter
+}
+
+// New returns a responder for c.
+func New(c config.ErrorsConf) *Responder {
+	return &Responder{writer: response.NewErrorWriter(response.ProblemOptions{
+		Always:   c.Format == "problem",
+		TypeBase: c.TypeBase,
+	})}
+}
+
+// Fail writes the error response for err as mapped by errorx.From. An error that is not an
+// AppError is logged here, since the INTERNAL_ERROR it turns into hides the cause from the client.
+// The message and validation details are translated with the localizer of the request, see
+// i18n.FromContext.
+func (p *Responder) Fail(w http.ResponseWriter, r *http.Request, err error) {
+	appErr := errorx.From(r.Context(), err)
+	if appErr == nil {
+		return
+	}
+	if appErr.Code == errorx.ErrInternal.Code && !errorx.Is(err, errorx.ErrInternal) {
+		logx.WithContext(r.Context()).Errorf("unhandled error: %v", err)
+	}
+
+	message, details := appErr.Message, appErr.Details
+	if localizer := i18n.FromContext(r.Context()); localizer != nil {
+		message = localizer.Message(appErr.Code, message)
+		details = localizer.Details(details)
+		w.Header().Set("Content-Language", localizer.Locale())
+	}
+	p.writer.Write(w, r, appErr.Status, appErr.Code, message, details)
+}
+
+// Done answers a request that returns no data with {"message": text}, where text is the text key
+// of the catalogs in the locale of the request.
+func (p *Responder) Done(w http.ResponseWriter, r *http.Request, key string) {
+	localizer := i18n.FromContext(r.Context())
+	if localizer != nil {
+		w.Header().Set("Content-Language", localizer.Locale())
+	}
+	response.Success(w, r, map[string]string{"message": localizer.Text(key, nil)})
+}
@@ -0,0 +1,32 @@
+// Package retry holds the helpers shared by the background senders that retry failed work from a
+// table, such as the event relay and the webhook dispatcher.
+package retry
+
+import (
+	"strings"
+	"time"
+)
+
+// MaxErrorLength is the size of the last_error columns.
+const MaxErrorLength = 500
+
+// Backoff returns base doubled for every attempt after the first, capped at max.
+func Backoff(base, max time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
+
+// Truncate shortens message to MaxErrorLength characters so it fits a last_error column.
+func Truncate(message string) string {
+	message = strings.ToValidUTF8(message, "")
+	if runes := []rune(message); len(runes) > MaxErrorLength {
+		return string(runes[:MaxErrorLength])
+	}
+	return message
+}
@@ -0,0 +1,223 @@
+package scim
+
+import "strings"
+
+type supported struct {
+	Supported bool `json:"supported"`
+}
+
+type bulkSupport struct {
+	Supported      bool `json:"supported"`
+	MaxOperations  int  `json:"maxOperations"`
+	MaxPayloadSize int  `json:"maxPayloadSize"`
+}
+
+type filterSupport struct {
+	Supported  bool `json:"supported"`
+	MaxResults int  `json:"maxResults"`
+}
+
+type authenticationScheme struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Primary     bool   `json:"primary"`
+}
+
+type ServiceProviderConfig struct {
+	Schemas               []string               `json:"schemas"`
+	Patch                 supported              `json:"patch"`
+	Bulk                  bulkSupport            `json:"bulk"`
+	Filter                filterSupport          `json:"filter"`
+	ChangePassword        supported              `json:"changePassword"`
+	Sort                  supported              `json:"sort"`
+	ETag                  supported              `json:"etag"`
+	AuthenticationSchemes []authenticationScheme `json:"authenticationSchemes"`
+	Meta                  Meta                   `json:"meta"`
+}
+
+type schemaExtension struct {
+	Schema   string `json:"schema"`
+	Required bool   `json:"required"`
+}
+
+type ResourceType struct {
+	Schemas          []string          `json:"schemas"`
+	ID               string            `json:"id"`
+	Name             string            `json:"name"`
+	Endpoint         string            `json:"endpoint"`
+	Description      string            `json:"description"`
+	Schema           string            `json:"schema"`
+	SchemaExtensions []schemaExtension `json:"schemaExtensions,omitempty"`
+	Meta             Meta              `json:"meta"`
+}
+
+type SchemaAttribute struct {
+	Name           string            `json:"name"`
+	Type           string            `json:"type"`
+	MultiValued    bool              `json:"multiValued"`
+	Required       bool              `json:"required"`
+	CaseExact      bool              `json:"caseExact"`
+	Mutability     string            `json:"mutability"`
+	Returned       string            `json:"returned"`
+	Uniqueness     string            `json:"uniqueness"`
+	ReferenceTypes []string          `json:"referenceTypes,omitempty"`
+	SubAttributes  []SchemaAttribute `json:"subAttributes,omitempty"`
+}
+
+type Schema struct {
+	Schemas     []string          `json:"schemas"`
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Attributes  []SchemaAttribute `json:"attributes"`
+	Meta        Meta              `json:"meta"`
+}
+
+// attribute builds a readWrite, default-returned, non-unique string attribute; the options
+// adjust it.
+func attribute(name string, options ...func(*SchemaAttribute)) SchemaAttribute {
+	attr := SchemaAttribute{Name: name, Type: "string", Mutability: "readWrite", Returned: "default", Uniqueness: "none"}
+	for _, option := range options {
+		option(&attr)
+	}
+	return attr
+}
+
+func typed(kind string) func(*SchemaAttribute) {
+	return func(a *SchemaAttribute) { a.Type = kind }
+}
+
+func mutability(value string) func(*SchemaAttribute) {
+	return func(a *SchemaAttribute) { a.Mutability = value }
+}
+
+func returned(value string) func(*SchemaAttribute) {
+	return func(a *SchemaAttribute) { a.Returned = value }
+}
+
+func required(a *SchemaAttribute)    { a.Required = true }
+func multiValued(a *SchemaAttribute) { a.MultiValued = true }
+func unique(a *SchemaAttribute)      { a.Uniqueness = "server" }
+
+func subAttributes(attrs ...SchemaAttribute) func(*SchemaAttribute) {
+	return func(a *SchemaAttribute) {
+		a.Type = "complex"
+		a.SubAttributes = attrs
+	}
+}
+
+func reference(types ...string) SchemaAttribute {
+	return attribute("$ref", typed("reference"), mutability("immutable"), func(a *SchemaAttribute) {
+		a.ReferenceTypes = types
+	})
+}
+
+// Discovery serves the ServiceProviderConfig, ResourceTypes and Schemas endpoints. BaseURL is
+// the absolute URL of /scim/v2 and is used for resource locations.
+type Discovery struct {
+	BaseURL    string
+	MaxResults int
+}
+
+func (d Discovery) meta(resourceType, path string) Meta {
+	return Meta{ResourceType: resourceType, Location: strings.TrimRight(d.BaseURL, "/") + path}
+}
+
+func (d Discovery) ServiceProviderConfig() ServiceProviderConfig {
+	return ServiceProviderConfig{
+		Schemas: []string{SchemaServiceProviderConfig},
+		Patch:   supported{Supported: true},
+		Bulk:    bulkSupport{},
+		Filter:  filterSupport{Supported: true, MaxResults: d.MaxResults},
+		AuthenticationSchemes: []authenticationScheme{{
+			Type:        "oauthbearertoken",
+			Name:        "Bearer Token",
+			Description: "Static bearer token configured in SCIM.Token",
+			Primary:     true,
+		}},
+		Meta: d.meta("ServiceProviderConfig", "/ServiceProviderConfig"),
+	}
+}
+
+func (d Discovery) ResourceTypes() []ResourceType {
+	return []ResourceType{
+		{
+			Schemas:          []string{SchemaResourceType},
+			ID:               ResourceUser,
+			Name:             ResourceUser,
+			Endpoint:         "/Users",
+			Description:      "User account",
+			Schema:           SchemaUser,
+			SchemaExtensions: []schemaExtension{{Schema: SchemaEnterpriseUser}},
+			Meta:             d.meta("ResourceType", "/ResourceTypes/"+ResourceUser),
+		},
+		{
+			Schemas:     []string{SchemaResourceType},
+			ID:          ResourceGroup,
+			Name:        ResourceGroup,
+			Endpoint:    "/Groups",
+			Description: "Role; members are the users holding it directly",
+			Schema:      SchemaGroup,
+			Meta:        d.meta("ResourceType", "/ResourceTypes/"+ResourceGroup),
+		},
+	}
+}
+
+func (d Discovery) Schemas() []Schema {
+	return []Schema{
+		{
+			Schemas:     []string{SchemaSchema},
+			ID:          SchemaUser,
+			Name:        ResourceUser,
+			Description: "User account",
+			Attributes: []SchemaAttribute{
+				attribute("userName", required, unique),
+				attribute("name", subAttributes(
+					attribute("formatted"),
+					attribute("givenName"),
+					attribute("familyName"),
+				)),
+				attribute("displayName"),
+				attribute("emails", multiValued, required, subAttributes(
+					attribute("value", required),
+					attribute("type"),
+					attribute("primary", typed("boolean")),
+				)),
+				attribute("active", typed("boolean")),
+				attribute("password", mutability("writeOnly"), returned("never")),
+				attribute("groups", multiValued, mutability("readOnly"), subAttributes(
+					attribute("value", mutability("readOnly")),
+					attribute("display", mutability("readOnly")),
+					reference("Group"),
+				)),
+			},
+			Meta: d.meta("Schema", "/Schemas/"+SchemaUser),
+		},
+		{
+			Schemas:     []string{SchemaSchema},
+			ID:          SchemaEnterpriseUser,
+			Name:        "EnterpriseUser",
+			Description: "Enterprise user extension",
+			Attributes: []SchemaAttribute{
+				attribute("department"),
+			},
+			Meta: d.meta("Schema", "/Schemas/"+SchemaEnterpriseUser),
+		},
+		{
+			Schemas:     []string{SchemaSchema},
+			ID:          SchemaGroup,
+			Name:        ResourceGroup,
+			Description: "Role",
+			Attributes: []SchemaAttribute{
+				attribute("displayName", required, unique),
+				attribute("members", multiValued, subAttributes(
+					attribute("value", mutability("immutable")),
+					attribute("display", mutability("readOnly")),
+					reference("User"),
+				)),
+			},
+			Meta: d.meta("Schema", "/Schemas/"+SchemaGroup),
+		},
+	}
+}
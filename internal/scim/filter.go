@@ -0,0 +1,337 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Expr is a parsed filter expression (RFC 7644 section 3.4.2.2).
+type Expr interface {
+	expr()
+}
+
+// Compare is "attrPath op value"; Value is a string, float64, bool or nil.
+type Compare struct {
+	Path  string
+	Op    string
+	Value interface{}
+}
+
+// Present is "attrPath pr".
+type Present struct {
+	Path string
+}
+
+// Logical joins two expressions with "and" or "or".
+type Logical struct {
+	Op    string
+	Left  Expr
+	Right Expr
+}
+
+// Not negates an expression.
+type Not struct {
+	Expr Expr
+}
+
+// ValuePath is "attr[filter]", a filter over the entries of a multi-valued attribute.
+type ValuePath struct {
+	Attr   string
+	Filter Expr
+}
+
+func (Compare) expr()   {}
+func (Present) expr()   {}
+func (Logical) expr()   {}
+func (Not) expr()       {}
+func (ValuePath) expr() {}
+
+// Limits on filters, which arrive from the client in query strings and PATCH paths: the parser
+// and the SQL translation recurse once per nesting level.
+const (
+	MaxFilterLength = 4096
+	MaxFilterDepth  = 16
+)
+
+var compareOps = map[string]struct{}{
+	"eq": {}, "ne": {}, "co": {}, "sw": {}, "ew": {}, "gt": {}, "ge": {}, "lt": {}, "le": {},
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenString
+	tokenNumber
+	tokenLParen
+	tokenRParen
+	tokenLBracket
+	tokenRBracket
+	tokenEOF
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// ParseFilter parses a filter. Operators and keywords are case-insensitive; attribute paths are
+// returned as written. Filters longer than MaxFilterLength or with groups, not() or value paths
+// nested deeper than MaxFilterDepth are rejected.
+func ParseFilter(input string) (Expr, error) {
+	if len(input) > MaxFilterLength {
+		return nil, fmt.Errorf("filter longer than %d bytes", MaxFilterLength)
+	}
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at %d", tok.text, tok.pos)
+	}
+	return expr, nil
+}
+
+func lex(input string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(input); {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case c == '[':
+			tokens = append(tokens, token{kind: tokenLBracket, text: "[", pos: i})
+			i++
+		case c == ']':
+			tokens = append(tokens, token{kind: tokenRBracket, text: "]", pos: i})
+			i++
+		case c == '"':
+			end := i + 1
+			for ; end < len(input); end++ {
+				if input[end] == '\\' {
+					end++
+					continue
+				}
+				if input[end] == '"' {
+					break
+				}
+			}
+			if end >= len(input) {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			var value string
+			if err := json.Unmarshal([]byte(input[i:end+1]), &value); err != nil {
+				return nil, fmt.Errorf("invalid string at %d", i)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: value, pos: i})
+			i = end + 1
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(input) && strings.IndexByte("0123456789.eE+-", input[end]) >= 0 {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: input[i:end], pos: i})
+			i = end
+		case isWordChar(rune(c)):
+			end := i
+			for end < len(input) && isWordChar(rune(input[end])) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: input[i:end], pos: i})
+			i = end
+		default:
+			return nil, fmt.Errorf("unexpected character %q at %d", c, i)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(input)}), nil
+}
+
+// isWordChar accepts attribute names, dotted sub-attributes and schema URNs such as
+// urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department.
+func isWordChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.' || r == ':' || r == '$'
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) peekKeyword(keyword string) bool {
+	tok := p.peek()
+	return tok.kind == tokenWord && strings.EqualFold(tok.text, keyword)
+}
+
+func (p *parser) expect(kind tokenKind, text string) error {
+	if tok := p.next(); tok.kind != kind {
+		return fmt.Errorf("expected %q at %d", text, tok.pos)
+	}
+	return nil
+}
+
+// enter counts one nesting level opened at pos; the caller calls leave when the level closes.
+func (p *parser) enter(pos int) error {
+	p.depth++
+	if p.depth > MaxFilterDepth {
+		return fmt.Errorf("filter nested deeper than %d at %d", MaxFilterDepth, pos)
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = Logical{Op: "or", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = Logical{Op: "and", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	if p.peekKeyword("not") && p.tokens[p.pos+1].kind == tokenLParen {
+		p.next()
+		inner, err := p.parseGroup()
+		if err != nil {
+			return nil, err
+		}
+		return Not{Expr: inner}, nil
+	}
+	if p.peek().kind == tokenLParen {
+		return p.parseGroup()
+	}
+	return p.parseAttrExpr()
+}
+
+func (p *parser) parseGroup() (Expr, error) {
+	open := p.peek()
+	if err := p.expect(tokenLParen, "("); err != nil {
+		return nil, err
+	}
+	if err := p.enter(open.pos); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+	inner, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(tokenRParen, ")"); err != nil {
+		return nil, err
+	}
+	return inner, nil
+}
+
+func (p *parser) parseAttrExpr() (Expr, error) {
+	attr := p.next()
+	if attr.kind != tokenWord {
+		return nil, fmt.Errorf("expected attribute path at %d", attr.pos)
+	}
+	if p.peek().kind == tokenLBracket {
+		open := p.next()
+		if err := p.enter(open.pos); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenRBracket, "]"); err != nil {
+			return nil, err
+		}
+		return ValuePath{Attr: attr.text, Filter: inner}, nil
+	}
+
+	opToken := p.next()
+	op := strings.ToLower(opToken.text)
+	if opToken.kind != tokenWord {
+		return nil, fmt.Errorf("expected operator at %d", opToken.pos)
+	}
+	if op == "pr" {
+		return Present{Path: attr.text}, nil
+	}
+	if _, ok := compareOps[op]; !ok {
+		return nil, fmt.Errorf("unknown operator %q at %d", opToken.text, opToken.pos)
+	}
+
+	valueToken := p.next()
+	var value interface{}
+	switch valueToken.kind {
+	case tokenString:
+		value = valueToken.text
+	case tokenNumber:
+		var number float64
+		if err := json.Unmarshal([]byte(valueToken.text), &number); err != nil {
+			return nil, fmt.Errorf("invalid number %q at %d", valueToken.text, valueToken.pos)
+		}
+		value = number
+	case tokenWord:
+		switch strings.ToLower(valueToken.text) {
+		case "true":
+			value = true
+		case "false":
+			value = false
+		case "null":
+			value = nil
+		default:
+			return nil, fmt.Errorf("invalid value %q at %d", valueToken.text, valueToken.pos)
+		}
+	default:
+		return nil, fmt.Errorf("expected value at %d", valueToken.pos)
+	}
+	return Compare{Path: attr.text, Op: op, Value: value}, nil
+}
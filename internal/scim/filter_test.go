@@ -0,0 +1,159 @@
+package scim
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  Expr
+	}{
+		{name: "compare string", input: `userName eq "bjensen"`, want: Compare{Path: "userName", Op: "eq", Value: "bjensen"}},
+		{name: "case-insensitive operator", input: `userName EQ "bjensen"`, want: Compare{Path: "userName", Op: "eq", Value: "bjensen"}},
+		{name: "escaped string", input: `displayName co "say \"hi\""`, want: Compare{Path: "displayName", Op: "co", Value: `say "hi"`}},
+		{name: "number", input: `id gt 41.5`, want: Compare{Path: "id", Op: "gt", Value: 41.5}},
+		{name: "boolean and null", input: `active eq True or externalId eq null`, want: Logical{
+			Op:    "or",
+			Left:  Compare{Path: "active", Op: "eq", Value: true},
+			Right: Compare{Path: "externalId", Op: "eq", Value: nil},
+		}},
+		{name: "present", input: `title pr`, want: Present{Path: "title"}},
+		{name: "and binds tighter than or", input: `a pr or b pr and c pr`, want: Logical{
+			Op:    "or",
+			Left:  Present{Path: "a"},
+			Right: Logical{Op: "and", Left: Present{Path: "b"}, Right: Present{Path: "c"}},
+		}},
+		{name: "grouping", input: `(a pr or b pr) and c pr`, want: Logical{
+			Op:    "and",
+			Left:  Logical{Op: "or", Left: Present{Path: "a"}, Right: Present{Path: "b"}},
+			Right: Present{Path: "c"},
+		}},
+		{name: "not", input: `not (userName sw "x")`, want: Not{Expr: Compare{Path: "userName", Op: "sw", Value: "x"}}},
+		{name: "value path", input: `emails[type eq "work" and value ew "@example.com"]`, want: ValuePath{
+			Attr: "emails",
+			Filter: Logical{
+				Op:    "and",
+				Left:  Compare{Path: "type", Op: "eq", Value: "work"},
+				Right: Compare{Path: "value", Op: "ew", Value: "@example.com"},
+			},
+		}},
+		{name: "schema urn path", input: `urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department eq "R&D"`, want: Compare{
+			Path: "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department", Op: "eq", Value: "R&D",
+		}},
+		{name: "nesting at the limit", input: strings.Repeat("(", MaxFilterDepth) + "a pr" + strings.Repeat(")", MaxFilterDepth), want: Present{Path: "a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFilter(tt.input)
+			if err != nil {
+				t.Fatalf("ParseFilter(%q) err = %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ParseFilter(%q) = %#v, want %#v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilterRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "empty", input: ``, wantErr: "expected attribute path"},
+		{name: "unknown operator", input: `userName like "x"`, wantErr: "unknown operator"},
+		{name: "missing value", input: `userName eq`, wantErr: "expected value"},
+		{name: "bare word value", input: `userName eq bjensen`, wantErr: "invalid value"},
+		{name: "unterminated string", input: `userName eq "bjensen`, wantErr: "unterminated string"},
+		{name: "bad number", input: `id eq 1.2.3`, wantErr: "invalid number"},
+		{name: "unbalanced group", input: `(a pr`, wantErr: `expected ")"`},
+		{name: "unbalanced value path", input: `emails[type pr`, wantErr: `expected "]"`},
+		{name: "trailing input", input: `a pr b pr`, wantErr: "unexpected"},
+		{name: "dangling and", input: `a pr and`, wantErr: "expected attribute path"},
+		{name: "stray character", input: `a eq 'x'`, wantErr: "unexpected character"},
+		{name: "groups too deep", input: strings.Repeat("(", MaxFilterDepth+1) + "a pr" + strings.Repeat(")", MaxFilterDepth+1), wantErr: "nested deeper"},
+		{name: "not too deep", input: strings.Repeat("not (", MaxFilterDepth+1) + "a pr" + strings.Repeat(")", MaxFilterDepth+1), wantErr: "nested deeper"},
+		{name: "value paths too deep", input: strings.Repeat("a[", MaxFilterDepth+1) + "b pr" + strings.Repeat("]", MaxFilterDepth+1), wantErr: "nested deeper"},
+		{name: "deep nesting over the length cap", input: strings.Repeat("(", 100000), wantErr: "longer than"},
+		{name: "too long", input: `userName eq "` + strings.Repeat("x", MaxFilterLength) + `"`, wantErr: "longer than"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFilter(tt.input)
+			if err == nil {
+				t.Fatalf("ParseFilter(%q) err = nil, want %q", tt.input, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ParseFilter err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    Path
+		wantErr bool
+	}{
+		{raw: "", want: Path{}},
+		{raw: "userName", want: Path{Attr: "username"}},
+		{raw: "name.givenName", want: Path{Attr: "name", Sub: "givenname"}},
+		{raw: SchemaUser + ":active", want: Path{Attr: "active"}},
+		{raw: SchemaEnterpriseUser + ":department", want: Path{Attr: strings.ToLower(SchemaEnterpriseUser), Sub: "department"}},
+		{raw: `members[value eq "42"]`, want: Path{Attr: "members", Filter: Compare{Path: "value", Op: "eq", Value: "42"}}},
+		{raw: `emails[type eq "work"].value`, want: Path{Attr: "emails", Filter: Compare{Path: "type", Op: "eq", Value: "work"}, Sub: "value"}},
+		{raw: `emails]type eq "work"[`, wantErr: true},
+		{raw: `emails[type eq "work"]value`, wantErr: true},
+		{raw: `members[value eq]`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := ParsePath(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParsePath(%q) err = nil, want error", tt.raw)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePath(%q) err = %v", tt.raw, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ParsePath(%q) = %#v, want %#v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEqualValues(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   []string
+		ok     bool
+	}{
+		{filter: `value eq "1"`, want: []string{"1"}, ok: true},
+		{filter: `value eq "1" or VALUE eq "2" or value eq "3"`, want: []string{"1", "2", "3"}, ok: true},
+		{filter: `value eq "1" and value eq "2"`},
+		{filter: `value ne "1"`},
+		{filter: `display eq "1"`},
+		{filter: `value eq 1`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			expr, err := ParseFilter(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := EqualValues(expr, "value")
+			if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("EqualValues = %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package scim
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Path is a parsed PATCH path such as `userName`, `name.givenName`, `emails[type eq "work"].value`
+// or `members[value eq "42"]`. Attr and Sub are lower-cased; an enterprise extension attribute has
+// the extension URN as Attr.
+type Path struct {
+	Attr   string
+	Filter Expr
+	Sub    string
+}
+
+// ParsePath parses a PATCH path; an empty path yields the zero Path.
+func ParsePath(raw string) (Path, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return Path{}, nil
+	}
+
+	if open := strings.IndexByte(raw, '['); open >= 0 {
+		end := strings.LastIndexByte(raw, ']')
+		if end < open {
+			return Path{}, fmt.Errorf("unbalanced brackets in path %q", raw)
+		}
+		filter, err := ParseFilter(raw[open+1 : end])
+		if err != nil {
+			return Path{}, err
+		}
+		path := Path{Attr: NormalizePath(raw[:open]), Filter: filter}
+		if rest := raw[end+1:]; rest != "" {
+			if !strings.HasPrefix(rest, ".") {
+				return Path{}, fmt.Errorf("invalid sub-attribute in path %q", raw)
+			}
+			path.Sub = strings.ToLower(rest[1:])
+		}
+		return path, nil
+	}
+
+	normalized := NormalizePath(raw)
+	enterprise := strings.ToLower(SchemaEnterpriseUser)
+	if strings.HasPrefix(normalized, enterprise) {
+		return Path{Attr: enterprise, Sub: strings.TrimPrefix(strings.TrimPrefix(normalized, enterprise), ":")}, nil
+	}
+	if dot := strings.IndexByte(normalized, '.'); dot >= 0 {
+		return Path{Attr: normalized[:dot], Sub: normalized[dot+1:]}, nil
+	}
+	return Path{Attr: normalized}, nil
+}
+
+// EqualValues extracts the values of `attr eq "v"` comparisons joined by "or", as used by
+// `members[value eq "1" or value eq "2"]`. ok is false for any other filter shape.
+func EqualValues(expr Expr, attr string) (values []string, ok bool) {
+	switch e := expr.(type) {
+	case Compare:
+		value, isString := e.Value.(string)
+		if !isString || e.Op != "eq" || !strings.EqualFold(e.Path, attr) {
+			return nil, false
+		}
+		return []string{value}, true
+	case Logical:
+		if e.Op != "or" {
+			return nil, false
+		}
+		left, ok := EqualValues(e.Left, attr)
+		if !ok {
+			return nil, false
+		}
+		right, ok := EqualValues(e.Right, attr)
+		if !ok {
+			return nil, false
+		}
+		return append(left, right...), true
+	}
+	return nil, false
+}
@@ -0,0 +1,237 @@
+package scim
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"usermgmt/internal/dialect"
+)
+
+// AttrType is the SCIM data type of a filterable attribute.
+type AttrType int
+
+const (
+	TypeString AttrType = iota
+	TypeInteger
+	TypeBoolean
+	TypeDateTime
+)
+
+// Attribute maps a filterable attribute onto SQL.
+type Attribute struct {
+	// Column is the column expression compared against the value.
+	Column string
+	Type   AttrType
+	// CaseExact disables the case-insensitive comparison SCIM uses for strings by default.
+	CaseExact bool
+	// Bool converts a boolean filter value to the stored value, e.g. active -> status.
+	Bool func(bool) interface{}
+	// Member, when set, replaces the column comparison: it is a condition with one placeholder
+	// receiving the numeric ID from "attr eq id", e.g. a membership subquery.
+	Member string
+}
+
+// Attributes maps lower-cased attribute paths (core schema prefix stripped) to their SQL mapping.
+type Attributes map[string]Attribute
+
+// ToSQL translates expr into a WHERE condition with "?" placeholders.
+func ToSQL(expr Expr, attrs Attributes) (string, []interface{}, error) {
+	b := &sqlBuilder{attrs: attrs}
+	condition, err := b.build(expr, "")
+	if err != nil {
+		return "", nil, err
+	}
+	return condition, b.args, nil
+}
+
+// NormalizePath lower-cases an attribute path and strips the core schema URN prefixes, so
+// "urn:ietf:params:scim:schemas:core:2.0:User:userName" and "userName" are the same attribute.
+func NormalizePath(path string) string {
+	lower := strings.ToLower(strings.TrimSpace(path))
+	for _, schema := range []string{SchemaUser, SchemaGroup} {
+		prefix := strings.ToLower(schema) + ":"
+		if strings.HasPrefix(lower, prefix) {
+			return strings.TrimPrefix(lower, prefix)
+		}
+	}
+	return lower
+}
+
+type sqlBuilder struct {
+	attrs Attributes
+	args  []interface{}
+}
+
+func (b *sqlBuilder) build(expr Expr, prefix string) (string, error) {
+	switch e := expr.(type) {
+	case Logical:
+		left, err := b.build(e.Left, prefix)
+		if err != nil {
+			return "", err
+		}
+		right, err := b.build(e.Right, prefix)
+		if err != nil {
+			return "", err
+		}
+		return "(" + left + " " + strings.ToUpper(e.Op) + " " + right + ")", nil
+	case Not:
+		inner, err := b.build(e.Expr, prefix)
+		if err != nil {
+			return "", err
+		}
+		return "NOT (" + inner + ")", nil
+	case ValuePath:
+		if prefix != "" {
+			return "", fmt.Errorf("nested value path %q", e.Attr)
+		}
+		return b.build(e.Filter, e.Attr+".")
+	case Present:
+		attr, err := b.lookup(prefix + e.Path)
+		if err != nil {
+			return "", err
+		}
+		if attr.Member != "" {
+			return "", fmt.Errorf("operator pr is not supported on %q", e.Path)
+		}
+		if attr.Type == TypeString {
+			return "(" + attr.Column + " IS NOT NULL AND " + attr.Column + " <> '')", nil
+		}
+		return attr.Column + " IS NOT NULL", nil
+	case Compare:
+		attr, err := b.lookup(prefix + e.Path)
+		if err != nil {
+			return "", err
+		}
+		return b.compare(attr, prefix+e.Path, e.Op, e.Value)
+	}
+	return "", fmt.Errorf("unsupported expression")
+}
+
+func (b *sqlBuilder) lookup(path string) (Attribute, error) {
+	attr, ok := b.attrs[NormalizePath(path)]
+	if !ok {
+		return Attribute{}, fmt.Errorf("attribute %q is not filterable", path)
+	}
+	return attr, nil
+}
+
+func (b *sqlBuilder) compare(attr Attribute, path, op string, value interface{}) (string, error) {
+	if value == nil && attr.Member == "" {
+		switch op {
+		case "eq":
+			return attr.Column + " IS NULL", nil
+		case "ne":
+			return attr.Column + " IS NOT NULL", nil
+		}
+		return "", fmt.Errorf("operator %s cannot compare %q with null", op, path)
+	}
+
+	if attr.Member != "" {
+		if op != "eq" {
+			return "", fmt.Errorf("only eq is supported on %q", path)
+		}
+		id, ok := parseID(value)
+		if !ok {
+			return "1 = 0", nil
+		}
+		b.args = append(b.args, id)
+		return attr.Member, nil
+	}
+
+	switch attr.Type {
+	case TypeBoolean:
+		flag, ok := value.(bool)
+		if !ok || (op != "eq" && op != "ne") {
+			return "", fmt.Errorf("%q only supports eq/ne with true or false", path)
+		}
+		var stored interface{} = flag
+		if attr.Bool != nil {
+			stored = attr.Bool(flag)
+		}
+		b.args = append(b.args, stored)
+		return attr.Column + sqlOperator(op) + "?", nil
+
+	case TypeInteger:
+		if isTextOp(op) {
+			return "", fmt.Errorf("operator %s is not supported on %q", op, path)
+		}
+		id, ok := parseID(value)
+		if !ok {
+			return "", fmt.Errorf("%q expects a numeric id", path)
+		}
+		b.args = append(b.args, id)
+		return attr.Column + sqlOperator(op) + "?", nil
+
+	case TypeDateTime:
+		text, ok := value.(string)
+		if !ok || isTextOp(op) {
+			return "", fmt.Errorf("%q only supports comparisons with an RFC 3339 timestamp", path)
+		}
+		at, err := time.Parse(time.RFC3339, text)
+		if err != nil {
+			return "", fmt.Errorf("%q expects an RFC 3339 timestamp", path)
+		}
+		b.args = append(b.args, at)
+		return attr.Column + sqlOperator(op) + "?", nil
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%q expects a string", path)
+	}
+	column := attr.Column
+	if !attr.CaseExact {
+		column = "LOWER(" + column + ")"
+		text = strings.ToLower(text)
+	}
+	switch op {
+	case "co":
+		b.args = append(b.args, "%"+dialect.EscapeLike(text)+"%")
+		return column + " LIKE ?" + dialect.LikeEscape, nil
+	case "sw":
+		b.args = append(b.args, dialect.EscapeLike(text)+"%")
+		return column + " LIKE ?" + dialect.LikeEscape, nil
+	case "ew":
+		b.args = append(b.args, "%"+dialect.EscapeLike(text))
+		return column + " LIKE ?" + dialect.LikeEscape, nil
+	}
+	b.args = append(b.args, text)
+	return column + sqlOperator(op) + "?", nil
+}
+
+func sqlOperator(op string) string {
+	switch op {
+	case "ne":
+		return " <> "
+	case "gt":
+		return " > "
+	case "ge":
+		return " >= "
+	case "lt":
+		return " < "
+	case "le":
+		return " <= "
+	}
+	return " = "
+}
+
+func isTextOp(op string) bool {
+	return op == "co" || op == "sw" || op == "ew"
+}
+
+// parseID accepts resource IDs given as strings (the SCIM form) or numbers.
+func parseID(value interface{}) (uint64, bool) {
+	switch v := value.(type) {
+	case string:
+		id, err := strconv.ParseUint(v, 10, 64)
+		return id, err == nil
+	case float64:
+		if v < 0 || v != float64(uint64(v)) {
+			return 0, false
+		}
+		return uint64(v), true
+	}
+	return 0, false
+}
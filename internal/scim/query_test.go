@@ -0,0 +1,117 @@
+package scim
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testAttributes = Attributes{
+	"id":           {Column: "users.id", Type: TypeInteger},
+	"externalid":   {Column: "users.external_id", CaseExact: true},
+	"username":     {Column: "users.username"},
+	"emails.value": {Column: "users.email"},
+	"active": {Column: "users.status", Type: TypeBoolean, Bool: func(active bool) interface{} {
+		if active {
+			return "enabled"
+		}
+		return "disabled"
+	}},
+	"meta.created": {Column: "users.created_at", Type: TypeDateTime},
+	"groups":       {Member: "users.id IN (SELECT user_id FROM user_roles WHERE role_id = ?)"},
+}
+
+func TestToSQL(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name     string
+		filter   string
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{name: "case-insensitive string", filter: `userName eq "BJensen"`, wantSQL: "LOWER(users.username) = ?", wantArgs: []interface{}{"bjensen"}},
+		{name: "case-exact string", filter: `externalId ne "AbC"`, wantSQL: "users.external_id <> ?", wantArgs: []interface{}{"AbC"}},
+		{name: "core schema prefix", filter: SchemaUser + `:userName sw "b"`, wantSQL: "LOWER(users.username) LIKE ? ESCAPE '!'", wantArgs: []interface{}{"b%"}},
+		{name: "like wildcards escaped", filter: `userName co "50%_off!"`, wantSQL: "LOWER(users.username) LIKE ? ESCAPE '!'", wantArgs: []interface{}{"%50!%!_off!!%"}},
+		{name: "ends with", filter: `userName ew "sen"`, wantSQL: "LOWER(users.username) LIKE ? ESCAPE '!'", wantArgs: []interface{}{"%sen"}},
+		{name: "boolean mapped", filter: `active eq false`, wantSQL: "users.status = ?", wantArgs: []interface{}{"disabled"}},
+		{name: "integer from string", filter: `id ge "7"`, wantSQL: "users.id >= ?", wantArgs: []interface{}{uint64(7)}},
+		{name: "datetime", filter: `meta.created lt "2024-01-02T03:04:05Z"`, wantSQL: "users.created_at < ?", wantArgs: []interface{}{created}},
+		{name: "null", filter: `externalId eq null`, wantSQL: "users.external_id IS NULL"},
+		{name: "present string", filter: `userName pr`, wantSQL: "(users.username IS NOT NULL AND users.username <> '')"},
+		{name: "present other", filter: `id pr`, wantSQL: "users.id IS NOT NULL"},
+		{name: "member", filter: `groups eq "3"`, wantSQL: "users.id IN (SELECT user_id FROM user_roles WHERE role_id = ?)", wantArgs: []interface{}{uint64(3)}},
+		{name: "member with impossible id", filter: `groups eq "admins"`, wantSQL: "1 = 0"},
+		{name: "value path", filter: `emails[value ew "@example.com"]`, wantSQL: "LOWER(users.email) LIKE ? ESCAPE '!'", wantArgs: []interface{}{"%@example.com"}},
+		{name: "logical and not", filter: `not (active eq true) or userName eq "a" and id eq 2`,
+			wantSQL:  "(NOT (users.status = ?) OR (LOWER(users.username) = ? AND users.id = ?))",
+			wantArgs: []interface{}{"enabled", "a", uint64(2)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseFilter(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sql, args, err := ToSQL(expr, testAttributes)
+			if err != nil {
+				t.Fatalf("ToSQL(%q) err = %v", tt.filter, err)
+			}
+			if sql != tt.wantSQL {
+				t.Fatalf("ToSQL(%q) sql = %q, want %q", tt.filter, sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Fatalf("ToSQL(%q) args = %#v, want %#v", tt.filter, args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestToSQLRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  string
+		wantErr string
+	}{
+		{name: "unknown attribute", filter: `password eq "x"`, wantErr: "not filterable"},
+		{name: "other schema prefix", filter: SchemaGroup + `:members eq "1"`, wantErr: "not filterable"},
+		{name: "text operator on integer", filter: `id co "1"`, wantErr: "not supported"},
+		{name: "non-numeric id", filter: `id eq "abc"`, wantErr: "numeric id"},
+		{name: "boolean ordering", filter: `active gt true`, wantErr: "eq/ne"},
+		{name: "boolean from string", filter: `active eq "true"`, wantErr: "eq/ne"},
+		{name: "bad timestamp", filter: `meta.created gt "yesterday"`, wantErr: "RFC 3339"},
+		{name: "string with number", filter: `userName eq 1`, wantErr: "expects a string"},
+		{name: "ordering with null", filter: `userName gt null`, wantErr: "null"},
+		{name: "member ne", filter: `groups ne "1"`, wantErr: "only eq"},
+		{name: "member present", filter: `groups pr`, wantErr: "not supported"},
+		{name: "nested value path", filter: `emails[value[type pr]]`, wantErr: "nested value path"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseFilter(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, _, err = ToSQL(expr, testAttributes)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ToSQL(%q) err = %v, want %q", tt.filter, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNormalizePath(t *testing.T) {
+	tests := map[string]string{
+		"userName":                            "username",
+		" " + SchemaUser + ":Name.GivenName ": "name.givenname",
+		SchemaGroup + ":displayName":          "displayname",
+		SchemaEnterpriseUser + ":department":  strings.ToLower(SchemaEnterpriseUser) + ":department",
+	}
+	for path, want := range tests {
+		if got := NormalizePath(path); got != want {
+			t.Fatalf("NormalizePath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package scim
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// ContentType is the media type of SCIM requests and responses.
+const ContentType = "application/scim+json"
+
+// Error scimType values (RFC 7644 section 3.12).
+const (
+	ErrorInvalidFilter = "invalidFilter"
+	ErrorInvalidPath   = "invalidPath"
+	ErrorInvalidSyntax = "invalidSyntax"
+	ErrorInvalidValue  = "invalidValue"
+	ErrorMutability    = "mutability"
+	ErrorUniqueness    = "uniqueness"
+	ErrorNoTarget      = "noTarget"
+	ErrorTooMany       = "tooMany"
+)
+
+// Error is the SCIM error response body; Status is the HTTP status as a string.
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// WriteJSON writes payload as application/scim+json.
+func WriteJSON(w http.ResponseWriter, r *http.Request, status int, payload interface{}) {
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(status)
+	if payload == nil {
+		return
+	}
+	if err := json.NewEncoder(w).Encode(payload); err != nil {
+		logx.WithContext(r.Context()).Errorf("write scim response failed: %v", err)
+	}
+}
+
+// WriteError writes a SCIM error response.
+func WriteError(w http.ResponseWriter, r *http.Request, status int, scimType, detail string) {
+	WriteJSON(w, r, status, Error{
+		Schemas:  []string{SchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
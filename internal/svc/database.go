@@ -0,0 +1,104 @@
+package svc
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/proc"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"usermgmt/internal/config"
+	"usermgmt/internal/dialect"
+)
+
+// openDialector picks the GORM dialector for the configured driver. Settings the service relies
+// on are added to the DSN unless it sets them itself: MySQL must scan DATETIME into time.Time, and
+// SQLite must enforce foreign keys (ON DELETE CASCADE) and take the write lock when a transaction
+// begins, so that concurrent transactions wait for each other instead of failing with SQLITE_BUSY.
+func openDialector(c config.DatabaseConf) (gorm.Dialector, error) {
+	switch driver := strings.ToLower(strings.TrimSpace(c.Driver)); driver {
+	case "", dialect.Postgres:
+		return postgres.Open(c.DSN), nil
+	case dialect.MySQL:
+		return mysql.Open(withParams(c.DSN, "parseTime=true")), nil
+	case dialect.SQLite:
+		dsn := c.DSN
+		if dsn == "" {
+			dsn = "usermgmt.db"
+		}
+		return sqlite.Open(withParams(dsn, "_pragma=foreign_keys(1)", "_txlock=immediate")), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", c.Driver)
+	}
+}
+
+// withParams appends the query parameters that dsn does not set yet. A parameter is identified by
+// its key, or by the pragma name for _pragma=name(value).
+func withParams(dsn string, params ...string) string {
+	for _, param := range params {
+		eq := strings.Index(param, "=")
+		key := param[:eq]
+		if open := strings.Index(param, "("); open > eq {
+			key = param[eq+1 : open]
+		}
+		if strings.Contains(dsn, key) {
+			continue
+		}
+		if strings.Contains(dsn, "?") {
+			dsn += "&" + param
+		} else {
+			dsn += "?" + param
+		}
+	}
+	return dsn
+}
+
+func isSQLiteMemory(c config.DatabaseConf) bool {
+	return strings.EqualFold(strings.TrimSpace(c.Driver), dialect.SQLite) &&
+		(strings.Contains(c.DSN, ":memory:") || strings.Contains(c.DSN, "mode=memory"))
+}
+
+// openWithRetry opens the database, retrying up to ConnectAttempts times with a backoff that starts
+// at ConnectBackoff and doubles up to ConnectMaxBackoff, so that the service can start before its
+// database does (e.g. both brought up by the same compose file). A shutdown signal stops retrying.
+func openWithRetry(c config.DatabaseConf, dialector gorm.Dialector, opts *gorm.Config) (*gorm.DB, error) {
+	attempts := c.ConnectAttempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+	backoff := c.ConnectBackoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	for attempt := 1; ; attempt++ {
+		db, err := gorm.Open(dialector, opts)
+		if err == nil {
+			return db, nil
+		}
+		if db != nil {
+			// gorm.Open leaves the pool open when the first ping fails.
+			if sqlDB, dbErr := db.DB(); dbErr == nil {
+				sqlDB.Close()
+			}
+		}
+		if attempt >= attempts {
+			return nil, fmt.Errorf("giving up after %d attempts: %w", attempts, err)
+		}
+
+		logx.Errorf("connect database failed (attempt %d/%d), retrying in %s: %v", attempt, attempts, backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-proc.Done():
+			return nil, fmt.Errorf("shutting down after %d attempts: %w", attempt, err)
+		}
+		backoff *= 2
+		if c.ConnectMaxBackoff > 0 && backoff > c.ConnectMaxBackoff {
+			backoff = c.ConnectMaxBackoff
+		}
+	}
+}
@@ -1,71 +1,272 @@
 package svc
 
 import (
-	"log"
+	"context"
+	"reflect"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/prometheus"
 	"github.com/zeromicro/go-zero/rest"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 
+	migrations "usermgmt/db"
+	"usermgmt/internal/authn"
 	"usermgmt/internal/config"
+	"usermgmt/internal/event"
+	"usermgmt/internal/i18n"
+	"usermgmt/internal/logging"
+	"usermgmt/internal/metrics"
 	"usermgmt/internal/middleware"
 	"usermgmt/internal/model"
+	"usermgmt/internal/policy"
+	"usermgmt/internal/repository"
+	"usermgmt/internal/responder"
+	"usermgmt/internal/tenant"
+	"usermgmt/internal/tracing"
+	"usermgmt/internal/webhook"
 )
 
 // ServiceContext wires together shared resources that handlers and logic layers rely on.
 type ServiceContext struct {
-	Config         config.Config
-	DB             *gorm.DB
-	Validator      *validator.Validate
-	AuthMiddleware rest.Middleware
-	RoleGuard      func(roles ...string) rest.Middleware
+	Config           config.Config
+	DB               *gorm.DB
+	Store            repository.Store
+	Validator        *validator.Validate
+	AuthMiddleware   rest.Middleware
+	RoleGuard        func(roles ...string) rest.Middleware
+	Tenants          *tenant.Resolver
+	TenantMiddleware rest.Middleware
+	OrgAdminGuard    func(globalRoles ...string) rest.Middleware
+	Policy           *policy.Engine
+	Webhooks         *webhook.Dispatcher
+	Events           *event.Bus
+	Outbox           *event.Relay
+	SCIMMiddleware   rest.Middleware
+	Authenticator    *authn.Chain
+	I18n             *i18n.Bundle
+	Responder        *responder.Responder
+
+	draining atomic.Bool
 }
 
 // NewServiceContext builds the service context with DB, validator and middlewares.
 func NewServiceContext(c config.Config) *ServiceContext {
 	db := mustInitDB(c)
-	validate := validator.New(validator.WithRequiredStructEnabled())
+	ctx := newServiceContext(c, repository.NewGormStore(db))
+	ctx.DB = db
+	ctx.Webhooks = webhook.NewDispatcher(db, c.Webhooks)
+	ctx.Outbox = event.NewRelay(db, c.Outbox, ctx.Events, ctx.Webhooks)
+	ctx.Tenants = tenant.NewResolver(db)
+	ctx.TenantMiddleware = middleware.NewTenantMiddleware(ctx.Tenants, c.Tenancy.Header, c.Tenancy.SuperAdminRole, ctx.Responder).Handle
+	return ctx
+}
+
+// NewServiceContextWithStore builds a service context without a database, e.g. over
+// repository.NewMemory() in unit tests. Only logic that goes through Store works; DB, Tenants,
+// Webhooks and Outbox stay nil.
+func NewServiceContextWithStore(c config.Config, store repository.Store) *ServiceContext {
+	return newServiceContext(c, store)
+}
+
+func newServiceContext(c config.Config, store repository.Store) *ServiceContext {
+	engine, err := policy.NewEngine(c.Policy.File)
+	if err != nil {
+		logx.Errorf("failed to load policy file %s: %v", c.Policy.File, err)
+		panic(err)
+	}
+
+	bundle, err := i18n.NewBundle(c.I18n.DefaultLocale, c.I18n.Dir)
+	if err != nil {
+		logx.Errorf("failed to load locales: %v", err)
+		panic(err)
+	}
 
 	ctx := &ServiceContext{
 		Config:    c,
-		DB:        db,
-		Validator: validate,
+		Store:     store,
+		Validator: newValidator(),
+		Policy:    engine,
+		Events:    event.NewBus(),
+		I18n:      bundle,
+		Responder: responder.New(c.Errors),
 	}
-	ctx.AuthMiddleware = middleware.NewAuthMiddleware(c.JWT.AccessSecret).Handle
+	if c.Metrics.Enabled {
+		// go-zero records its metric vectors, including the HTTP server ones, only once enabled.
+		prometheus.Enable()
+	}
+	metrics.SubscribeUserEvents(ctx.Events)
+	ctx.Authenticator = mustInitAuthenticator(c, store.Users())
+	ctx.AuthMiddleware = middleware.NewAuthMiddleware(c.JWT.AccessSecret, ctx.Responder).Handle
 	ctx.RoleGuard = func(roles ...string) rest.Middleware {
-		return middleware.NewRoleGuard(roles...)
+		return middleware.NewRoleGuard(ctx.Responder, roles...)
+	}
+	ctx.SCIMMiddleware = middleware.NewSCIMAuthMiddleware(c.SCIM.Token).Handle
+	ctx.OrgAdminGuard = func(globalRoles ...string) rest.Middleware {
+		return middleware.NewOrgAdminGuard(ctx.Responder, c.Tenancy.SuperAdminRole, c.Tenancy.OrgAdminRole, globalRoles...)
 	}
 	return ctx
 }
 
-// AutoMigrate ensures schema is created or updated.
+// newValidator reports fields by the name clients send, taken from the json, form, header or path
+// tag, so that validation details match the ones errorx.FromParseError gives.
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, key := range []string{"json", "form", "header", "path"} {
+			if name, _, _ := strings.Cut(field.Tag.Get(key), ","); name != "" && name != "-" {
+				return name
+			}
+		}
+		return field.Name
+	})
+	return v
+}
+
+// schemaModels are the models AutoMigrate creates, in dependency order.
+var schemaModels = []interface{}{
+	&model.User{},
+	&model.Role{},
+	&model.Permission{},
+	&model.UserRole{},
+	&model.RolePermission{},
+	&model.AuditLog{},
+	&model.Organization{},
+	&model.OrgMember{},
+	&model.OrgMemberRole{},
+	&model.Group{},
+	&model.GroupMember{},
+	&model.GroupRole{},
+	&model.ChangeRequest{},
+	&model.AccessReview{},
+	&model.AccessReviewItem{},
+	&model.WebhookSubscription{},
+	&model.WebhookDelivery{},
+	&model.OutboxEvent{},
+	&model.SchemaMigration{},
+}
+
+// AutoMigrate ensures schema is created or updated. It records no version in schema_migrations,
+// which only lists the SQL migrations under db/migrations that were run; readiness checks the
+// tables and columns themselves through MissingSchema.
 func (s *ServiceContext) AutoMigrate() error {
-	return s.DB.AutoMigrate(
-		&model.User{},
-		&model.Role{},
-		&model.Permission{},
-		&model.UserRole{},
-		&model.RolePermission{},
-	)
+	return s.DB.AutoMigrate(schemaModels...)
+}
+
+// MissingSchema lists the tables of the schema that do not exist in the database, and the columns
+// missing from the tables that do, e.g. "webhook_deliveries" or "users.auth_source".
+func (s *ServiceContext) MissingSchema(ctx context.Context) ([]string, error) {
+	migrator := s.DB.WithContext(ctx).Migrator()
+	var missing []string
+	for _, m := range schemaModels {
+		stmt := &gorm.Statement{DB: s.DB}
+		if err := stmt.Parse(m); err != nil {
+			return nil, err
+		}
+		if !migrator.HasTable(m) {
+			missing = append(missing, stmt.Table)
+			continue
+		}
+		columns, err := migrator.ColumnTypes(m)
+		if err != nil {
+			return nil, err
+		}
+		existing := make(map[string]struct{}, len(columns))
+		for _, column := range columns {
+			existing[strings.ToLower(column.Name())] = struct{}{}
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" || field.IgnoreMigration {
+				continue
+			}
+			if _, ok := existing[strings.ToLower(field.DBName)]; !ok {
+				missing = append(missing, stmt.Table+"."+field.DBName)
+			}
+		}
+	}
+	return missing, nil
+}
+
+// SchemaVersions returns the newest migration version recorded in schema_migrations (0 if none)
+// and the newest one embedded for the configured driver. A database migrated with SQL is behind
+// while applied is less than latest; one built by AutoMigrate alone records no version.
+func (s *ServiceContext) SchemaVersions(ctx context.Context) (applied, latest uint, err error) {
+	latest, err = migrations.LatestVersion(s.Config.Database.Driver)
+	if err != nil {
+		return 0, 0, err
+	}
+	err = s.DB.WithContext(ctx).Model(&model.SchemaMigration{}).
+		Select("COALESCE(MAX(version), 0)").Scan(&applied).Error
+	return applied, latest, err
+}
+
+// StartDraining marks the service as shutting down, so that /readyz fails and the load balancer
+// stops routing to it while in-flight requests finish.
+func (s *ServiceContext) StartDraining() {
+	s.draining.Store(true)
+}
+
+// Draining reports whether StartDraining was called.
+func (s *ServiceContext) Draining() bool {
+	return s.draining.Load()
+}
+
+// Close closes the database connection pool, waiting for the queries in progress. Nothing may
+// use the context afterwards.
+func (s *ServiceContext) Close() error {
+	if s.DB == nil {
+		return nil
+	}
+	sqlDB, err := s.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// mustInitAuthenticator builds the login provider chain in the configured order.
+func mustInitAuthenticator(c config.Config, users repository.UserRepository) *authn.Chain {
+	names := c.Auth.Providers
+	if len(names) == 0 {
+		names = []string{model.AuthSourceLocal}
+	}
+	providers := make([]authn.Provider, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case model.AuthSourceLocal:
+			providers = append(providers, authn.NewLocalProvider(users))
+		case model.AuthSourceLDAP:
+			provider, err := authn.NewLDAPProvider(c.Auth.LDAP, nil)
+			if err != nil {
+				logx.Errorf("failed to configure ldap auth provider: %v", err)
+				panic(err)
+			}
+			providers = append(providers, provider)
+		default:
+			logx.Errorf("unknown auth provider %q", name)
+			panic("unknown auth provider " + name)
+		}
+	}
+	return authn.NewChain(providers...)
 }
 
 // mustInitDB establishes the GORM connection and tunes the connection pool.
 func mustInitDB(c config.Config) *gorm.DB {
-	gormLogger := logger.New(
-		log.New(log.Writer(), "GORM", log.LstdFlags),
-		logger.Config{
-			SlowThreshold:             time.Second,
-			LogLevel:                  logger.Info,
-			IgnoreRecordNotFoundError: true,
-			ParameterizedQueries:      true,
-		},
-	)
-
-	db, err := gorm.Open(postgres.Open(c.Database.DSN), &gorm.Config{Logger: gormLogger})
+	gormLogger, err := logging.NewGormLogger(c.Database.LogLevel, time.Second)
+	if err != nil {
+		logx.Errorf("invalid database config: %v", err)
+		panic(err)
+	}
+
+	dialector, err := openDialector(c.Database)
+	if err != nil {
+		logx.Errorf("invalid database config: %v", err)
+		panic(err)
+	}
+	db, err := openWithRetry(c.Database, dialector, &gorm.Config{Logger: gormLogger, TranslateError: true})
 	if err != nil {
 		logx.Errorf("failed to connect database: %v", err)
 		panic(err)
@@ -85,6 +286,10 @@ func mustInitDB(c config.Config) *gorm.DB {
 	if maxOpen <= 0 {
 		maxOpen = 30
 	}
+	if isSQLiteMemory(c.Database) {
+		// Every connection to :memory: opens a database of its own.
+		maxIdle, maxOpen = 1, 1
+	}
 	connLifetime := c.Database.ConnMaxLifetime
 	if connLifetime <= 0 {
 		connLifetime = time.Hour
@@ -94,5 +299,13 @@ func mustInitDB(c config.Config) *gorm.DB {
 	sqlDB.SetMaxOpenConns(maxOpen)
 	sqlDB.SetConnMaxLifetime(connLifetime)
 
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		panic(err)
+	}
+	if err := db.Use(tracing.GormPlugin{System: c.Database.Driver}); err != nil {
+		panic(err)
+	}
+	metrics.WatchPool(sqlDB, c.Database.Driver)
+
 	return db
 }
@@ -0,0 +1,86 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"usermgmt/internal/errorx"
+	"usermgmt/internal/model"
+	"usermgmt/internal/types"
+)
+
+// Resolver looks up organisations and the roles a user holds inside them.
+type Resolver struct {
+	db *gorm.DB
+}
+
+// NewResolver creates a resolver backed by the given database.
+func NewResolver(db *gorm.DB) *Resolver {
+	return &Resolver{db: db}
+}
+
+// FindOrg loads an organisation by numeric ID or slug.
+func (r *Resolver) FindOrg(ctx context.Context, ref string) (*model.Organization, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, errorx.ErrOrgNotFound
+	}
+
+	query := r.db.WithContext(ctx)
+	if id, err := strconv.ParseUint(ref, 10, 64); err == nil {
+		query = query.Where("id = ?", id)
+	} else {
+		query = query.Where("slug = ?", strings.ToLower(ref))
+	}
+
+	var org model.Organization
+	if err := query.First(&org).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorx.ErrOrgNotFound
+		}
+		return nil, err
+	}
+	return &org, nil
+}
+
+// Resolve builds the tenant scope for userID inside the referenced organisation.
+// Super admins may enter any organisation; everyone else must be a member.
+func (r *Resolver) Resolve(ctx context.Context, userID uint, ref string, superAdmin bool) (*types.Tenant, error) {
+	org, err := r.FindOrg(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	db := r.db.WithContext(ctx)
+	var count int64
+	if err := db.Model(&model.OrgMember{}).
+		Where("org_id = ? AND user_id = ?", org.ID, userID).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 && !superAdmin {
+		return nil, errorx.ErrNotOrgMember
+	}
+
+	roles := make([]string, 0)
+	if count > 0 {
+		if err := db.Model(&model.OrgMemberRole{}).
+			Joins("JOIN roles ON roles.id = org_member_roles.role_id").
+			Where("org_member_roles.org_id = ? AND org_member_roles.user_id = ?", org.ID, userID).
+			Order("roles.name").
+			Pluck("roles.name", &roles).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return &types.Tenant{
+		OrgID:      org.ID,
+		OrgSlug:    org.Slug,
+		Roles:      roles,
+		SuperAdmin: superAdmin,
+	}, nil
+}
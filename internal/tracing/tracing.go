@@ -0,0 +1,69 @@
+// Package tracing adds OpenTelemetry spans below the per-route server spans go-zero already opens
+// (RestConf.Telemetry, Middlewares.Trace): one per logic method, per bcrypt operation and per GORM
+// statement. Spans go to the global tracer provider, so they are dropped unless Telemetry has an
+// Endpoint.
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"usermgmt/pkg/security"
+)
+
+const tracerName = "usermgmt"
+
+// UserIDKey is the attribute holding the ID of the authenticated caller.
+const UserIDKey = attribute.Key("enduser.id")
+
+func tracer() oteltrace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// Logic opens a span named name and points *ctx at it, so that the calls a logic method makes
+// with its l.ctx become children of the span. The returned func ends the span and restores *ctx,
+// which keeps spans flat when one logic value is called repeatedly:
+//
+//	defer tracing.Logic(&l.ctx, "ListUsersLogic.List")()
+func Logic(ctx *context.Context, name string) func() {
+	parent := *ctx
+	spanCtx, span := tracer().Start(parent, name, oteltrace.WithSpanKind(oteltrace.SpanKindInternal))
+	*ctx = spanCtx
+	return func() {
+		span.End()
+		*ctx = parent
+	}
+}
+
+// SetUserID tags the current span with the caller's user ID.
+func SetUserID(ctx context.Context, userID uint) {
+	oteltrace.SpanFromContext(ctx).SetAttributes(UserIDKey.Int64(int64(userID)))
+}
+
+// HashPassword is security.HashPassword inside a "bcrypt.hash" span.
+func HashPassword(ctx context.Context, password string, cost int) (string, error) {
+	_, span := tracer().Start(ctx, "bcrypt.hash", oteltrace.WithAttributes(attribute.Int("bcrypt.cost", cost)))
+	defer span.End()
+
+	hash, err := security.HashPassword(password, cost)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return hash, err
+}
+
+// VerifyPassword is security.VerifyPassword inside a "bcrypt.verify" span. A mismatch is an
+// expected outcome, so it is recorded as bcrypt.match=false rather than as a span error.
+func VerifyPassword(ctx context.Context, hashed, password string) error {
+	_, span := tracer().Start(ctx, "bcrypt.verify")
+	defer span.End()
+
+	err := security.VerifyPassword(hashed, password)
+	span.SetAttributes(attribute.Bool("bcrypt.match", err == nil))
+	return err
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -16,6 +17,7 @@ type RegisterRequest struct {
 type LoginRequest struct {
 	Username string `json:"username" validate:"required"`
 	Password string `json:"password" validate:"required"`
+	Org      string `json:"org,optional"`
 }
 
 type LoginResponse struct {
@@ -26,14 +28,25 @@ type LoginResponse struct {
 }
 
 type UserDTO struct {
-	ID        uint      `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	FullName  string    `json:"fullName"`
-	Status    string    `json:"status"`
-	Roles     []string  `json:"roles"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID          uint           `json:"id"`
+	Username    string         `json:"username"`
+	Email       string         `json:"email"`
+	FullName    string         `json:"fullName"`
+	Department  string         `json:"department"`
+	Status      string         `json:"status"`
+	Roles       []string       `json:"roles"`
+	RoleGrants  []RoleGrantDTO `json:"roleGrants"`
+	Groups      []string       `json:"groups"`
+	RoleVersion uint           `json:"roleVersion"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	UpdatedAt   time.Time      `json:"updatedAt"`
+}
+
+type RoleGrantDTO struct {
+	Role      string     `json:"role"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	GrantedBy *uint      `json:"grantedBy,omitempty"`
+	GrantedAt time.Time  `json:"grantedAt"`
 }
 
 type ProfileResponse struct {
@@ -51,10 +64,11 @@ type ChangePasswordRequest struct {
 }
 
 type ListUsersRequest struct {
-	Page     int    `form:"page"`
-	PageSize int    `form:"pageSize"`
-	Keyword  string `form:"keyword"`
-	Status   string `form:"status"`
+	Page     int    `form:"page,optional"`
+	PageSize int    `form:"pageSize,optional"`
+	Keyword  string `form:"keyword,optional"`
+	Status   string `form:"status,optional"`
+	Group    string `form:"group,optional"`
 }
 
 type ListUsersResponse struct {
@@ -70,11 +84,384 @@ type UpdateUserStatusRequest struct {
 }
 
 type AssignRolesRequest struct {
-	Roles []string `json:"roles" validate:"required,min=1,dive,required"`
+	Roles   []string `json:"roles" validate:"dive,required"`
+	IfMatch string   `header:"If-Match,optional"`
+}
+
+type GrantRoleRequest struct {
+	ExpiresAt string `json:"expiresAt,optional" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	TTL       string `json:"ttl,optional"`
+}
+
+type RoleDTO struct {
+	Name                 string   `json:"name"`
+	Description          string   `json:"description"`
+	Parent               string   `json:"parent,omitempty"`
+	Ancestors            []string `json:"ancestors"`
+	Permissions          []string `json:"permissions"`
+	EffectivePermissions []string `json:"effectivePermissions"`
+}
+
+type ListRolesResponse struct {
+	Data []RoleDTO `json:"data"`
+}
+
+type SetRoleParentRequest struct {
+	Parent string `json:"parent,optional"`
+}
+
+type UserPermissionsResponse struct {
+	UserID      uint     `json:"userId"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+}
+
+type PermissionPathDTO struct {
+	Role  string   `json:"role"`
+	Chain []string `json:"chain"`
+}
+
+type ExplainPermissionResponse struct {
+	UserID     uint                `json:"userId"`
+	Permission string              `json:"permission"`
+	Granted    bool                `json:"granted"`
+	Paths      []PermissionPathDTO `json:"paths"`
+}
+
+type GroupDTO struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Roles       []string  `json:"roles"`
+	MemberCount int64     `json:"memberCount"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type ListGroupsResponse struct {
+	Data []GroupDTO `json:"data"`
+}
+
+type CreateGroupRequest struct {
+	Name        string   `json:"name" validate:"required,min=2,max=50"`
+	Description string   `json:"description,optional" validate:"max=255"`
+	Roles       []string `json:"roles,optional" validate:"dive,required"`
+}
+
+type SetGroupRolesRequest struct {
+	Roles []string `json:"roles" validate:"dive,required"`
+}
+
+type AddGroupMembersRequest struct {
+	UserIDs []uint `json:"userIds" validate:"required,min=1,dive,gt=0"`
+}
+
+type ExplainPolicyRequest struct {
+	Action     string            `json:"action" validate:"required"`
+	SubjectID  uint              `json:"subjectId,optional"`
+	ResourceID uint              `json:"resourceId" validate:"required,gt=0"`
+	Params     map[string]string `json:"params,optional"`
+}
+
+type PolicyRuleDTO struct {
+	ID          string `json:"id"`
+	Effect      string `json:"effect"`
+	Description string `json:"description"`
+}
+
+type ExplainPolicyResponse struct {
+	Action        string                 `json:"action"`
+	Decision      string                 `json:"decision"`
+	DecidingRule  string                 `json:"decidingRule,omitempty"`
+	Default       bool                   `json:"default"`
+	Matched       []PolicyRuleDTO        `json:"matched"`
+	PolicyVersion string                 `json:"policyVersion"`
+	Subject       map[string]interface{} `json:"subject"`
+	Resource      map[string]interface{} `json:"resource"`
+	Params        map[string]interface{} `json:"params"`
+}
+
+type ChangeRequestDTO struct {
+	ID           uint                   `json:"id"`
+	Action       string                 `json:"action"`
+	TargetUserID uint                   `json:"targetUserId"`
+	Payload      map[string]interface{} `json:"payload"`
+	Status       string                 `json:"status"`
+	RequestedBy  uint                   `json:"requestedBy"`
+	ReviewedBy   *uint                  `json:"reviewedBy,omitempty"`
+	Comment      string                 `json:"comment,omitempty"`
+	Error        string                 `json:"error,omitempty"`
+	ExpiresAt    time.Time              `json:"expiresAt"`
+	ReviewedAt   *time.Time             `json:"reviewedAt,omitempty"`
+	CreatedAt    time.Time              `json:"createdAt"`
+}
+
+type ListApprovalsRequest struct {
+	Status string `form:"status,optional" validate:"omitempty,oneof=pending approved rejected expired failed"`
+}
+
+type ListApprovalsResponse struct {
+	Data []ChangeRequestDTO `json:"data"`
+}
+
+type ReviewApprovalRequest struct {
+	Comment string `json:"comment,optional" validate:"max=500"`
+}
+
+type CreateAccessReviewRequest struct {
+	Name        string   `json:"name" validate:"required,max=100"`
+	Roles       []string `json:"roles" validate:"required,min=1,dive,required"`
+	ReviewerIDs []uint   `json:"reviewerIds" validate:"required,min=1,dive,gt=0"`
+	Deadline    string   `json:"deadline" validate:"required"`
+	AutoRevoke  bool     `json:"autoRevoke,optional"`
+}
+
+type AccessReviewItemDTO struct {
+	ID             uint       `json:"id"`
+	ReviewID       uint       `json:"reviewId"`
+	UserID         uint       `json:"userId"`
+	Username       string     `json:"username"`
+	Role           string     `json:"role"`
+	GrantExpiresAt *time.Time `json:"grantExpiresAt,omitempty"`
+	ReviewerID     *uint      `json:"reviewerId,omitempty"`
+	Decision       string     `json:"decision,omitempty"`
+	DecidedBy      *uint      `json:"decidedBy,omitempty"`
+	DecidedAt      *time.Time `json:"decidedAt,omitempty"`
+	Comment        string     `json:"comment,omitempty"`
+	Outcome        string     `json:"outcome,omitempty"`
+	Error          string     `json:"error,omitempty"`
+}
+
+type AccessReviewDTO struct {
+	ID         uint                  `json:"id"`
+	Name       string                `json:"name"`
+	Roles      []string              `json:"roles"`
+	Status     string                `json:"status"`
+	Deadline   time.Time             `json:"deadline"`
+	AutoRevoke bool                  `json:"autoRevoke"`
+	CreatedBy  *uint                 `json:"createdBy,omitempty"`
+	ClosedBy   *uint                 `json:"closedBy,omitempty"`
+	ClosedAt   *time.Time            `json:"closedAt,omitempty"`
+	Total      int64                 `json:"total"`
+	Decided    int64                 `json:"decided"`
+	CreatedAt  time.Time             `json:"createdAt"`
+	Items      []AccessReviewItemDTO `json:"items,omitempty"`
+}
+
+type ListAccessReviewsResponse struct {
+	Data []AccessReviewDTO `json:"data"`
+}
+
+type ExportAccessReviewRequest struct {
+	Format string `form:"format,optional" validate:"omitempty,oneof=csv ndjson"`
+}
+
+type ListReviewItemsRequest struct {
+	ReviewID uint `form:"reviewId,optional"`
+	All      bool `form:"all,optional"`
+}
+
+type ListReviewItemsResponse struct {
+	Data []AccessReviewItemDTO `json:"data"`
+}
+
+type DecideReviewItemRequest struct {
+	Decision string `json:"decision" validate:"required,oneof=keep revoke"`
+	Comment  string `json:"comment,optional" validate:"max=500"`
+}
+
+type CreateWebhookRequest struct {
+	Name   string   `json:"name" validate:"required,max=100"`
+	URL    string   `json:"url" validate:"required,url,max=500"`
+	Events []string `json:"events" validate:"required,min=1,dive,required"`
+	Secret string   `json:"secret,optional" validate:"omitempty,min=16,max=100"`
+	Active *bool    `json:"active,optional"`
+}
+
+type UpdateWebhookRequest struct {
+	Name         string   `json:"name" validate:"required,max=100"`
+	URL          string   `json:"url" validate:"required,url,max=500"`
+	Events       []string `json:"events" validate:"required,min=1,dive,required"`
+	Active       *bool    `json:"active,optional"`
+	RotateSecret bool     `json:"rotateSecret,optional"`
+}
+
+// WebhookDTO only carries Secret when it was just created or rotated.
+type WebhookDTO struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type ListWebhooksResponse struct {
+	Data []WebhookDTO `json:"data"`
+}
+
+type ListWebhookDeliveriesRequest struct {
+	Status string `form:"status,optional" validate:"omitempty,oneof=pending succeeded failed"`
+	Limit  int    `form:"limit,optional" validate:"omitempty,min=1,max=500"`
+}
+
+type WebhookDeliveryDTO struct {
+	ID             uint            `json:"id"`
+	SubscriptionID uint            `json:"subscriptionId"`
+	EventID        string          `json:"eventId"`
+	Event          string          `json:"event"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"nextAttemptAt"`
+	LastStatusCode int             `json:"lastStatusCode,omitempty"`
+	LastError      string          `json:"lastError,omitempty"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	Payload        json.RawMessage `json:"payload"`
+}
+
+type ListWebhookDeliveriesResponse struct {
+	Data []WebhookDeliveryDTO `json:"data"`
+}
+
+type OrgDTO struct {
+	ID        uint      `json:"id"`
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type CreateOrgRequest struct {
+	Slug string `json:"slug" validate:"required,min=2,max=50"`
+	Name string `json:"name" validate:"required,min=2,max=100"`
+}
+
+type ListOrgsResponse struct {
+	Data []OrgDTO `json:"data"`
+}
+
+type UpsertOrgMemberRequest struct {
+	Roles []string `json:"roles" validate:"dive,required"`
+}
+
+type ListOrgMembersResponse struct {
+	Data []OrgMemberDTO `json:"data"`
+}
+
+type OrgMemberDTO struct {
+	OrgID    uint     `json:"orgId"`
+	UserID   uint     `json:"userId"`
+	Username string   `json:"username"`
+	Roles    []string `json:"roles"`
+}
+
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+type ReadinessResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+type VersionResponse struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"buildTime"`
+	GoVersion string `json:"goVersion"`
+	Modified  bool   `json:"modified"`
+}
+
+type BulkUserFilter struct {
+	Keyword string `json:"keyword,optional"`
+	Status  string `json:"status,optional" validate:"omitempty,oneof=enabled disabled"`
+	Role    string `json:"role,optional"`
+	All     bool   `json:"all,optional"`
+}
+
+type BulkUserRequest struct {
+	Action string          `json:"action" validate:"required,oneof=status addRoles removeRoles delete"`
+	IDs    []uint          `json:"ids,optional" validate:"omitempty,dive,gt=0"`
+	Filter *BulkUserFilter `json:"filter,optional"`
+	Status string          `json:"status,optional" validate:"required_if=Action status,omitempty,oneof=enabled disabled"`
+	Roles  []string        `json:"roles,optional" validate:"required_if=Action addRoles,required_if=Action removeRoles,dive,required"`
+	Mode   string          `json:"mode,optional" validate:"omitempty,oneof=atomic bestEffort"`
+	DryRun bool            `json:"dryRun,optional"`
+}
+
+type BulkUserResult struct {
+	UserID   uint   `json:"userId"`
+	Username string `json:"username,omitempty"`
+	Outcome  string `json:"outcome"`
+	Changed  bool   `json:"changed"`
+	Code     string `json:"code,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+type BulkUserResponse struct {
+	Action    string           `json:"action"`
+	Mode      string           `json:"mode"`
+	DryRun    bool             `json:"dryRun"`
+	Total     int              `json:"total"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkUserResult `json:"results"`
+}
+
+type ImportUsersRequest struct {
+	Format string `form:"format,optional" validate:"omitempty,oneof=csv ndjson"`
+	Mode   string `form:"mode,optional" validate:"omitempty,oneof=create upsert"`
+	DryRun bool   `form:"dryRun,optional"`
+}
+
+type ImportUserRow struct {
+	Username     string   `json:"username" validate:"required,min=3,max=50"`
+	Email        string   `json:"email" validate:"required,email"`
+	FullName     string   `json:"fullName" validate:"required,min=2,max=100"`
+	Department   string   `json:"department" validate:"max=100"`
+	Roles        []string `json:"roles" validate:"dive,required"`
+	Status       string   `json:"status" validate:"omitempty,oneof=enabled disabled"`
+	PasswordHash string   `json:"passwordHash"`
+}
+
+type ImportRowError struct {
+	Row      int         `json:"row"`
+	Username string      `json:"username,omitempty"`
+	Code     string      `json:"code"`
+	Message  string      `json:"message"`
+	Details  interface{} `json:"details,omitempty"`
+}
+
+type ImportUsersResponse struct {
+	Format  string           `json:"format"`
+	Mode    string           `json:"mode"`
+	DryRun  bool             `json:"dryRun"`
+	Total   int              `json:"total"`
+	Created int              `json:"created"`
+	Updated int              `json:"updated"`
+	Failed  int              `json:"failed"`
+	Errors  []ImportRowError `json:"errors"`
+}
+
+type ExportUsersRequest struct {
+	Format  string `form:"format,optional" validate:"omitempty,oneof=csv ndjson"`
+	Keyword string `form:"keyword,optional"`
+	Status  string `form:"status,optional"`
 }
 
 type JwtClaims struct {
 	jwt.RegisteredClaims
-	UserID uint     `json:"userId"`
-	Roles  []string `json:"roles"`
+	UserID        uint             `json:"userId"`
+	Roles         []string         `json:"roles"`
+	RoleExpiresAt map[string]int64 `json:"roleExp,omitempty"`
+	OrgID         uint             `json:"orgId,omitempty"`
+}
+
+// Tenant is the organisation a request operates in, resolved from the token or a header.
+type Tenant struct {
+	OrgID      uint
+	OrgSlug    string
+	Roles      []string
+	SuperAdmin bool
 }
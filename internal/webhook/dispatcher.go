@@ -0,0 +1,223 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+
+	"usermgmt/internal/config"
+	"usermgmt/internal/event"
+	"usermgmt/internal/model"
+	"usermgmt/internal/retry"
+)
+
+// Dispatcher is the webhook sink of the outbox relay: it queues events for matching subscriptions
+// and delivers due attempts. Deliveries live in the webhook_deliveries table, so retries survive
+// restarts.
+type Dispatcher struct {
+	db     *gorm.DB
+	conf   config.WebhookConf
+	client *http.Client
+}
+
+// NewDispatcher builds a dispatcher with defaults filled in for unset config values.
+func NewDispatcher(db *gorm.DB, conf config.WebhookConf) *Dispatcher {
+	if conf.Timeout <= 0 {
+		conf.Timeout = 10 * time.Second
+	}
+	if conf.MaxAttempts <= 0 {
+		conf.MaxAttempts = 8
+	}
+	if conf.BaseBackoff <= 0 {
+		conf.BaseBackoff = 30 * time.Second
+	}
+	if conf.MaxBackoff < conf.BaseBackoff {
+		conf.MaxBackoff = conf.BaseBackoff
+	}
+	if conf.Batch <= 0 {
+		conf.Batch = 100
+	}
+	if conf.Concurrency <= 0 {
+		conf.Concurrency = 8
+	}
+	return &Dispatcher{
+		db:     db,
+		conf:   conf,
+		client: &http.Client{Timeout: conf.Timeout},
+	}
+}
+
+func (d *Dispatcher) Name() string {
+	return "webhook"
+}
+
+// Publish queues msg for every active subscription that wants it. Subscriptions that already have
+// a delivery for msg.ID are skipped, so a relay retry does not queue the event twice.
+func (d *Dispatcher) Publish(ctx context.Context, msg event.Message) error {
+	db := d.db.WithContext(ctx)
+
+	var subscriptions []model.WebhookSubscription
+	if err := db.Where("active = ?", true).Find(&subscriptions).Error; err != nil {
+		return err
+	}
+
+	var queued []uint
+	if err := db.Model(&model.WebhookDelivery{}).Where("event_id = ?", msg.ID).Pluck("subscription_id", &queued).Error; err != nil {
+		return err
+	}
+	skip := make(map[uint]struct{}, len(queued))
+	for _, id := range queued {
+		skip[id] = struct{}{}
+	}
+
+	now := time.Now()
+	deliveries := make([]model.WebhookDelivery, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		if _, ok := skip[subscription.ID]; ok || !Matches(subscription.Events, msg.Type) {
+			continue
+		}
+		deliveries = append(deliveries, model.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			EventID:        msg.ID,
+			Event:          msg.Type,
+			Payload:        string(msg.Body),
+			Status:         model.WebhookDeliveryPending,
+			NextAttemptAt:  now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return db.Create(&deliveries).Error
+}
+
+// Redeliver queues a fresh attempt of an earlier delivery with the same event ID and payload.
+// The original delivery keeps its history.
+func (d *Dispatcher) Redeliver(ctx context.Context, original *model.WebhookDelivery) (*model.WebhookDelivery, error) {
+	delivery := model.WebhookDelivery{
+		SubscriptionID: original.SubscriptionID,
+		EventID:        original.EventID,
+		Event:          original.Event,
+		Payload:        original.Payload,
+		Status:         model.WebhookDeliveryPending,
+		NextAttemptAt:  time.Now(),
+	}
+	if err := d.db.WithContext(ctx).Create(&delivery).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// DeliverDue sends up to one batch of deliveries whose next attempt is due and returns how many
+// were attempted. Each delivery is claimed by pushing its next attempt past the request timeout,
+// so concurrent dispatchers (e.g. several replicas) do not send it twice.
+func (d *Dispatcher) DeliverDue(ctx context.Context, now time.Time) (int, error) {
+	db := d.db.WithContext(ctx)
+
+	var due []model.WebhookDelivery
+	if err := db.Preload("Subscription").
+		Where("status = ? AND next_attempt_at <= ?", model.WebhookDeliveryPending, now).
+		Order("next_attempt_at").
+		Limit(d.conf.Batch).
+		Find(&due).Error; err != nil {
+		return 0, err
+	}
+
+	lease := now.Add(2 * d.conf.Timeout)
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, d.conf.Concurrency)
+	attempted := 0
+	for i := range due {
+		delivery := &due[i]
+		claim := db.Model(&model.WebhookDelivery{}).
+			Where("id = ? AND status = ? AND next_attempt_at = ?", delivery.ID, model.WebhookDeliveryPending, delivery.NextAttemptAt).
+			Update("next_attempt_at", lease)
+		if claim.Error != nil {
+			return attempted, claim.Error
+		}
+		if claim.RowsAffected == 0 {
+			continue
+		}
+		attempted++
+
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			d.attempt(ctx, delivery)
+		}()
+	}
+	wg.Wait()
+	return attempted, nil
+}
+
+// attempt sends one delivery and records the result, scheduling a retry on failure.
+func (d *Dispatcher) attempt(ctx context.Context, delivery *model.WebhookDelivery) {
+	delivery.Attempts++
+	updates := map[string]interface{}{"attempts": delivery.Attempts}
+
+	var statusCode int
+	var sendErr error
+	if !delivery.Subscription.Active {
+		sendErr = fmt.Errorf("subscription disabled")
+	} else {
+		statusCode, sendErr = d.send(ctx, &delivery.Subscription, delivery)
+	}
+	updates["last_status_code"] = statusCode
+
+	now := time.Now()
+	switch {
+	case sendErr == nil:
+		updates["status"] = model.WebhookDeliverySucceeded
+		updates["last_error"] = ""
+		updates["delivered_at"] = now
+	case delivery.Attempts >= d.conf.MaxAttempts || !delivery.Subscription.Active:
+		updates["status"] = model.WebhookDeliveryFailed
+		updates["last_error"] = retry.Truncate(sendErr.Error())
+	default:
+		updates["last_error"] = retry.Truncate(sendErr.Error())
+		updates["next_attempt_at"] = now.Add(retry.Backoff(d.conf.BaseBackoff, d.conf.MaxBackoff, delivery.Attempts))
+	}
+
+	if err := d.db.WithContext(ctx).Model(&model.WebhookDelivery{}).Where("id = ?", delivery.ID).Updates(updates).Error; err != nil {
+		logx.WithContext(ctx).Errorf("record webhook delivery %d failed: %v", delivery.ID, err)
+	}
+	if sendErr != nil {
+		logx.WithContext(ctx).Infof("webhook delivery %d (%s) attempt %d failed: %v", delivery.ID, delivery.Event, delivery.Attempts, sendErr)
+	}
+}
+
+// send POSTs the payload and treats any 2xx response as success.
+func (d *Dispatcher) send(ctx context.Context, subscription *model.WebhookSubscription, delivery *model.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "usermgmt-webhooks/1.0")
+	req.Header.Set(HeaderEventID, delivery.EventID)
+	req.Header.Set(HeaderEventType, delivery.Event)
+	req.Header.Set(HeaderSignature, Sign(subscription.Secret, time.Now(), body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, retry.MaxErrorLength))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, snippet)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,42 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"usermgmt/internal/event"
+)
+
+// EventAll subscribes to every event, including ones added later.
+const EventAll = "*"
+
+// IsKnownEvent reports whether name is a domain event type or the wildcard.
+func IsKnownEvent(name string) bool {
+	if name == EventAll {
+		return true
+	}
+	for _, known := range event.Types {
+		if known == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether a comma-separated subscription event list includes eventType.
+func Matches(events, eventType string) bool {
+	for _, name := range strings.Split(events, ",") {
+		if name == EventAll || name == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// NewSecret generates a signing secret for a subscription.
+func NewSecret() string {
+	buf := make([]byte, 32)
+	_, _ = rand.Read(buf)
+	return "whsec_" + hex.EncodeToString(buf)
+}
@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	tests := []struct {
+		name   string
+		secret string
+		at     time.Time
+		body   string
+		want   string
+	}{
+		{
+			name:   "known vector",
+			secret: "whsec_test",
+			at:     at,
+			body:   `{"id":"evt_1"}`,
+			want:   "t=1700000000,v1=c89214b5b5da833daed6f0b8c5bb6bd58cea9022bd80ccc78230f3942d632925",
+		},
+		{
+			name: "empty secret and body",
+			at:   at,
+			want: "t=1700000000,v1=c1da1b6c6b8e9da7f4bbb90f7cab0820f271ad19ccbf80c88479c4e14f37d1c6",
+		},
+		{
+			name:   "timestamp in seconds regardless of zone",
+			secret: "whsec_test",
+			at:     at.Add(999 * time.Millisecond).In(time.FixedZone("UTC+8", 8*3600)),
+			body:   `{"id":"evt_1"}`,
+			want:   "t=1700000000,v1=c89214b5b5da833daed6f0b8c5bb6bd58cea9022bd80ccc78230f3942d632925",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sign(tt.secret, tt.at, []byte(tt.body)); got != tt.want {
+				t.Fatalf("Sign = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignCoversSecretTimestampAndBody(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	base := Sign("whsec_test", at, []byte("body"))
+	variants := map[string]string{
+		"secret":    Sign("whsec_other", at, []byte("body")),
+		"timestamp": Sign("whsec_test", at.Add(time.Second), []byte("body")),
+		"body":      Sign("whsec_test", at, []byte("body!")),
+	}
+	for changed, got := range variants {
+		if got == base {
+			t.Fatalf("signature unchanged when %s differs", changed)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"usermgmt/internal/svc"
+)
+
+// purgeInterval is how often published outbox events past their retention are deleted.
+const purgeInterval = time.Hour
+
+// OutboxRelay polls the outbox and publishes committed events to the sinks.
+type OutboxRelay struct {
+	svcCtx    *svc.ServiceContext
+	interval  time.Duration
+	lastPurge time.Time
+	done      chan struct{}
+	stopOnce  sync.Once
+}
+
+// NewOutboxRelay builds a relay worker from Outbox config.
+func NewOutboxRelay(svcCtx *svc.ServiceContext) *OutboxRelay {
+	interval := svcCtx.Config.Outbox.PollInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return &OutboxRelay{
+		svcCtx:   svcCtx,
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start blocks, publishing due events on every tick until Stop is called.
+func (r *OutboxRelay) Start() {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.publish()
+		r.purge()
+		select {
+		case <-ticker.C:
+		case <-r.done:
+			return
+		}
+	}
+}
+
+// Stop ends the loop.
+func (r *OutboxRelay) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.done)
+	})
+}
+
+func (r *OutboxRelay) publish() {
+	ctx := context.Background()
+	// Drain the backlog; attempted events are either published or pushed into the future.
+	for {
+		attempted, err := r.svcCtx.Outbox.PublishDue(ctx, time.Now())
+		if err != nil {
+			logx.WithContext(ctx).Errorf("outbox poll failed: %v", err)
+			return
+		}
+		if attempted == 0 {
+			return
+		}
+		select {
+		case <-r.done:
+			return
+		default:
+		}
+	}
+}
+
+func (r *OutboxRelay) purge() {
+	now := time.Now()
+	if now.Sub(r.lastPurge) < purgeInterval {
+		return
+	}
+	r.lastPurge = now
+	ctx := context.Background()
+	purged, err := r.svcCtx.Outbox.Purge(ctx, now)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("purge outbox failed: %v", err)
+		return
+	}
+	if purged > 0 {
+		logx.WithContext(ctx).Infof("purged %d published outbox events", purged)
+	}
+}
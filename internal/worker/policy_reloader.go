@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"usermgmt/internal/svc"
+)
+
+// PolicyReloader polls the policy file and swaps in the new rules when it changes.
+// A file that fails to parse is logged and the previous rules stay in force.
+type PolicyReloader struct {
+	svcCtx   *svc.ServiceContext
+	interval time.Duration
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewPolicyReloader builds a reloader from Policy config.
+func NewPolicyReloader(svcCtx *svc.ServiceContext) *PolicyReloader {
+	interval := svcCtx.Config.Policy.ReloadInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	return &PolicyReloader{
+		svcCtx:   svcCtx,
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start blocks, checking the file on every tick until Stop is called.
+func (p *PolicyReloader) Start() {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.reload()
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// Stop ends the reload loop.
+func (p *PolicyReloader) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.done)
+	})
+}
+
+func (p *PolicyReloader) reload() {
+	changed, err := p.svcCtx.Policy.Reload()
+	if err != nil {
+		logx.Errorf("policy reload failed, keeping version %s: %v", p.svcCtx.Policy.Version(), err)
+		return
+	}
+	if changed {
+		logx.Infof("policy reloaded, version %s", p.svcCtx.Policy.Version())
+	}
+}
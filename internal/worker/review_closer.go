@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	adminlogic "usermgmt/internal/logic/admin"
+	"usermgmt/internal/svc"
+)
+
+// ReviewCloser closes access review campaigns once their deadline has passed,
+// applying revocations (and auto-revocations) exactly as a manual sign-off would.
+type ReviewCloser struct {
+	svcCtx   *svc.ServiceContext
+	interval time.Duration
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewReviewCloser builds a closer from Reviews config.
+func NewReviewCloser(svcCtx *svc.ServiceContext) *ReviewCloser {
+	interval := svcCtx.Config.Reviews.SweepInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &ReviewCloser{
+		svcCtx:   svcCtx,
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start blocks, closing overdue campaigns on every tick until Stop is called.
+func (c *ReviewCloser) Start() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.sweep()
+		select {
+		case <-ticker.C:
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// Stop ends the loop.
+func (c *ReviewCloser) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.done)
+	})
+}
+
+func (c *ReviewCloser) sweep() {
+	ctx, cancel := context.WithTimeout(context.Background(), c.interval)
+	defer cancel()
+
+	closed, err := adminlogic.NewCloseAccessReviewLogic(ctx, c.svcCtx).CloseOverdue(time.Now())
+	if err != nil {
+		logx.WithContext(ctx).Errorf("access review sweep failed: %v", err)
+		return
+	}
+	if closed > 0 {
+		logx.WithContext(ctx).Infof("closed %d overdue access reviews", closed)
+	}
+}
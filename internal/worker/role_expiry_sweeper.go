@@ -0,0 +1,80 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	adminlogic "usermgmt/internal/logic/admin"
+	"usermgmt/internal/svc"
+)
+
+// RoleExpirySweeper periodically removes expired role grants.
+// It satisfies go-zero's service.Service so that lifecycle.Runner can run it next to the REST server.
+type RoleExpirySweeper struct {
+	svcCtx   *svc.ServiceContext
+	interval time.Duration
+	batch    int
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewRoleExpirySweeper builds a sweeper from RoleGrants config.
+func NewRoleExpirySweeper(svcCtx *svc.ServiceContext) *RoleExpirySweeper {
+	interval := svcCtx.Config.RoleGrants.SweepInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	batch := svcCtx.Config.RoleGrants.SweepBatch
+	if batch <= 0 {
+		batch = 500
+	}
+	return &RoleExpirySweeper{
+		svcCtx:   svcCtx,
+		interval: interval,
+		batch:    batch,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start blocks, sweeping on every tick until Stop is called.
+func (s *RoleExpirySweeper) Start() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.sweep()
+		select {
+		case <-ticker.C:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Stop ends the sweep loop.
+func (s *RoleExpirySweeper) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.done)
+	})
+}
+
+func (s *RoleExpirySweeper) sweep() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.interval)
+	defer cancel()
+
+	logic := adminlogic.NewExpireRoleGrantsLogic(ctx, s.svcCtx)
+	// Drain in batches so a large backlog is cleared within one tick.
+	for {
+		removed, err := logic.Sweep(time.Now(), s.batch)
+		if err != nil {
+			logx.WithContext(ctx).Errorf("role expiry sweep failed: %v", err)
+			return
+		}
+		if removed < s.batch {
+			return
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"usermgmt/internal/svc"
+)
+
+// WebhookSender polls for due webhook deliveries and sends them.
+type WebhookSender struct {
+	svcCtx   *svc.ServiceContext
+	interval time.Duration
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewWebhookSender builds a sender from Webhooks config.
+func NewWebhookSender(svcCtx *svc.ServiceContext) *WebhookSender {
+	interval := svcCtx.Config.Webhooks.PollInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	return &WebhookSender{
+		svcCtx:   svcCtx,
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start blocks, sending due deliveries on every tick until Stop is called.
+func (s *WebhookSender) Start() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.send()
+		select {
+		case <-ticker.C:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Stop ends the loop.
+func (s *WebhookSender) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.done)
+	})
+}
+
+func (s *WebhookSender) send() {
+	ctx := context.Background()
+	// Keep draining until nothing is due so a backlog is not limited to one batch per tick.
+	// Attempted deliveries either finish or move their next attempt into the future.
+	for {
+		attempted, err := s.svcCtx.Webhooks.DeliverDue(ctx, time.Now())
+		if err != nil {
+			logx.WithContext(ctx).Errorf("webhook delivery poll failed: %v", err)
+			return
+		}
+		if attempted == 0 {
+			return
+		}
+		select {
+		case <-s.done:
+			return
+		default:
+		}
+	}
+}
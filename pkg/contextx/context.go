@@ -9,7 +9,9 @@ import (
 type contextKey string
 
 const (
-	claimsKey contextKey = "authClaims"
+	claimsKey  contextKey = "authClaims"
+	tenantKey  contextKey = "tenant"
+	requestKey contextKey = "request"
 )
 
 // WithClaims stores JWT claims into context.
@@ -34,3 +36,52 @@ func MustGetClaims(ctx context.Context) *types.JwtClaims {
 	claims, _ := ClaimsFromContext(ctx)
 	return claims
 }
+
+// WithTenant stores the resolved organisation scope into context.
+func WithTenant(ctx context.Context, tenant *types.Tenant) context.Context {
+	if tenant == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, tenantKey, tenant)
+}
+
+// TenantFromContext returns the organisation scope, or nil when the request is global.
+func TenantFromContext(ctx context.Context) *types.Tenant {
+	if ctx == nil {
+		return nil
+	}
+	tenant, _ := ctx.Value(tenantKey).(*types.Tenant)
+	return tenant
+}
+
+// RequestInfo describes the request being served. It is shared by pointer, so middlewares further
+// down the chain can fill in what they learn (e.g. the user ID) for the access log.
+type RequestInfo struct {
+	ID     string
+	UserID uint
+}
+
+// WithRequestInfo stores the request info into context.
+func WithRequestInfo(ctx context.Context, info *RequestInfo) context.Context {
+	if info == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, requestKey, info)
+}
+
+// RequestInfoFromContext returns the request info, or nil outside of a request.
+func RequestInfoFromContext(ctx context.Context) *RequestInfo {
+	if ctx == nil {
+		return nil
+	}
+	info, _ := ctx.Value(requestKey).(*RequestInfo)
+	return info
+}
+
+// RequestIDFromContext returns the request ID, or "" outside of a request.
+func RequestIDFromContext(ctx context.Context) string {
+	if info := RequestInfoFromContext(ctx); info != nil {
+		return info.ID
+	}
+	return ""
+}
@@ -0,0 +1,124 @@
+package response
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	"usermgmt/pkg/contextx"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details.
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details object. Code, RequestID and Details are extension members
+// carrying the same values as ErrorBody, so clients can switch formats without losing anything.
+type Problem struct {
+	Type      string      `json:"type"`
+	Title     string      `json:"title"`
+	Status    int         `json:"status"`
+	Detail    string      `json:"detail,omitempty"`
+	Instance  string      `json:"instance,omitempty"`
+	Code      string      `json:"code"`
+	RequestID string      `json:"requestId,omitempty"`
+	Details   interface{} `json:"details,omitempty"`
+}
+
+// ProblemOptions selects when Error writes problem details.
+type ProblemOptions struct {
+	// Always writes every error as problem details; otherwise only requests that accept
+	// application/problem+json get them.
+	Always bool
+	// TypeBase is prefixed to the error code to form the problem type URI, e.g.
+	// https://docs.example.com/errors/ gives https://docs.example.com/errors/USER_EXISTS. Without
+	// it the type is about:blank.
+	TypeBase string
+}
+
+// ErrorWriter writes error responses in the format its ProblemOptions select.
+type ErrorWriter struct {
+	opts ProblemOptions
+}
+
+// NewErrorWriter returns an error writer for opts.
+func NewErrorWriter(opts ProblemOptions) *ErrorWriter {
+	return &ErrorWriter{opts: opts}
+}
+
+// Write writes a standardized error response body with code and message, tagged with the request
+// ID so that a client report can be matched with the server logs. The body is an RFC 7807 problem
+// instead when configured or asked for. Message and details are written as given; translating
+// them is up to the caller.
+func (e *ErrorWriter) Write(w http.ResponseWriter, r *http.Request, status int, code, message string, details interface{}) {
+	requestID := contextx.RequestIDFromContext(r.Context())
+	if e.wantsProblem(r) {
+		writeProblem(w, r, e.newProblem(r, status, code, message, details, requestID))
+		return
+	}
+	httpx.WriteJsonCtx(r.Context(), w, status, ErrorBody{
+		Code:      code,
+		Message:   message,
+		Details:   details,
+		RequestID: requestID,
+	})
+}
+
+func (e *ErrorWriter) newProblem(r *http.Request, status int, code, message string, details interface{}, requestID string) Problem {
+	problemType := "about:blank"
+	if e.opts.TypeBase != "" {
+		problemType = e.opts.TypeBase + code
+	}
+	title := http.StatusText(status)
+	if title == "" {
+		// E.g. 499, which has no standard reason phrase.
+		title = message
+	}
+	return Problem{
+		Type:      problemType,
+		Title:     title,
+		Status:    status,
+		Detail:    message,
+		Instance:  r.URL.Path,
+		Code:      code,
+		RequestID: requestID,
+		Details:   details,
+	}
+}
+
+// wantsProblem reports whether the error for r is written as problem details: always when
+// configured, otherwise when the Accept header lists application/problem+json with a non-zero q.
+func (e *ErrorWriter) wantsProblem(r *http.Request) bool {
+	if e.opts.Always {
+		return true
+	}
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil || mediaType != ProblemContentType {
+			continue
+		}
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q <= 0 {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func writeProblem(w http.ResponseWriter, r *http.Request, problem Problem) {
+	body, err := json.Marshal(problem)
+	if err != nil {
+		logx.WithContext(r.Context()).Errorf("encode problem details failed: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
+	if _, err := w.Write(body); err != nil {
+		logx.WithContext(r.Context()).Errorf("write problem details failed: %v", err)
+	}
+}
@@ -9,8 +9,8 @@ import (
 	"usermgmt/internal/types"
 )
 
-// GenerateToken builds a signed JWT token for the current user.
-func GenerateToken(userID uint, roles []string, secret string, expireSeconds time.Duration) (string, time.Time, error) {
+// GenerateToken signs claims (user, roles, per-role expiry, org) after stamping issue and expiry times.
+func GenerateToken(claims types.JwtClaims, secret string, expireSeconds time.Duration) (string, time.Time, error) {
 	if secret == "" {
 		return "", time.Time{}, errors.New("jwt secret missing")
 	}
@@ -19,13 +19,9 @@ func GenerateToken(userID uint, roles []string, secret string, expireSeconds tim
 	}
 
 	expireAt := time.Now().Add(expireSeconds)
-	claims := types.JwtClaims{
-		UserID: userID,
-		Roles:  roles,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expireAt),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(expireAt),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -25,3 +25,15 @@ func VerifyPassword(hashed, password string) error {
 	}
 	return bcrypt.CompareHashAndPassword([]byte(hashed), []byte(password))
 }
+
+// UnusablePasswordHash marks accounts that cannot log in with a password until one is set.
+const UnusablePasswordHash = "!"
+
+// IsBcryptHash reports whether hashed is a well-formed bcrypt hash.
+func IsBcryptHash(hashed string) bool {
+	if len(hashed) != 60 {
+		return false
+	}
+	_, err := bcrypt.Cost([]byte(hashed))
+	return err == nil
+}